@@ -0,0 +1,81 @@
+// Command typetalk-alertmanager is an Alertmanager webhook receiver that posts
+// alerts to Typetalk topics.
+//
+//	typetalk-alertmanager -addr :9095 -config alertmanager.json
+//
+// The configuration file looks like this:
+//
+//	{
+//	  "token": "Typetalk Token of the bot",
+//	  "routes": [{"match": {"team": "api"}, "topicId": 123}],
+//	  "defaultTopicId": 456,
+//	  "template": "message.tmpl",
+//	  "talks": true,
+//	  "store": "incidents.json"
+//	}
+//
+// The token can also be given by the TYPETALK_TOKEN environment variable.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/nulab/go-typetalk/v3/typetalk/alertmanager"
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+)
+
+type config struct {
+	Token          string                `json:"token"`
+	Routes         []*alertmanager.Route `json:"routes"`
+	DefaultTopicID int                   `json:"defaultTopicId"`
+	Template       string                `json:"template"`
+	Talks          bool                  `json:"talks"`
+	Store          string                `json:"store"`
+}
+
+func main() {
+	addr := flag.String("addr", ":9095", "address to listen on")
+	configPath := flag.String("config", "alertmanager.json", "path to the configuration file")
+	flag.Parse()
+
+	b, err := ioutil.ReadFile(*configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	c := &config{}
+	if err := json.Unmarshal(b, c); err != nil {
+		log.Fatal(err)
+	}
+	if token := os.Getenv("TYPETALK_TOKEN"); token != "" {
+		c.Token = token
+	}
+
+	opt := &alertmanager.Options{
+		Routes:         c.Routes,
+		DefaultTopicID: c.DefaultTopicID,
+		Talks:          c.Talks,
+	}
+	if c.Template != "" {
+		text, err := ioutil.ReadFile(c.Template)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if opt.Template, err = alertmanager.NewTemplate(c.Template, string(text)); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if c.Store != "" {
+		if opt.Store, err = alertmanager.OpenFileStore(c.Store); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	client := v1.NewClient(nil).SetTypetalkToken(c.Token)
+	log.Printf("listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, alertmanager.NewReceiver(client, opt)))
+}
@@ -0,0 +1,144 @@
+// Command typetalk-audit reports who has access to which topic, as a matrix
+// of topics and accounts, groups and invitations.
+//
+//	typetalk-audit -snapshot 2020-q2.json -previous 2020-q1.json -csv access.csv -html access.html
+//
+// Access that is not in the previous snapshot is marked as new, and access
+// of the previous snapshot that is gone as removed. Keep the snapshot to
+// compare it with the next audit. All the spaces of the user are audited
+// unless -space is given; the user should be a member of every topic. The
+// Typetalk Token is read from the TYPETALK_TOKEN environment variable.
+//
+// The Typetalk API doesn't list the accounts of a group. With -groups, the
+// accounts of the member groups of a topic are listed as well, from a JSON
+// file of the names of the accounts of each group key:
+//
+//	{"sre": ["alice", "bob"]}
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/nulab/go-typetalk/v3/typetalk/audit"
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+	v2 "github.com/nulab/go-typetalk/v3/typetalk/v2"
+)
+
+func main() {
+	spaces := flag.String("space", "", "comma separated keys of the spaces to audit")
+	snapshotPath := flag.String("snapshot", "", "path the snapshot is written to")
+	previousPath := flag.String("previous", "", "path to the previous snapshot")
+	csvPath := flag.String("csv", "", "path the CSV report is written to")
+	htmlPath := flag.String("html", "", "path the HTML report is written to")
+	groupsPath := flag.String("groups", "", "path to the JSON file of the account names of each group")
+	flag.Parse()
+
+	var previous *audit.Snapshot
+	if *previousPath != "" {
+		f, err := os.Open(*previousPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		previous, err = audit.ReadSnapshot(f)
+		f.Close()
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		cancel()
+	}()
+
+	token := os.Getenv("TYPETALK_TOKEN")
+	opt := &audit.Options{}
+	if *spaces != "" {
+		opt.Spaces = strings.Split(*spaces, ",")
+	}
+	clientV1 := v1.NewClient(nil).SetTypetalkToken(token)
+	if *groupsPath != "" {
+		b, err := ioutil.ReadFile(*groupsPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		groups := map[string][]string{}
+		if err := json.Unmarshal(b, &groups); err != nil {
+			log.Fatalf("%s: %v", *groupsPath, err)
+		}
+		opt.GroupMembers = groupMembers(clientV1, groups)
+	}
+	a := audit.New(clientV1, v2.NewClient(nil).SetTypetalkToken(token), opt)
+	snapshot, err := a.Snapshot(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	report := audit.Compare(previous, snapshot)
+
+	write := func(path string, f func(*os.File) error) {
+		if path == "" {
+			return
+		}
+		out, err := os.Create(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := f(out); err != nil {
+			log.Fatal(err)
+		}
+		if err := out.Close(); err != nil {
+			log.Fatal(err)
+		}
+	}
+	write(*snapshotPath, func(f *os.File) error { return snapshot.WriteJSON(f) })
+	write(*htmlPath, func(f *os.File) error { return report.WriteHTML(f) })
+	if *csvPath == "" && *htmlPath == "" {
+		if err := report.WriteCSV(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+	}
+	write(*csvPath, func(f *os.File) error { return report.WriteCSV(f) })
+	log.Printf("%d accesses, %d new, %d removed", len(snapshot.Entries), len(report.Added), len(report.Removed))
+}
+
+// groupMembers returns the accounts of the groups by their names. A name
+// that is not a member of the space is an error, rather than a guest of the
+// audit, as it is likely a typo of the file.
+func groupMembers(client *v1.Client, groups map[string][]string) func(context.Context, string, *v1.Group) ([]*v1.Account, error) {
+	spaces := map[string]map[string]*v1.Account{}
+	return func(ctx context.Context, spaceKey string, group *v1.Group) ([]*v1.Account, error) {
+		members, ok := spaces[spaceKey]
+		if !ok {
+			m, _, err := client.Organizations.GetOrganizationMembers(ctx, spaceKey)
+			if err != nil {
+				return nil, err
+			}
+			members = map[string]*v1.Account{}
+			for _, account := range m.Accounts {
+				members[account.Name] = account
+			}
+			spaces[spaceKey] = members
+		}
+		var accounts []*v1.Account
+		for _, name := range groups[group.Key] {
+			account, ok := members[name]
+			if !ok {
+				return nil, fmt.Errorf("%s is not a member of the space %s", name, spaceKey)
+			}
+			accounts = append(accounts, account)
+		}
+		return accounts, nil
+	}
+}
@@ -0,0 +1,144 @@
+// Command typetalk-bulk applies an action to all the messages matching a
+// search.
+//
+//	typetalk-bulk -space SPACEKEY -q "password" -topic 123 -dry-run delete
+//	typetalk-bulk -space SPACEKEY -q "incident 42" -from 2020-01-01 talk 123 456
+//	typetalk-bulk -space SPACEKEY -q "release" like
+//	typetalk-bulk -space SPACEKEY -q "release" -o posts.json export
+//
+// Actions are delete, like, unlike, talk TOPIC_ID TALK_ID and export. The
+// matches are counted and confirmed on the terminal before the action is
+// applied, unless -yes is given. The Typetalk Token is read from the
+// TYPETALK_TOKEN environment variable.
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nulab/go-typetalk/v3/typetalk/bulk"
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+	v2 "github.com/nulab/go-typetalk/v3/typetalk/v2"
+)
+
+type ints []int
+
+func (i *ints) String() string {
+	return fmt.Sprint(*i)
+}
+
+func (i *ints) Set(s string) error {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+	*i = append(*i, n)
+	return nil
+}
+
+func main() {
+	spaceKey := flag.String("space", "", "space key")
+	q := flag.String("q", "", "search query")
+	from := flag.String("from", "", "search posts since this date (2006-01-02)")
+	to := flag.String("to", "", "search posts until this date (2006-01-02)")
+	var topicIDs, accountIDs ints
+	flag.Var(&topicIDs, "topic", "search in this topic (repeatable)")
+	flag.Var(&accountIDs, "account", "search posts of this account (repeatable)")
+	dryRun := flag.Bool("dry-run", false, "only report the posts the action would apply to")
+	yes := flag.Bool("yes", false, "apply the action without confirmation")
+	concurrency := flag.Int("concurrency", 4, "number of requests made at the same time")
+	output := flag.String("o", "", "file to export to (standard output by default)")
+	flag.Parse()
+	if *spaceKey == "" || *q == "" || flag.NArg() == 0 {
+		log.Fatal("usage: typetalk-bulk -space key -q query [options] delete|like|unlike|talk|export")
+	}
+
+	search := &v2.SearchMessagesOptions{TopicIDs: topicIDs, AccountIDs: accountIDs}
+	if *from != "" {
+		t, err := time.ParseInLocation("2006-01-02", *from, time.Local)
+		if err != nil {
+			log.Fatal(err)
+		}
+		search.From = &t
+	}
+	if *to != "" {
+		t, err := time.ParseInLocation("2006-01-02", *to, time.Local)
+		if err != nil {
+			log.Fatal(err)
+		}
+		search.To = &t
+	}
+
+	token := os.Getenv("TYPETALK_TOKEN")
+	opt := &bulk.Options{DryRun: *dryRun, Concurrency: *concurrency}
+	if !*yes {
+		opt.Confirm = confirm
+	}
+	b := bulk.New(v1.NewClient(nil).SetTypetalkToken(token), v2.NewClient(nil).SetTypetalkToken(token), opt)
+
+	ctx := context.Background()
+	posts, err := b.Search(ctx, *spaceKey, *q, search)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var result *bulk.Result
+	switch action := flag.Arg(0); action {
+	case "delete":
+		result, err = b.Delete(ctx, posts)
+	case "like":
+		result, err = b.Like(ctx, posts)
+	case "unlike":
+		result, err = b.Unlike(ctx, posts)
+	case "talk":
+		topicID, err1 := strconv.Atoi(flag.Arg(1))
+		talkID, err2 := strconv.Atoi(flag.Arg(2))
+		if err1 != nil || err2 != nil {
+			log.Fatal("usage: typetalk-bulk ... talk TOPIC_ID TALK_ID")
+		}
+		result, err = b.AddToTalk(ctx, posts, topicID, talkID)
+	case "export":
+		var w io.Writer = os.Stdout
+		if *output != "" {
+			f, err := os.Create(*output)
+			if err != nil {
+				log.Fatal(err)
+			}
+			defer f.Close()
+			w = f
+		}
+		result, err = b.Export(ctx, posts, w)
+	default:
+		log.Fatalf("unknown action %q", action)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	verb := "applied to"
+	if result.DryRun {
+		verb = "would apply to"
+	}
+	fmt.Fprintf(os.Stderr, "%s %s %d posts\n", result.Action, verb, len(result.Done))
+	for _, id := range result.Failed() {
+		fmt.Fprintf(os.Stderr, "post %d: %v\n", id, result.Errors[id])
+	}
+	if len(result.Errors) > 0 {
+		os.Exit(1)
+	}
+}
+
+func confirm(action string, posts []*v2.Post) bool {
+	fmt.Fprintf(os.Stderr, "%s %d posts? [y/N] ", action, len(posts))
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,70 @@
+// Command typetalk-drift reports the drift between JSON responses of the
+// Typetalk API and the models of this library: the JSON the models drop,
+// the JSON kept untyped in interface{} fields and the fields the JSON never
+// sets.
+//
+//	typetalk-drift -testdata testdata
+//	typetalk-drift -endpoint v1/get-my-profile response.json
+//
+// Without -endpoint, the fixtures of all the endpoints under the testdata
+// directory are checked. The command exits with status 1 when it finds
+// drift.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/nulab/go-typetalk/v3/typetalk/drift"
+)
+
+func main() {
+	testdata := flag.String("testdata", "testdata", "directory of the fixtures")
+	endpoint := flag.String("endpoint", "", "fixture name of the endpoint of the files, such as v1/get-my-profile")
+	flag.Parse()
+
+	found := false
+	check := func(name, path string, e *drift.Endpoint) {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		report, err := drift.Check(data, e.New())
+		if err != nil {
+			log.Fatalf("%s: %v", name, err)
+		}
+		if !report.Empty() {
+			found = true
+			fmt.Printf("# %s\n%s\n", name, report)
+		}
+	}
+
+	if *endpoint == "" {
+		if flag.NArg() != 0 {
+			log.Fatal("usage: typetalk-drift [-testdata dir] | -endpoint name file...")
+		}
+		for _, e := range drift.Endpoints {
+			check(e.Fixture, filepath.Join(*testdata, e.Fixture+".json"), e)
+		}
+	} else {
+		var e *drift.Endpoint
+		for _, c := range drift.Endpoints {
+			if c.Fixture == *endpoint {
+				e = c
+			}
+		}
+		if e == nil || flag.NArg() == 0 {
+			log.Fatal("usage: typetalk-drift -endpoint name file...")
+		}
+		for _, path := range flag.Args() {
+			check(path, path, e)
+		}
+	}
+	if found {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,131 @@
+// Command typetalk-membership keeps the members of topics in sync with a
+// YAML file kept in version control, and adds people to or removes them
+// from all the topics of a space.
+//
+//	typetalk-membership plan -config members.yaml
+//	typetalk-membership apply -config members.yaml
+//	typetalk-membership onboard -space abcdefghij -account 123 -role Member -groups sre -rules rules.yaml -dry-run
+//	typetalk-membership offboard -space abcdefghij -account 123
+//
+// plan prints the changes that apply would make. Its exit status suits CI:
+// 0 when the topics are in sync, 2 when there are changes and 1 on errors,
+// including unknown accounts or groups. The other commands exit with 0 or
+// 1.
+//
+// onboard and offboard only see the topics the user of the token belongs
+// to. The Typetalk Token of a topic admin is read from the TYPETALK_TOKEN
+// environment variable.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/nulab/go-typetalk/v3/typetalk/membership"
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+	v2 "github.com/nulab/go-typetalk/v3/typetalk/v2"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: typetalk-membership plan|apply|onboard|offboard ...")
+	os.Exit(1)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		cancel()
+	}()
+
+	token := os.Getenv("TYPETALK_TOKEN")
+	client := v1.NewClient(nil).SetTypetalkToken(token)
+	cmd, args := os.Args[1], os.Args[2:]
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	switch cmd {
+	case "plan", "apply":
+		configPath := fs.String("config", "members.yaml", "path to the desired membership")
+		fs.Parse(args)
+		os.Exit(sync(ctx, client, cmd == "apply", *configPath))
+	case "onboard", "offboard":
+		spaceKey := fs.String("space", "", "key of the space")
+		accountID := fs.Int("account", 0, "ID of the account")
+		dryRun := fs.Bool("dry-run", false, "only report the topics that would change")
+		rulesPath := fs.String("rules", "onboarding.yaml", "path to the rules selecting the topics (onboard)")
+		role := fs.String("role", "", "role of the account in the space, matched by rules with a role (onboard)")
+		groups := fs.String("groups", "", "comma separated keys of the groups of the account, matched by rules with a group (onboard)")
+		fs.Parse(args)
+		if *spaceKey == "" || *accountID == 0 {
+			log.Fatalf("usage: typetalk-membership %s -space key -account id [-dry-run]", cmd)
+		}
+		b := membership.NewBoarding(client, v2.NewClient(nil).SetTypetalkToken(token), &membership.BoardingOptions{DryRun: *dryRun})
+		var report *membership.BoardingReport
+		var err error
+		if cmd == "onboard" {
+			var rules *membership.Rules
+			if rules, err = membership.LoadRules(*rulesPath); err != nil {
+				log.Fatal(err)
+			}
+			if *role != "" {
+				rules.Role = *role
+			}
+			if *groups != "" {
+				rules.Groups = strings.Split(*groups, ",")
+			}
+			report, err = b.Onboard(ctx, *spaceKey, *accountID, rules)
+		} else {
+			report, err = b.Offboard(ctx, *spaceKey, *accountID)
+		}
+		if report != nil {
+			report.Write(os.Stdout)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if report.Failed() {
+			os.Exit(1)
+		}
+	default:
+		usage()
+	}
+}
+
+// sync prints the plan of the desired membership in configPath, applies it
+// if apply is set, and returns the exit status.
+func sync(ctx context.Context, client *v1.Client, apply bool, configPath string) int {
+	config, err := membership.LoadConfig(configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	s := membership.NewSyncer(client, config)
+	plan, err := s.Plan(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := plan.Write(os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+	switch {
+	case plan.HasErrors():
+		return 1
+	case apply:
+		if err := s.Apply(ctx, plan); err != nil {
+			log.Fatal(err)
+		}
+	case plan.HasChanges():
+		return 2
+	}
+	return 0
+}
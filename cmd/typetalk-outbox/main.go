@@ -0,0 +1,117 @@
+// Command typetalk-outbox queues posts in a durable outbox and delivers them
+// to Typetalk, retrying while Typetalk is unreachable.
+//
+//	typetalk-outbox -dir /var/spool/typetalk send -topic 123 -file log.txt "Deployed v1.2.3"
+//	typetalk-outbox -dir /var/spool/typetalk flush
+//	typetalk-outbox -dir /var/spool/typetalk run -interval 30s
+//	typetalk-outbox -dir /var/spool/typetalk status
+//	typetalk-outbox -dir /var/spool/typetalk list -state dead
+//	typetalk-outbox -dir /var/spool/typetalk retry 000000000042
+//
+// send enqueues a post and tries to deliver the pending posts once; posts
+// that can't be delivered yet stay in the outbox for the next flush or run.
+// The Typetalk Token is read from the TYPETALK_TOKEN environment variable.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/nulab/go-typetalk/v3/typetalk/outbox"
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+)
+
+type files []string
+
+func (f *files) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *files) Set(s string) error {
+	*f = append(*f, s)
+	return nil
+}
+
+func main() {
+	dir := flag.String("dir", "outbox", "directory the outbox is stored in")
+	flag.Parse()
+	if flag.NArg() == 0 {
+		log.Fatal("usage: typetalk-outbox [-dir dir] send|flush|run|status|list|retry ...")
+	}
+
+	client := v1.NewClient(nil).SetTypetalkToken(os.Getenv("TYPETALK_TOKEN"))
+	o, err := outbox.Open(*dir, client, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		cancel()
+	}()
+
+	cmd, args := flag.Arg(0), flag.Args()[1:]
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	switch cmd {
+	case "send":
+		topicID := fs.Int("topic", 0, "topic to post to")
+		replyTo := fs.Int("reply-to", 0, "post to reply to")
+		var attachments files
+		fs.Var(&attachments, "file", "file to attach (repeatable)")
+		fs.Parse(args)
+		if *topicID == 0 || fs.NArg() != 1 {
+			log.Fatal("usage: typetalk-outbox send -topic id [-reply-to id] [-file name]... message")
+		}
+		e, err := o.Enqueue(*topicID, fs.Arg(0), &outbox.PostOptions{ReplyTo: *replyTo, Files: attachments})
+		if err != nil {
+			log.Fatal(err)
+		}
+		o.Flush(ctx)
+		printJSON(o.Get(e.ID))
+	case "flush":
+		fs.Parse(args)
+		sent, err := o.Flush(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("sent %d\n", sent)
+	case "run":
+		interval := fs.Duration("interval", 30*time.Second, "interval between flushes")
+		fs.Parse(args)
+		if err := o.Run(ctx, *interval); err != nil && err != context.Canceled {
+			log.Fatal(err)
+		}
+	case "status":
+		fs.Parse(args)
+		printJSON(o.Status())
+	case "list":
+		state := fs.String("state", "", "list only the entries in this state (pending, sent or dead)")
+		fs.Parse(args)
+		printJSON(o.Entries(*state))
+	case "retry":
+		fs.Parse(args)
+		for _, id := range fs.Args() {
+			if err := o.Retry(id); err != nil {
+				log.Fatal(err)
+			}
+		}
+	default:
+		log.Fatalf("unknown command %q", cmd)
+	}
+}
+
+func printJSON(v interface{}) {
+	b, _ := json.MarshalIndent(v, "", "  ")
+	fmt.Println(string(b))
+}
@@ -0,0 +1,96 @@
+// Command typetalk-provision creates the topics of a project from a template,
+// or clones an existing topic.
+//
+//	typetalk-provision apply -spec project.yaml -var project=apollo
+//	typetalk-provision clone -from 123 gemini-dev
+//
+// apply creates the topics of the template that don't exist and adds the
+// missing members and talks to the others. ${name} in the template is
+// replaced with the value given by -var name=value. The Typetalk Token is
+// read from the TYPETALK_TOKEN environment variable. The results are
+// written to standard output as JSON.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/nulab/go-typetalk/v3/typetalk/provision"
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+	v2 "github.com/nulab/go-typetalk/v3/typetalk/v2"
+)
+
+type vars map[string]string
+
+func (v vars) String() string {
+	return fmt.Sprint(map[string]string(v))
+}
+
+func (v vars) Set(s string) error {
+	i := strings.Index(s, "=")
+	if i <= 0 {
+		return fmt.Errorf("%q is not name=value", s)
+	}
+	v[s[:i]] = s[i+1:]
+	return nil
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatal("usage: typetalk-provision apply|clone ...")
+	}
+	token := os.Getenv("TYPETALK_TOKEN")
+	p := provision.New(v1.NewClient(nil).SetTypetalkToken(token), v2.NewClient(nil).SetTypetalkToken(token), nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		cancel()
+	}()
+
+	cmd, args := os.Args[1], os.Args[2:]
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	switch cmd {
+	case "apply":
+		specPath := fs.String("spec", "provision.yaml", "path to the template")
+		v := vars{}
+		fs.Var(v, "var", "name=value replacing ${name} in the template; can be repeated")
+		fs.Parse(args)
+		spec, err := provision.LoadSpec(*specPath, v)
+		if err != nil {
+			log.Fatal(err)
+		}
+		results, err := p.Provision(ctx, spec)
+		printJSON(results)
+		if err != nil {
+			log.Fatal(err)
+		}
+	case "clone":
+		from := fs.Int("from", 0, "topic to clone")
+		fs.Parse(args)
+		if *from == 0 || fs.NArg() != 1 {
+			log.Fatal("usage: typetalk-provision clone -from id name")
+		}
+		result, err := p.CloneTopic(ctx, *from, fs.Arg(0))
+		printJSON(result)
+		if err != nil {
+			log.Fatal(err)
+		}
+	default:
+		log.Fatalf("unknown command %q", cmd)
+	}
+}
+
+func printJSON(v interface{}) {
+	out, _ := json.MarshalIndent(v, "", "  ")
+	os.Stdout.Write(append(out, '\n'))
+}
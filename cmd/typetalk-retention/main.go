@@ -0,0 +1,84 @@
+// Command typetalk-retention deletes the messages of topics that are older
+// than their retention policy.
+//
+//	typetalk-retention -config retention.json -dry-run
+//	typetalk-retention -config retention.json -progress progress.json -audit deleted.jsonl
+//
+// The configuration file lists the policies:
+//
+//	{
+//	  "token": "Typetalk Token of a topic admin",
+//	  "policies": [{"topicId": 123, "maxAgeDays": 90, "keepTalks": true, "keepLiked": false}]
+//	}
+//
+// The token can also be given by the TYPETALK_TOKEN environment variable.
+// The report is written to standard output as JSON.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/nulab/go-typetalk/v3/typetalk/retention"
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+)
+
+type config struct {
+	Token    string              `json:"token"`
+	Policies []*retention.Policy `json:"policies"`
+}
+
+func main() {
+	configPath := flag.String("config", "retention.json", "path to the configuration file")
+	dryRun := flag.Bool("dry-run", false, "only report the posts that would be deleted")
+	progressPath := flag.String("progress", "retention-progress.json", "path to the file keeping the progress")
+	auditPath := flag.String("audit", "retention-audit.jsonl", "path to the audit log the deleted posts are appended to")
+	flag.Parse()
+
+	b, err := ioutil.ReadFile(*configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	c := &config{}
+	if err := json.Unmarshal(b, c); err != nil {
+		log.Fatal(err)
+	}
+	if token := os.Getenv("TYPETALK_TOKEN"); token != "" {
+		c.Token = token
+	}
+
+	opt := &retention.Options{Policies: c.Policies, DryRun: *dryRun}
+	if !*dryRun {
+		if opt.Progress, err = retention.OpenFileProgress(*progressPath); err != nil {
+			log.Fatal(err)
+		}
+		audit, err := os.OpenFile(*auditPath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer audit.Close()
+		opt.AuditLog = audit
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		cancel()
+	}()
+
+	client := v1.NewClient(nil).SetTypetalkToken(c.Token)
+	report, err := retention.NewEnforcer(client, opt).Enforce(ctx)
+	out, _ := json.MarshalIndent(report, "", "  ")
+	os.Stdout.Write(append(out, '\n'))
+	if err != nil {
+		log.Fatal(err)
+	}
+}
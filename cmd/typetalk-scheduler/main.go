@@ -0,0 +1,128 @@
+// Command typetalk-scheduler posts scheduled and recurring messages to
+// Typetalk topics.
+//
+//	typetalk-scheduler -store schedules.json add -topic 123 -cron "55 9 * * MON-FRI" "Standup in 5 minutes"
+//	typetalk-scheduler -store schedules.json add -topic 123 -at "2020-04-01 10:00" -tz Asia/Tokyo "Release day"
+//	typetalk-scheduler -store schedules.json list
+//	typetalk-scheduler -store schedules.json cancel 0123456789abcdef
+//	typetalk-scheduler -store schedules.json run -catch-up once
+//
+// Schedules added while run is running are picked up at its next check.
+// Cron expressions and times without an offset are evaluated in the time
+// zone given by -tz, or in the time zone of the account of the token. The Typetalk Token is read from the
+// TYPETALK_TOKEN environment variable.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"text/tabwriter"
+	"time"
+
+	"github.com/nulab/go-typetalk/v3/typetalk/scheduler"
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+)
+
+func main() {
+	storePath := flag.String("store", "schedules.json", "path to the schedules file")
+	flag.Parse()
+	if flag.NArg() == 0 {
+		log.Fatal("usage: typetalk-scheduler [-store file] add|list|cancel|run ...")
+	}
+
+	client := v1.NewClient(nil).SetTypetalkToken(os.Getenv("TYPETALK_TOKEN"))
+	opt := &scheduler.Options{Store: scheduler.NewFileStore(*storePath)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		cancel()
+	}()
+
+	cmd, args := flag.Arg(0), flag.Args()[1:]
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	switch cmd {
+	case "add":
+		topicID := fs.Int("topic", 0, "topic to post to")
+		at := fs.String("at", "", `time of a one-shot post, "2006-01-02 15:04" or RFC 3339`)
+		cron := fs.String("cron", "", "cron expression of a recurring post")
+		tz := fs.String("tz", "", "time zone, such as Asia/Tokyo")
+		catchUp := fs.String("catch-up", "", "catch-up policy of the schedule: skip, once or all")
+		fs.Parse(args)
+		if fs.NArg() != 1 {
+			log.Fatal("usage: typetalk-scheduler add -topic id (-at time | -cron expr) [-tz zone] [-catch-up policy] message")
+		}
+		sched := &scheduler.Schedule{TopicID: *topicID, Message: fs.Arg(0), Cron: *cron, Timezone: *tz, CatchUp: *catchUp}
+		if *at != "" {
+			t, err := parseTime(ctx, client, *at, *tz)
+			if err != nil {
+				log.Fatal(err)
+			}
+			sched.At = t
+		}
+		added, err := scheduler.New(client, opt).Add(ctx, sched)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("%s next at %s\n", added.ID, added.Next.Format(time.RFC3339))
+	case "list":
+		fs.Parse(args)
+		schedules, err := scheduler.New(client, opt).List()
+		if err != nil {
+			log.Fatal(err)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tTOPIC\tWHEN\tNEXT\tMESSAGE")
+		for _, s := range schedules {
+			when := s.Cron + " " + s.Timezone
+			if s.Cron == "" {
+				when = "once"
+			}
+			fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\n", s.ID, s.TopicID, when, s.Next.Format(time.RFC3339), s.Message)
+		}
+		w.Flush()
+	case "cancel":
+		fs.Parse(args)
+		s := scheduler.New(client, opt)
+		for _, id := range fs.Args() {
+			if err := s.Cancel(id); err != nil {
+				log.Fatal(err)
+			}
+		}
+	case "run":
+		interval := fs.Duration("interval", 30*time.Second, "interval between checks")
+		fs.StringVar(&opt.CatchUp, "catch-up", scheduler.CatchUpOnce, "default catch-up policy: skip, once or all")
+		fs.DurationVar(&opt.Grace, "grace", 5*time.Minute, "how late a run may be and still count as on time")
+		fs.Parse(args)
+		if err := scheduler.New(client, opt).Run(ctx, *interval); err != nil && err != context.Canceled {
+			log.Fatal(err)
+		}
+	default:
+		log.Fatalf("unknown command %q", cmd)
+	}
+}
+
+func parseTime(ctx context.Context, client *v1.Client, s, tz string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if tz == "" {
+		profile, _, err := client.Accounts.GetMyProfile(ctx)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("getting the time zone of the account: %v", err)
+		}
+		tz = scheduler.AccountTimezone(profile.Account)
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.ParseInLocation("2006-01-02 15:04", s, loc)
+}
@@ -0,0 +1,49 @@
+// Command typetalk-slack-webhook accepts Slack incoming webhook payloads and
+// posts them to Typetalk topics.
+//
+//	typetalk-slack-webhook -addr :8080 -config webhook.json
+//	typetalk-slack-webhook -new-secret
+//
+// The configuration file lists the topics, the Typetalk Tokens used to post
+// to them and the secrets of their URLs, which -new-secret generates:
+//
+//	{"topics": [{"topicId": 123, "token": "...", "path": "hooks/deploy", "secret": "..."}]}
+//
+// The payloads of this topic are posted to /hooks/deploy/<secret>.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/nulab/go-typetalk/v3/typetalk/slack"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	configPath := flag.String("config", "webhook.json", "path to the configuration file")
+	newSecret := flag.Bool("new-secret", false, "print a new random secret and exit")
+	flag.Parse()
+
+	if *newSecret {
+		secret, err := slack.NewWebhookSecret()
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(secret)
+		return
+	}
+
+	config, err := slack.LoadWebhookConfig(*configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	handler, err := slack.NewWebhookHandler(config, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, handler))
+}
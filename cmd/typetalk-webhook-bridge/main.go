@@ -0,0 +1,47 @@
+// Command typetalk-webhook-bridge receives arbitrary JSON webhooks and posts
+// them to Typetalk topics.
+//
+//	typetalk-webhook-bridge -addr :8080 -config bridge.yaml
+//
+// The configuration file, in YAML or JSON, maps URL paths to topics and
+// message templates:
+//
+//	token: DEFAULT_TOKEN
+//	routes:
+//	  - path: /github
+//	    secret: s3cr3t
+//	    filter: '$.action == "opened"'
+//	    topicId: 123
+//	    template: '{{ .sender.login }} opened {{ .pull_request.html_url }}'
+//
+// The TYPETALK_TOKEN environment variable overrides the default token.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/nulab/go-typetalk/v3/typetalk/bridge"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	configPath := flag.String("config", "bridge.yaml", "path to the configuration file")
+	flag.Parse()
+
+	config, err := bridge.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if token := os.Getenv("TYPETALK_TOKEN"); token != "" {
+		config.Token = token
+	}
+	handler, err := bridge.New(config, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, handler))
+}
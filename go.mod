@@ -5,4 +5,5 @@ go 1.13
 require (
 	github.com/nulab/go-typetalk v2.1.1+incompatible
 	golang.org/x/oauth2 v0.0.0-20191202225959-858c2ad4c8b6
+	gopkg.in/yaml.v2 v2.4.0
 )
@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nulab/go-typetalk/v3/typetalk/v1"
+)
+
+func Test_Lifecycle_should_create_a_topic_post_like_talk_and_delete(t *testing.T) {
+	if target == targetReplay {
+		t.Skip("the cassette does not hold the lifecycle")
+	}
+	ctx := context.Background()
+
+	name := fmt.Sprintf("go-typetalk - lifecycle %d", time.Now().Unix())
+	created, resp, err := clientV1.Topics.CreateTopic(ctx, &v1.CreateTopicOptions{Name: name, SpaceKey: spaceKey})
+	test(t, created, resp, err)
+	id := created.Topic.ID
+	deleted := false
+	defer func() {
+		if !deleted {
+			clientV1.Topics.DeleteTopic(ctx, id)
+		}
+	}()
+
+	topics, resp, err := clientV2.Topics.GetMyTopics(ctx, spaceKey)
+	test(t, topics, resp, err)
+	found := false
+	for _, topic := range topics {
+		found = found || topic.Topic.ID == id
+	}
+	if !found {
+		t.Errorf("v2 GetMyTopics does not return the topic %d", id)
+	}
+
+	posted, resp, err := clientV1.Messages.PostMessage(ctx, id, "go-typetalk - lifecycle post", nil)
+	test(t, posted, resp, err)
+	reply, resp, err := clientV1.Messages.PostMessage(ctx, id, "go-typetalk - lifecycle reply", &v1.PostMessageOptions{ReplyTo: posted.Post.ID})
+	test(t, reply, resp, err)
+
+	liked, resp, err := clientV1.Messages.LikeMessage(ctx, id, posted.Post.ID)
+	test(t, liked, resp, err)
+	message, resp, err := clientV1.Messages.GetMessage(ctx, id, posted.Post.ID)
+	test(t, message, resp, err)
+	if len(message.Post.Likes) != 1 {
+		t.Errorf("the post has %d likes, want 1", len(message.Post.Likes))
+	}
+	if len(message.Replies) != 1 || message.Replies[0].ID != reply.Post.ID {
+		t.Errorf("the post has the replies %+v, want %d", message.Replies, reply.Post.ID)
+	}
+
+	talk, resp, err := clientV1.Talks.CreateTalk(ctx, id, "go-typetalk - lifecycle talk", posted.Post.ID, reply.Post.ID)
+	test(t, talk, resp, err)
+	inTalk, resp, err := clientV1.Talks.GetMessagesInTalk(ctx, id, talk.Talk.ID, nil)
+	test(t, inTalk, resp, err)
+	if len(inTalk.Posts) != 2 {
+		t.Errorf("the talk has %d posts, want 2", len(inTalk.Posts))
+	}
+
+	read, resp, err := clientV3.Notifications.ReadNotification(ctx, spaceKey)
+	test(t, read, resp, err)
+
+	deletedTalk, resp, err := clientV1.Talks.DeleteTalk(ctx, id, talk.Talk.ID)
+	test(t, deletedTalk, resp, err)
+	unliked, resp, err := clientV1.Messages.UnlikeMessage(ctx, id, posted.Post.ID)
+	test(t, unliked, resp, err)
+	deletedPost, resp, err := clientV1.Messages.DeleteMessage(ctx, id, reply.Post.ID)
+	test(t, deletedPost, resp, err)
+	message, resp, err = clientV1.Messages.GetMessage(ctx, id, posted.Post.ID)
+	test(t, message, resp, err)
+	if len(message.Post.Likes) != 0 || len(message.Replies) != 0 {
+		t.Errorf("the post still has likes %+v or replies %+v", message.Post.Likes, message.Replies)
+	}
+
+	deletedTopic, resp, err := clientV1.Topics.DeleteTopic(ctx, id)
+	test(t, deletedTopic, resp, err)
+	deleted = true
+	if _, _, err := clientV1.Topics.GetTopicDetails(ctx, id); err == nil {
+		t.Errorf("the topic %d is not deleted", id)
+	}
+}
@@ -1,7 +1,6 @@
 package tests
 
 import (
-	"context"
 	"encoding/json"
 	"net/http"
 	"net/url"
@@ -10,12 +9,33 @@ import (
 	"testing"
 
 	"github.com/nulab/go-typetalk/typetalk/shared"
-	"github.com/nulab/go-typetalk/typetalk/v1"
-	"github.com/nulab/go-typetalk/typetalk/v2"
-	"github.com/nulab/go-typetalk/typetalk/v3"
+	"github.com/nulab/go-typetalk/v3/typetalk/cassette"
+	"github.com/nulab/go-typetalk/v3/typetalk/typetalktest"
+	"github.com/nulab/go-typetalk/v3/typetalk/v1"
+	"github.com/nulab/go-typetalk/v3/typetalk/v2"
+	"github.com/nulab/go-typetalk/v3/typetalk/v3"
 	"golang.org/x/oauth2"
 )
 
+// The suite runs against one of these targets: the live API when OAuth2
+// credentials are given, the interactions of cassettePath when TT_REPLAY is
+// set, and otherwise an in-process stand-in of the API. CI runs it against
+// the stand-in with make test and against the cassette with make
+// test-replay. The cassette doesn't hold the lifecycle scenario, which is
+// skipped in replay.
+const (
+	targetLive    = "live"
+	targetReplay  = "replay"
+	targetStandIn = "stand-in"
+)
+
+// cassettePath is replayed with TT_REPLAY, and recorded from the live API
+// when TT_RECORD is set. Its interactions use the spaceKey, topicID and
+// postID defaults of initReplay.
+const cassettePath = "testdata/integration.json"
+
+var cassetteOptions = &cassette.Options{IgnoreParams: []string{"from", "to"}}
+
 var (
 	clientV1                   *v1.Client
 	clientV2                   *v2.Client
@@ -26,6 +46,9 @@ var (
 	topicID                    int
 	postID                     int
 	spaceKey                   string
+	target                     string
+	recorder                   *cassette.Recorder
+	standIn                    *typetalktest.Server
 )
 
 type AccessToken struct {
@@ -36,56 +59,119 @@ type AccessToken struct {
 }
 
 func init() {
-	spaceKey = os.Getenv("TT_SPACE_KEY")
 	clientID := os.Getenv("TT_CLIENT_ID")
 	clientSecret := os.Getenv("TT_CLIENT_SECRET")
+	switch {
+	case clientID != "" && clientSecret != "":
+		initLive(clientID, clientSecret)
+	case os.Getenv("TT_REPLAY") != "":
+		initReplay()
+	default:
+		initStandIn()
+	}
+	print("!!! Integration test runs against the " + target + " target. !!!\n\n")
+}
+
+func initLive(clientID, clientSecret string) {
+	target = targetLive
+	spaceKey = os.Getenv("TT_SPACE_KEY")
 	if v, err := strconv.Atoi(os.Getenv("TT_TOPIC_ID")); err == nil {
 		topicID = v
 	}
 	if v, err := strconv.Atoi(os.Getenv("TT_POST_ID")); err == nil {
 		postID = v
 	}
-	if clientID == "" || clientSecret == "" {
-		print("!!! Integration test using OAuth2 requires client_id and client_secret. !!!\n\n")
-		clientV1 = v1.NewClient(nil)
-	} else {
-		form := url.Values{}
-		form.Add("client_id", clientID)
-		form.Add("client_secret", clientSecret)
-		form.Add("grant_type", "client_credentials")
-		form.Add("scope", "topic.read,topic.post,topic.write,topic.delete,my")
-		resp, err := http.PostForm("https://typetalk.com/oauth2/access_token", form)
-		if err != nil {
-			print("Client Credential request returned error")
-		}
-		if resp == nil {
-			print("Client Credential request returned nil response")
-		}
-		v := &AccessToken{}
-		json.NewDecoder(resp.Body).Decode(v)
-
-		tc := oauth2.NewClient(context.Background(), oauth2.StaticTokenSource(
-			&oauth2.Token{AccessToken: v.AccessToken},
-		))
-		clientV1 = v1.NewClient(tc)
-		clientV2 = v2.NewClient(tc)
-		clientV3 = v3.NewClient(tc)
+
+	form := url.Values{}
+	form.Add("client_id", clientID)
+	form.Add("client_secret", clientSecret)
+	form.Add("grant_type", "client_credentials")
+	form.Add("scope", "topic.read,topic.post,topic.write,topic.delete,my")
+	resp, err := http.PostForm("https://typetalk.com/oauth2/access_token", form)
+	if err != nil {
+		print("Client Credential request returned error")
 	}
+	if resp == nil {
+		print("Client Credential request returned nil response")
+	}
+	v := &AccessToken{}
+	json.NewDecoder(resp.Body).Decode(v)
 
-	clientUsingTypetalkTokenV1 = v1.NewClient(nil)
-	clientUsingTypetalkTokenV2 = v2.NewClient(nil)
-	clientUsingTypetalkTokenV3 = v3.NewClient(nil)
+	httpClient := http.DefaultClient
+	if os.Getenv("TT_RECORD") != "" {
+		if recorder, err = cassette.New(cassettePath, cassette.ModeRecord, cassetteOptions); err != nil {
+			panic(err)
+		}
+		httpClient = recorder.Client()
+	}
 
 	typetalkToken := os.Getenv("TT_TOKEN")
+	if typetalkToken == "" {
+		print("!!! Integration test using Typetalk Token requires Typetalk Token. !!!\n\n")
+	}
+	setClients(httpClient, v.AccessToken, typetalkToken)
+}
+
+func initReplay() {
+	target = targetReplay
+	spaceKey, topicID, postID = "abcdefghij", 208, 307
+	var err error
+	if recorder, err = cassette.New(cassettePath, cassette.ModeReplay, cassetteOptions); err != nil {
+		panic(err)
+	}
+	setClients(recorder.Client(), "", "")
+}
+
+// initStandIn seeds the stand-in with a topic shared with a friend, which
+// holds a post of our own mentioning "test".
+func initStandIn() {
+	target = targetStandIn
+	standIn = typetalktest.NewServer()
+	friend := standIn.AddAccount("friend")
+	spaceKey = typetalktest.DefaultSpace
+	topicID = standIn.AddTopic("go-typetalk", friend)
+	postID = standIn.AddPost(topicID, standIn.Me(), "go-typetalk - test message")
+	setClients(standIn.Client(), "access-token", "typetalk-token")
+}
+
+// setClients creates the clients of the suite on top of httpClient. The
+// OAuth2 clients send accessToken, and the other ones typetalkToken.
+func setClients(httpClient *http.Client, accessToken, typetalkToken string) {
+	tc := httpClient
+	if accessToken != "" {
+		tc = &http.Client{Transport: &oauth2.Transport{
+			Source: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: accessToken}),
+			Base:   httpClient.Transport,
+		}}
+	}
+	clientV1 = v1.NewClient(tc)
+	clientV2 = v2.NewClient(tc)
+	clientV3 = v3.NewClient(tc)
+
+	clientUsingTypetalkTokenV1 = v1.NewClient(httpClient)
+	clientUsingTypetalkTokenV2 = v2.NewClient(httpClient)
+	clientUsingTypetalkTokenV3 = v3.NewClient(httpClient)
 	if typetalkToken != "" {
 		clientUsingTypetalkTokenV1.SetTypetalkToken(typetalkToken)
 		clientUsingTypetalkTokenV2.SetTypetalkToken(typetalkToken)
 		clientUsingTypetalkTokenV3.SetTypetalkToken(typetalkToken)
-	} else {
-		print("!!! Integration test using Typetalk Token requires Typetalk Token. !!!\n\n")
 	}
 }
 
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if standIn != nil {
+		standIn.Close()
+	}
+	if recorder != nil {
+		if err := recorder.Stop(); err != nil {
+			print(err.Error() + "\n")
+			code = 1
+		}
+	}
+	os.Exit(code)
+}
+
 func test(t *testing.T, result interface{}, resp *shared.Response, err error) {
 	if err != nil {
 		t.Fatalf("Returned error: %v", err)
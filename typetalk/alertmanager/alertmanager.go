@@ -0,0 +1,340 @@
+package alertmanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+)
+
+// KV is a set of labels or annotations.
+type KV map[string]string
+
+// Names returns the sorted names of the set.
+func (kv KV) Names() []string {
+	names := make([]string, 0, len(kv))
+	for k := range kv {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// String returns the set formatted as "name=value, ..." sorted by name.
+func (kv KV) String() string {
+	pairs := make([]string, 0, len(kv))
+	for _, k := range kv.Names() {
+		pairs = append(pairs, k+"="+kv[k])
+	}
+	return strings.Join(pairs, ", ")
+}
+
+// Alert represents an alert in a webhook notification.
+type Alert struct {
+	Status       string    `json:"status"`
+	Labels       KV        `json:"labels"`
+	Annotations  KV        `json:"annotations"`
+	StartsAt     time.Time `json:"startsAt"`
+	EndsAt       time.Time `json:"endsAt"`
+	GeneratorURL string    `json:"generatorURL"`
+	Fingerprint  string    `json:"fingerprint"`
+}
+
+// Alerts is a list of alerts.
+type Alerts []*Alert
+
+// Firing returns the firing alerts.
+func (as Alerts) Firing() Alerts {
+	return as.filter(StatusFiring)
+}
+
+// Resolved returns the resolved alerts.
+func (as Alerts) Resolved() Alerts {
+	return as.filter(StatusResolved)
+}
+
+func (as Alerts) filter(status string) Alerts {
+	var result Alerts
+	for _, a := range as {
+		if a.Status == status {
+			result = append(result, a)
+		}
+	}
+	return result
+}
+
+// Alert statuses.
+const (
+	StatusFiring   = "firing"
+	StatusResolved = "resolved"
+)
+
+// Data represents the payload Alertmanager sends to webhook receivers.
+type Data struct {
+	Version           string `json:"version"`
+	GroupKey          string `json:"groupKey"`
+	TruncatedAlerts   int    `json:"truncatedAlerts"`
+	Status            string `json:"status"`
+	Receiver          string `json:"receiver"`
+	GroupLabels       KV     `json:"groupLabels"`
+	CommonLabels      KV     `json:"commonLabels"`
+	CommonAnnotations KV     `json:"commonAnnotations"`
+	ExternalURL       string `json:"externalURL"`
+	Alerts            Alerts `json:"alerts"`
+}
+
+// DefaultMaxBodySize is the size limit of a notification when
+// Options.MaxBodySize is zero. It leaves room for groups of thousands of
+// alerts.
+const DefaultMaxBodySize = 8 << 20
+
+// Route sends alert groups to a topic.
+type Route struct {
+	// Match selects the groups whose common labels have all the given values.
+	Match map[string]string `json:"match"`
+	// TopicID is the topic the group is posted to.
+	TopicID int `json:"topicId"`
+}
+
+func (r *Route) matches(labels KV) bool {
+	for k, v := range r.Match {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Options configures a Receiver.
+type Options struct {
+	// Routes are evaluated in order and the first matching route is used.
+	Routes []*Route
+	// DefaultTopicID is used when no route matches.
+	DefaultTopicID int
+	// Template renders the message of a post. DefaultTemplate is used when
+	// it is nil.
+	Template *template.Template
+	// Talks enables collecting the posts of each incident into a talk.
+	Talks bool
+	// TalkName renders the name of the talk created for an incident.
+	// DefaultTalkName is used when it is nil.
+	TalkName *template.Template
+	// Store keeps track of the posts of each incident. An in-memory store
+	// is used when it is nil.
+	Store Store
+	// ErrorLog is used to log failed notifications. The standard logger is
+	// used when it is nil.
+	ErrorLog *log.Logger
+	// MaxBodySize is the size limit of a notification in bytes. Larger
+	// notifications are rejected with 413 Request Entity Too Large.
+	// DefaultMaxBodySize is used when it is zero.
+	MaxBodySize int64
+}
+
+// Receiver is an Alertmanager webhook receiver that posts notifications to
+// Typetalk topics.
+//
+// Each alert group (incident) is posted once. Later notifications of the same
+// group update the original post instead of posting again, so resolved
+// alerts are shown in place. Alerts that join a group after it was posted
+// are posted as a new message.
+type Receiver struct {
+	client *v1.Client
+	opt    Options
+	mu     sync.Mutex
+}
+
+// NewReceiver returns a Receiver that posts through the given client.
+func NewReceiver(client *v1.Client, opt *Options) *Receiver {
+	r := &Receiver{client: client}
+	if opt != nil {
+		r.opt = *opt
+	}
+	if r.opt.Template == nil {
+		r.opt.Template = DefaultTemplate
+	}
+	if r.opt.TalkName == nil {
+		r.opt.TalkName = DefaultTalkName
+	}
+	if r.opt.Store == nil {
+		r.opt.Store = NewMemoryStore()
+	}
+	return r
+}
+
+// TopicID returns the topic the group is routed to, or 0 if there is none.
+func (r *Receiver) TopicID(data *Data) int {
+	for _, route := range r.opt.Routes {
+		if route.matches(data.CommonLabels) {
+			return route.TopicID
+		}
+	}
+	return r.opt.DefaultTopicID
+}
+
+// Notify posts or updates the messages of the alert group in data.
+func (r *Receiver) Notify(ctx context.Context, data *Data) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	incident, err := r.opt.Store.Get(data.GroupKey)
+	if err != nil {
+		return err
+	}
+	if incident == nil {
+		topicID := r.TopicID(data)
+		if topicID == 0 {
+			return fmt.Errorf("alertmanager: no topic for group %s", data.GroupKey)
+		}
+		incident = &Incident{GroupKey: data.GroupKey, TopicID: topicID, Posts: map[string]int{}}
+	}
+
+	posts := map[int]Alerts{}
+	var unknown Alerts
+	for _, a := range data.Alerts {
+		if postID, ok := incident.Posts[a.Fingerprint]; ok {
+			posts[postID] = append(posts[postID], a)
+		} else {
+			unknown = append(unknown, a)
+		}
+	}
+
+	postIDs := make([]int, 0, len(posts))
+	for postID := range posts {
+		postIDs = append(postIDs, postID)
+	}
+	sort.Ints(postIDs)
+	for _, postID := range postIDs {
+		message, err := r.render(r.opt.Template, data, posts[postID])
+		if err != nil {
+			return err
+		}
+		if _, _, err := r.client.Messages.UpdateMessage(ctx, incident.TopicID, postID, message); err != nil {
+			return fmt.Errorf("alertmanager: updating post %d: %v", postID, err)
+		}
+	}
+
+	if len(unknown) > 0 {
+		if err := r.post(ctx, incident, data, unknown); err != nil {
+			return err
+		}
+	}
+
+	if data.Status == StatusResolved {
+		return r.opt.Store.Delete(data.GroupKey)
+	}
+	return r.opt.Store.Put(incident)
+}
+
+func (r *Receiver) post(ctx context.Context, incident *Incident, data *Data, alerts Alerts) error {
+	message, err := r.render(r.opt.Template, data, alerts)
+	if err != nil {
+		return err
+	}
+	posted, _, err := r.client.Messages.PostMessage(ctx, incident.TopicID, message, nil)
+	if err != nil {
+		return fmt.Errorf("alertmanager: posting to topic %d: %v", incident.TopicID, err)
+	}
+	postID := posted.Post.ID
+	for _, a := range alerts {
+		incident.Posts[a.Fingerprint] = postID
+	}
+	if !r.opt.Talks {
+		return nil
+	}
+	// The post is made, so a failure to file it in the talk is only logged:
+	// returning it would lose the post and post it again on the next
+	// notification.
+	if err := r.addToTalk(ctx, incident, data, alerts, postID); err != nil {
+		r.logf("%v", err)
+	}
+	return nil
+}
+
+// addToTalk adds a post to the talk of the incident, creating the talk with
+// the post when the incident has none.
+func (r *Receiver) addToTalk(ctx context.Context, incident *Incident, data *Data, alerts Alerts, postID int) error {
+	if incident.TalkID != 0 {
+		if _, _, err := r.client.Talks.AddMessagesToTalk(ctx, incident.TopicID, incident.TalkID, postID); err != nil {
+			return fmt.Errorf("alertmanager: adding post %d to talk %d: %v", postID, incident.TalkID, err)
+		}
+		return nil
+	}
+	name, err := r.render(r.opt.TalkName, data, alerts)
+	if err != nil {
+		return err
+	}
+	created, _, err := r.client.Talks.CreateTalk(ctx, incident.TopicID, strings.TrimSpace(name), postID)
+	if err != nil {
+		return fmt.Errorf("alertmanager: creating talk: %v", err)
+	}
+	incident.TalkID = created.Talk.ID
+	return nil
+}
+
+func (r *Receiver) logf(format string, args ...interface{}) {
+	if r.opt.ErrorLog != nil {
+		r.opt.ErrorLog.Printf(format, args...)
+	} else {
+		log.Printf(format, args...)
+	}
+}
+
+func (r *Receiver) render(tmpl *template.Template, data *Data, alerts Alerts) (string, error) {
+	d := *data
+	d.Alerts = alerts
+	d.Status = StatusResolved
+	if len(alerts.Firing()) > 0 {
+		d.Status = StatusFiring
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, &d); err != nil {
+		return "", fmt.Errorf("alertmanager: rendering %s: %v", tmpl.Name(), err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// ServeHTTP decodes an Alertmanager webhook notification and passes it to
+// Notify.
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	limit := r.opt.MaxBodySize
+	if limit == 0 {
+		limit = DefaultMaxBodySize
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, req.Body, limit))
+	if err != nil {
+		if int64(len(body)) >= limit {
+			http.Error(w, "payload too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "reading body failed", http.StatusBadRequest)
+		return
+	}
+	data := &Data{}
+	if err := json.Unmarshal(body, data); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	// The error is logged rather than returned, as it may hold details of
+	// the API.
+	if err := r.Notify(req.Context(), data); err != nil {
+		r.logf("%v", err)
+		http.Error(w, "notification failed", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
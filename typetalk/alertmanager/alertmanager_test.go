@@ -0,0 +1,230 @@
+package alertmanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/nulab/go-typetalk/v3/typetalk/internal"
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+)
+
+var (
+	mux    *http.ServeMux
+	client *v1.Client
+	server *httptest.Server
+)
+
+const fixturesPath = "../../testdata/alertmanager/"
+
+func setup() {
+	mux = http.NewServeMux()
+	server = httptest.NewServer(mux)
+
+	client = v1.NewClient(NewTestClient(server))
+	client.SetTypetalkToken("DUMMY_TOKEN")
+}
+
+func teardown() {
+	server.Close()
+}
+
+func readData(t *testing.T, name string) *Data {
+	b, err := ioutil.ReadFile(fixturesPath + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var data *Data
+	if err := json.Unmarshal(b, &data); err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+type request struct {
+	method, path, message, talkName string
+	postIDs                         []string
+}
+
+func handleTopic(t *testing.T, topicID int) *[]request {
+	var requests []request
+	postID := 100
+	mux.HandleFunc(fmt.Sprintf("/api/v1/topics/%d/", topicID), func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		requests = append(requests, request{r.Method, r.URL.Path, r.Form.Get("message"), r.Form.Get("talkName"), r.Form["postIds[0]"]})
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/talks"):
+			fmt.Fprintf(w, `{"talk": {"id": 7, "name": %q}}`, r.Form.Get("talkName"))
+		default:
+			fmt.Fprint(w, `{}`)
+		}
+	})
+	mux.HandleFunc(fmt.Sprintf("/api/v1/topics/%d", topicID), func(w http.ResponseWriter, r *http.Request) {
+		TestMethod(t, r, http.MethodPost)
+		r.ParseForm()
+		requests = append(requests, request{method: r.Method, path: r.URL.Path, message: r.Form.Get("message")})
+		postID++
+		fmt.Fprintf(w, `{"post": {"id": %d}}`, postID)
+	})
+	return &requests
+}
+
+func Test_Receiver_TopicID_should_route_by_labels(t *testing.T) {
+	r := NewReceiver(client, &Options{
+		Routes: []*Route{
+			{Match: map[string]string{"team": "db"}, TopicID: 1},
+			{Match: map[string]string{"team": "api", "severity": "critical"}, TopicID: 2},
+		},
+		DefaultTopicID: 3,
+	})
+	data := readData(t, "firing.json")
+	if got := r.TopicID(data); got != 2 {
+		t.Errorf("TopicID: got %d, want 2", got)
+	}
+	data.CommonLabels = KV{"team": "web"}
+	if got := r.TopicID(data); got != 3 {
+		t.Errorf("TopicID: got %d, want 3", got)
+	}
+}
+
+func Test_Receiver_Notify_should_post_then_update_in_place(t *testing.T) {
+	setup()
+	defer teardown()
+	requests := handleTopic(t, 2)
+	store := NewMemoryStore()
+	r := NewReceiver(client, &Options{DefaultTopicID: 2, Talks: true, Store: store})
+	ctx := context.Background()
+
+	if err := r.Notify(ctx, readData(t, "firing.json")); err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+	if err := r.Notify(ctx, readData(t, "joined.json")); err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+	incident, _ := store.Get(`{}:{alertname="HighLatency"}`)
+	if incident == nil || incident.Posts["a1"] != 101 || incident.Posts["a2"] != 102 || incident.TalkID != 7 {
+		t.Fatalf("incident: got %+v", incident)
+	}
+	if err := r.Notify(ctx, readData(t, "resolved.json")); err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+	if incident, _ := store.Get(`{}:{alertname="HighLatency"}`); incident != nil {
+		t.Errorf("resolved incident must be forgotten: %+v", incident)
+	}
+
+	got := *requests
+	want := []request{
+		{method: http.MethodPost, path: "/api/v1/topics/2"},
+		{method: http.MethodPost, path: "/api/v1/topics/2/talks", talkName: "HighLatency 2020-01-01 00:00", postIDs: []string{"101"}},
+		{method: http.MethodPut, path: "/api/v1/topics/2/posts/101"},
+		{method: http.MethodPost, path: "/api/v1/topics/2"},
+		{method: http.MethodPost, path: "/api/v1/topics/2/talks/7/posts", postIDs: []string{"102"}},
+		{method: http.MethodPut, path: "/api/v1/topics/2/posts/101"},
+		{method: http.MethodPut, path: "/api/v1/topics/2/posts/102"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("requests: got %d, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i].method != want[i].method || got[i].path != want[i].path ||
+			got[i].talkName != want[i].talkName || fmt.Sprint(got[i].postIDs) != fmt.Sprint(want[i].postIDs) {
+			t.Errorf("request %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if !strings.HasPrefix(got[0].message, ":fire: **[FIRING:1] HighLatency**") {
+		t.Errorf("first post: got %q", got[0].message)
+	}
+	if !strings.HasPrefix(got[2].message, ":white_check_mark: **[RESOLVED] HighLatency**") {
+		t.Errorf("update of resolved alert: got %q", got[2].message)
+	}
+	if !strings.Contains(got[3].message, "(api-2)") || strings.Contains(got[3].message, "(api-1)") {
+		t.Errorf("post of joined alert: got %q", got[3].message)
+	}
+}
+
+func Test_Receiver_Notify_should_fail_without_route(t *testing.T) {
+	r := NewReceiver(client, nil)
+	if err := r.Notify(context.Background(), readData(t, "firing.json")); err == nil {
+		t.Error("Expected error to be returned")
+	}
+}
+
+func Test_Receiver_ServeHTTP(t *testing.T) {
+	setup()
+	defer teardown()
+	handleTopic(t, 2)
+	r := NewReceiver(client, &Options{DefaultTopicID: 2, ErrorLog: log.New(ioutil.Discard, "", 0)})
+
+	b, _ := ioutil.ReadFile(fixturesPath + "firing.json")
+	tests := []struct {
+		method string
+		body   []byte
+		want   int
+	}{
+		{http.MethodPost, b, http.StatusOK},
+		{http.MethodGet, nil, http.StatusMethodNotAllowed},
+		{http.MethodPost, []byte("{"), http.StatusBadRequest},
+		{http.MethodPost, []byte(`{"groupKey": "x", "commonLabels": {}, "alerts": [{"status": "firing", "fingerprint": "f"}]}`), http.StatusOK},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(tt.method, "/", bytes.NewReader(tt.body)))
+		if w.Code != tt.want {
+			t.Errorf("%s %s: got %d, want %d", tt.method, tt.body, w.Code, tt.want)
+		}
+	}
+
+	r = NewReceiver(client, &Options{ErrorLog: log.New(ioutil.Discard, "", 0)})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(b)))
+	if w.Code != http.StatusInternalServerError || strings.Contains(w.Body.String(), "alertmanager:") {
+		t.Errorf("unrouted group: got %d %q, want %d", w.Code, w.Body.String(), http.StatusInternalServerError)
+	}
+
+	r = NewReceiver(client, &Options{DefaultTopicID: 2, MaxBodySize: 16})
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(b)))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("large payload: got %d, want %d", w.Code, http.StatusRequestEntityTooLarge)
+	}
+}
+
+func Test_Receiver_Notify_should_keep_the_post_when_the_talk_fails(t *testing.T) {
+	setup()
+	defer teardown()
+	posts := 0
+	mux.HandleFunc("/api/v1/topics/2", func(w http.ResponseWriter, r *http.Request) {
+		TestMethod(t, r, http.MethodPost)
+		posts++
+		fmt.Fprintf(w, `{"post": {"id": %d}}`, 100+posts)
+	})
+	mux.HandleFunc("/api/v1/topics/2/", func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/talks") {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	})
+	var logged bytes.Buffer
+	r := NewReceiver(client, &Options{DefaultTopicID: 2, Talks: true, ErrorLog: log.New(&logged, "", 0)})
+
+	data := readData(t, "firing.json")
+	for i := 0; i < 2; i++ {
+		if err := r.Notify(context.Background(), data); err != nil {
+			t.Fatalf("Notify %d: %v", i, err)
+		}
+	}
+	if posts != 1 {
+		t.Errorf("posts: got %d, want 1", posts)
+	}
+	if !strings.Contains(logged.String(), "creating talk") {
+		t.Errorf("log: got %q", logged.String())
+	}
+}
@@ -0,0 +1,92 @@
+package alertmanager
+
+import (
+	"sync"
+
+	"github.com/nulab/go-typetalk/v3/typetalk/internal"
+)
+
+// Incident tracks the posts of an alert group.
+type Incident struct {
+	GroupKey string `json:"groupKey"`
+	TopicID  int    `json:"topicId"`
+	// TalkID is the talk the posts are collected into, if any.
+	TalkID int `json:"talkId,omitempty"`
+	// Posts maps alert fingerprints to the IDs of the posts showing them.
+	Posts map[string]int `json:"posts"`
+}
+
+// Store keeps incidents between notifications.
+type Store interface {
+	// Get returns the incident of the group, or nil if there is none.
+	Get(groupKey string) (*Incident, error)
+	Put(incident *Incident) error
+	Delete(groupKey string) error
+}
+
+// MemoryStore is a Store that keeps incidents in memory.
+type MemoryStore struct {
+	mu        sync.Mutex
+	incidents map[string]*Incident
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{incidents: map[string]*Incident{}}
+}
+
+// Get implements Store.
+func (s *MemoryStore) Get(groupKey string) (*Incident, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.incidents[groupKey], nil
+}
+
+// Put implements Store.
+func (s *MemoryStore) Put(incident *Incident) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.incidents[incident.GroupKey] = incident
+	return nil
+}
+
+// Delete implements Store.
+func (s *MemoryStore) Delete(groupKey string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.incidents, groupKey)
+	return nil
+}
+
+// FileStore is a Store that persists incidents to a JSON file, so that
+// posts are still updated after the receiver restarts.
+type FileStore struct {
+	MemoryStore
+	file internal.JSONFile
+}
+
+// OpenFileStore loads the incidents saved at path. The file is created on
+// the first change if it doesn't exist.
+func OpenFileStore(path string) (*FileStore, error) {
+	s := &FileStore{MemoryStore: *NewMemoryStore(), file: internal.JSONFile{Path: path}}
+	if err := s.file.Load(&s.incidents); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Put implements Store.
+func (s *FileStore) Put(incident *Incident) error {
+	s.MemoryStore.Put(incident)
+	return s.save()
+}
+
+// Delete implements Store.
+func (s *FileStore) Delete(groupKey string) error {
+	s.MemoryStore.Delete(groupKey)
+	return s.save()
+}
+
+func (s *FileStore) save() error {
+	return s.file.Save(&s.mu, s.incidents)
+}
@@ -0,0 +1,48 @@
+package alertmanager
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_FileStore_should_persist_incidents(t *testing.T) {
+	dir, err := ioutil.TempDir("", "alertmanager")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "incidents.json")
+
+	s, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+	want := &Incident{GroupKey: "g1", TopicID: 1, TalkID: 2, Posts: map[string]int{"a1": 3}}
+	if err := s.Put(want); err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+	s.Put(&Incident{GroupKey: "g2", TopicID: 1, Posts: map[string]int{}})
+	if err := s.Delete("g2"); err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+
+	reopened, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+	if got, _ := reopened.Get("g1"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Get(g1): got %+v, want %+v", got, want)
+	}
+	if got, _ := reopened.Get("g2"); got != nil {
+		t.Errorf("Get(g2): got %+v, want nil", got)
+	}
+}
+
+func Test_OpenFileStore_should_return_error_for_broken_file(t *testing.T) {
+	if _, err := OpenFileStore(fixturesPath); err == nil {
+		t.Error("Expected error to be returned")
+	}
+}
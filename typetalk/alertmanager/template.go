@@ -0,0 +1,43 @@
+package alertmanager
+
+import (
+	"strings"
+	"text/template"
+	"time"
+)
+
+// TemplateFuncs are the functions available in message templates in
+// addition to the text/template builtins.
+var TemplateFuncs = template.FuncMap{
+	"toUpper": strings.ToUpper,
+	"toLower": strings.ToLower,
+	"title":   strings.Title,
+	"join":    strings.Join,
+	"since": func(t time.Time) string {
+		return time.Since(t).Round(time.Second).String()
+	},
+	"date": func(layout string, t time.Time) string {
+		return t.UTC().Format(layout)
+	},
+}
+
+// NewTemplate parses a message template with TemplateFuncs available.
+func NewTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(TemplateFuncs).Parse(text)
+}
+
+// DefaultTemplate renders the alerts of a post as a list.
+var DefaultTemplate = template.Must(NewTemplate("message", `
+{{- if eq .Status "firing" }}:fire:{{ else }}:white_check_mark:{{ end }} **[{{ .Status | toUpper }}{{ if eq .Status "firing" }}:{{ len .Alerts.Firing }}{{ end }}] {{ or .CommonLabels.alertname .GroupLabels.String }}**
+{{ range .Alerts -}}
+- {{ if eq .Status "firing" }}:fire:{{ else }}:white_check_mark:{{ end }} {{ or .Annotations.summary .Labels.alertname }}{{ if .Labels.instance }} ({{ .Labels.instance }}){{ end }} since {{ date "2006-01-02 15:04 MST" .StartsAt }}
+{{ if .Annotations.description }}  {{ .Annotations.description }}
+{{ end }}{{ end }}
+{{- if .ExternalURL }}{{ .ExternalURL }}{{ end }}
+`))
+
+// DefaultTalkName renders the name of an incident's talk from the alert
+// name and the time the incident started.
+var DefaultTalkName = template.Must(NewTemplate("talk", `
+{{- or .CommonLabels.alertname .GroupLabels.String }} {{ with index .Alerts 0 }}{{ date "2006-01-02 15:04" .StartsAt }}{{ end }}
+`))
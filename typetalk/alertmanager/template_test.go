@@ -0,0 +1,44 @@
+package alertmanager
+
+import (
+	"testing"
+)
+
+func Test_DefaultTemplate_should_render_alerts(t *testing.T) {
+	r := NewReceiver(nil, nil)
+	data := readData(t, "joined.json")
+	got, err := r.render(DefaultTemplate, data, data.Alerts)
+	if err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+	want := ":fire: **[FIRING:1] HighLatency**\n" +
+		"- :white_check_mark: p99 latency is above 1s (api-1) since 2020-01-01 00:00 UTC\n" +
+		"- :fire: p99 latency is above 1s (api-2) since 2020-01-01 00:05 UTC\n" +
+		"http://alertmanager.example.com"
+	if got != want {
+		t.Errorf("render:\n got  %q,\n want %q", got, want)
+	}
+}
+
+func Test_NewTemplate_should_provide_funcs(t *testing.T) {
+	tmpl, err := NewTemplate("custom", `{{ .CommonLabels.team | toUpper }} {{ join .CommonLabels.Names "," }} {{ .GroupLabels }}`)
+	if err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+	r := NewReceiver(nil, &Options{Template: tmpl})
+	data := readData(t, "firing.json")
+	got, err := r.render(tmpl, data, data.Alerts)
+	if err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+	if want := "API alertname,severity,team alertname=HighLatency"; got != want {
+		t.Errorf("render: got %q, want %q", got, want)
+	}
+}
+
+func Test_Receiver_render_should_return_template_errors(t *testing.T) {
+	tmpl, _ := NewTemplate("broken", `{{ .Missing }}`)
+	if _, err := NewReceiver(nil, nil).render(tmpl, readData(t, "firing.json"), nil); err == nil {
+		t.Error("Expected error to be returned")
+	}
+}
@@ -0,0 +1,244 @@
+// Package audit reports who has access to which topic, for access reviews.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/nulab/go-typetalk/v3/typetalk/membership"
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+	v2 "github.com/nulab/go-typetalk/v3/typetalk/v2"
+)
+
+// Kinds of access.
+const (
+	// KindMember is an account of the space that is a member of the topic.
+	KindMember = "member"
+	// KindGuest is a member of the topic that is not a member of the space.
+	KindGuest = "guest"
+	// KindBot is a bot account that is a member of the topic.
+	KindBot = "bot"
+	// KindGroup is a group that is a member of the topic. Every account of
+	// the group has access, and is listed with the Group of its entry set
+	// when Options.GroupMembers is.
+	KindGroup = "group"
+	// KindInvitation is a pending invitation to the topic.
+	KindInvitation = "invitation"
+)
+
+// Entry is the access of an account, a group or an invitation to a topic.
+type Entry struct {
+	SpaceKey  string `json:"spaceKey"`
+	TopicID   int    `json:"topicId"`
+	TopicName string `json:"topicName"`
+	Kind      string `json:"kind"`
+	// Principal is the name of the account, the key of the group or the
+	// email address of the invitation.
+	Principal string `json:"principal"`
+	// Group is the key of the group the account has access through. It is
+	// empty for the accounts that are members of the topic themselves.
+	Group string `json:"group,omitempty"`
+}
+
+// Label names the holder of the access, such as "alice", "group:sre" or
+// "invitation:carol@example.com".
+func (e *Entry) Label() string {
+	switch e.Kind {
+	case KindGroup, KindInvitation:
+		return e.Kind + ":" + e.Principal
+	}
+	return e.Principal
+}
+
+func (e *Entry) key() string {
+	return fmt.Sprintf("%s/%d/%s/%s", e.SpaceKey, e.TopicID, e.Label(), e.Group)
+}
+
+// Snapshot is the access to all the topics at a point in time.
+type Snapshot struct {
+	TakenAt time.Time `json:"takenAt"`
+	Entries []*Entry  `json:"entries"`
+}
+
+// WriteJSON writes the snapshot, to be compared with the next one.
+func (s *Snapshot) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// ReadSnapshot reads a snapshot written by WriteJSON.
+func ReadSnapshot(r io.Reader) (*Snapshot, error) {
+	s := &Snapshot{}
+	if err := json.NewDecoder(r).Decode(s); err != nil {
+		return nil, fmt.Errorf("audit: reading snapshot: %v", err)
+	}
+	return s, nil
+}
+
+// Options configures an Auditor.
+type Options struct {
+	// Spaces limits the audit to the spaces with these keys. All the spaces
+	// of the user are audited when it is empty.
+	Spaces []string
+	// GroupMembers lists the accounts of a group, which the Typetalk API
+	// doesn't, for example from the directory the groups are synced with.
+	// When it is set, every account of a member group of a topic is listed
+	// with the key of the group, as well as the group itself.
+	GroupMembers func(ctx context.Context, spaceKey string, group *v1.Group) ([]*v1.Account, error)
+}
+
+// Auditor takes snapshots of the access to topics. Only the topics the user
+// of the client belongs to are seen, so it should run as an admin who is a
+// member of every topic.
+type Auditor struct {
+	v1  *v1.Client
+	v2  *v2.Client
+	opt Options
+	now func() time.Time
+}
+
+// New returns an Auditor that lists the topics of spaces through the v2
+// client and makes every other request through the v1 client.
+func New(clientV1 *v1.Client, clientV2 *v2.Client, opt *Options) *Auditor {
+	a := &Auditor{v1: clientV1, v2: clientV2, now: time.Now}
+	if opt != nil {
+		a.opt = *opt
+	}
+	return a
+}
+
+// Snapshot returns the access to every topic of the spaces.
+func (a *Auditor) Snapshot(ctx context.Context) (*Snapshot, error) {
+	spaces := a.opt.Spaces
+	if len(spaces) == 0 {
+		orgs, _, err := a.v1.Organizations.GetMyOrganizations(ctx, false)
+		if err != nil {
+			return nil, fmt.Errorf("audit: getting spaces: %v", err)
+		}
+		for _, org := range orgs {
+			if org.Space != nil {
+				spaces = append(spaces, org.Space.Key)
+			}
+		}
+	}
+
+	s := &Snapshot{TakenAt: a.now()}
+	for _, spaceKey := range spaces {
+		entries, err := a.space(ctx, spaceKey)
+		if err != nil {
+			return nil, err
+		}
+		s.Entries = append(s.Entries, entries...)
+	}
+	sortEntries(s.Entries)
+	return s, nil
+}
+
+func (a *Auditor) space(ctx context.Context, spaceKey string) ([]*Entry, error) {
+	members, _, err := a.v1.Organizations.GetOrganizationMembers(ctx, spaceKey)
+	if err != nil {
+		return nil, fmt.Errorf("audit: getting members of space %s: %v", spaceKey, err)
+	}
+	inSpace := map[int]bool{}
+	for _, account := range members.Accounts {
+		inSpace[account.ID] = true
+	}
+	topics, _, err := a.v2.Topics.GetMyTopics(ctx, spaceKey)
+	if err != nil {
+		return nil, fmt.Errorf("audit: getting topics of space %s: %v", spaceKey, err)
+	}
+
+	var entries []*Entry
+	groupMembers := map[string][]*v1.Account{}
+	for _, t := range topics {
+		if t.Topic.IsDirectMessage {
+			continue
+		}
+		details, _, err := a.v1.Topics.GetTopicDetails(ctx, t.Topic.ID)
+		if err != nil {
+			return nil, fmt.Errorf("audit: getting topic %d: %v", t.Topic.ID, err)
+		}
+		add := func(kind, principal, group string) {
+			entries = append(entries, &Entry{SpaceKey: spaceKey, TopicID: t.Topic.ID, TopicName: t.Topic.Name, Kind: kind, Principal: principal, Group: group})
+		}
+		addAccount := func(account *v1.Account, group string) {
+			switch {
+			case account.IsBot:
+				add(KindBot, account.Name, group)
+			case !inSpace[account.ID]:
+				add(KindGuest, account.Name, group)
+			default:
+				add(KindMember, account.Name, group)
+			}
+		}
+		for _, account := range details.Accounts {
+			addAccount(account, "")
+		}
+		for _, v := range details.AccountsForAPI {
+			if name := apiAccountName(v); name != "" {
+				add(KindBot, name, "")
+			}
+		}
+		for _, g := range details.Groups {
+			if g.Group == nil {
+				continue
+			}
+			add(KindGroup, g.Group.Key, "")
+			if a.opt.GroupMembers == nil {
+				continue
+			}
+			accounts, ok := groupMembers[g.Group.Key]
+			if !ok {
+				if accounts, err = a.opt.GroupMembers(ctx, spaceKey, g.Group); err != nil {
+					return nil, fmt.Errorf("audit: getting members of group %s: %v", g.Group.Key, err)
+				}
+				groupMembers[g.Group.Key] = accounts
+			}
+			for _, account := range accounts {
+				addAccount(account, g.Group.Key)
+			}
+		}
+		for _, inv := range membership.PendingInvitations(details) {
+			add(KindInvitation, inv.Email, "")
+		}
+	}
+	return entries, nil
+}
+
+// apiAccountName returns the name of an account of AccountsForAPI, which
+// TopicDetails doesn't type.
+func apiAccountName(v interface{}) string {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	if account, ok := m["account"].(map[string]interface{}); ok {
+		m = account
+	}
+	name, _ := m["name"].(string)
+	return name
+}
+
+func sortEntries(entries []*Entry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.SpaceKey != b.SpaceKey {
+			return a.SpaceKey < b.SpaceKey
+		}
+		if a.TopicName != b.TopicName {
+			return a.TopicName < b.TopicName
+		}
+		if a.TopicID != b.TopicID {
+			return a.TopicID < b.TopicID
+		}
+		if a.Label() != b.Label() {
+			return a.Label() < b.Label()
+		}
+		return a.Group < b.Group
+	})
+}
@@ -0,0 +1,140 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/nulab/go-typetalk/v3/typetalk/internal"
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+	v2 "github.com/nulab/go-typetalk/v3/typetalk/v2"
+)
+
+var (
+	mux      *http.ServeMux
+	clientV1 *v1.Client
+	clientV2 *v2.Client
+	server   *httptest.Server
+)
+
+func setup() {
+	mux = http.NewServeMux()
+	server = httptest.NewServer(mux)
+
+	clientV1 = v1.NewClient(NewTestClient(server)).SetTypetalkToken("DUMMY_TOKEN")
+	clientV2 = v2.NewClient(NewTestClient(server)).SetTypetalkToken("DUMMY_TOKEN")
+}
+
+func teardown() {
+	server.Close()
+}
+
+func Test_Auditor_Snapshot_should_list_access(t *testing.T) {
+	setup()
+	defer teardown()
+	mux.HandleFunc("/api/v1/spaces", func(w http.ResponseWriter, r *http.Request) {
+		TestMethod(t, r, "GET")
+		fmt.Fprint(w, `{"mySpaces": [{"space": {"key": "space"}}]}`)
+	})
+	mux.HandleFunc("/api/v1/spaces/space/members", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"accounts": [{"id": 10, "name": "alice"}, {"id": 12, "name": "deploy-bot", "isBot": true}]}`)
+	})
+	mux.HandleFunc("/api/v2/topics", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"topics": [
+		  {"topic": {"id": 1, "name": "ops"}},
+		  {"topic": {"id": 2, "name": "", "isDirectMessage": true}}
+		]}`)
+	})
+	mux.HandleFunc("/api/v1/topics/1", func(w http.ResponseWriter, r *http.Request) {
+		TestMethod(t, r, "GET")
+		fmt.Fprint(w, `{
+		  "accounts": [
+		    {"id": 10, "name": "alice"},
+		    {"id": 11, "name": "vendor"},
+		    {"id": 12, "name": "deploy-bot", "isBot": true}
+		  ],
+		  "accountsForApi": [{"account": {"id": 13, "name": "ci"}}],
+		  "groups": [{"group": {"id": 20, "key": "sre"}}],
+		  "invites": [{"mailAddress": "carol@example.com"}]
+		}`)
+	})
+	mux.HandleFunc("/api/v1/topics/2", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("direct messages should not be audited")
+	})
+	a := New(clientV1, clientV2, nil)
+	now := time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC)
+	a.now = func() time.Time { return now }
+
+	s, err := a.Snapshot(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := func(kind, principal string) *Entry {
+		return &Entry{SpaceKey: "space", TopicID: 1, TopicName: "ops", Kind: kind, Principal: principal}
+	}
+	want := &Snapshot{TakenAt: now, Entries: []*Entry{
+		entry(KindMember, "alice"),
+		entry(KindBot, "ci"),
+		entry(KindBot, "deploy-bot"),
+		entry(KindGroup, "sre"),
+		entry(KindInvitation, "carol@example.com"),
+		entry(KindGuest, "vendor"),
+	}}
+	if !reflect.DeepEqual(s, want) {
+		for _, e := range s.Entries {
+			t.Logf("%+v", e)
+		}
+		t.Errorf("Snapshot: got %+v", s)
+	}
+
+	var b bytes.Buffer
+	if err := s.WriteJSON(&b); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadSnapshot(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read, s) {
+		t.Errorf("ReadSnapshot: got %+v, want %+v", read, s)
+	}
+
+	calls := 0
+	a.opt.GroupMembers = func(ctx context.Context, spaceKey string, group *v1.Group) ([]*v1.Account, error) {
+		calls++
+		if spaceKey != "space" || group.Key != "sre" {
+			t.Errorf("GroupMembers: got %s %+v", spaceKey, group)
+		}
+		return []*v1.Account{{ID: 10, Name: "alice"}, {ID: 14, Name: "oncall"}}, nil
+	}
+	s, err = a.Snapshot(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	viaGroup := func(kind, principal string) *Entry {
+		e := entry(kind, principal)
+		e.Group = "sre"
+		return e
+	}
+	want.Entries = []*Entry{
+		entry(KindMember, "alice"),
+		viaGroup(KindMember, "alice"),
+		entry(KindBot, "ci"),
+		entry(KindBot, "deploy-bot"),
+		entry(KindGroup, "sre"),
+		entry(KindInvitation, "carol@example.com"),
+		viaGroup(KindGuest, "oncall"),
+		entry(KindGuest, "vendor"),
+	}
+	if !reflect.DeepEqual(s, want) || calls != 1 {
+		for _, e := range s.Entries {
+			t.Logf("%+v", e)
+		}
+		t.Errorf("Snapshot with group members: got %d calls", calls)
+	}
+}
@@ -0,0 +1,211 @@
+package audit
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"time"
+)
+
+// Report is a snapshot compared with the previous one.
+type Report struct {
+	Snapshot *Snapshot
+	// Added is the access that is not in the previous snapshot.
+	Added []*Entry
+	// Removed is the access of the previous snapshot that is gone.
+	Removed []*Entry
+}
+
+// Compare compares a snapshot with the previous one, which may be nil.
+func Compare(previous, current *Snapshot) *Report {
+	r := &Report{Snapshot: current}
+	if previous == nil {
+		return r
+	}
+	before := map[string]bool{}
+	for _, e := range previous.Entries {
+		before[e.key()] = true
+	}
+	now := map[string]bool{}
+	for _, e := range current.Entries {
+		now[e.key()] = true
+		if !before[e.key()] {
+			r.Added = append(r.Added, e)
+		}
+	}
+	for _, e := range previous.Entries {
+		if !now[e.key()] {
+			r.Removed = append(r.Removed, e)
+		}
+	}
+	return r
+}
+
+// Changes of a cell of the matrix.
+const (
+	changeAdded   = "added"
+	changeRemoved = "removed"
+)
+
+type cell struct {
+	Kind   string
+	Group  string
+	Change string
+}
+
+// Label is the kind of access, followed by the group it comes through.
+func (c *cell) Label() string {
+	if c.Group != "" {
+		return c.Kind + " via group:" + c.Group
+	}
+	return c.Kind
+}
+
+// Text is the content of the cell in CSV: the label of the access, prefixed
+// with "+" when it is new and "-" when it was removed.
+func (c *cell) Text() string {
+	switch c.Change {
+	case changeAdded:
+		return "+" + c.Label()
+	case changeRemoved:
+		return "-" + c.Label()
+	}
+	return c.Label()
+}
+
+// replaces reports whether c is shown instead of old, when an account has
+// several accesses to a topic: the current access is shown before the
+// removed one, and the direct access before the access through a group.
+func (c *cell) replaces(old *cell) bool {
+	if old.Kind == "" {
+		return true
+	}
+	if (c.Change == changeRemoved) != (old.Change == changeRemoved) {
+		return old.Change == changeRemoved
+	}
+	return c.Group == "" && old.Group != ""
+}
+
+type row struct {
+	SpaceKey  string
+	TopicID   int
+	TopicName string
+	Cells     []*cell
+}
+
+type matrix struct {
+	TakenAt time.Time
+	Added   int
+	Removed int
+	Columns []string
+	Rows    []*row
+}
+
+// matrix lays out the report with a row per topic and a column per holder
+// of access. The removed access is included, so that it can be shown.
+func (r *Report) matrix() *matrix {
+	changes := map[*Entry]string{}
+	entries := append([]*Entry{}, r.Snapshot.Entries...)
+	for _, e := range r.Added {
+		changes[e] = changeAdded
+	}
+	for _, e := range r.Removed {
+		changes[e] = changeRemoved
+		entries = append(entries, e)
+	}
+	sortEntries(entries)
+
+	m := &matrix{TakenAt: r.Snapshot.TakenAt, Added: len(r.Added), Removed: len(r.Removed)}
+	seen := map[string]bool{}
+	for _, e := range entries {
+		if !seen[e.Label()] {
+			seen[e.Label()] = true
+			m.Columns = append(m.Columns, e.Label())
+		}
+	}
+	sort.Strings(m.Columns)
+	columns := map[string]int{}
+	for i, c := range m.Columns {
+		columns[c] = i
+	}
+
+	rows := map[string]*row{}
+	for _, e := range entries {
+		k := fmt.Sprintf("%s/%d", e.SpaceKey, e.TopicID)
+		rw, ok := rows[k]
+		if !ok {
+			rw = &row{SpaceKey: e.SpaceKey, TopicID: e.TopicID, TopicName: e.TopicName, Cells: make([]*cell, len(m.Columns))}
+			for i := range rw.Cells {
+				rw.Cells[i] = &cell{}
+			}
+			rows[k] = rw
+			m.Rows = append(m.Rows, rw)
+		}
+		c := &cell{Kind: e.Kind, Group: e.Group, Change: changes[e]}
+		if i := columns[e.Label()]; c.replaces(rw.Cells[i]) {
+			rw.Cells[i] = c
+		}
+	}
+	return m
+}
+
+// WriteCSV writes the report as a matrix with a row per topic and a column
+// per account, group and invitation. A cell holds the kind of access and
+// the group it comes through, prefixed with "+" when it is new and "-" when
+// it was removed. The direct access of an account is shown before its
+// access through a group.
+func (r *Report) WriteCSV(w io.Writer) error {
+	m := r.matrix()
+	cw := csv.NewWriter(w)
+	if err := cw.Write(append([]string{"space", "topic id", "topic"}, m.Columns...)); err != nil {
+		return err
+	}
+	for _, rw := range m.Rows {
+		record := []string{rw.SpaceKey, fmt.Sprint(rw.TopicID), rw.TopicName}
+		for _, c := range rw.Cells {
+			record = append(record, c.Text())
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+var htmlTemplate = template.Must(template.New("audit").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Typetalk access audit</title>
+<style>
+table { border-collapse: collapse; font-family: sans-serif; font-size: 13px; }
+th, td { border: 1px solid #ccc; padding: 2px 6px; }
+.guest { background: #fff4d6; }
+.bot { background: #e8e8ff; }
+.added { background: #d6f5d6; font-weight: bold; }
+.removed { background: #fbd6d6; text-decoration: line-through; }
+</style>
+</head>
+<body>
+<h1>Typetalk access audit</h1>
+<p>Taken at {{.TakenAt.Format "2006-01-02 15:04:05 MST"}}: {{.Added}} new and {{.Removed}} removed accesses.</p>
+<table>
+<thead>
+<tr><th>Space</th><th>Topic</th>{{range .Columns}}<th>{{.}}</th>{{end}}</tr>
+</thead>
+<tbody>
+{{range .Rows}}<tr><td>{{.SpaceKey}}</td><td>{{.TopicName}} ({{.TopicID}})</td>{{range .Cells}}<td class="{{.Kind}} {{.Change}}">{{.Label}}</td>{{end}}</tr>
+{{end}}</tbody>
+</table>
+</body>
+</html>
+`))
+
+// WriteHTML writes the matrix of WriteCSV as an HTML page, highlighting
+// guests, bots, and new and removed access.
+func (r *Report) WriteHTML(w io.Writer) error {
+	return htmlTemplate.Execute(w, r.matrix())
+}
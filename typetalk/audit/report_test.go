@@ -0,0 +1,102 @@
+package audit
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testSnapshots() (*Snapshot, *Snapshot) {
+	ops := func(kind, principal string) *Entry {
+		return &Entry{SpaceKey: "space", TopicID: 1, TopicName: "ops", Kind: kind, Principal: principal}
+	}
+	random := func(kind, principal string) *Entry {
+		return &Entry{SpaceKey: "space", TopicID: 2, TopicName: "random", Kind: kind, Principal: principal}
+	}
+	previous := &Snapshot{Entries: []*Entry{ops(KindMember, "alice"), ops(KindGuest, "vendor"), random(KindMember, "alice")}}
+	current := &Snapshot{
+		TakenAt: time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC),
+		Entries: []*Entry{ops(KindMember, "alice"), ops(KindGroup, "sre"), random(KindMember, "alice"), random(KindBot, "ci")},
+	}
+	return previous, current
+}
+
+func Test_Compare_should_find_new_and_removed_access(t *testing.T) {
+	previous, current := testSnapshots()
+
+	r := Compare(previous, current)
+	if len(r.Added) != 2 || r.Added[0].Label() != "group:sre" || r.Added[1].Label() != "ci" {
+		t.Errorf("Added: got %+v", r.Added)
+	}
+	if len(r.Removed) != 1 || r.Removed[0].Label() != "vendor" {
+		t.Errorf("Removed: got %+v", r.Removed)
+	}
+	if r := Compare(nil, current); r.Added != nil || r.Removed != nil {
+		t.Errorf("Compare without a previous snapshot: got %+v", r)
+	}
+}
+
+func Test_Report_WriteCSV(t *testing.T) {
+	r := Compare(testSnapshots())
+
+	var b bytes.Buffer
+	if err := r.WriteCSV(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := `space,topic id,topic,alice,ci,group:sre,vendor
+space,1,ops,member,,+group,-guest
+space,2,random,member,+bot,,
+`
+	if b.String() != want {
+		t.Errorf("WriteCSV: got\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func Test_Report_WriteCSV_should_show_access_through_groups(t *testing.T) {
+	ops := func(kind, principal, group string) *Entry {
+		return &Entry{SpaceKey: "space", TopicID: 1, TopicName: "ops", Kind: kind, Principal: principal, Group: group}
+	}
+	previous := &Snapshot{Entries: []*Entry{ops(KindMember, "alice", ""), ops(KindMember, "bob", "")}}
+	current := &Snapshot{Entries: []*Entry{
+		ops(KindMember, "alice", ""),
+		ops(KindMember, "alice", "sre"),
+		ops(KindMember, "bob", "sre"),
+		ops(KindGuest, "vendor", "sre"),
+	}}
+	r := Compare(previous, current)
+	if len(r.Added) != 3 || len(r.Removed) != 1 || r.Removed[0].Principal != "bob" {
+		t.Errorf("Compare: got %+v, %+v", r.Added, r.Removed)
+	}
+
+	var b bytes.Buffer
+	if err := r.WriteCSV(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := `space,topic id,topic,alice,bob,vendor
+space,1,ops,member,+member via group:sre,+guest via group:sre
+`
+	if b.String() != want {
+		t.Errorf("WriteCSV: got\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func Test_Report_WriteHTML(t *testing.T) {
+	r := Compare(testSnapshots())
+
+	var b bytes.Buffer
+	if err := r.WriteHTML(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"2 new and 1 removed accesses",
+		`<th>group:sre</th>`,
+		`<td class="group added">group</td>`,
+		`<td class="guest removed">guest</td>`,
+		`<td>ops (1)</td>`,
+	} {
+		if !strings.Contains(b.String(), s) {
+			t.Errorf("WriteHTML: %q not found in\n%s", s, b.String())
+		}
+	}
+}
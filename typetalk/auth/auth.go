@@ -0,0 +1,201 @@
+// Package auth provides the credentials of the clients. A client consults
+// its CredentialsProvider before every request, so that a token can be
+// rotated while requests are in flight:
+//
+//	p, err := auth.File("/run/secrets/typetalk-token", nil)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer p.Close()
+//	client := v1.NewClient(nil).SetCredentials(p)
+//
+// TopicTokens resolves the bot token of the topic of each request, so that
+// one client can serve the topics of many bots.
+package auth
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// Credentials are the credentials of a request. The Typetalk token is sent
+// in the X-Typetalk-Token header and the access token in the Authorization
+// header.
+type Credentials struct {
+	TypetalkToken string
+	AccessToken   string
+}
+
+// CredentialsProvider provides the credentials of every request. It must be
+// safe for concurrent use. The returned Credentials may be shared with other
+// requests, so callers must not modify them.
+type CredentialsProvider interface {
+	Credentials(ctx context.Context) (*Credentials, error)
+}
+
+type static struct {
+	credentials *Credentials
+}
+
+// Static returns a provider of a Typetalk token.
+func Static(token string) CredentialsProvider {
+	return &static{&Credentials{TypetalkToken: token}}
+}
+
+func (s *static) Credentials(ctx context.Context) (*Credentials, error) {
+	return s.credentials, nil
+}
+
+type env struct {
+	name string
+}
+
+// Env returns a provider of the Typetalk token of an environment variable,
+// which is read on every request.
+func Env(name string) CredentialsProvider {
+	return &env{name}
+}
+
+func (e *env) Credentials(ctx context.Context) (*Credentials, error) {
+	token := os.Getenv(e.name)
+	if token == "" {
+		return nil, fmt.Errorf("auth: the environment variable %s is empty", e.name)
+	}
+	return &Credentials{TypetalkToken: token}, nil
+}
+
+type tokenSource struct {
+	ts oauth2.TokenSource
+}
+
+// TokenSource returns a provider of the OAuth2 access tokens of ts, such as
+// the refreshing source of an oauth2.Config.
+func TokenSource(ts oauth2.TokenSource) CredentialsProvider {
+	return &tokenSource{ts}
+}
+
+func (s *tokenSource) Credentials(ctx context.Context) (*Credentials, error) {
+	t, err := s.ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return &Credentials{AccessToken: t.AccessToken}, nil
+}
+
+// FileOptions configures a FileProvider.
+type FileOptions struct {
+	// Interval is the interval between checks of the file. The default is 10
+	// seconds.
+	Interval time.Duration
+	// OnError is called when the file fails to reload. The provider keeps
+	// the last token.
+	OnError func(error)
+}
+
+// FileProvider provides the Typetalk token of a file, such as a mounted
+// secret, and reloads it when the file changes.
+type FileProvider struct {
+	path string
+	opt  FileOptions
+
+	mu      sync.RWMutex
+	token   string
+	modTime time.Time
+	size    int64
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// File returns a provider of the Typetalk token in the file at path, with
+// surrounding spaces trimmed, and starts watching the file. A nil opt
+// means the default options.
+func File(path string, opt *FileOptions) (*FileProvider, error) {
+	p := &FileProvider{path: path, stop: make(chan struct{}), done: make(chan struct{})}
+	if opt != nil {
+		p.opt = *opt
+	}
+	if p.opt.Interval <= 0 {
+		p.opt.Interval = 10 * time.Second
+	}
+	if err := p.Reload(); err != nil {
+		return nil, err
+	}
+	go p.watch()
+	return p, nil
+}
+
+// Credentials returns the last token of the file.
+func (p *FileProvider) Credentials(ctx context.Context) (*Credentials, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return &Credentials{TypetalkToken: p.token}, nil
+}
+
+// Reload reads the file. The provider keeps its token when it fails.
+func (p *FileProvider) Reload() error {
+	fi, err := os.Stat(p.path)
+	if err != nil {
+		return err
+	}
+	b, err := ioutil.ReadFile(p.path)
+	if err != nil {
+		return err
+	}
+	token := strings.TrimSpace(string(b))
+	if token == "" {
+		return fmt.Errorf("auth: %s is empty", p.path)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.token, p.modTime, p.size = token, fi.ModTime(), fi.Size()
+	return nil
+}
+
+// Close stops watching the file. The provider keeps its last token.
+func (p *FileProvider) Close() error {
+	p.once.Do(func() {
+		close(p.stop)
+		<-p.done
+	})
+	return nil
+}
+
+func (p *FileProvider) watch() {
+	defer close(p.done)
+	ticker := time.NewTicker(p.opt.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+		}
+		if !p.changed() {
+			continue
+		}
+		if err := p.Reload(); err != nil && p.opt.OnError != nil {
+			p.opt.OnError(err)
+		}
+	}
+}
+
+// changed reports whether the file changed since it was last read. A file
+// that cannot be read is reported as changed so that its error is reported.
+func (p *FileProvider) changed() bool {
+	fi, err := os.Stat(p.path)
+	if err != nil {
+		return true
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return !fi.ModTime().Equal(p.modTime) || fi.Size() != p.size
+}
@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func token(t *testing.T, p CredentialsProvider) string {
+	c, err := p.Credentials(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c.TypetalkToken
+}
+
+func Test_Static_Env_and_TokenSource_should_provide_credentials(t *testing.T) {
+	if got := token(t, Static("STATIC")); got != "STATIC" {
+		t.Errorf("Static: got %q", got)
+	}
+
+	os.Setenv("GO_TYPETALK_TEST_TOKEN", "ENV")
+	defer os.Unsetenv("GO_TYPETALK_TEST_TOKEN")
+	p := Env("GO_TYPETALK_TEST_TOKEN")
+	if got := token(t, p); got != "ENV" {
+		t.Errorf("Env: got %q", got)
+	}
+	os.Setenv("GO_TYPETALK_TEST_TOKEN", "ROTATED")
+	if got := token(t, p); got != "ROTATED" {
+		t.Errorf("Env after a change: got %q", got)
+	}
+	if _, err := Env("GO_TYPETALK_TEST_MISSING").Credentials(context.Background()); err == nil {
+		t.Error("Env of a missing variable: want an error")
+	}
+
+	c, err := TokenSource(oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "ACCESS"})).Credentials(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.AccessToken != "ACCESS" || c.TypetalkToken != "" {
+		t.Errorf("TokenSource: got %+v", c)
+	}
+}
+
+func Test_File_should_reload_a_rotated_token(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "auth")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "token")
+	if err := ioutil.WriteFile(path, []byte("FIRST\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	errs := make(chan error, 10)
+	p, err := File(path, &FileOptions{Interval: 5 * time.Millisecond, OnError: func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	if got := token(t, p); got != "FIRST" {
+		t.Fatalf("File: got %q", got)
+	}
+
+	// readers race with the rotation
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if got := token(t, p); got != "FIRST" && got != "SECOND TOKEN" {
+					t.Errorf("File: got %q", got)
+					return
+				}
+			}
+		}()
+	}
+	if err := ioutil.WriteFile(path, []byte("SECOND TOKEN\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return token(t, p) == "SECOND TOKEN" })
+	close(stop)
+	wg.Wait()
+
+	if err := ioutil.WriteFile(path, []byte(" \n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-errs:
+		if err == nil {
+			t.Error("OnError: got nil")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("OnError is not called for an empty file")
+	}
+	if got := token(t, p); got != "SECOND TOKEN" {
+		t.Errorf("File keeps %q after a failed reload", got)
+	}
+
+	p.Close()
+	p.Close()
+	if _, err := File(filepath.Join(dir, "missing"), nil); err == nil {
+		t.Error("File of a missing file: want an error")
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the rotation")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
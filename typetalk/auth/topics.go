@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// RequestCredentialsProvider is a CredentialsProvider whose credentials
+// depend on the request. The clients call RequestCredentials instead of
+// Credentials.
+type RequestCredentialsProvider interface {
+	CredentialsProvider
+	RequestCredentials(req *http.Request) (*Credentials, error)
+}
+
+// NoTopicTokenError is returned for a request of a topic which has no token.
+type NoTopicTokenError struct {
+	TopicID int
+}
+
+func (e *NoTopicTokenError) Error() string {
+	return fmt.Sprintf("auth: no token is configured for the topic %d", e.TopicID)
+}
+
+// TopicTokens provides the bot token of the topic of every request. Bot
+// tokens are scoped to a single topic, so that one client can serve the
+// topics of many tokens:
+//
+//	tokens := auth.NewTopicTokens(map[int]string{1: "TOKEN1", 2: "TOKEN2"})
+//	client := v1.NewClient(nil).SetCredentials(tokens)
+//
+// The topic of a request is the ID following "topics" in its path, such as
+// topics/1/posts/2, or its topicId parameter.
+type TopicTokens struct {
+	mu       sync.RWMutex
+	tokens   map[int]string
+	fallback CredentialsProvider
+}
+
+// NewTopicTokens returns a provider of the tokens keyed by topic ID.
+func NewTopicTokens(tokens map[int]string) *TopicTokens {
+	t := &TopicTokens{tokens: map[int]string{}}
+	for id, token := range tokens {
+		t.tokens[id] = token
+	}
+	return t
+}
+
+// Set sets the token of a topic, or removes it when the token is empty.
+func (t *TopicTokens) Set(topicID int, token string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if token == "" {
+		delete(t.tokens, topicID)
+		return
+	}
+	t.tokens[topicID] = token
+}
+
+// SetDefault sets the provider of the requests without a topic, such as
+// getting the profile. Those requests fail when it is nil.
+func (t *TopicTokens) SetDefault(p CredentialsProvider) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.fallback = p
+}
+
+// Credentials returns the credentials of the default provider, since the
+// topic is not known without the request.
+func (t *TopicTokens) Credentials(ctx context.Context) (*Credentials, error) {
+	t.mu.RLock()
+	p := t.fallback
+	t.mu.RUnlock()
+	if p == nil {
+		return nil, errors.New("auth: the topic tokens have no default provider")
+	}
+	return p.Credentials(ctx)
+}
+
+// RequestCredentials returns the token of the topic of the request, or the
+// credentials of the default provider for a request without a topic. It
+// returns a *NoTopicTokenError when the topic has no token.
+func (t *TopicTokens) RequestCredentials(req *http.Request) (*Credentials, error) {
+	id, ok := TopicID(req)
+	if !ok {
+		t.mu.RLock()
+		p := t.fallback
+		t.mu.RUnlock()
+		if p == nil {
+			return nil, fmt.Errorf("auth: %s %s has no topic and the topic tokens have no default provider", req.Method, req.URL.Path)
+		}
+		return p.Credentials(req.Context())
+	}
+	t.mu.RLock()
+	token, ok := t.tokens[id]
+	t.mu.RUnlock()
+	if !ok {
+		return nil, &NoTopicTokenError{TopicID: id}
+	}
+	return &Credentials{TypetalkToken: token}, nil
+}
+
+// TopicID returns the topic of a request: the ID following "topics" in its
+// path, or its topicId parameter.
+func TopicID(req *http.Request) (int, bool) {
+	segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	for i := 0; i+1 < len(segments); i++ {
+		if segments[i] != "topics" {
+			continue
+		}
+		if id, err := strconv.Atoi(segments[i+1]); err == nil {
+			return id, true
+		}
+	}
+	if id, err := strconv.Atoi(req.URL.Query().Get("topicId")); err == nil {
+		return id, true
+	}
+	return 0, false
+}
@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func Test_TopicID_should_find_the_topic_of_a_request(t *testing.T) {
+	cases := []struct {
+		url  string
+		want int
+		ok   bool
+	}{
+		{"https://typetalk.com/api/v1/topics/12", 12, true},
+		{"https://typetalk.com/api/v1/topics/12/posts/3/like", 12, true},
+		{"https://typetalk.com/api/v1/bookmarks?topicId=7&postId=1", 7, true},
+		{"https://typetalk.com/api/v1/topics", 0, false},
+		{"https://typetalk.com/api/v1/profile", 0, false},
+	}
+	for _, c := range cases {
+		req, _ := http.NewRequest(http.MethodGet, c.url, nil)
+		if got, ok := TopicID(req); got != c.want || ok != c.ok {
+			t.Errorf("TopicID(%s): got %d, %v, want %d, %v", c.url, got, ok, c.want, c.ok)
+		}
+	}
+}
+
+func Test_TopicTokens_should_resolve_the_token_of_the_topic(t *testing.T) {
+	tokens := NewTopicTokens(map[int]string{1: "TOKEN1", 2: "TOKEN2"})
+	credentials := func(url string) (*Credentials, error) {
+		req, _ := http.NewRequest(http.MethodPost, url, nil)
+		return tokens.RequestCredentials(req)
+	}
+
+	c, err := credentials("https://typetalk.com/api/v1/topics/2")
+	if err != nil || c.TypetalkToken != "TOKEN2" {
+		t.Errorf("topic 2: got %+v, %v", c, err)
+	}
+	_, err = credentials("https://typetalk.com/api/v1/topics/40/posts")
+	if e, ok := err.(*NoTopicTokenError); !ok || e.TopicID != 40 || e.Error() != "auth: no token is configured for the topic 40" {
+		t.Errorf("topic 40: got %v", err)
+	}
+	if _, err := credentials("https://typetalk.com/api/v1/profile"); err == nil {
+		t.Error("a request without a topic: want an error")
+	}
+	if _, err := tokens.Credentials(context.Background()); err == nil {
+		t.Error("Credentials without a default: want an error")
+	}
+
+	tokens.SetDefault(Static("DEFAULT"))
+	if c, err := credentials("https://typetalk.com/api/v1/profile"); err != nil || c.TypetalkToken != "DEFAULT" {
+		t.Errorf("a request without a topic: got %+v, %v", c, err)
+	}
+	tokens.Set(1, "")
+	if _, err := credentials("https://typetalk.com/api/v1/topics/1"); err == nil {
+		t.Error("a removed topic: want an error")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				tokens.Set(100+i, "TOKEN")
+				credentials("https://typetalk.com/api/v1/topics/2")
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,304 @@
+package bridge
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"text/template"
+
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+	"gopkg.in/yaml.v2"
+)
+
+// DefaultSignatureHeader is the header the HMAC signature of a payload is
+// read from when a route doesn't name one.
+const DefaultSignatureHeader = "X-Hub-Signature-256"
+
+// DefaultMaxBodySize is the size limit of a payload when Bridge.MaxBodySize
+// is zero. It is above the 25 MB cap GitHub documents for its payloads.
+const DefaultMaxBodySize = 32 << 20
+
+// RouteConfig configures how the webhooks received at a path are posted.
+type RouteConfig struct {
+	// Path is the URL path the route is served at.
+	Path string `yaml:"path"`
+	// Secret enables verifying the HMAC-SHA256 signature of the payload.
+	Secret string `yaml:"secret"`
+	// SignatureHeader is the header carrying the hex encoded signature,
+	// optionally prefixed with "sha256=".
+	SignatureHeader string `yaml:"signatureHeader"`
+	// Filter skips the payloads it doesn't match. See Filter.
+	Filter string `yaml:"filter"`
+	// TopicID is the topic the message is posted to.
+	TopicID int `yaml:"topicId"`
+	// Token is the Typetalk Token used to post. Config.Token is used when
+	// it is empty.
+	Token string `yaml:"token"`
+	// Template renders the message from the decoded payload.
+	Template string `yaml:"template"`
+	// FileURLs and FileNames are paths selecting the URLs and names of
+	// files to attach.
+	FileURLs  string `yaml:"fileUrls"`
+	FileNames string `yaml:"fileNames"`
+}
+
+// Config configures a Bridge.
+type Config struct {
+	// Token is the default Typetalk Token used to post.
+	Token  string         `yaml:"token"`
+	Routes []*RouteConfig `yaml:"routes"`
+}
+
+// LoadConfig reads a Config from a YAML or JSON file.
+func LoadConfig(name string) (*Config, error) {
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	config := &Config{}
+	if err := yaml.UnmarshalStrict(b, config); err != nil {
+		return nil, fmt.Errorf("bridge: parsing %s: %v", name, err)
+	}
+	return config, nil
+}
+
+type route struct {
+	config    *RouteConfig
+	filter    Filter
+	template  *template.Template
+	fileURLs  Path
+	fileNames Path
+	client    *v1.Client
+}
+
+// Bridge is an http.Handler that receives arbitrary JSON webhooks and posts
+// them to Typetalk topics as configured by its routes.
+type Bridge struct {
+	routes map[string]*route
+	// ErrorLog is used to log failed posts. The standard logger is used
+	// when it is nil.
+	ErrorLog *log.Logger
+	// MaxBodySize is the size limit of a payload in bytes. Larger payloads
+	// are rejected with 413 Request Entity Too Large. DefaultMaxBodySize is
+	// used when it is zero.
+	MaxBodySize int64
+}
+
+// New returns a Bridge for the configured routes. httpClient is used to
+// call the Typetalk API; http.DefaultClient is used when it is nil.
+func New(config *Config, httpClient *http.Client) (*Bridge, error) {
+	b := &Bridge{routes: map[string]*route{}}
+	for _, c := range config.Routes {
+		r, err := newRoute(c, config.Token, httpClient)
+		if err != nil {
+			return nil, err
+		}
+		p := "/" + strings.Trim(c.Path, "/")
+		if _, ok := b.routes[p]; ok {
+			return nil, fmt.Errorf("bridge: duplicated path %s", p)
+		}
+		b.routes[p] = r
+	}
+	return b, nil
+}
+
+func newRoute(c *RouteConfig, token string, httpClient *http.Client) (*route, error) {
+	if c.TopicID == 0 || c.Template == "" {
+		return nil, fmt.Errorf("bridge: route %s needs a topic id and a template", c.Path)
+	}
+	if c.Token != "" {
+		token = c.Token
+	}
+	if token == "" {
+		return nil, fmt.Errorf("bridge: route %s has no token", c.Path)
+	}
+	r := &route{config: c, client: v1.NewClient(httpClient).SetTypetalkToken(token)}
+	var err error
+	if r.filter, err = ParseFilter(c.Filter); err != nil {
+		return nil, err
+	}
+	if r.template, err = NewTemplate(c.Path, c.Template); err != nil {
+		return nil, fmt.Errorf("bridge: parsing template of %s: %v", c.Path, err)
+	}
+	if c.FileURLs != "" {
+		if r.fileURLs, err = ParsePath(c.FileURLs); err != nil {
+			return nil, err
+		}
+		if c.FileNames != "" {
+			if r.fileNames, err = ParsePath(c.FileNames); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return r, nil
+}
+
+// ServeHTTP verifies, filters and posts the webhook in req.
+func (b *Bridge) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	r, ok := b.routes["/"+strings.Trim(req.URL.Path, "/")]
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+	limit := b.MaxBodySize
+	if limit == 0 {
+		limit = DefaultMaxBodySize
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, req.Body, limit))
+	if err != nil {
+		if int64(len(body)) >= limit {
+			http.Error(w, "payload too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "reading body failed", http.StatusBadRequest)
+		return
+	}
+	if !r.verify(req.Header, body) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	var payload interface{}
+	if err := decodeJSON(body, &payload); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	if !r.filter.Match(payload) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	var buf bytes.Buffer
+	if err := r.template.Execute(&buf, payload); err != nil {
+		b.logf("bridge: rendering %s: %v", r.config.Path, err)
+		http.Error(w, "rendering failed", http.StatusInternalServerError)
+		return
+	}
+	message := strings.TrimSpace(buf.String())
+	if message == "" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if _, _, err := r.client.Messages.PostMessage(req.Context(), r.config.TopicID, message, r.attachments(payload)); err != nil {
+		b.logf("bridge: posting %s to topic %d: %v", r.config.Path, r.config.TopicID, err)
+		http.Error(w, "posting failed", http.StatusBadGateway)
+		return
+	}
+	fmt.Fprint(w, "ok")
+}
+
+func (b *Bridge) logf(format string, args ...interface{}) {
+	if b.ErrorLog != nil {
+		b.ErrorLog.Printf(format, args...)
+	} else {
+		log.Printf(format, args...)
+	}
+}
+
+func (r *route) verify(header http.Header, body []byte) bool {
+	if r.config.Secret == "" {
+		return true
+	}
+	name := r.config.SignatureHeader
+	if name == "" {
+		name = DefaultSignatureHeader
+	}
+	got, err := hex.DecodeString(strings.TrimPrefix(header.Get(name), "sha256="))
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(r.config.Secret))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+func (r *route) attachments(payload interface{}) *v1.PostMessageOptions {
+	opt := &v1.PostMessageOptions{}
+	if r.fileURLs == nil {
+		return opt
+	}
+	var names []interface{}
+	if r.fileNames != nil {
+		names = r.fileNames.Eval(payload)
+	}
+	for i, u := range r.fileURLs.Eval(payload) {
+		s, ok := u.(string)
+		if !ok || s == "" {
+			continue
+		}
+		name := s[strings.LastIndex(s, "/")+1:]
+		if i < len(names) {
+			if n, ok := names[i].(string); ok && n != "" {
+				name = n
+			}
+		}
+		opt.FileUrls = append(opt.FileUrls, s)
+		opt.FileNames = append(opt.FileNames, name)
+	}
+	return opt
+}
+
+// NewTemplate parses a message template. In addition to the text/template
+// builtins, templates can use
+//
+//	path "$.a.b" .      the first value the path selects in the payload
+//	default "x" value   value, or "x" when value is empty
+//	truncate 100 text   text cut to at most 100 characters
+//	join ", " list      the elements of list joined by the separator
+//	json value          value encoded as JSON
+//	upper, lower        change the case of a string
+func NewTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(templateFuncs).Parse(text)
+}
+
+var templateFuncs = template.FuncMap{
+	"path": func(expr string, v interface{}) (interface{}, error) {
+		p, err := ParsePath(expr)
+		if err != nil {
+			return nil, err
+		}
+		return p.First(v), nil
+	},
+	"default": func(def, v interface{}) interface{} {
+		if !isTruthy(v) {
+			return def
+		}
+		return v
+	},
+	"truncate": func(n int, s string) string {
+		r := []rune(s)
+		if len(r) <= n {
+			return s
+		}
+		if n <= 1 {
+			return string(r[:n])
+		}
+		return string(r[:n-1]) + "…"
+	},
+	"join": func(sep string, v interface{}) string {
+		list, ok := v.([]interface{})
+		if !ok {
+			return fmt.Sprint(v)
+		}
+		s := make([]string, len(list))
+		for i, x := range list {
+			s[i] = fmt.Sprint(x)
+		}
+		return strings.Join(s, sep)
+	},
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
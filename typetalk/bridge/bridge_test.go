@@ -0,0 +1,180 @@
+package bridge
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/nulab/go-typetalk/v3/typetalk/internal"
+)
+
+var (
+	mux    *http.ServeMux
+	server *httptest.Server
+)
+
+const fixturesPath = "../../testdata/bridge/"
+
+func setup() {
+	mux = http.NewServeMux()
+	server = httptest.NewServer(mux)
+}
+
+func teardown() {
+	server.Close()
+}
+
+func newTestBridge(t *testing.T) *Bridge {
+	config, err := LoadConfig(fixturesPath + "config.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := New(config, NewTestClient(server))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.ErrorLog = log.New(ioutil.Discard, "", 0)
+	return b
+}
+
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func Test_Bridge_should_post_rendered_github_webhook(t *testing.T) {
+	setup()
+	defer teardown()
+	mux.HandleFunc("/api/v1/topics/1", func(w http.ResponseWriter, r *http.Request) {
+		TestMethod(t, r, http.MethodPost)
+		TestHeader(t, r, "X-Typetalk-Token", "DEFAULT_TOKEN")
+		TestFormValues(t, r, Values{
+			"message": "octocat opened [Add webhook bridge](https://github.com/nulab/go-typetalk/pull/42)\nenhancement",
+		})
+		fmt.Fprint(w, `{"post": {"id": 1}}`)
+	})
+	b := newTestBridge(t)
+	body, _ := ioutil.ReadFile(fixturesPath + "github-pull-request.json")
+
+	req := httptest.NewRequest(http.MethodPost, "/github", bytes.NewReader(body))
+	req.Header.Set(DefaultSignatureHeader, sign("s3cr3t", body))
+	w := httptest.NewRecorder()
+	b.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("response: got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func Test_Bridge_should_attach_files_from_payload(t *testing.T) {
+	setup()
+	defer teardown()
+	mux.HandleFunc("/api/v1/topics/2", func(w http.ResponseWriter, r *http.Request) {
+		TestHeader(t, r, "X-Typetalk-Token", "SENTRY_TOKEN")
+		TestFormValues(t, r, Values{
+			"message":                 "**NullPointerExceptio…** (prod, web)",
+			"attachments[0].fileUrl":  "https://sentry.example.com/files/1/trace.txt",
+			"attachments[0].fileName": "trace.txt",
+			"attachments[1].fileUrl":  "https://sentry.example.com/files/2/screen.png",
+			"attachments[1].fileName": "screen.png",
+		})
+		fmt.Fprint(w, `{"post": {"id": 1}}`)
+	})
+	b := newTestBridge(t)
+	body, _ := ioutil.ReadFile(fixturesPath + "sentry-issue.json")
+
+	w := httptest.NewRecorder()
+	b.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/sentry/", bytes.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Errorf("response: got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func Test_Bridge_should_reject_or_skip_requests(t *testing.T) {
+	setup()
+	defer teardown()
+	mux.HandleFunc("/api/v1/topics/2", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	b := newTestBridge(t)
+	body, _ := ioutil.ReadFile(fixturesPath + "github-pull-request.json")
+	closed := bytes.Replace(body, []byte(`"opened"`), []byte(`"closed"`), 1)
+
+	tests := []struct {
+		method, path, signature string
+		body                    []byte
+		want                    int
+	}{
+		{http.MethodGet, "/github", "", nil, http.StatusMethodNotAllowed},
+		{http.MethodPost, "/unknown", "", body, http.StatusNotFound},
+		{http.MethodPost, "/github", "", body, http.StatusUnauthorized},
+		{http.MethodPost, "/github", sign("wrong", body), body, http.StatusUnauthorized},
+		{http.MethodPost, "/github", sign("s3cr3t", []byte("{")), []byte("{"), http.StatusBadRequest},
+		{http.MethodPost, "/github", sign("s3cr3t", closed), closed, http.StatusNoContent},
+		{http.MethodPost, "/sentry", "", []byte(`{}`), http.StatusInternalServerError},
+		{http.MethodPost, "/sentry", "", []byte(`{"data": {"issue": {"title": "x"}}}`), http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, bytes.NewReader(tt.body))
+		if tt.signature != "" {
+			req.Header.Set(DefaultSignatureHeader, tt.signature)
+		}
+		w := httptest.NewRecorder()
+		b.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%s %s: got %d, want %d", tt.method, tt.path, w.Code, tt.want)
+		}
+	}
+
+	b.MaxBodySize = int64(len(body) - 1)
+	req := httptest.NewRequest(http.MethodPost, "/github", bytes.NewReader(body))
+	req.Header.Set(DefaultSignatureHeader, sign("s3cr3t", body))
+	w := httptest.NewRecorder()
+	b.ServeHTTP(w, req)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("POST /github of %d bytes: got %d, want %d", len(body), w.Code, http.StatusRequestEntityTooLarge)
+	}
+}
+
+func Test_New_should_validate_routes(t *testing.T) {
+	configs := []*Config{
+		{Routes: []*RouteConfig{{Path: "/a", TopicID: 1, Template: "x"}}},
+		{Token: "t", Routes: []*RouteConfig{{Path: "/a", Template: "x"}}},
+		{Token: "t", Routes: []*RouteConfig{{Path: "/a", TopicID: 1, Template: "{{"}}},
+		{Token: "t", Routes: []*RouteConfig{{Path: "/a", TopicID: 1, Template: "x", Filter: "$.a == x"}}},
+		{Token: "t", Routes: []*RouteConfig{{Path: "/a", TopicID: 1, Template: "x", FileURLs: "$["}}},
+		{Token: "t", Routes: []*RouteConfig{{Path: "/a", TopicID: 1, Template: "x"}, {Path: "a/", TopicID: 2, Template: "y"}}},
+	}
+	for i, c := range configs {
+		if _, err := New(c, nil); err == nil {
+			t.Errorf("config %d: expected error to be returned", i)
+		}
+	}
+}
+
+func Test_LoadConfig_should_accept_json(t *testing.T) {
+	f, err := ioutil.TempFile("", "bridge-*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"token": "T", "routes": [{"path": "/a", "topicId": 1, "template": "{{ .x }}"}]}`)
+	f.Close()
+
+	config, err := LoadConfig(f.Name())
+	if err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+	if config.Token != "T" || len(config.Routes) != 1 || config.Routes[0].TopicID != 1 {
+		t.Errorf("config: got %+v", config)
+	}
+	if _, err := LoadConfig(fixturesPath + "github-pull-request.json"); err == nil {
+		t.Error("Expected error to be returned for unknown fields")
+	}
+}
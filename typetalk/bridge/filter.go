@@ -0,0 +1,164 @@
+package bridge
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Filter decides whether a payload is posted. It is a list of conditions
+// joined by "&&", each of which is one of
+//
+//	$.path              the path selects a value that is not false, null or ""
+//	!$.path             the opposite of the above
+//	$.path == literal   a selected value equals the JSON literal
+//	$.path != literal   no selected value equals the JSON literal
+//
+// for example `$.action == "opened" && $.pull_request.draft == false`.
+// Operators inside the string literals, such as `$.title == "a && b"`, are
+// part of the literals.
+type Filter []condition
+
+type condition struct {
+	path    Path
+	op      string
+	literal interface{}
+	negate  bool
+}
+
+// ParseFilter parses a filter expression. An empty expression matches every
+// payload.
+func ParseFilter(s string) (Filter, error) {
+	var filter Filter
+	if strings.TrimSpace(s) == "" {
+		return filter, nil
+	}
+	for _, expr := range splitUnquoted(s, "&&") {
+		expr = strings.TrimSpace(expr)
+		c := condition{}
+		pathExpr := expr
+		i := -1
+		for _, op := range []string{"==", "!="} {
+			if j := indexUnquoted(expr, op); j >= 0 && (i < 0 || j < i) {
+				i, c.op = j, op
+			}
+		}
+		if i >= 0 {
+			pathExpr = expr[:i]
+			if err := decodeJSON([]byte(strings.TrimSpace(expr[i+len(c.op):])), &c.literal); err != nil {
+				return nil, fmt.Errorf("bridge: invalid literal in filter %q: %v", expr, err)
+			}
+		}
+		pathExpr = strings.TrimSpace(pathExpr)
+		if c.op == "" && strings.HasPrefix(pathExpr, "!") {
+			c.negate = true
+			pathExpr = pathExpr[1:]
+		}
+		path, err := ParsePath(pathExpr)
+		if err != nil {
+			return nil, err
+		}
+		c.path = path
+		filter = append(filter, c)
+	}
+	return filter, nil
+}
+
+// indexUnquoted returns the index of the first sep in s which is not inside
+// a JSON string, or -1 if there is none.
+func indexUnquoted(s, sep string) int {
+	quoted := false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case quoted && s[i] == '\\':
+			i++
+		case s[i] == '"':
+			quoted = !quoted
+		case !quoted && strings.HasPrefix(s[i:], sep):
+			return i
+		}
+	}
+	return -1
+}
+
+// splitUnquoted splits s around the seps which are not inside JSON strings.
+func splitUnquoted(s, sep string) []string {
+	var parts []string
+	for {
+		i := indexUnquoted(s, sep)
+		if i < 0 {
+			return append(parts, s)
+		}
+		parts = append(parts, s[:i])
+		s = s[i+len(sep):]
+	}
+}
+
+// Match reports whether the decoded payload v satisfies every condition.
+func (f Filter) Match(v interface{}) bool {
+	for _, c := range f {
+		if !c.match(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c condition) match(v interface{}) bool {
+	values := c.path.Eval(v)
+	switch c.op {
+	case "==":
+		return containsValue(values, c.literal)
+	case "!=":
+		return !containsValue(values, c.literal)
+	}
+	truthy := false
+	for _, x := range values {
+		if isTruthy(x) {
+			truthy = true
+			break
+		}
+	}
+	return truthy != c.negate
+}
+
+func containsValue(values []interface{}, literal interface{}) bool {
+	want, _ := json.Marshal(literal)
+	for _, v := range values {
+		if got, _ := json.Marshal(v); bytes.Equal(got, want) {
+			return true
+		}
+	}
+	return false
+}
+
+func isTruthy(v interface{}) bool {
+	switch x := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return x
+	case string:
+		return x != ""
+	case json.Number:
+		return x.String() != "0"
+	}
+	return true
+}
+
+func decodeJSON(b []byte, v interface{}) error {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	return d.Decode(v)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,49 @@
+package bridge
+
+import "testing"
+
+func Test_Filter_Match(t *testing.T) {
+	var doc interface{}
+	decodeJSON([]byte(`{"action": "opened", "draft": false, "count": 3, "tags": ["a", "b"], "empty": "", "title": "a && b == c", "quote": "say \"!=\""}`), &doc)
+	tests := []struct {
+		filter string
+		want   bool
+	}{
+		{"", true},
+		{`$.action == "opened"`, true},
+		{`$.action != "opened"`, false},
+		{`$.action == "closed"`, false},
+		{`$.count == 3`, true},
+		{`$.draft == false`, true},
+		{`$.tags[*] == "b"`, true},
+		{`$.tags[*] != "c" && $.count`, true},
+		{`$.draft`, false},
+		{`!$.draft`, true},
+		{`$.empty`, false},
+		{`$.missing`, false},
+		{`!$.missing && $.action == "opened"`, true},
+		{`$.title == "a && b == c"`, true},
+		{`$.title != "a && b" && $.action == "opened"`, true},
+		{`$.title == "a && b"`, false},
+		{`$.quote == "say \"!=\""`, true},
+		{`$.action == "opened" && $.title == "a && b == c" && $.count != 2`, true},
+	}
+	for _, tt := range tests {
+		f, err := ParseFilter(tt.filter)
+		if err != nil {
+			t.Errorf("ParseFilter(%q) returned error: %v", tt.filter, err)
+			continue
+		}
+		if got := f.Match(doc); got != tt.want {
+			t.Errorf("Match(%q): got %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+}
+
+func Test_ParseFilter_should_return_error_for_invalid_filter(t *testing.T) {
+	for _, s := range []string{`$.a == opened`, `$.a[ == 1`, `$.a == "b && $.c`} {
+		if _, err := ParseFilter(s); err == nil {
+			t.Errorf("ParseFilter(%q): expected error to be returned", s)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package bridge
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Path is a JSONPath-like expression selecting values in a decoded JSON
+// document. It supports the subset "$.key.key[0].key[*]": member access by
+// name, array access by index and the "*" wildcard over array elements or
+// object members. The leading "$" is optional.
+type Path []pathSegment
+
+type pathSegment struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// ParsePath parses a path expression.
+func ParsePath(s string) (Path, error) {
+	expr := strings.TrimSpace(s)
+	expr = strings.TrimPrefix(expr, "$")
+	var path Path
+	for len(expr) > 0 {
+		switch expr[0] {
+		case '.':
+			expr = expr[1:]
+			end := strings.IndexAny(expr, ".[")
+			if end < 0 {
+				end = len(expr)
+			}
+			key := expr[:end]
+			if key == "" || strings.Contains(key, "]") {
+				return nil, fmt.Errorf("bridge: invalid key %q in path %q", key, s)
+			}
+			if key == "*" {
+				path = append(path, pathSegment{wildcard: true})
+			} else {
+				path = append(path, pathSegment{key: key})
+			}
+			expr = expr[end:]
+		case '[':
+			end := strings.Index(expr, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("bridge: unclosed bracket in path %q", s)
+			}
+			inner := strings.TrimSpace(expr[1:end])
+			switch {
+			case inner == "*":
+				path = append(path, pathSegment{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				path = append(path, pathSegment{key: inner[1 : len(inner)-1]})
+			default:
+				i, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("bridge: invalid index %q in path %q", inner, s)
+				}
+				path = append(path, pathSegment{index: i, isIndex: true})
+			}
+			expr = expr[end+1:]
+		default:
+			if len(path) > 0 {
+				return nil, fmt.Errorf("bridge: unexpected %q in path %q", expr[0], s)
+			}
+			// Allow paths written without the leading "$.".
+			expr = "." + expr
+		}
+	}
+	return path, nil
+}
+
+// MustParsePath is like ParsePath but panics if the expression can't be
+// parsed.
+func MustParsePath(s string) Path {
+	p, err := ParsePath(s)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// Eval returns the values the path selects in v. The result is empty when
+// nothing matches.
+func (p Path) Eval(v interface{}) []interface{} {
+	values := []interface{}{v}
+	for _, seg := range p {
+		var next []interface{}
+		for _, v := range values {
+			switch x := v.(type) {
+			case map[string]interface{}:
+				if seg.wildcard {
+					for _, k := range sortedKeys(x) {
+						next = append(next, x[k])
+					}
+				} else if !seg.isIndex {
+					if child, ok := x[seg.key]; ok {
+						next = append(next, child)
+					}
+				}
+			case []interface{}:
+				if seg.wildcard {
+					next = append(next, x...)
+				} else if seg.isIndex {
+					i := seg.index
+					if i < 0 {
+						i += len(x)
+					}
+					if i >= 0 && i < len(x) {
+						next = append(next, x[i])
+					}
+				}
+			}
+		}
+		values = next
+	}
+	return values
+}
+
+// First returns the first value the path selects in v, or nil.
+func (p Path) First(v interface{}) interface{} {
+	if values := p.Eval(v); len(values) > 0 {
+		return values[0]
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package bridge
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func Test_Path_Eval(t *testing.T) {
+	var doc interface{}
+	decodeJSON([]byte(`{"a": {"b": [{"c": 1}, {"c": 2}, {"d": 3}]}, "x.y": "dotted", "e": null}`), &doc)
+	tests := []struct {
+		path string
+		want []interface{}
+	}{
+		{"$.a.b[0].c", []interface{}{json.Number("1")}},
+		{"a.b[1].c", []interface{}{json.Number("2")}},
+		{"$.a.b[-1].d", []interface{}{json.Number("3")}},
+		{"$.a.b[*].c", []interface{}{json.Number("1"), json.Number("2")}},
+		{"$['x.y']", []interface{}{"dotted"}},
+		{"$.a.*[2].d", []interface{}{json.Number("3")}},
+		{"$.e", []interface{}{nil}},
+		{"$.a.b[5]", nil},
+		{"$.missing.key", nil},
+		{"$", []interface{}{doc}},
+	}
+	for _, tt := range tests {
+		p, err := ParsePath(tt.path)
+		if err != nil {
+			t.Errorf("ParsePath(%q) returned error: %v", tt.path, err)
+			continue
+		}
+		if got := p.Eval(doc); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Eval(%q): got %#v, want %#v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func Test_ParsePath_should_return_error_for_invalid_path(t *testing.T) {
+	for _, s := range []string{"$.a..b", "$.a[0", "$.a[x]", "$.a]b"} {
+		if _, err := ParsePath(s); err == nil {
+			t.Errorf("ParsePath(%q): expected error to be returned", s)
+		}
+	}
+}
+
+func Test_MustParsePath_should_panic_for_invalid_path(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic")
+		}
+	}()
+	MustParsePath("$.a[")
+}
+
+func Test_Path_First(t *testing.T) {
+	var doc interface{}
+	decodeJSON([]byte(`{"a": ["x", "y"]}`), &doc)
+	if got := MustParsePath("$.a[*]").First(doc); got != "x" {
+		t.Errorf("First: got %v, want x", got)
+	}
+	if got := MustParsePath("$.b").First(doc); got != nil {
+		t.Errorf("First: got %v, want nil", got)
+	}
+}
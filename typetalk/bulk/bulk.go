@@ -0,0 +1,264 @@
+// Package bulk runs actions, such as deleting or liking, on all the messages
+// matching a search.
+package bulk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+	v2 "github.com/nulab/go-typetalk/v3/typetalk/v2"
+)
+
+// ErrNotConfirmed is returned when Options.Confirm declines an action.
+var ErrNotConfirmed = errors.New("bulk: action was not confirmed")
+
+// Options configures a Bulk.
+type Options struct {
+	// DryRun reports the posts an action would apply to without applying
+	// it.
+	DryRun bool
+	// Concurrency is the number of requests made at the same time. It
+	// defaults to 4.
+	Concurrency int
+	// Confirm is called with the name of the action and its posts before
+	// the action is applied. The action is applied only when it returns
+	// true. Actions are not confirmed when it is nil or in a dry run.
+	Confirm func(action string, posts []*v2.Post) bool
+}
+
+// Result describes the outcome of an action.
+type Result struct {
+	Action string `json:"action"`
+	DryRun bool   `json:"dryRun"`
+	// Done are the IDs of the posts the action was applied to, or would be
+	// in a dry run.
+	Done []int `json:"done"`
+	// Errors maps the IDs of the posts the action failed on to their
+	// errors.
+	Errors map[int]error `json:"-"`
+}
+
+// Failed returns the sorted IDs of the posts the action failed on.
+func (r *Result) Failed() []int {
+	ids := make([]int, 0, len(r.Errors))
+	for id := range r.Errors {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// Bulk applies actions to many posts.
+type Bulk struct {
+	v1  *v1.Client
+	v2  *v2.Client
+	opt Options
+}
+
+// New returns a Bulk that searches through the v2 client and applies
+// actions through the v1 client.
+func New(clientV1 *v1.Client, clientV2 *v2.Client, opt *Options) *Bulk {
+	b := &Bulk{v1: clientV1, v2: clientV2}
+	if opt != nil {
+		b.opt = *opt
+	}
+	if b.opt.Concurrency <= 0 {
+		b.opt.Concurrency = 4
+	}
+	return b
+}
+
+// Search returns all the posts matching the query, newest first.
+//
+// A search returns a limited number of posts and reports IsLimited when
+// there are more. Search then repeats it for the posts older than the
+// oldest post returned, until all the matches are found.
+func (b *Bulk) Search(ctx context.Context, spaceKey, q string, opt *v2.SearchMessagesOptions) ([]*v2.Post, error) {
+	o := v2.SearchMessagesOptions{}
+	if opt != nil {
+		o = *opt
+	}
+	var posts []*v2.Post
+	seen := map[int]bool{}
+	for {
+		result, _, err := b.v2.Messages.SearchMessages(ctx, spaceKey, q, &o)
+		if err != nil {
+			return posts, err
+		}
+		found := 0
+		for _, p := range result.Posts {
+			if !seen[p.ID] {
+				seen[p.ID] = true
+				posts = append(posts, p)
+				found++
+			}
+		}
+		if !result.IsLimited || found == 0 {
+			break
+		}
+		oldest := result.Posts[0].CreatedAt
+		for _, p := range result.Posts {
+			if p.CreatedAt.Before(oldest) {
+				oldest = p.CreatedAt
+			}
+		}
+		if o.To != nil && !oldest.Before(*o.To) {
+			// Every post shares the same time; searching again can't
+			// return older posts.
+			break
+		}
+		o.To = &oldest
+	}
+	sort.SliceStable(posts, func(i, j int) bool { return posts[i].CreatedAt.After(posts[j].CreatedAt) })
+	return posts, nil
+}
+
+// Delete deletes the posts.
+func (b *Bulk) Delete(ctx context.Context, posts []*v2.Post) (*Result, error) {
+	return b.each(ctx, "delete", posts, func(ctx context.Context, p *v2.Post) error {
+		_, _, err := b.v1.Messages.DeleteMessage(ctx, p.TopicID, p.ID)
+		return err
+	})
+}
+
+// Like likes the posts.
+func (b *Bulk) Like(ctx context.Context, posts []*v2.Post) (*Result, error) {
+	return b.each(ctx, "like", posts, func(ctx context.Context, p *v2.Post) error {
+		_, _, err := b.v1.Messages.LikeMessage(ctx, p.TopicID, p.ID)
+		return err
+	})
+}
+
+// Unlike removes the likes of the posts.
+func (b *Bulk) Unlike(ctx context.Context, posts []*v2.Post) (*Result, error) {
+	return b.each(ctx, "unlike", posts, func(ctx context.Context, p *v2.Post) error {
+		_, _, err := b.v1.Messages.UnlikeMessage(ctx, p.TopicID, p.ID)
+		return err
+	})
+}
+
+// talkBatchSize is the number of posts added to a talk per request.
+const talkBatchSize = 50
+
+// AddToTalk adds the posts to a talk of the topic. Posts of other topics
+// fail, as talks only hold the posts of their topic.
+func (b *Bulk) AddToTalk(ctx context.Context, posts []*v2.Post, topicID, talkID int) (*Result, error) {
+	r := &Result{Action: "talk", DryRun: b.opt.DryRun, Errors: map[int]error{}}
+	var ids []int
+	for _, p := range posts {
+		if p.TopicID != topicID {
+			r.Errors[p.ID] = fmt.Errorf("bulk: post %d is in topic %d, not %d", p.ID, p.TopicID, topicID)
+		} else {
+			ids = append(ids, p.ID)
+		}
+	}
+	if b.opt.DryRun {
+		r.Done = ids
+		return r, nil
+	}
+	if !b.confirm(r.Action, posts) {
+		return nil, ErrNotConfirmed
+	}
+	for len(ids) > 0 {
+		n := talkBatchSize
+		if n > len(ids) {
+			n = len(ids)
+		}
+		batch := ids[:n]
+		ids = ids[n:]
+		if _, _, err := b.v1.Talks.AddMessagesToTalk(ctx, topicID, talkID, batch...); err != nil {
+			for _, id := range batch {
+				r.Errors[id] = err
+			}
+			continue
+		}
+		r.Done = append(r.Done, batch...)
+	}
+	return r, nil
+}
+
+// Export writes the posts to w as a JSON array.
+func (b *Bulk) Export(ctx context.Context, posts []*v2.Post, w io.Writer) (*Result, error) {
+	r := &Result{Action: "export", DryRun: b.opt.DryRun, Errors: map[int]error{}}
+	for _, p := range posts {
+		r.Done = append(r.Done, p.ID)
+	}
+	if b.opt.DryRun {
+		return r, nil
+	}
+	if !b.confirm(r.Action, posts) {
+		return nil, ErrNotConfirmed
+	}
+	if posts == nil {
+		posts = []*v2.Post{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(posts); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (b *Bulk) confirm(action string, posts []*v2.Post) bool {
+	return b.opt.Confirm == nil || b.opt.Confirm(action, posts)
+}
+
+// each applies f to every post, Options.Concurrency posts at a time.
+func (b *Bulk) each(ctx context.Context, action string, posts []*v2.Post, f func(context.Context, *v2.Post) error) (*Result, error) {
+	r := &Result{Action: action, DryRun: b.opt.DryRun, Errors: map[int]error{}}
+	if b.opt.DryRun {
+		for _, p := range posts {
+			r.Done = append(r.Done, p.ID)
+		}
+		return r, nil
+	}
+	if !b.confirm(action, posts) {
+		return nil, ErrNotConfirmed
+	}
+
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		sem  = make(chan struct{}, b.opt.Concurrency)
+		done = map[int]bool{}
+	)
+	for _, p := range posts {
+		if err := ctx.Err(); err != nil {
+			mu.Lock()
+			r.Errors[p.ID] = err
+			mu.Unlock()
+			continue
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(p *v2.Post) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			err := f(ctx, p)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				r.Errors[p.ID] = err
+			} else {
+				done[p.ID] = true
+			}
+		}(p)
+	}
+	wg.Wait()
+	// Keep the order of the posts.
+	for _, p := range posts {
+		if done[p.ID] {
+			r.Done = append(r.Done, p.ID)
+		}
+	}
+	return r, nil
+}
@@ -0,0 +1,229 @@
+package bulk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/nulab/go-typetalk/v3/typetalk/internal"
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+	v2 "github.com/nulab/go-typetalk/v3/typetalk/v2"
+)
+
+var (
+	mux      *http.ServeMux
+	clientV1 *v1.Client
+	clientV2 *v2.Client
+	server   *httptest.Server
+)
+
+func setup() {
+	mux = http.NewServeMux()
+	server = httptest.NewServer(mux)
+
+	clientV1 = v1.NewClient(NewTestClient(server)).SetTypetalkToken("DUMMY_TOKEN")
+	clientV2 = v2.NewClient(NewTestClient(server)).SetTypetalkToken("DUMMY_TOKEN")
+}
+
+func teardown() {
+	server.Close()
+}
+
+func testPosts(n int) []*v2.Post {
+	base := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	var posts []*v2.Post
+	for i := 1; i <= n; i++ {
+		posts = append(posts, &v2.Post{ID: i, TopicID: 1 + i%2, CreatedAt: base.Add(time.Duration(i) * time.Minute)})
+	}
+	return posts
+}
+
+func Test_Bulk_Search_should_page_beyond_limited_results(t *testing.T) {
+	setup()
+	defer teardown()
+	all := testPosts(5)
+	calls := 0
+	mux.HandleFunc("/api/v2/search/posts", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		q := r.URL.Query()
+		if q.Get("q") != "incident" || q.Get("spaceKey") != "space" {
+			t.Errorf("Query: got %v", q)
+		}
+		to := time.Now()
+		if s := q.Get("to"); s != "" {
+			to, _ = time.Parse(time.RFC3339, s)
+		}
+		var matched []*v2.Post
+		for i := len(all) - 1; i >= 0; i-- {
+			if !all[i].CreatedAt.After(to) {
+				matched = append(matched, all[i])
+			}
+		}
+		result := &v2.SearchMessagesResult{Count: len(matched), Posts: matched}
+		if len(matched) > 2 {
+			result.Posts, result.IsLimited = matched[:2], true
+		}
+		json.NewEncoder(w).Encode(result)
+	})
+	b := New(clientV1, clientV2, nil)
+
+	posts, err := b.Search(context.Background(), "space", "incident", nil)
+	if err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+	var ids []int
+	for _, p := range posts {
+		ids = append(ids, p.ID)
+	}
+	if !reflect.DeepEqual(ids, []int{5, 4, 3, 2, 1}) {
+		t.Errorf("Posts: got %v", ids)
+	}
+	if calls != 4 {
+		t.Errorf("Searched %d times, want 4", calls)
+	}
+}
+
+func Test_Bulk_Delete_should_report_per_post_errors(t *testing.T) {
+	setup()
+	defer teardown()
+	var (
+		mu      sync.Mutex
+		deleted []string
+	)
+	for _, topicID := range []int{1, 2} {
+		mux.HandleFunc(fmt.Sprintf("/api/v1/topics/%d/posts/", topicID), func(w http.ResponseWriter, r *http.Request) {
+			TestMethod(t, r, http.MethodDelete)
+			if strings.HasSuffix(r.URL.Path, "/3") {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			mu.Lock()
+			deleted = append(deleted, strings.TrimPrefix(r.URL.Path, "/api/v1/topics/"))
+			mu.Unlock()
+			fmt.Fprint(w, `{}`)
+		})
+	}
+	var confirmed []int
+	b := New(clientV1, clientV2, &Options{Concurrency: 2, Confirm: func(action string, posts []*v2.Post) bool {
+		if action != "delete" {
+			t.Errorf("Confirm: got action %q", action)
+		}
+		for _, p := range posts {
+			confirmed = append(confirmed, p.ID)
+		}
+		return true
+	}})
+
+	r, err := b.Delete(context.Background(), testPosts(4))
+	if err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+	sort.Strings(deleted)
+	if !reflect.DeepEqual(deleted, []string{"1/posts/2", "1/posts/4", "2/posts/1"}) {
+		t.Errorf("Deleted: got %v", deleted)
+	}
+	if !reflect.DeepEqual(r.Done, []int{1, 2, 4}) || !reflect.DeepEqual(r.Failed(), []int{3}) {
+		t.Errorf("Result: done %v, failed %v", r.Done, r.Failed())
+	}
+	if len(confirmed) != 4 {
+		t.Errorf("Confirmed: got %v", confirmed)
+	}
+}
+
+func Test_Bulk_should_not_act_in_dry_run_or_when_declined(t *testing.T) {
+	setup()
+	defer teardown()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+	})
+	posts := testPosts(3)
+
+	dry := New(clientV1, clientV2, &Options{DryRun: true, Confirm: func(string, []*v2.Post) bool {
+		t.Error("Dry runs should not be confirmed")
+		return false
+	}})
+	for _, action := range []func(context.Context, []*v2.Post) (*Result, error){dry.Delete, dry.Like, dry.Unlike} {
+		r, err := action(context.Background(), posts)
+		if err != nil || !r.DryRun || len(r.Done) != 3 {
+			t.Errorf("Dry run: got %+v, %v", r, err)
+		}
+	}
+
+	declined := New(clientV1, clientV2, &Options{Confirm: func(string, []*v2.Post) bool { return false }})
+	if _, err := declined.Like(context.Background(), posts); err != ErrNotConfirmed {
+		t.Errorf("Declined: got %v", err)
+	}
+	if _, err := declined.AddToTalk(context.Background(), posts, 1, 1); err != ErrNotConfirmed {
+		t.Errorf("Declined: got %v", err)
+	}
+}
+
+func Test_Bulk_Like_and_Unlike(t *testing.T) {
+	setup()
+	defer teardown()
+	var (
+		mu      sync.Mutex
+		methods = map[string]int{}
+	)
+	for _, topicID := range []int{1, 2} {
+		mux.HandleFunc(fmt.Sprintf("/api/v1/topics/%d/posts/", topicID), func(w http.ResponseWriter, r *http.Request) {
+			if !strings.HasSuffix(r.URL.Path, "/like") {
+				t.Errorf("Unexpected path %s", r.URL.Path)
+			}
+			mu.Lock()
+			methods[r.Method]++
+			mu.Unlock()
+			fmt.Fprint(w, `{}`)
+		})
+	}
+	b := New(clientV1, clientV2, nil)
+	b.Like(context.Background(), testPosts(3))
+	b.Unlike(context.Background(), testPosts(2))
+	if methods[http.MethodPost] != 3 || methods[http.MethodDelete] != 2 {
+		t.Errorf("Requests: got %v", methods)
+	}
+}
+
+func Test_Bulk_AddToTalk_should_add_posts_of_the_topic_in_batches(t *testing.T) {
+	setup()
+	defer teardown()
+	var batches []int
+	mux.HandleFunc("/api/v1/topics/2/talks/9/posts", func(w http.ResponseWriter, r *http.Request) {
+		TestMethod(t, r, http.MethodPost)
+		r.ParseForm()
+		batches = append(batches, len(r.PostForm))
+		fmt.Fprint(w, `{}`)
+	})
+	b := New(clientV1, clientV2, nil)
+
+	r, err := b.AddToTalk(context.Background(), testPosts(120), 2, 9)
+	if err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+	if !reflect.DeepEqual(batches, []int{50, 10}) || len(r.Done) != 60 || len(r.Failed()) != 60 {
+		t.Errorf("Batches %v, done %d, failed %d", batches, len(r.Done), len(r.Failed()))
+	}
+}
+
+func Test_Bulk_Export(t *testing.T) {
+	b := New(nil, nil, nil)
+	var buf bytes.Buffer
+	r, err := b.Export(context.Background(), testPosts(2), &buf)
+	if err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+	var got []*v2.Post
+	json.Unmarshal(buf.Bytes(), &got)
+	if len(got) != 2 || got[1].ID != 2 || len(r.Done) != 2 {
+		t.Errorf("Exported: got %s", buf.String())
+	}
+}
@@ -0,0 +1,352 @@
+// Package cassette records the HTTP interactions of a client with the
+// Typetalk API into a file, and replays them in tests that run without
+// credentials or network access.
+//
+// A Recorder is an http.RoundTripper, so it can be used by any client:
+//
+//	rec, err := cassette.New("testdata/profile.json", cassette.ModeReplay, nil)
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	defer rec.Stop()
+//	client := v1.NewClient(rec.Client())
+//
+// Tokens are never written to a cassette: the Authorization, X-Typetalk-Token
+// and cookie headers, the token parameters of requests and the tokens of
+// responses are replaced by Redacted.
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Mode is the mode of a Recorder.
+type Mode int
+
+const (
+	// ModeReplay answers requests with the interactions of the cassette.
+	ModeReplay Mode = iota
+	// ModeRecord sends requests and records the interactions into the
+	// cassette.
+	ModeRecord
+)
+
+// Redacted replaces tokens in cassettes.
+const Redacted = "REDACTED"
+
+var (
+	redactedHeaders         = []string{"Authorization", "Cookie", "X-Typetalk-Token"}
+	redactedResponseHeaders = []string{"Set-Cookie"}
+	redactedParams          = []string{"access_token", "client_secret", "refresh_token", "typetalkToken"}
+)
+
+// Cassette is the content of a cassette file.
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Interaction is a request and the response it got.
+type Interaction struct {
+	Request  *Request  `json:"request"`
+	Response *Response `json:"response"`
+}
+
+// Request is a recorded request. Form holds both the query and the
+// form-encoded body, and is what requests are matched on, with the method
+// and the path. Multipart and binary bodies are not recorded.
+type Request struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Form   url.Values  `json:"form,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+}
+
+// Response is a recorded response. The body is kept as JSON when it is
+// valid JSON, as text otherwise, or is read from BodyFile, a path relative
+// to the cassette, so that existing fixtures can be reused.
+type Response struct {
+	Status   int             `json:"status"`
+	Header   http.Header     `json:"header,omitempty"`
+	Body     json.RawMessage `json:"body,omitempty"`
+	Text     string          `json:"text,omitempty"`
+	BodyFile string          `json:"bodyFile,omitempty"`
+}
+
+// Options configures a Recorder.
+type Options struct {
+	// Transport sends the requests in ModeRecord. http.DefaultTransport is
+	// used when it is nil.
+	Transport http.RoundTripper
+	// IgnoreParams are parameters which are not compared when matching
+	// requests, such as timestamps that change on every run.
+	IgnoreParams []string
+}
+
+// Recorder is an http.RoundTripper which records or replays a cassette.
+type Recorder struct {
+	path     string
+	mode     Mode
+	opt      Options
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+// New returns a Recorder of the cassette at path. In ModeReplay the cassette
+// must exist; in ModeRecord it is written by Stop.
+func New(path string, mode Mode, opt *Options) (*Recorder, error) {
+	if opt == nil {
+		opt = &Options{}
+	}
+	r := &Recorder{path: path, mode: mode, opt: *opt, cassette: &Cassette{}}
+	if r.opt.Transport == nil {
+		r.opt.Transport = http.DefaultTransport
+	}
+	if mode == ModeReplay {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("cassette: %v", err)
+		}
+		if err := json.Unmarshal(b, r.cassette); err != nil {
+			return nil, fmt.Errorf("cassette: %s: %v", path, err)
+		}
+		r.used = make([]bool, len(r.cassette.Interactions))
+	}
+	return r, nil
+}
+
+// Mode returns the mode of the recorder.
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// Client returns an http.Client using the recorder.
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// RoundTrip records or replays a request. In ModeReplay, a request that
+// matches none of the unused interactions of the cassette is an error.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded, req, err := newRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if r.mode == ModeReplay {
+		return r.replay(req, recorded)
+	}
+
+	resp, err := r.opt.Transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	response := &Response{Status: resp.StatusCode, Header: redactHeader(resp.Header, redactedResponseHeaders)}
+	if json.Valid(body) {
+		response.Body = redactJSON(body)
+	} else {
+		response.Text = string(body)
+	}
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, &Interaction{Request: recorded, Response: response})
+	r.mu.Unlock()
+	return resp, nil
+}
+
+func (r *Recorder) replay(req *http.Request, recorded *Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, in := range r.cassette.Interactions {
+		if r.used[i] || !r.matches(in.Request, recorded) {
+			continue
+		}
+		r.used[i] = true
+		body := []byte(in.Response.Text)
+		switch {
+		case in.Response.BodyFile != "":
+			b, err := ioutil.ReadFile(filepath.Join(filepath.Dir(r.path), in.Response.BodyFile))
+			if err != nil {
+				return nil, fmt.Errorf("cassette: %v", err)
+			}
+			body = b
+		case in.Response.Body != nil:
+			body = in.Response.Body
+		}
+		header := in.Response.Header.Clone()
+		if header == nil {
+			header = http.Header{}
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.Response.Status, http.StatusText(in.Response.Status)),
+			StatusCode:    in.Response.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          ioutil.NopCloser(bytes.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("cassette: no interaction of %s matches %s %s %s", r.path, recorded.Method, recorded.Path, r.normalize(recorded.Form).Encode())
+}
+
+func (r *Recorder) matches(a, b *Request) bool {
+	if a.Method != b.Method || strings.TrimSuffix(a.Path, "/") != strings.TrimSuffix(b.Path, "/") {
+		return false
+	}
+	fa, fb := r.normalize(a.Form), r.normalize(b.Form)
+	if len(fa) != len(fb) {
+		return false
+	}
+	for k, va := range fa {
+		vb, ok := fb[k]
+		if !ok || strings.Join(va, "\x00") != strings.Join(vb, "\x00") {
+			return false
+		}
+	}
+	return true
+}
+
+// normalize returns the form without the ignored and redacted parameters,
+// with sorted values.
+func (r *Recorder) normalize(form url.Values) url.Values {
+	n := url.Values{}
+	for k, vs := range form {
+		if contains(r.opt.IgnoreParams, k) || contains(redactedParams, k) {
+			continue
+		}
+		vs = append([]string(nil), vs...)
+		sort.Strings(vs)
+		n[k] = vs
+	}
+	return n
+}
+
+// Stop ends the recording: in ModeRecord, the cassette is written.
+func (r *Recorder) Stop() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.path, append(b, '\n'), 0644)
+}
+
+// newRequest returns the redacted record of req and the request to send. A
+// form body is read to be recorded, so a clone of req with a copy of the body
+// is sent, as a RoundTripper must not modify its request.
+func newRequest(req *http.Request) (*Request, *http.Request, error) {
+	form := url.Values{}
+	for k, vs := range req.URL.Query() {
+		form[k] = vs
+	}
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if req.Body != nil && mediaType == "application/x-www-form-urlencoded" {
+		body, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+		req = req.Clone(req.Context())
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil, nil, fmt.Errorf("cassette: %v", err)
+		}
+		for k, vs := range values {
+			form[k] = append(form[k], vs...)
+		}
+	}
+	for _, k := range redactedParams {
+		if _, ok := form[k]; ok {
+			form[k] = []string{Redacted}
+		}
+	}
+	if len(form) == 0 {
+		form = nil
+	}
+	return &Request{Method: req.Method, Path: req.URL.Path, Form: form, Header: redactHeader(req.Header, redactedHeaders)}, req, nil
+}
+
+// redactHeader returns a copy of header with the values of keys redacted.
+func redactHeader(header http.Header, keys []string) http.Header {
+	header = header.Clone()
+	for _, k := range keys {
+		if header.Get(k) != "" {
+			header.Set(k, Redacted)
+		}
+	}
+	return header
+}
+
+// redactJSON replaces the tokens of a JSON body. The body is returned as is
+// when it holds none.
+func redactJSON(body []byte) []byte {
+	var v interface{}
+	d := json.NewDecoder(bytes.NewReader(body))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil || !redact(v) {
+		return body
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return body
+	}
+	return b
+}
+
+func redact(v interface{}) bool {
+	redacted := false
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			if contains(redactedParams, k) {
+				v[k] = Redacted
+				redacted = true
+			} else if redact(e) {
+				redacted = true
+			}
+		}
+	case []interface{}:
+		for _, e := range v {
+			if redact(e) {
+				redacted = true
+			}
+		}
+	}
+	return redacted
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
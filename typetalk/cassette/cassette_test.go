@@ -0,0 +1,171 @@
+package cassette
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/nulab/go-typetalk/v3/typetalk/internal"
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+)
+
+const (
+	fixturesPath = "../../testdata/v1/"
+)
+
+var (
+	mux    *http.ServeMux
+	server *httptest.Server
+	dir    string
+)
+
+func setup() {
+	mux = http.NewServeMux()
+	server = httptest.NewServer(mux)
+	dir, _ = ioutil.TempDir("", "cassette")
+}
+
+func teardown() {
+	server.Close()
+	os.RemoveAll(dir)
+}
+
+func Test_Recorder_should_record_and_replay_interactions(t *testing.T) {
+	setup()
+	defer teardown()
+	mux.HandleFunc("/api/v1/profile", func(w http.ResponseWriter, r *http.Request) {
+		TestMethod(t, r, "GET")
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "SECRET_SESSION"})
+		b, _ := ioutil.ReadFile(fixturesPath + "get-my-profile.json")
+		fmt.Fprint(w, string(b))
+	})
+	mux.HandleFunc("/api/v1/topics/1", func(w http.ResponseWriter, r *http.Request) {
+		TestMethod(t, r, "POST")
+		TestFormValues(t, r, Values{"message": "hello", "replyTo": 2})
+		fmt.Fprint(w, `{"post":{"id":3,"message":"hello"},"access_token":"live"}`)
+	})
+	path := filepath.Join(dir, "cassette.json")
+
+	rec, err := New(path, ModeRecord, &Options{Transport: NewTestClient(server).Transport})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := v1.NewClient(rec.Client()).SetTypetalkToken("SECRET_TOKEN")
+	ctx := context.Background()
+	if _, _, err := client.Accounts.GetMyProfile(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := client.Messages.PostMessage(ctx, 1, "hello", &v1.PostMessageOptions{ReplyTo: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "SECRET_TOKEN") || strings.Contains(string(b), "live") || strings.Contains(string(b), "SECRET_SESSION") {
+		t.Errorf("cassette contains a token: %s", b)
+	}
+
+	server.Close()
+	rec, err = New(path, ModeReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client = v1.NewClient(rec.Client())
+	profile, _, err := client.Accounts.GetMyProfile(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.Account.Name == "" {
+		t.Errorf("GetMyProfile: got %+v", profile.Account)
+	}
+	posted, _, err := client.Messages.PostMessage(ctx, 1, "hello", &v1.PostMessageOptions{ReplyTo: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if posted.Post.ID != 3 {
+		t.Errorf("PostMessage: got %+v", posted.Post)
+	}
+	if _, _, err := client.Accounts.GetMyProfile(ctx); err == nil || !strings.Contains(err.Error(), "no interaction") {
+		t.Errorf("replaying an interaction twice: got %v", err)
+	}
+	if _, _, err := client.Messages.PostMessage(ctx, 1, "bye", nil); err == nil || !strings.Contains(err.Error(), "POST /api/v1/topics/1 message=bye") {
+		t.Errorf("unmatched request: got %v", err)
+	}
+}
+
+func Test_Recorder_RoundTrip_should_not_modify_the_request(t *testing.T) {
+	setup()
+	defer teardown()
+	mux.HandleFunc("/api/v1/topics/1", func(w http.ResponseWriter, r *http.Request) {
+		TestFormValues(t, r, Values{"message": "hello"})
+		fmt.Fprint(w, `{"post":{"id":3}}`)
+	})
+	rec, err := New(filepath.Join(dir, "cassette.json"), ModeRecord, &Options{Transport: NewTestClient(server).Transport})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/api/v1/topics/1", strings.NewReader("message=hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	body := req.Body
+	resp, err := rec.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if req.Body != body {
+		t.Error("RoundTrip replaced the body of the request")
+	}
+}
+
+func Test_Recorder_should_replay_fixtures_and_ignore_params(t *testing.T) {
+	setup()
+	defer teardown()
+	abs, _ := filepath.Abs(fixturesPath + "get-likes-give.json")
+	path := filepath.Join(dir, "cassette.json")
+	cassette := `{"interactions":[{
+		"request":{"method":"GET","path":"/api/v1/likes/give","form":{"from":["1"]}},
+		"response":{"status":200,"bodyFile":` + fmt.Sprintf("%q", relative(t, dir, abs)) + `}
+	}]}`
+	if err := ioutil.WriteFile(path, []byte(cassette), 0644); err != nil {
+		t.Fatal(err)
+	}
+	rec, err := New(path, ModeReplay, &Options{IgnoreParams: []string{"from"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := v1.NewClient(rec.Client())
+	likes, _, err := client.Likes.GetLikesGive(context.Background(), &v1.GetLikesOptions{From: 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(likes) == 0 {
+		t.Error("GetLikesGive: got no likes")
+	}
+}
+
+func Test_New_should_fail_to_replay_a_missing_cassette(t *testing.T) {
+	if _, err := New("testdata/missing.json", ModeReplay, nil); err == nil {
+		t.Error("want an error")
+	}
+}
+
+func relative(t *testing.T, base, target string) string {
+	rel, err := filepath.Rel(base, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rel
+}
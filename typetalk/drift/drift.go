@@ -0,0 +1,65 @@
+// Package drift detects drift between the JSON of the Typetalk API and the
+// models of this library: the JSON which the models drop, the JSON which
+// they keep untyped in interface{} fields, and the fields that the JSON
+// never sets.
+//
+//	report, err := drift.Check(body, &v1.MyProfile{})
+//
+// Endpoints maps the fixtures under testdata and the requests of the
+// clients to their models, so that Transport can check live responses.
+package drift
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/nulab/go-typetalk/v3/typetalk/internal"
+)
+
+// Report is the drift of a JSON document. Paths are made of the JSON names,
+// with "[]" for the elements of arrays and "*" for the values of maps, such
+// as "posts[].account.name".
+type Report struct {
+	// Unknown are the paths that no field of the model represents.
+	Unknown []string
+	// Untyped are the paths of objects and arrays decoded into interface{}.
+	Untyped []string
+	// Unpopulated are the paths of the fields that the JSON never sets.
+	// The fields of a struct that is never set are not listed.
+	Unpopulated []string
+}
+
+// Empty reports whether the report has no drift.
+func (r *Report) Empty() bool {
+	return len(r.Unknown) == 0 && len(r.Untyped) == 0 && len(r.Unpopulated) == 0
+}
+
+// String returns the report with one path per line, each prefixed by its
+// kind, such as "unknown posts[].links".
+func (r *Report) String() string {
+	var b strings.Builder
+	for _, kind := range []struct {
+		name  string
+		paths []string
+	}{{"unknown", r.Unknown}, {"untyped", r.Untyped}, {"unpopulated", r.Unpopulated}} {
+		for _, p := range kind.paths {
+			fmt.Fprintf(&b, "%s %s\n", kind.name, p)
+		}
+	}
+	return b.String()
+}
+
+// Check decodes data into model, which must be a pointer, and returns the
+// drift between them. Decoding errors are returned as is.
+func Check(data []byte, model interface{}) (*Report, error) {
+	if err := json.Unmarshal(data, model); err != nil {
+		return nil, err
+	}
+	d, err := internal.CheckJSON(data, reflect.TypeOf(model))
+	if err != nil {
+		return nil, err
+	}
+	return &Report{Unknown: d.Unknown, Untyped: d.Untyped, Unpopulated: d.Unpopulated}, nil
+}
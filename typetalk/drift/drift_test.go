@@ -0,0 +1,178 @@
+package drift
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/nulab/go-typetalk/v3/typetalk/internal"
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+)
+
+const (
+	fixturesPath = "../../testdata/"
+	reportPath   = fixturesPath + "drift/fixtures.txt"
+)
+
+var update = flag.Bool("update", false, "update "+reportPath)
+
+type base struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type model struct {
+	base
+	Name      string            `json:"fullName"`
+	Tags      []*base           `json:"tags"`
+	Labels    map[string]*base  `json:"labels"`
+	Extra     interface{}       `json:"extra"`
+	CreatedAt *time.Time        `json:"createdAt"`
+	Ignored   string            `json:"-"`
+	Unset     *base             `json:"unset"`
+	Never     string            `json:"never"`
+	Headers   map[string]string `json:"headers"`
+}
+
+func Test_Check_should_report_drift(t *testing.T) {
+	data := `{
+		"id": 1, "name": "base", "fullName": "model", "Headers": {"a": "b"},
+		"tags": [{"id": 2, "color": "red"}, {"name": "tag"}],
+		"labels": {"a": {"id": 3, "size": 1}},
+		"extra": {"key": "value"},
+		"createdAt": "2020-01-01T00:00:00Z",
+		"unknown": {"nested": true}
+	}`
+	m := &model{}
+	report, err := Check([]byte(data), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Report{
+		Unknown:     []string{"labels.*.size", "tags[].color", "unknown"},
+		Untyped:     []string{"extra"},
+		Unpopulated: []string{"labels.*.name", "never", "unset"},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("Check:\n got  %+v,\n want %+v", report, want)
+	}
+	if m.Name != "model" || m.base.Name != "base" || len(m.Tags) != 2 {
+		t.Errorf("Check does not decode the model: %+v", m)
+	}
+	if _, err := Check([]byte(`{"id": "1"}`), &model{}); err == nil {
+		t.Error("Check: want a decoding error")
+	}
+}
+
+func Test_Endpoints_should_cover_the_fixtures_with_known_drift(t *testing.T) {
+	known := map[string]bool{}
+	for _, e := range Endpoints {
+		known[e.Fixture] = true
+	}
+	for _, v := range []string{"v1", "v2", "v3", "v4", "v5"} {
+		files, _ := filepath.Glob(fixturesPath + v + "/*.json")
+		for _, f := range files {
+			if name := v + "/" + strings.TrimSuffix(filepath.Base(f), ".json"); !known[name] {
+				t.Errorf("the fixture %s has no endpoint", name)
+			}
+		}
+	}
+
+	var b strings.Builder
+	for _, e := range Endpoints {
+		data, err := ioutil.ReadFile(fixturesPath + e.Fixture + ".json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		report, err := Check(data, e.New())
+		if err != nil {
+			t.Errorf("%s: %v", e.Fixture, err)
+			continue
+		}
+		if !report.Empty() {
+			fmt.Fprintf(&b, "# %s\n%s\n", e.Fixture, report)
+		}
+	}
+	if *update {
+		if err := ioutil.WriteFile(reportPath, []byte(b.String()), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := ioutil.ReadFile(reportPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); got != string(want) {
+		t.Errorf("the drift of the fixtures changed, check it and run go test -update:\n%s", got)
+	}
+}
+
+func Test_Lookup_should_find_the_endpoint_of_a_request(t *testing.T) {
+	cases := []struct {
+		method, path, want string
+	}{
+		{"GET", "/api/v1/profile", "v1/get-my-profile"},
+		{"GET", "/api/v1/profile/alice", "v1/get-friend-profile"},
+		{"POST", "/api/v2/spaces/abc/messages/@alice", "v2/post-direct-message"},
+		{"GET", "/api/v1/topics/1", "v1/get-topic-messages"},
+		{"PUT", "/api/v1/topics/1", "v1/update-topic"},
+		{"GET", "/api/v1/topics/1/unknown", ""},
+		{"GET", "/api/v2/spaces/abc/messages/alice", ""},
+	}
+	for _, c := range cases {
+		got := ""
+		if e := Lookup(c.method, c.path); e != nil {
+			got = e.Fixture
+		}
+		if got != c.want {
+			t.Errorf("Lookup(%s, %s): got %q, want %q", c.method, c.path, got, c.want)
+		}
+	}
+}
+
+func Test_Transport_should_report_the_drift_of_responses(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	mux.HandleFunc("/api/v1/profile", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"account":{"id":1,"name":"me","nickname":"new"}}`)
+	})
+	mux.HandleFunc("/api/v1/profile/unknown", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "", http.StatusNotFound)
+	})
+
+	var reports []*Report
+	transport := &Transport{
+		Base: NewTestClient(server).Transport,
+		Report: func(req *http.Request, e *Endpoint, r *Report, err error) {
+			if err != nil {
+				t.Error(err)
+			}
+			if e.Fixture != "v1/get-my-profile" {
+				t.Errorf("Report: got the endpoint %s", e.Fixture)
+			}
+			reports = append(reports, r)
+		},
+	}
+	client := v1.NewClient(&http.Client{Transport: transport})
+	profile, _, err := client.Accounts.GetMyProfile(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.Account.Name != "me" {
+		t.Errorf("GetMyProfile: got %+v", profile.Account)
+	}
+	client.Accounts.GetFriendProfile(context.Background(), "unknown")
+
+	if len(reports) != 1 || !reflect.DeepEqual(reports[0].Unknown, []string{"account.nickname"}) {
+		t.Errorf("Report: got %+v", reports)
+	}
+}
@@ -0,0 +1,285 @@
+package drift
+
+import (
+	"net/http"
+	"strings"
+
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+	v2 "github.com/nulab/go-typetalk/v3/typetalk/v2"
+	v3 "github.com/nulab/go-typetalk/v3/typetalk/v3"
+	v4 "github.com/nulab/go-typetalk/v3/typetalk/v4"
+	v5 "github.com/nulab/go-typetalk/v3/typetalk/v5"
+)
+
+// Endpoint is an endpoint of the API and the model its responses are
+// decoded into. Responses that the clients unwrap, such as the likedPosts of
+// GetLikesGive, are modelled by the wrapping struct.
+type Endpoint struct {
+	// Fixture is the name of the fixture of the endpoint under testdata,
+	// such as "v1/get-my-profile".
+	Fixture string
+	// Method and Path match the requests of the endpoint. Path is relative
+	// to the API root, where "*" matches a segment and "@*" a segment
+	// starting with "@".
+	Method string
+	Path   string
+	// New returns a new model.
+	New func() interface{}
+}
+
+// Endpoints are the endpoints with a fixture. The details of a topic have no
+// Path, because the clients request them with the same path as its
+// messages.
+var Endpoints = []*Endpoint{
+	{"v1/add-messages-to-talk", http.MethodPost, "v1/topics/*/talks/*/posts", func() interface{} {
+		return &v1.MessagesInTalk{}
+	}},
+	{"v1/create-talk", http.MethodPost, "v1/topics/*/talks", func() interface{} {
+		return &v1.CreatedTalkResult{}
+	}},
+	{"v1/create-topic", http.MethodPost, "v1/topics", func() interface{} {
+		return &v1.TopicDetails{}
+	}},
+	{"v1/delete-message", http.MethodDelete, "v1/topics/*/posts/*", func() interface{} {
+		return &v1.Post{}
+	}},
+	{"v1/delete-talk", http.MethodDelete, "v1/topics/*/talks/*", func() interface{} {
+		return &v1.DeletedTalkResult{}
+	}},
+	{"v1/delete-topic", http.MethodDelete, "v1/topics/*", func() interface{} {
+		return &v1.Topic{}
+	}},
+	{"v1/favorite-topic", http.MethodPost, "v1/topics/*/favorite", func() interface{} {
+		return &v1.FavoriteTopic{}
+	}},
+	{"v1/get-direct-messages", http.MethodGet, "v1/messages/@*", func() interface{} {
+		return &v1.DirectMessages{}
+	}},
+	{"v1/get-friend-profile", http.MethodGet, "v1/profile/*", func() interface{} {
+		return &v1.Profile{}
+	}},
+	{"v1/get-likes-discover", http.MethodGet, "v1/likes/discover", func() interface{} {
+		return &struct {
+			LikedPosts []*v1.DiscoverLikedPost `json:"likedPosts"`
+		}{}
+	}},
+	{"v1/get-likes-give", http.MethodGet, "v1/likes/give", func() interface{} {
+		return &struct {
+			LikedPosts []*v1.GiveLikedPost `json:"likedPosts"`
+		}{}
+	}},
+	{"v1/get-likes-receive", http.MethodGet, "v1/likes/receive", func() interface{} {
+		return &struct {
+			LikedPosts []*v1.ReceiveLikedPost `json:"likedPosts"`
+		}{}
+	}},
+	{"v1/get-mention-list", http.MethodGet, "v1/mentions", func() interface{} {
+		return &struct {
+			Mentions []*v1.Mention `json:"mentions"`
+		}{}
+	}},
+	{"v1/get-message", http.MethodGet, "v1/topics/*/posts/*", func() interface{} {
+		return &v1.Message{}
+	}},
+	{"v1/get-messages-in-talk", http.MethodGet, "v1/topics/*/talks/*/posts", func() interface{} {
+		return &v1.MessagesInTalk{}
+	}},
+	{"v1/get-my-direct-message-topics", http.MethodGet, "v1/messages", func() interface{} {
+		return &struct {
+			Topics []*v1.DirectMessageTopic `json:"topics"`
+		}{}
+	}},
+	{"v1/get-my-friends", http.MethodGet, "v1/search/friends", func() interface{} {
+		return &v1.Friends{}
+	}},
+	{"v1/get-my-organizations", http.MethodGet, "v1/spaces", func() interface{} {
+		return &struct {
+			MySpaces []*v1.Organization `json:"mySpaces"`
+		}{}
+	}},
+	{"v1/get-my-profile", http.MethodGet, "v1/profile", func() interface{} {
+		return &v1.MyProfile{}
+	}},
+	{"v1/get-my-topics", http.MethodGet, "v1/topics", func() interface{} {
+		return &struct {
+			Topics []*v1.FavoriteTopicWithUnread `json:"topics"`
+		}{}
+	}},
+	{"v1/get-notification-count", http.MethodGet, "v1/notifications/status", func() interface{} {
+		return &v1.NotificationCount{}
+	}},
+	{"v1/get-notification-list", http.MethodGet, "v1/notifications", func() interface{} {
+		return &v1.NotificationList{}
+	}},
+	{"v1/get-online-status", http.MethodGet, "v1/accounts/status", func() interface{} {
+		return &v1.OnlineStatus{}
+	}},
+	{"v1/get-organization-members", http.MethodGet, "v1/spaces/*/members", func() interface{} {
+		return &v1.OrganizationMembers{}
+	}},
+	{"v1/get-talk-list", http.MethodGet, "v1/topics/*/talks", func() interface{} {
+		return &struct {
+			Talks []*v1.Talk `json:"talks"`
+		}{}
+	}},
+	{"v1/get-topic-details", "", "", func() interface{} {
+		return &v1.TopicDetails{}
+	}},
+	{"v1/get-topic-messages", http.MethodGet, "v1/topics/*", func() interface{} {
+		return &v1.TopicMessages{}
+	}},
+	{"v1/like-message", http.MethodPost, "v1/topics/*/posts/*/like", func() interface{} {
+		return &v1.LikedMessageResult{}
+	}},
+	{"v1/post-direct-message", http.MethodPost, "v1/messages/@*", func() interface{} {
+		return &v1.PostedMessageResult{}
+	}},
+	{"v1/post-message", http.MethodPost, "v1/topics/*", func() interface{} {
+		return &v1.PostedMessageResult{}
+	}},
+	{"v1/read-mention", http.MethodPut, "v1/mentions/*", func() interface{} {
+		return &struct {
+			Mention v1.Mention `json:"mention"`
+		}{}
+	}},
+	{"v1/read-messages-in-topic", http.MethodPut, "v1/bookmarks", func() interface{} {
+		return &struct {
+			Unread *v1.Unread `json:"unread"`
+		}{}
+	}},
+	{"v1/read-notification", http.MethodPut, "v1/notifications", func() interface{} {
+		return &struct {
+			Access *v1.Access `json:"access"`
+		}{}
+	}},
+	{"v1/read-received-likes", http.MethodPost, "v1/likes/receive/bookmark/save", func() interface{} {
+		return &v1.ReadReceivedLikesResult{}
+	}},
+	{"v1/remove-messages-from-talk", http.MethodDelete, "v1/topics/*/talks/*/posts", func() interface{} {
+		return &v1.RemovedMessagesResult{}
+	}},
+	{"v1/save-user-status", http.MethodPost, "v1/spaces/*/userStatuses", func() interface{} {
+		return &v1.SaveUserStatusResult{}
+	}},
+	{"v1/search-accounts", http.MethodGet, "v1/search/accounts", func() interface{} {
+		return &v1.Account{}
+	}},
+	{"v1/unfavorite-topic", http.MethodDelete, "v1/topics/*/favorite", func() interface{} {
+		return &v1.FavoriteTopic{}
+	}},
+	{"v1/unlike-message", http.MethodDelete, "v1/topics/*/posts/*/like", func() interface{} {
+		return &struct {
+			Like v1.Like `json:"like"`
+		}{}
+	}},
+	{"v1/update-message", http.MethodPut, "v1/topics/*/posts/*", func() interface{} {
+		return &v1.UpdatedMessageResult{}
+	}},
+	{"v1/update-talk", http.MethodPut, "v1/topics/*/talks/*", func() interface{} {
+		return &v1.UpdatedTalkResult{}
+	}},
+	{"v1/update-topic", http.MethodPut, "v1/topics/*", func() interface{} {
+		return &v1.TopicDetails{}
+	}},
+	{"v1/update-topic-members", http.MethodPost, "v1/topics/*/members/update", func() interface{} {
+		return &v1.TopicDetails{}
+	}},
+	{"v1/upload-attachment-file", http.MethodPost, "v1/topics/*/attachments", func() interface{} {
+		return &v1.AttachmentFile{}
+	}},
+	{"v2/get-direct-messages", http.MethodGet, "v2/spaces/*/messages/@*", func() interface{} {
+		return &v2.DirectMessages{}
+	}},
+	{"v2/get-dm-topics", http.MethodGet, "v2/messages", func() interface{} {
+		return &struct {
+			Topics []*v2.DirectMessageTopic `json:"topics"`
+		}{}
+	}},
+	{"v2/get-likes-discover", http.MethodGet, "v2/likes/discover", func() interface{} {
+		return &struct {
+			LikedPosts []*v2.DiscoverLikedPost `json:"likedPosts"`
+		}{}
+	}},
+	{"v2/get-likes-give", http.MethodGet, "v2/likes/give", func() interface{} {
+		return &struct {
+			LikedPosts []*v2.GiveLikedPost `json:"likedPosts"`
+		}{}
+	}},
+	{"v2/get-likes-receive", http.MethodGet, "v2/likes/receive", func() interface{} {
+		return &struct {
+			LikedPosts []*v2.ReceiveLikedPost `json:"likedPosts"`
+		}{}
+	}},
+	{"v2/get-mention-list", http.MethodGet, "v2/mentions", func() interface{} {
+		return &struct {
+			Mentions []*v2.Mention `json:"mentions"`
+		}{}
+	}},
+	{"v2/get-my-topics", http.MethodGet, "v2/topics", func() interface{} {
+		return &struct {
+			Topics []*v2.FavoriteTopicWithUnread `json:"topics"`
+		}{}
+	}},
+	{"v2/get-notification-count", http.MethodGet, "v2/notifications/status", func() interface{} {
+		return &v2.NotificationCount{}
+	}},
+	{"v2/post-direct-message", http.MethodPost, "v2/spaces/*/messages/@*", func() interface{} {
+		return &v2.PostedMessageResult{}
+	}},
+	{"v2/read-notification", http.MethodPut, "v2/notifications", func() interface{} {
+		return &v2.ReadNotificationResult{}
+	}},
+	{"v2/read-received-likes", http.MethodPost, "v2/likes/receive/bookmark/save", func() interface{} {
+		return &v2.ReadReceivedLikesResult{}
+	}},
+	{"v2/search-messages", http.MethodGet, "v2/search/posts", func() interface{} {
+		return &v2.SearchMessagesResult{}
+	}},
+	{"v3/get-my-friends", http.MethodGet, "v3/search/friends", func() interface{} {
+		return &struct {
+			Accounts []*v3.Account `json:"accounts"`
+		}{}
+	}},
+	{"v3/read-notification", http.MethodPut, "v3/notifications", func() interface{} {
+		return &v3.ReadNotificationResult{}
+	}},
+	{"v4/get-my-friends", http.MethodGet, "v4/search/friends", func() interface{} {
+		return &v4.Friends{}
+	}},
+	{"v5/get-notification-count", http.MethodGet, "v5/notifications/status", func() interface{} {
+		return &v5.NotificationCount{}
+	}},
+}
+
+// Lookup returns the endpoint of a request, given the path of its URL such
+// as "/api/v1/profile", or nil.
+func Lookup(method, path string) *Endpoint {
+	if i := strings.Index(path, "/api/"); i >= 0 {
+		path = path[i+len("/api/"):]
+	}
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for _, e := range Endpoints {
+		if e.Method == method && e.Path != "" && match(strings.Split(e.Path, "/"), segments) {
+			return e
+		}
+	}
+	return nil
+}
+
+func match(pattern, segments []string) bool {
+	if len(pattern) != len(segments) {
+		return false
+	}
+	for i, p := range pattern {
+		switch {
+		case p == "*":
+		case p == "@*":
+			if !strings.HasPrefix(segments[i], "@") {
+				return false
+			}
+		case p != segments[i]:
+			return false
+		}
+	}
+	return true
+}
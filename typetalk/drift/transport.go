@@ -0,0 +1,46 @@
+package drift
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+)
+
+// Transport is an http.RoundTripper which checks the successful responses of
+// the known Endpoints against their models, to detect drift in live
+// responses.
+type Transport struct {
+	// Base sends the requests. http.DefaultTransport is used when it is nil.
+	Base http.RoundTripper
+	// Report is called with the drift of every checked response which has
+	// some, or with the error decoding it.
+	Report func(req *http.Request, endpoint *Endpoint, report *Report, err error)
+}
+
+// RoundTrip sends the request and checks the response.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	resp, err := base.RoundTrip(req)
+	if err != nil || resp.StatusCode/100 != 2 || t.Report == nil {
+		return resp, err
+	}
+	e := Lookup(req.Method, req.URL.Path)
+	if e == nil {
+		return resp, nil
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	report, err := Check(body, e.New())
+	if err != nil || !report.Empty() {
+		t.Report(req, e, report, err)
+	}
+	return resp, nil
+}
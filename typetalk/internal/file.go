@@ -0,0 +1,69 @@
+package internal
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// WriteFileAtomic replaces the file at path with b. The data is written and
+// synced to a temporary file of the same directory, which is then renamed,
+// so a crash never leaves a truncated file behind.
+func WriteFileAtomic(path string, b []byte) error {
+	f, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// JSONFile is a file holding a JSON snapshot of a value, such as the map of
+// a file store.
+type JSONFile struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+// Load decodes the file into v. A missing file leaves v as is.
+func (f *JSONFile) Load(v interface{}) error {
+	b, err := ioutil.ReadFile(f.Path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// Save writes a snapshot of v, which is marshalled while holding lock, the
+// lock guarding v. Saves are serialized from the marshalling to the rename,
+// so the file always ends with the snapshot of the last call.
+func (f *JSONFile) Save(lock sync.Locker, v interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	lock.Lock()
+	b, err := json.MarshalIndent(v, "", "  ")
+	lock.Unlock()
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(f.Path, b)
+}
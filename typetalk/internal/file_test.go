@@ -0,0 +1,50 @@
+package internal
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func Test_JSONFile_should_keep_the_last_snapshot_of_concurrent_saves(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "internal")
+	defer os.RemoveAll(dir)
+	f := &JSONFile{Path: filepath.Join(dir, "store.json")}
+
+	var mu sync.Mutex
+	m := map[string]int{}
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			mu.Lock()
+			m[strconv.Itoa(i)] = i
+			mu.Unlock()
+			if err := f.Save(&mu, m); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	loaded := map[string]int{}
+	if err := f.Load(&loaded); err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != 50 {
+		t.Errorf("Load: got %d keys, want 50", len(loaded))
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*"))
+	if len(files) != 1 {
+		t.Errorf("temporary files are left: %v", files)
+	}
+
+	missing := map[string]int{"kept": 1}
+	if err := (&JSONFile{Path: filepath.Join(dir, "missing.json")}).Load(&missing); err != nil || missing["kept"] != 1 {
+		t.Errorf("Load of a missing file: got %v, %v", missing, err)
+	}
+}
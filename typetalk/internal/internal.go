@@ -5,16 +5,19 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"reflect"
 	"strings"
+	"sync"
 
 	"bytes"
 	"mime/multipart"
 	"os"
 
 	"github.com/nulab/go-typetalk/typetalk/shared"
+	"github.com/nulab/go-typetalk/v3/typetalk/auth"
 )
 
 const (
@@ -28,9 +31,17 @@ const (
 type ClientCore struct {
 	Client *http.Client
 
-	BaseURL       *url.URL
-	UserAgent     string
+	BaseURL   *url.URL
+	UserAgent string
+	// Deprecated: TypetalkToken is sent only when no credentials provider
+	// is set, and must not be changed while requests are in flight. Use
+	// SetCredentials or SetTypetalkToken instead.
 	TypetalkToken string
+
+	mu          sync.RWMutex
+	credentials auth.CredentialsProvider
+	rawJSON     bool
+	strict      func(req *http.Request, unknown []string)
 }
 
 func (c *ClientCore) NewRequest(method, urlStr string, body interface{}) (*http.Request, error) {
@@ -61,9 +72,6 @@ func (c *ClientCore) NewRequest(method, urlStr string, body interface{}) (*http.
 	if c.UserAgent != "" {
 		req.Header.Set("User-Agent", c.UserAgent)
 	}
-	if c.TypetalkToken != "" {
-		req.Header.Set("X-Typetalk-Token", c.TypetalkToken)
-	}
 	return req, nil
 }
 
@@ -103,9 +111,6 @@ func (c *ClientCore) NewMultipartRequest(urlStr string, values map[string]io.Rea
 	if c.UserAgent != "" {
 		req.Header.Set("User-Agent", c.UserAgent)
 	}
-	if c.TypetalkToken != "" {
-		req.Header.Set("X-Typetalk-Token", c.TypetalkToken)
-	}
 	return req, nil
 }
 
@@ -129,15 +134,77 @@ func (c *ClientCore) NewUploadRequest(urlStr string, reader io.Reader, size int6
 	if c.UserAgent != "" {
 		req.Header.Set("User-Agent", c.UserAgent)
 	}
-	if c.TypetalkToken != "" {
-		req.Header.Set("X-Typetalk-Token", c.TypetalkToken)
-	}
 	return req, nil
 
 }
 
+// SetCredentials sets the provider of the credentials of the requests. It
+// is safe to call while requests are in flight.
+func (c *ClientCore) SetCredentials(p auth.CredentialsProvider) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.credentials = p
+}
+
+// SetTypetalkToken sets a static provider of the token. An empty token
+// makes the requests go without a token, even when the deprecated
+// TypetalkToken field is set.
+func (c *ClientCore) SetTypetalkToken(token string) {
+	c.SetCredentials(auth.Static(token))
+}
+
+// SetRawJSON makes the results keep their raw JSON and unknown keys. It is
+// safe to call while requests are in flight.
+func (c *ClientCore) SetRawJSON(retain bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rawJSON = retain
+}
+
+// SetStrict sets the hook called with the unknown fields of the decoded
+// responses, or turns it off when it is nil. It is safe to call while
+// requests are in flight.
+func (c *ClientCore) SetStrict(hook func(req *http.Request, unknown []string)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.strict = hook
+}
+
+// Authorize sets the headers of the credentials of the request.
+func (c *ClientCore) Authorize(ctx context.Context, req *http.Request) error {
+	c.mu.RLock()
+	p := c.credentials
+	c.mu.RUnlock()
+	if p == nil {
+		if c.TypetalkToken != "" {
+			req.Header.Set("X-Typetalk-Token", c.TypetalkToken)
+		}
+		return nil
+	}
+	var cred *auth.Credentials
+	var err error
+	if rp, ok := p.(auth.RequestCredentialsProvider); ok {
+		cred, err = rp.RequestCredentials(req.WithContext(ctx))
+	} else {
+		cred, err = p.Credentials(ctx)
+	}
+	if err != nil {
+		return err
+	}
+	if cred.TypetalkToken != "" {
+		req.Header.Set("X-Typetalk-Token", cred.TypetalkToken)
+	}
+	if cred.AccessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+cred.AccessToken)
+	}
+	return nil
+}
+
 func (c *ClientCore) Do(ctx context.Context, req *http.Request, v interface{}) (*shared.Response, error) {
 	req = req.WithContext(ctx)
+	if err := c.Authorize(ctx, req); err != nil {
+		return nil, err
+	}
 
 	resp, err := c.Client.Do(req)
 	if err != nil {
@@ -158,6 +225,10 @@ func (c *ClientCore) Do(ctx context.Context, req *http.Request, v interface{}) (
 
 	response := &shared.Response{Response: resp}
 
+	c.mu.RLock()
+	rawJSON, strict := c.rawJSON, c.strict
+	c.mu.RUnlock()
+
 	err = CheckResponse(resp)
 	if err != nil {
 		return response, err
@@ -166,6 +237,11 @@ func (c *ClientCore) Do(ctx context.Context, req *http.Request, v interface{}) (
 	if v != nil {
 		if w, ok := v.(io.Writer); ok {
 			io.Copy(w, resp.Body)
+		} else if rawJSON || strict != nil {
+			var body []byte
+			if body, err = ioutil.ReadAll(resp.Body); err == nil {
+				err = decode(req, body, v, rawJSON, strict)
+			}
 		} else {
 			err = json.NewDecoder(resp.Body).Decode(v)
 			if err == io.EOF {
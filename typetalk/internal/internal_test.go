@@ -1,6 +1,8 @@
 package internal
 
 import (
+	"context"
+	"encoding/json"
 	"net/http"
 	"net/url"
 	"reflect"
@@ -163,3 +165,80 @@ func Test_Client_addQueries_should_add_queries_to_url(t *testing.T) {
 		}
 	}
 }
+
+func Test_decode_should_keep_raw_json_of_every_result(t *testing.T) {
+	type raw struct {
+		Raw   json.RawMessage            `json:"-"`
+		Extra map[string]json.RawMessage `json:"-"`
+	}
+	type result struct {
+		raw
+		ID int `json:"id"`
+	}
+	var unknown []string
+	strict := func(req *http.Request, fields []string) {
+		unknown = append(unknown, fields...)
+	}
+
+	var r *result
+	var v interface{} = &r
+	if err := decode(nil, []byte(`{"id":1,"new":2}`), &v, true, strict); err != nil {
+		t.Fatal(err)
+	}
+	if r.ID != 1 || string(r.Raw) != `{"id":1,"new":2}` || string(r.Extra["new"]) != "2" {
+		t.Errorf("decode: got %+v", r)
+	}
+	var likes []*result
+	v = &likes
+	if err := decode(nil, []byte(`[{"id":1,"old":true}]`), &v, true, strict); err != nil {
+		t.Fatal(err)
+	}
+	if len(likes) != 1 || string(likes[0].Raw) != `{"id":1,"old":true}` || string(likes[0].Extra["old"]) != "true" {
+		t.Errorf("decode: got %+v", likes)
+	}
+	var wrapper *struct {
+		Result  result             `json:"result"`
+		Results map[string]*result `json:"results"`
+	}
+	v = &wrapper
+	if err := decode(nil, []byte(`{"result":{"id":2},"results":{"a":{"id":3,"x":0}}}`), &v, true, nil); err != nil {
+		t.Fatal(err)
+	}
+	if string(wrapper.Result.Raw) != `{"id":2}` || wrapper.Result.Extra != nil || string(wrapper.Results["a"].Extra["x"]) != "0" {
+		t.Errorf("decode: got %+v", wrapper)
+	}
+	if err := decode(nil, []byte(" \n"), &v, true, strict); err != nil {
+		t.Errorf("decode of an empty body: got %v", err)
+	}
+	if want := []string{"new", "[].old"}; !reflect.DeepEqual(unknown, want) {
+		t.Errorf("Strict: got %v, want %v", unknown, want)
+	}
+}
+
+func Test_ClientCore_Authorize_should_send_deprecated_token_without_provider(t *testing.T) {
+	c := &ClientCore{TypetalkToken: "OLD"}
+	req, _ := http.NewRequest(http.MethodGet, "https://typetalk.com/api/v1/profile", nil)
+	if err := c.Authorize(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if got := req.Header.Get("X-Typetalk-Token"); got != "OLD" {
+		t.Errorf("X-Typetalk-Token: got %q, want OLD", got)
+	}
+
+	c.SetTypetalkToken("NEW")
+	if err := c.Authorize(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if got := req.Header.Get("X-Typetalk-Token"); got != "NEW" {
+		t.Errorf("X-Typetalk-Token: got %q, want NEW", got)
+	}
+
+	c.SetTypetalkToken("")
+	req, _ = http.NewRequest(http.MethodGet, "https://typetalk.com/api/v1/profile", nil)
+	if err := c.Authorize(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if got := req.Header.Get("X-Typetalk-Token"); got != "" {
+		t.Errorf("X-Typetalk-Token after clearing the token: got %q", got)
+	}
+}
@@ -0,0 +1,321 @@
+package internal
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// JSONDrift is the drift between a JSON document and the type it is decoded
+// into. Paths are made of the JSON names, with "[]" for the elements of
+// arrays and "*" for the values of maps, such as "posts[].account.name".
+type JSONDrift struct {
+	// Unknown are the paths that no field of the type represents.
+	Unknown []string
+	// Untyped are the paths of objects and arrays decoded into interface{}.
+	Untyped []string
+	// Unpopulated are the paths of the fields that the JSON never sets.
+	// The fields of a struct that is never set are not listed.
+	Unpopulated []string
+}
+
+var (
+	jsonUnmarshaler = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	rawMessage      = reflect.TypeOf(json.RawMessage(nil))
+	rawMessages     = reflect.TypeOf(map[string]json.RawMessage(nil))
+)
+
+type checker struct {
+	unknown  map[string]bool
+	untyped  map[string]bool
+	declared map[string]bool
+	set      map[string]bool
+}
+
+// CheckJSON returns the drift between data and the type t.
+func CheckJSON(data []byte, t reflect.Type) (*JSONDrift, error) {
+	var v interface{}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+	c := &checker{unknown: map[string]bool{}, untyped: map[string]bool{}, declared: map[string]bool{}, set: map[string]bool{}}
+	c.walk("", v, t)
+
+	r := &JSONDrift{Unknown: keys(c.unknown), Untyped: keys(c.untyped)}
+	for p := range c.declared {
+		if !c.set[p] {
+			r.Unpopulated = append(r.Unpopulated, p)
+		}
+	}
+	sort.Strings(r.Unpopulated)
+	return r, nil
+}
+
+func (c *checker) walk(path string, v interface{}, t reflect.Type) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if v == nil || reflect.PtrTo(t).Implements(jsonUnmarshaler) || reflect.PtrTo(t).Implements(textUnmarshaler) {
+		return
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		switch t.Kind() {
+		case reflect.Interface:
+			c.untyped[path] = true
+		case reflect.Map:
+			for _, e := range v {
+				c.walk(join(path, "*"), e, t.Elem())
+			}
+		case reflect.Struct:
+			fields := fieldsOf(t)
+			for name := range fields {
+				c.declared[join(path, name)] = true
+			}
+			for k, e := range v {
+				name, f := lookup(fields, k)
+				if f == nil {
+					c.unknown[join(path, k)] = true
+					continue
+				}
+				c.set[join(path, name)] = true
+				c.walk(join(path, name), e, f.Type)
+			}
+		}
+	case []interface{}:
+		switch t.Kind() {
+		case reflect.Interface:
+			if len(v) > 0 {
+				c.untyped[path] = true
+			}
+		case reflect.Slice, reflect.Array:
+			for _, e := range v {
+				c.walk(path+"[]", e, t.Elem())
+			}
+		}
+	}
+}
+
+// fieldsOf returns the fields of a struct by their JSON names, with the
+// fields of embedded structs promoted as encoding/json does.
+func fieldsOf(t reflect.Type) map[string]*reflect.StructField {
+	fields := map[string]*reflect.StructField{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			for n, e := range fieldsOf(ft) {
+				if _, ok := fields[n]; !ok {
+					fields[n] = e
+				}
+			}
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = &f
+	}
+	return fields
+}
+
+// lookup finds the field of a JSON key, preferring an exact match to a case
+// insensitive one like encoding/json.
+func lookup(fields map[string]*reflect.StructField, key string) (string, *reflect.StructField) {
+	if f, ok := fields[key]; ok {
+		return key, f
+	}
+	for name, f := range fields {
+		if strings.EqualFold(name, key) {
+			return name, f
+		}
+	}
+	return "", nil
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func keys(m map[string]bool) []string {
+	var s []string
+	for k := range m {
+		s = append(s, k)
+	}
+	sort.Strings(s)
+	return s
+}
+
+// decode decodes the body of a response into v, keeps the raw JSON of the
+// result when rawJSON is set, and passes its unknown fields to strict.
+func decode(req *http.Request, body []byte, v interface{}, rawJSON bool, strict func(req *http.Request, unknown []string)) error {
+	if len(bytes.TrimSpace(body)) == 0 {
+		// ignore empty response body
+		return nil
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return err
+	}
+	result := reflect.ValueOf(v)
+	for result.Kind() == reflect.Ptr || result.Kind() == reflect.Interface {
+		if result.IsNil() {
+			return nil
+		}
+		result = result.Elem()
+	}
+	if strict != nil {
+		drift, err := CheckJSON(body, result.Type())
+		if err != nil {
+			return err
+		}
+		if len(drift.Unknown) > 0 {
+			strict(req, drift.Unknown)
+		}
+	}
+	if rawJSON {
+		retain(body, result)
+	}
+	return nil
+}
+
+// retain sets the Raw and Extra fields of every value of the result that
+// has them, such as the result itself or the model in the anonymous struct
+// a service decodes into, to the raw JSON of the value and to its keys that
+// no field represents.
+func retain(body []byte, v reflect.Value) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if !holdsRaw(v.Type()) {
+		return
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(body, &object); err != nil || object == nil {
+			return
+		}
+		if f := v.FieldByName("Raw"); f.IsValid() && f.Type() == rawMessage && f.CanSet() {
+			f.Set(reflect.ValueOf(json.RawMessage(append([]byte(nil), body...))))
+		}
+		fields := fieldsOf(v.Type())
+		extra := map[string]json.RawMessage{}
+		for k, e := range object {
+			_, field := lookup(fields, k)
+			if field == nil {
+				extra[k] = e
+				continue
+			}
+			if f := fieldByName(v, field.Name); f.IsValid() {
+				retain(e, f)
+			}
+		}
+		if f := v.FieldByName("Extra"); len(extra) > 0 && f.IsValid() && f.Type() == rawMessages && f.CanSet() {
+			f.Set(reflect.ValueOf(extra))
+		}
+	case reflect.Slice, reflect.Array:
+		var elems []json.RawMessage
+		if err := json.Unmarshal(body, &elems); err != nil {
+			return
+		}
+		for i := 0; i < len(elems) && i < v.Len(); i++ {
+			retain(elems[i], v.Index(i))
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return
+		}
+		var elems map[string]json.RawMessage
+		if err := json.Unmarshal(body, &elems); err != nil {
+			return
+		}
+		for k, e := range elems {
+			// Only the values behind pointers can be set.
+			retain(e, v.MapIndex(reflect.ValueOf(k).Convert(v.Type().Key())))
+		}
+	}
+}
+
+// fieldByName returns the field of the struct v, or the zero Value when it
+// is promoted from a nil embedded pointer.
+func fieldByName(v reflect.Value, name string) reflect.Value {
+	f, ok := v.Type().FieldByName(name)
+	if !ok {
+		return reflect.Value{}
+	}
+	for i, x := range f.Index {
+		if i > 0 {
+			for v.Kind() == reflect.Ptr {
+				if v.IsNil() {
+					return reflect.Value{}
+				}
+				v = v.Elem()
+			}
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+var holdsRawCache sync.Map
+
+// holdsRaw reports whether a value of the type may hold a Raw or Extra
+// field, so that retain skips the values that can't.
+func holdsRaw(t reflect.Type) bool {
+	if b, ok := holdsRawCache.Load(t); ok {
+		return b.(bool)
+	}
+	b := holdsRawIn(t, map[reflect.Type]bool{})
+	holdsRawCache.Store(t, b)
+	return b
+}
+
+func holdsRawIn(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	if visiting[t] {
+		return false
+	}
+	visiting[t] = true
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return holdsRawIn(t.Elem(), visiting)
+	case reflect.Struct:
+		if reflect.PtrTo(t).Implements(jsonUnmarshaler) || reflect.PtrTo(t).Implements(textUnmarshaler) {
+			return false
+		}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.Name == "Raw" && f.Type == rawMessage || f.Name == "Extra" && f.Type == rawMessages {
+				return true
+			}
+			if f.Tag.Get("json") != "-" && holdsRawIn(f.Type, visiting) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,335 @@
+// Command mockgen generates the mocks of the service interfaces of a client
+// package. It is run by go generate in the directory of the package:
+//
+//	//go:generate go run ../internal/mockgen -o mocks/mocks.go api.go
+//
+// Every interface of the given file gets a mock with a Func field per method,
+// and the Client struct of the package gets a Client of mocks.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const importPrefix = "github.com/nulab/go-typetalk/v3/typetalk/"
+
+type param struct {
+	name     string
+	typ      string
+	variadic bool
+	context  bool
+}
+
+type method struct {
+	name    string
+	params  []*param
+	results []string
+}
+
+type iface struct {
+	name    string
+	methods []*method
+}
+
+type field struct {
+	name  string
+	iface string
+}
+
+type generator struct {
+	pkg     string
+	imports map[string]string
+	used    map[string]bool
+	ifaces  []*iface
+	fields  []*field
+}
+
+func main() {
+	out := flag.String("o", "", "path of the generated file")
+	flag.Parse()
+	if *out == "" || flag.NArg() != 1 {
+		log.Fatal("usage: mockgen -o mocks/mocks.go api.go")
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		log.Fatal(err)
+	}
+	g := &generator{pkg: filepath.Base(dir), imports: map[string]string{}, used: map[string]bool{"sync": true}}
+	if err := g.parse(dir, flag.Arg(0)); err != nil {
+		log.Fatal(err)
+	}
+	src, err := g.generate(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(*out), 0755); err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile(*out, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// parse reads the interfaces of the file and the Client struct of the
+// package in dir.
+func (g *generator) parse(dir, name string) error {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return err
+	}
+	pkg := pkgs[g.pkg]
+	if pkg == nil {
+		return fmt.Errorf("mockgen: no package %s in %s", g.pkg, dir)
+	}
+	file := pkg.Files[filepath.Join(dir, name)]
+	if file == nil {
+		return fmt.Errorf("mockgen: no file %s", name)
+	}
+	for _, imp := range file.Imports {
+		path, _ := strconv.Unquote(imp.Path.Value)
+		local := filepath.Base(path)
+		if imp.Name != nil {
+			local = imp.Name.Name
+		}
+		g.imports[local] = path
+	}
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			if it, ok := ts.Type.(*ast.InterfaceType); ok {
+				g.ifaces = append(g.ifaces, g.parseInterface(ts.Name.Name, it))
+			}
+		}
+	}
+
+	names := map[string]bool{}
+	for _, it := range g.ifaces {
+		names[it.name] = true
+	}
+	for _, f := range pkg.Files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			ts, ok := n.(*ast.TypeSpec)
+			if !ok || ts.Name.Name != "Client" {
+				return true
+			}
+			st, ok := ts.Type.(*ast.StructType)
+			if !ok {
+				return false
+			}
+			for _, fl := range st.Fields.List {
+				if id, ok := fl.Type.(*ast.Ident); ok && names[id.Name] {
+					for _, n := range fl.Names {
+						g.fields = append(g.fields, &field{n.Name, id.Name})
+					}
+				}
+			}
+			return false
+		})
+	}
+	return nil
+}
+
+func (g *generator) parseInterface(name string, it *ast.InterfaceType) *iface {
+	i := &iface{name: name}
+	for _, m := range it.Methods.List {
+		ft := m.Type.(*ast.FuncType)
+		meth := &method{name: m.Names[0].Name}
+		for n, p := range ft.Params.List {
+			names := p.Names
+			if len(names) == 0 {
+				names = []*ast.Ident{ast.NewIdent(fmt.Sprintf("arg%d", n))}
+			}
+			_, variadic := p.Type.(*ast.Ellipsis)
+			typ := g.qualify(p.Type)
+			for _, pn := range names {
+				meth.params = append(meth.params, &param{name: pn.Name, typ: typ, variadic: variadic, context: typ == "context.Context"})
+			}
+		}
+		if ft.Results != nil {
+			for _, r := range ft.Results.List {
+				n := len(r.Names)
+				if n == 0 {
+					n = 1
+				}
+				for j := 0; j < n; j++ {
+					meth.results = append(meth.results, g.qualify(r.Type))
+				}
+			}
+		}
+		i.methods = append(i.methods, meth)
+	}
+	return i
+}
+
+// qualify returns the source of a type, with the types of the package
+// qualified by its name, and records the packages it uses.
+func (g *generator) qualify(expr ast.Expr) string {
+	return types.ExprString(g.rewrite(expr))
+}
+
+func (g *generator) rewrite(expr ast.Expr) ast.Expr {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if ast.IsExported(e.Name) {
+			g.used[g.pkg] = true
+			return &ast.SelectorExpr{X: ast.NewIdent(g.pkg), Sel: e}
+		}
+		return e
+	case *ast.SelectorExpr:
+		if x, ok := e.X.(*ast.Ident); ok {
+			g.used[x.Name] = true
+		}
+		return e
+	case *ast.StarExpr:
+		return &ast.StarExpr{X: g.rewrite(e.X)}
+	case *ast.ArrayType:
+		return &ast.ArrayType{Len: e.Len, Elt: g.rewrite(e.Elt)}
+	case *ast.Ellipsis:
+		return &ast.Ellipsis{Elt: g.rewrite(e.Elt)}
+	case *ast.MapType:
+		return &ast.MapType{Key: g.rewrite(e.Key), Value: g.rewrite(e.Value)}
+	}
+	return expr
+}
+
+func (g *generator) generate(name string) ([]byte, error) {
+	var b bytes.Buffer
+	p := func(format string, a ...interface{}) {
+		fmt.Fprintf(&b, format, a...)
+	}
+	p("// Code generated by mockgen from %s; DO NOT EDIT.\n\n", name)
+	p("// Package mocks provides mocks of the services of %s.Client, which record\n", g.pkg)
+	p("// their calls and return the results of programmable functions.\n")
+	p("package mocks\n\nimport (\n")
+	var std, other []string
+	for local := range g.used {
+		switch {
+		case local == g.pkg:
+			other = append(other, fmt.Sprintf("%s %q", local, importPrefix+local))
+		case local == "sync":
+			std = append(std, `"sync"`)
+		case strings.Contains(g.imports[local], "."):
+			other = append(other, strconv.Quote(g.imports[local]))
+		default:
+			std = append(std, strconv.Quote(g.imports[local]))
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(other)
+	p("\t%s\n\n\t%s\n", strings.Join(std, "\n\t"), strings.Join(other, "\n\t"))
+	p(")\n\n")
+
+	p(`// Call is a call made to a mock.
+type Call struct {
+	// Method is the name of the method.
+	Method string
+	// Args are the arguments of the call, without the context.
+	Args []interface{}
+}
+
+type recorder struct {
+	mu    sync.Mutex
+	calls []*Call
+}
+
+func (r *recorder) record(method string, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, &Call{Method: method, Args: args})
+}
+
+// Calls returns the calls made to the mock.
+func (r *recorder) Calls() []*Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*Call(nil), r.calls...)
+}
+
+// CallsTo returns the calls made to a method of the mock.
+func (r *recorder) CallsTo(method string) []*Call {
+	var calls []*Call
+	for _, c := range r.Calls() {
+		if c.Method == method {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+`)
+
+	if len(g.fields) > 0 {
+		p("// Client holds a mock of every service of %s.Client.\ntype Client struct {\n", g.pkg)
+		for _, f := range g.fields {
+			p("\t%s *%s\n", f.name, f.iface)
+		}
+		p("}\n\n// NewClient returns a Client with a new mock of every service.\nfunc NewClient() *Client {\n\treturn &Client{\n")
+		for _, f := range g.fields {
+			p("\t\t%s: &%s{},\n", f.name, f.iface)
+		}
+		p("\t}\n}\n\n// Client returns a %s.Client whose services are the mocks. It is made by\n// %s.NewClient, so its setters, such as SetTypetalkToken, can be called.\nfunc (c *Client) Client() *%s.Client {\n\tclient := %s.NewClient(nil)\n", g.pkg, g.pkg, g.pkg, g.pkg)
+		for _, f := range g.fields {
+			p("\tclient.%s = c.%s\n", f.name, f.name)
+		}
+		p("\treturn client\n}\n\n")
+	}
+
+	for _, it := range g.ifaces {
+		p("// %s is a mock of %s.%s. A method returns the results of the\n", it.name, g.pkg, it.name)
+		p("// matching Func field, or zero values when the field is nil.\ntype %s struct {\n\trecorder\n\n", it.name)
+		for _, m := range it.methods {
+			p("\t%sFunc func(%s) (%s)\n", m.name, m.signature(), strings.Join(m.results, ", "))
+		}
+		p("}\n\nvar _ %s.%s = (*%s)(nil)\n\n", g.pkg, it.name, it.name)
+		for _, m := range it.methods {
+			var results, args, recorded []string
+			for i, r := range m.results {
+				results = append(results, fmt.Sprintf("r%d %s", i, r))
+			}
+			for _, prm := range m.params {
+				arg := prm.name
+				if prm.variadic {
+					arg += "..."
+				}
+				args = append(args, arg)
+				if !prm.context {
+					recorded = append(recorded, prm.name)
+				}
+			}
+			p("// %s records the call and calls %sFunc.\n", m.name, m.name)
+			p("func (m *%s) %s(%s) (%s) {\n", it.name, m.name, m.signature(), strings.Join(results, ", "))
+			p("\tm.record(%s)\n", strings.Join(append([]string{strconv.Quote(m.name)}, recorded...), ", "))
+			p("\tif m.%sFunc != nil {\n\t\treturn m.%sFunc(%s)\n\t}\n\treturn\n}\n\n", m.name, m.name, strings.Join(args, ", "))
+		}
+	}
+	return format.Source(b.Bytes())
+}
+
+func (m *method) signature() string {
+	var params []string
+	for _, p := range m.params {
+		params = append(params, p.name+" "+p.typ)
+	}
+	return strings.Join(params, ", ")
+}
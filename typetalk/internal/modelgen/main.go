@@ -0,0 +1,372 @@
+// Command modelgen generates the converters from the types of a versioned
+// package to the canonical types of the model package. It is run by go
+// generate in the directory of the model package:
+//
+//	//go:generate go run ../internal/modelgen -o from_v1.go -rename Organization=MySpace v1
+//
+// Every struct of the versioned package whose name, after renaming, is a
+// struct of the model package gets a converter FromV1Name and a converter of
+// slices FromV1Names. The fields are matched by name, and every field of a
+// converted struct must have a canonical field it can be converted to.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const importPrefix = "github.com/nulab/go-typetalk/v3/typetalk/"
+
+type field struct {
+	name string
+	typ  ast.Expr
+}
+
+type generator struct {
+	version string
+	// conv maps the converted types of the version to their model types.
+	conv    map[string]string
+	src     map[string][]*field
+	dst     map[string][]*field
+	locals  map[string]bool
+	imports map[string]string
+	used    map[string]bool
+}
+
+func main() {
+	out := flag.String("o", "", "path of the generated file")
+	rename := flag.String("rename", "", "comma separated renames of versioned types, such as Organization=MySpace")
+	flag.Parse()
+	if *out == "" || flag.NArg() != 1 {
+		log.Fatal("usage: modelgen -o from_v1.go [-rename Old=New,...] v1")
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		log.Fatal(err)
+	}
+	g := &generator{version: flag.Arg(0), conv: map[string]string{}, locals: map[string]bool{}, imports: map[string]string{}, used: map[string]bool{}}
+	if err := g.parse(dir, *rename); err != nil {
+		log.Fatal(err)
+	}
+	src, err := g.generate()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile(*out, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// parse reads the structs of the model package in dir, but its generated
+// converters, and the structs of the versioned package next to it.
+func (g *generator) parse(dir, rename string) error {
+	var err error
+	g.dst, err = g.parseDir(dir, "model", func(name string) bool {
+		return !strings.HasPrefix(name, "from_")
+	})
+	if err != nil {
+		return err
+	}
+	g.src, err = g.parseDir(filepath.Join(dir, "..", g.version), g.version, func(string) bool { return true })
+	if err != nil {
+		return err
+	}
+
+	renames := map[string]string{}
+	if rename != "" {
+		for _, r := range strings.Split(rename, ",") {
+			kv := strings.SplitN(r, "=", 2)
+			if len(kv) != 2 {
+				return fmt.Errorf("modelgen: invalid rename %q", r)
+			}
+			renames[kv[0]] = kv[1]
+		}
+	}
+	from := map[string]string{}
+	for name := range g.src {
+		m := name
+		if r, ok := renames[name]; ok {
+			m = r
+		}
+		if _, ok := g.dst[m]; !ok {
+			continue
+		}
+		if other, ok := from[m]; ok {
+			return fmt.Errorf("modelgen: %s.%s and %s.%s both convert to %s", g.version, other, g.version, name, m)
+		}
+		from[m] = name
+		g.conv[name] = m
+	}
+	return nil
+}
+
+// parseDir returns the structs of the package in dir, and records the
+// exported types and the imports of the versioned package.
+func (g *generator) parseDir(dir, pkgName string, keep func(string) bool) (map[string][]*field, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go") && keep(fi.Name())
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+	pkg := pkgs[pkgName]
+	if pkg == nil {
+		return nil, fmt.Errorf("modelgen: no package %s in %s", pkgName, dir)
+	}
+	structs := map[string][]*field{}
+	defined := map[string]string{}
+	for _, file := range pkg.Files {
+		if pkgName == g.version {
+			for _, imp := range file.Imports {
+				path, _ := strconv.Unquote(imp.Path.Value)
+				local := filepath.Base(path)
+				if imp.Name != nil {
+					local = imp.Name.Name
+				}
+				g.imports[local] = path
+			}
+		}
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				if !ts.Name.IsExported() {
+					continue
+				}
+				if pkgName == g.version {
+					g.locals[ts.Name.Name] = true
+				}
+				if id, ok := ts.Type.(*ast.Ident); ok {
+					defined[ts.Name.Name] = id.Name
+					continue
+				}
+				st, ok := ts.Type.(*ast.StructType)
+				if !ok {
+					continue
+				}
+				var fields []*field
+				for _, f := range st.Fields.List {
+					if len(f.Names) == 0 {
+						fields = append(fields, &field{name: "", typ: f.Type})
+					}
+					for _, n := range f.Names {
+						if n.IsExported() {
+							fields = append(fields, &field{name: n.Name, typ: f.Type})
+						}
+					}
+				}
+				structs[ts.Name.Name] = fields
+			}
+		}
+	}
+	// A type defined by a struct, such as type Profile AccountStatus, has
+	// the fields of the struct.
+	for name, underlying := range defined {
+		if fields, ok := structs[underlying]; ok {
+			structs[name] = fields
+		}
+	}
+	return structs, nil
+}
+
+func (g *generator) generate() ([]byte, error) {
+	var names []string
+	for name := range g.conv {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return g.conv[names[i]] < g.conv[names[j]] })
+
+	var body bytes.Buffer
+	p := func(format string, a ...interface{}) {
+		fmt.Fprintf(&body, format, a...)
+	}
+	for _, name := range names {
+		m := g.conv[name]
+		var lits, stmts bytes.Buffer
+		for _, f := range g.src[name] {
+			if f.name == "" {
+				return nil, fmt.Errorf("modelgen: %s.%s embeds %s", g.version, name, types.ExprString(f.typ))
+			}
+			d := g.field(m, f.name)
+			if d == nil {
+				return nil, fmt.Errorf("modelgen: %s.%s.%s has no field in %s", g.version, name, f.name, m)
+			}
+			lit, stmt, err := g.convert(f.name, f.typ, d.typ)
+			if err != nil {
+				return nil, fmt.Errorf("modelgen: %s.%s.%s: %v", g.version, name, f.name, err)
+			}
+			if lit != "" {
+				fmt.Fprintf(&lits, "\t\t%s: %s,\n", f.name, lit)
+			}
+			stmts.WriteString(stmt)
+		}
+		fn, fns := g.funcName(m), g.funcName(plural(m))
+		p("// %s converts a %s.%s to the canonical %s.\n", fn, g.version, name, m)
+		p("func %s(in *%s.%s) *%s {\n\tif in == nil {\n\t\treturn nil\n\t}\n", fn, g.version, name, m)
+		p("\tout := &%s{\n%s\t}\n%s\treturn out\n}\n\n", m, lits.String(), stmts.String())
+		p("// %s converts a slice of %s.%s to a slice of %s.\n", fns, g.version, name, m)
+		p("func %s(in []*%s.%s) []*%s {\n\tif in == nil {\n\t\treturn nil\n\t}\n", fns, g.version, name, m)
+		p("\tout := make([]*%s, len(in))\n\tfor i, e := range in {\n\t\tout[i] = %s(e)\n\t}\n\treturn out\n}\n\n", m, fn)
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by modelgen from %s; DO NOT EDIT.\n\npackage model\n\nimport (\n", g.version)
+	var std []string
+	for local := range g.used {
+		std = append(std, strconv.Quote(g.imports[local]))
+	}
+	sort.Strings(std)
+	if len(std) > 0 {
+		fmt.Fprintf(&b, "\t%s\n\n", strings.Join(std, "\n\t"))
+	}
+	fmt.Fprintf(&b, "\t%s %q\n)\n\n", g.version, importPrefix+g.version)
+	b.Write(body.Bytes())
+	return format.Source(b.Bytes())
+}
+
+func (g *generator) field(typ, name string) *field {
+	for _, f := range g.dst[typ] {
+		if f.name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// convert returns the expression which converts the field in the struct
+// literal of the result, or the statements which do after it.
+func (g *generator) convert(name string, src, dst ast.Expr) (lit, stmt string, err error) {
+	in, out := "in."+name, "out."+name
+	if sa, ok := src.(*ast.ArrayType); ok && sa.Len == nil {
+		da, ok := dst.(*ast.ArrayType)
+		if !ok || da.Len != nil {
+			return "", "", fmt.Errorf("cannot convert %s to %s", types.ExprString(src), types.ExprString(dst))
+		}
+		sp, se := deref(sa.Elt)
+		dp, de := deref(da.Elt)
+		if n, ok := g.local(se); ok {
+			m, err := g.model(n, dp, de)
+			if err != nil {
+				return "", "", err
+			}
+			if sp {
+				return fmt.Sprintf("%s(%s)", g.funcName(plural(m)), in), "", nil
+			}
+			return "", fmt.Sprintf("\tif %s != nil {\n\t\t%s = make([]*%s, len(%s))\n\t\tfor i := range %s {\n\t\t\t%s[i] = %s(&%s[i])\n\t\t}\n\t}\n",
+				in, out, m, in, in, out, g.funcName(m), in), nil
+		}
+	}
+
+	sp, sb := deref(src)
+	dp, db := deref(dst)
+	if n, ok := g.local(sb); ok {
+		m, err := g.model(n, dp, db)
+		if err != nil {
+			return "", "", err
+		}
+		if sp {
+			return fmt.Sprintf("%s(%s)", g.funcName(m), in), "", nil
+		}
+		return fmt.Sprintf("%s(&%s)", g.funcName(m), in), "", nil
+	}
+	if g.mentionsLocal(src) {
+		return "", "", fmt.Errorf("cannot convert %s", types.ExprString(src))
+	}
+	if types.ExprString(sb) != types.ExprString(db) {
+		return "", "", fmt.Errorf("cannot convert %s to %s", types.ExprString(src), types.ExprString(dst))
+	}
+	switch {
+	case sp == dp:
+		return in, "", nil
+	case dp:
+		g.use(sb)
+		return "", fmt.Sprintf("\t%s = new(%s)\n\t*%s = %s\n", out, types.ExprString(sb), out, in), nil
+	default:
+		return "", fmt.Sprintf("\tif %s != nil {\n\t\t%s = *%s\n\t}\n", in, out, in), nil
+	}
+}
+
+// model returns the model type which the versioned type n converts to,
+// which the field must hold by pointer.
+func (g *generator) model(n string, ptr bool, dst ast.Expr) (string, error) {
+	m, ok := g.conv[n]
+	if !ok {
+		return "", fmt.Errorf("%s.%s has no model type", g.version, n)
+	}
+	if id, ok := dst.(*ast.Ident); !ok || id.Name != m || !ptr {
+		return "", fmt.Errorf("cannot convert %s.%s to %s", g.version, n, types.ExprString(dst))
+	}
+	return m, nil
+}
+
+func (g *generator) local(e ast.Expr) (string, bool) {
+	id, ok := e.(*ast.Ident)
+	if ok && g.locals[id.Name] {
+		return id.Name, true
+	}
+	return "", false
+}
+
+func (g *generator) mentionsLocal(e ast.Expr) bool {
+	found := false
+	ast.Inspect(e, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			return false
+		case *ast.Ident:
+			found = found || g.locals[n.Name]
+		}
+		return !found
+	})
+	return found
+}
+
+func (g *generator) use(e ast.Expr) {
+	ast.Inspect(e, func(n ast.Node) bool {
+		if s, ok := n.(*ast.SelectorExpr); ok {
+			if x, ok := s.X.(*ast.Ident); ok {
+				g.used[x.Name] = true
+			}
+			return false
+		}
+		return true
+	})
+}
+
+func (g *generator) funcName(name string) string {
+	return "From" + strings.ToUpper(g.version[:1]) + g.version[1:] + name
+}
+
+func deref(e ast.Expr) (bool, ast.Expr) {
+	if s, ok := e.(*ast.StarExpr); ok {
+		return true, s.X
+	}
+	return false, e
+}
+
+func plural(name string) string {
+	switch {
+	case strings.HasSuffix(name, "s"):
+		return name + "es"
+	case strings.HasSuffix(name, "y"):
+		return name[:len(name)-1] + "ies"
+	}
+	return name + "s"
+}
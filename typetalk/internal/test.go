@@ -3,6 +3,7 @@ package internal
 import (
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"reflect"
 	"testing"
@@ -45,3 +46,24 @@ func TestHeader(t *testing.T, r *http.Request, header string, want string) {
 		t.Errorf("Header.Get(%q) returned\n %q, \n want %q", header, got, want)
 	}
 }
+
+type redirectTransport struct {
+	target *url.URL
+	base   http.RoundTripper
+}
+
+func (t *redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := req.Clone(req.Context())
+	r.URL.Scheme = t.target.Scheme
+	r.URL.Host = t.target.Host
+	r.Host = t.target.Host
+	return t.base.RoundTrip(r)
+}
+
+// NewTestClient returns an http.Client that sends every request to the given
+// test server. The request path is kept as is, so handlers are registered
+// with the full API path such as "/api/v1/topics/1".
+func NewTestClient(server *httptest.Server) *http.Client {
+	target, _ := url.Parse(server.URL)
+	return &http.Client{Transport: &redirectTransport{target: target, base: server.Client().Transport}}
+}
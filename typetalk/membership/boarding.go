@@ -0,0 +1,246 @@
+package membership
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+	v2 "github.com/nulab/go-typetalk/v3/typetalk/v2"
+	"gopkg.in/yaml.v2"
+)
+
+// Rule selects topics to add a person to. A topic matches a rule when it
+// matches all of the conditions set in the rule; a rule without a topic
+// pattern matches every topic.
+type Rule struct {
+	// TopicPattern is a regular expression matching topic names.
+	TopicPattern string `yaml:"topicPattern"`
+	// Group limits the rule to people in the group with this key. It is
+	// compared with Rules.Groups. It doesn't select the topics the group is
+	// a member of, whose access the people of the group already have.
+	Group string `yaml:"group"`
+	// Role limits the rule to people with this role in the space, such as
+	// "Member" or "Guest". It is compared with Rules.Role.
+	Role string `yaml:"role"`
+}
+
+// Rules select the topics Onboard adds a person to. A topic is selected when
+// it matches any of the rules.
+type Rules struct {
+	// Role is the role of the person in the space. The API doesn't tell the
+	// role of an account, so the caller gives it.
+	Role string `yaml:"role"`
+	// Groups are the keys of the groups of the person. The API doesn't list
+	// the accounts of a group, so the caller gives them.
+	Groups []string `yaml:"groups"`
+	Rules  []*Rule  `yaml:"rules"`
+}
+
+// LoadRules reads Rules from a YAML file:
+//
+//	rules:
+//	  - topicPattern: ^ops-
+//	    group: sre
+//	  - topicPattern: ^announcements$
+//	    role: Member
+func LoadRules(name string) (*Rules, error) {
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	rules := &Rules{}
+	if err := yaml.UnmarshalStrict(b, rules); err != nil {
+		return nil, fmt.Errorf("membership: parsing %s: %v", name, err)
+	}
+	return rules, nil
+}
+
+// BoardingOptions configures a Boarding.
+type BoardingOptions struct {
+	// DryRun only reports the topics that would change.
+	DryRun bool
+}
+
+// TopicChange is a topic a person is added to or removed from.
+type TopicChange struct {
+	TopicID int    `json:"topicId"`
+	Name    string `json:"name"`
+	// Error is set when the topic could not be changed.
+	Error string `json:"error,omitempty"`
+}
+
+// BoardingReport describes an Onboard or Offboard.
+type BoardingReport struct {
+	Action    string         `json:"action"`
+	AccountID int            `json:"accountId"`
+	DryRun    bool           `json:"dryRun"`
+	Topics    []*TopicChange `json:"topics"`
+}
+
+// Failed reports whether changing some of the topics failed.
+func (r *BoardingReport) Failed() bool {
+	for _, t := range r.Topics {
+		if t.Error != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Write writes the report in the format of Plan.Write, one line per topic.
+func (r *BoardingReport) Write(w io.Writer) error {
+	sign := "+"
+	if r.Action == "offboard" {
+		sign = "-"
+	}
+	var b strings.Builder
+	for _, t := range r.Topics {
+		fmt.Fprintf(&b, "%s account %d: topic %d (%s)", sign, r.AccountID, t.TopicID, t.Name)
+		if t.Error != "" {
+			fmt.Fprintf(&b, " ! %s", t.Error)
+		}
+		b.WriteString("\n")
+	}
+	if b.Len() == 0 {
+		b.WriteString("no changes\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Boarding adds people to and removes them from all the topics of a space.
+// Only the topics the user of the client belongs to are seen.
+type Boarding struct {
+	v1  *v1.Client
+	v2  *v2.Client
+	opt BoardingOptions
+}
+
+// NewBoarding returns a Boarding that lists the topics of a space through
+// the v2 client and makes every other request through the v1 client.
+func NewBoarding(clientV1 *v1.Client, clientV2 *v2.Client, opt *BoardingOptions) *Boarding {
+	b := &Boarding{v1: clientV1, v2: clientV2}
+	if opt != nil {
+		b.opt = *opt
+	}
+	return b
+}
+
+// Onboard adds the account to the topics of the space matching the rules.
+// Topics the account already belongs to are left out of the report.
+func (b *Boarding) Onboard(ctx context.Context, spaceKey string, accountID int, rules *Rules) (*BoardingReport, error) {
+	patterns := make([]*regexp.Regexp, len(rules.Rules))
+	for i, rule := range rules.Rules {
+		if rule.TopicPattern == "" {
+			continue
+		}
+		re, err := regexp.Compile(rule.TopicPattern)
+		if err != nil {
+			return nil, fmt.Errorf("membership: topic pattern %q: %v", rule.TopicPattern, err)
+		}
+		patterns[i] = re
+	}
+	matches := func(details *v1.TopicDetails) bool {
+		for i, rule := range rules.Rules {
+			if rule.Role != "" && !strings.EqualFold(rule.Role, rules.Role) {
+				continue
+			}
+			if patterns[i] != nil && !patterns[i].MatchString(details.Topic.Name) {
+				continue
+			}
+			if rule.Group != "" && !inGroup(rules.Groups, rule.Group) {
+				continue
+			}
+			return true
+		}
+		return false
+	}
+
+	r := &BoardingReport{Action: "onboard", AccountID: accountID, DryRun: b.opt.DryRun}
+	err := b.eachTopic(ctx, spaceKey, func(details *v1.TopicDetails) *v1.UpdateTopicMembersOptions {
+		if hasAccount(details, accountID) || !matches(details) {
+			return nil
+		}
+		return &v1.UpdateTopicMembersOptions{AddAccountIds: []int{accountID}}
+	}, r)
+	return r, err
+}
+
+// Offboard removes the account from every topic of the space it belongs
+// to, whichever rules it was added by. The groups of the topics are not
+// changed, so the account keeps the access of the groups it is in until it
+// is removed from them.
+func (b *Boarding) Offboard(ctx context.Context, spaceKey string, accountID int) (*BoardingReport, error) {
+	r := &BoardingReport{Action: "offboard", AccountID: accountID, DryRun: b.opt.DryRun}
+	err := b.eachTopic(ctx, spaceKey, func(details *v1.TopicDetails) *v1.UpdateTopicMembersOptions {
+		if !hasAccount(details, accountID) {
+			return nil
+		}
+		return &v1.UpdateTopicMembersOptions{
+			RemoveAccountsID:                    []int{accountID},
+			RemoveAccountsCancelSpaceInvitation: []bool{false},
+		}
+	}, r)
+	return r, err
+}
+
+// eachTopic updates the members of every topic of the space with the
+// options returned by change, skipping the topics it returns nil for.
+// Errors of a topic are recorded in the report.
+func (b *Boarding) eachTopic(ctx context.Context, spaceKey string, change func(*v1.TopicDetails) *v1.UpdateTopicMembersOptions, r *BoardingReport) error {
+	topics, _, err := b.v2.Topics.GetMyTopics(ctx, spaceKey)
+	if err != nil {
+		return fmt.Errorf("membership: getting topics of space %s: %v", spaceKey, err)
+	}
+	for _, t := range topics {
+		if t.Topic.IsDirectMessage {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		details, _, err := b.v1.Topics.GetTopicDetails(ctx, t.Topic.ID)
+		if err != nil {
+			r.Topics = append(r.Topics, &TopicChange{TopicID: t.Topic.ID, Name: t.Topic.Name, Error: err.Error()})
+			continue
+		}
+		if details.Topic == nil {
+			details.Topic = &v1.Topic{ID: t.Topic.ID, Name: t.Topic.Name}
+		}
+		opt := change(details)
+		if opt == nil {
+			continue
+		}
+		c := &TopicChange{TopicID: t.Topic.ID, Name: t.Topic.Name}
+		r.Topics = append(r.Topics, c)
+		if b.opt.DryRun {
+			continue
+		}
+		if _, _, err := b.v1.Topics.UpdateTopicMembers(ctx, t.Topic.ID, opt); err != nil {
+			c.Error = err.Error()
+		}
+	}
+	return nil
+}
+
+func hasAccount(details *v1.TopicDetails, accountID int) bool {
+	for _, a := range details.Accounts {
+		if a.ID == accountID {
+			return true
+		}
+	}
+	return false
+}
+
+func inGroup(groups []string, key string) bool {
+	for _, g := range groups {
+		if g == key {
+			return true
+		}
+	}
+	return false
+}
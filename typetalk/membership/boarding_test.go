@@ -0,0 +1,178 @@
+package membership
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	. "github.com/nulab/go-typetalk/v3/typetalk/internal"
+)
+
+// handleSpace serves three topics of the space: "ops" with the group sre,
+// "ops-alerts" with account 10, and "random". It records the member updates
+// made to each topic.
+func handleSpace(t *testing.T) map[int]string {
+	updates := map[int]string{}
+	mux.HandleFunc("/api/v2/topics", func(w http.ResponseWriter, r *http.Request) {
+		TestMethod(t, r, "GET")
+		if r.URL.Query().Get("spaceKey") != "space" {
+			t.Errorf("spaceKey: got %q", r.URL.Query().Get("spaceKey"))
+		}
+		fmt.Fprint(w, `{"topics": [
+		  {"topic": {"id": 1, "name": "ops"}},
+		  {"topic": {"id": 2, "name": "ops-alerts"}},
+		  {"topic": {"id": 3, "name": "random"}},
+		  {"topic": {"id": 4, "name": "", "isDirectMessage": true}}
+		]}`)
+	})
+	handleTopics(t, map[int]string{
+		1: `{"topic": {"id": 1, "name": "ops"}, "accounts": [{"id": 11}], "groups": [{"group": {"id": 20, "key": "sre"}}]}`,
+		2: `{"topic": {"id": 2, "name": "ops-alerts"}, "accounts": [{"id": 10}, {"id": 11}]}`,
+		3: `{"topic": {"id": 3, "name": "random"}, "accounts": [{"id": 10}]}`,
+	})
+	for id := 1; id <= 4; id++ {
+		id := id
+		mux.HandleFunc(fmt.Sprintf("/api/v1/topics/%d/members/update", id), func(w http.ResponseWriter, r *http.Request) {
+			TestMethod(t, r, "POST")
+			r.ParseForm()
+			updates[id] = r.PostForm.Encode()
+			fmt.Fprint(w, `{}`)
+		})
+	}
+	return updates
+}
+
+func Test_Boarding_Onboard_should_add_to_matching_topics(t *testing.T) {
+	setup()
+	defer teardown()
+	updates := handleSpace(t)
+	rules := &Rules{
+		Role: "Member",
+		Rules: []*Rule{
+			{TopicPattern: "^ops"},
+			{Group: "sre", Role: "Guest"},
+			{TopicPattern: "^random$", Role: "member"},
+		},
+	}
+
+	r, err := NewBoarding(client, clientV2, nil).Onboard(context.Background(), "space", 11, rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*TopicChange{{TopicID: 3, Name: "random"}}
+	if !reflect.DeepEqual(r.Topics, want) {
+		t.Errorf("Topics: got %+v, want %+v", r.Topics, want)
+	}
+	if want := map[int]string{3: "addAccountIds%5B0%5D=11"}; !reflect.DeepEqual(updates, want) {
+		t.Errorf("updates: got %v, want %v", updates, want)
+	}
+}
+
+func Test_Boarding_Onboard_should_match_the_groups_of_the_person(t *testing.T) {
+	setup()
+	defer teardown()
+	updates := handleSpace(t)
+	b := NewBoarding(client, clientV2, &BoardingOptions{DryRun: true})
+	ctx := context.Background()
+
+	r, err := b.Onboard(ctx, "space", 12, &Rules{Groups: []string{"dev", "sre"}, Rules: []*Rule{{Group: "sre"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	r.Write(&buf)
+	if want := "+ account 12: topic 1 (ops)\n+ account 12: topic 2 (ops-alerts)\n+ account 12: topic 3 (random)\n"; buf.String() != want {
+		t.Errorf("Write: got %q, want %q", buf.String(), want)
+	}
+	if len(updates) != 0 {
+		t.Errorf("dry run updated %v", updates)
+	}
+
+	r, err = b.Onboard(ctx, "space", 12, &Rules{Groups: []string{"dev"}, Rules: []*Rule{{Group: "sre"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Topics) != 0 {
+		t.Errorf("Topics of a person outside the group: got %+v", r.Topics)
+	}
+}
+
+func Test_Boarding_should_onboard_and_offboard_with_group_rules(t *testing.T) {
+	setup()
+	defer teardown()
+	updates := handleSpace(t)
+	b := NewBoarding(client, clientV2, nil)
+	ctx := context.Background()
+	rules := &Rules{Groups: []string{"sre"}, Rules: []*Rule{{TopicPattern: "^ops", Group: "sre"}}}
+
+	r, err := b.Onboard(ctx, "space", 12, rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*TopicChange{{TopicID: 1, Name: "ops"}, {TopicID: 2, Name: "ops-alerts"}}
+	if !reflect.DeepEqual(r.Topics, want) || r.Failed() {
+		t.Errorf("Onboard: got %+v, want %+v", r.Topics, want)
+	}
+	if want := map[int]string{1: "addAccountIds%5B0%5D=12", 2: "addAccountIds%5B0%5D=12"}; !reflect.DeepEqual(updates, want) {
+		t.Errorf("Onboard updates: got %v, want %v", updates, want)
+	}
+
+	for id := range updates {
+		delete(updates, id)
+	}
+	r, err = b.Offboard(ctx, "space", 11)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(r.Topics, want) || r.Failed() {
+		t.Errorf("Offboard: got %+v, want %+v", r.Topics, want)
+	}
+	removed := "removeAccounts%5B0%5D.cancelSpaceInvitation=false&removeAccounts%5B0%5D.id=11"
+	if want := map[int]string{1: removed, 2: removed}; !reflect.DeepEqual(updates, want) {
+		t.Errorf("Offboard updates: got %v, want %v", updates, want)
+	}
+}
+
+func Test_Boarding_Onboard_should_reject_bad_patterns(t *testing.T) {
+	_, err := NewBoarding(nil, nil, nil).Onboard(context.Background(), "space", 10, &Rules{Rules: []*Rule{{TopicPattern: "("}}})
+	if err == nil {
+		t.Error("Onboard: expected an error")
+	}
+}
+
+func Test_Boarding_Offboard_should_remove_from_all_topics(t *testing.T) {
+	setup()
+	defer teardown()
+	updates := handleSpace(t)
+
+	r, err := NewBoarding(client, clientV2, nil).Offboard(context.Background(), "space", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*TopicChange{{TopicID: 2, Name: "ops-alerts"}, {TopicID: 3, Name: "random"}}
+	if !reflect.DeepEqual(r.Topics, want) || r.Failed() {
+		t.Errorf("Topics: got %+v, want %+v", r.Topics, want)
+	}
+	for _, id := range []int{2, 3} {
+		if updates[id] != "removeAccounts%5B0%5D.cancelSpaceInvitation=false&removeAccounts%5B0%5D.id=10" {
+			t.Errorf("topic %d: got %q", id, updates[id])
+		}
+	}
+	if len(updates) != 2 {
+		t.Errorf("updates: got %v", updates)
+	}
+}
+
+func Test_LoadRules_should_read_rules(t *testing.T) {
+	rules, err := LoadRules(fixturesPath + "rules.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Rules{Rules: []*Rule{{TopicPattern: "^ops"}, {TopicPattern: "^ops-", Group: "sre"}, {TopicPattern: "^random$", Role: "Member"}}}
+	if !reflect.DeepEqual(rules, want) {
+		t.Errorf("LoadRules: got %+v, want %+v", rules, want)
+	}
+}
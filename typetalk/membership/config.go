@@ -0,0 +1,75 @@
+// Package membership keeps the members of topics in sync with a desired
+// state kept in version control.
+package membership
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Invitation is an email invitation to a topic.
+type Invitation struct {
+	Email string `yaml:"email"`
+	Role  string `yaml:"role"`
+}
+
+// Topic is the desired membership of a topic.
+type Topic struct {
+	ID int `yaml:"id"`
+	// Name is only used in plans, to make them easier to read.
+	Name string `yaml:"name"`
+	// Accounts are the names of the member accounts.
+	Accounts []string `yaml:"accounts"`
+	// Groups are the keys of the member groups.
+	Groups []string `yaml:"groups"`
+	// Invitations are the pending invitations of people without an
+	// account in the space.
+	Invitations []*Invitation `yaml:"invitations"`
+	// Additive only adds members; members missing from the desired state
+	// are kept. Otherwise they are removed, except for bots and the account
+	// the tool runs as, which are only removed by hand.
+	Additive bool `yaml:"additive"`
+}
+
+// Config is the desired membership of topics.
+type Config struct {
+	// Space is the key of the space account names and group keys are
+	// resolved in. The space of each topic is used when it is empty.
+	Space  string   `yaml:"space"`
+	Topics []*Topic `yaml:"topics"`
+}
+
+// LoadConfig reads a Config from a YAML file:
+//
+//	space: abcdefghij
+//	topics:
+//	  - id: 123
+//	    name: ops
+//	    accounts: [alice, bob]
+//	    groups: [sre]
+//	    invitations:
+//	      - email: carol@example.com
+//	        role: Member
+func LoadConfig(name string) (*Config, error) {
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	config := &Config{}
+	if err := yaml.UnmarshalStrict(b, config); err != nil {
+		return nil, fmt.Errorf("membership: parsing %s: %v", name, err)
+	}
+	seen := map[int]bool{}
+	for _, t := range config.Topics {
+		if t.ID == 0 {
+			return nil, fmt.Errorf("membership: %s: topic %q has no id", name, t.Name)
+		}
+		if seen[t.ID] {
+			return nil, fmt.Errorf("membership: %s: topic %d is listed twice", name, t.ID)
+		}
+		seen[t.ID] = true
+	}
+	return config, nil
+}
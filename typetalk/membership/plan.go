@@ -0,0 +1,334 @@
+package membership
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+)
+
+// PendingInvitation is an invitation of a topic that is not accepted yet.
+type PendingInvitation struct {
+	Email string
+	Role  string
+	// AccountID is the account the invitation was sent to, if any. Only
+	// invitations with an account can be cancelled.
+	AccountID int
+}
+
+// TopicPlan is the changes that bring a topic to its desired membership.
+type TopicPlan struct {
+	TopicID int
+	Name    string
+
+	AddAccounts       []*v1.Account
+	RemoveAccounts    []*v1.Account
+	AddGroups         []*v1.Group
+	RemoveGroups      []*v1.Group
+	Invite            []*Invitation
+	CancelInvitations []*PendingInvitation
+
+	// Errors are the problems that prevent applying the plan, such as
+	// unknown account names.
+	Errors []string
+}
+
+// HasChanges reports whether the topic differs from its desired state.
+func (p *TopicPlan) HasChanges() bool {
+	return len(p.AddAccounts)+len(p.RemoveAccounts)+len(p.AddGroups)+len(p.RemoveGroups)+
+		len(p.Invite)+len(p.CancelInvitations) > 0
+}
+
+// Options returns the UpdateTopicMembers options that apply the plan.
+func (p *TopicPlan) Options() *v1.UpdateTopicMembersOptions {
+	opt := &v1.UpdateTopicMembersOptions{}
+	for _, a := range p.AddAccounts {
+		opt.AddAccountIds = append(opt.AddAccountIds, a.ID)
+	}
+	for _, g := range p.AddGroups {
+		opt.AddGroupIds = append(opt.AddGroupIds, g.ID)
+	}
+	for _, inv := range p.Invite {
+		opt.InvitationsEmail = append(opt.InvitationsEmail, inv.Email)
+		opt.InvitationsRole = append(opt.InvitationsRole, inv.Role)
+	}
+	// RemoveAccountsID and RemoveAccountsCancelSpaceInvitation are indexed
+	// together, so both are filled for every removed account.
+	for _, a := range p.RemoveAccounts {
+		opt.RemoveAccountsID = append(opt.RemoveAccountsID, a.ID)
+		opt.RemoveAccountsCancelSpaceInvitation = append(opt.RemoveAccountsCancelSpaceInvitation, false)
+	}
+	for _, inv := range p.CancelInvitations {
+		opt.RemoveAccountsID = append(opt.RemoveAccountsID, inv.AccountID)
+		opt.RemoveAccountsCancelSpaceInvitation = append(opt.RemoveAccountsCancelSpaceInvitation, true)
+	}
+	for _, g := range p.RemoveGroups {
+		opt.RemoveGroupIds = append(opt.RemoveGroupIds, g.ID)
+	}
+	return opt
+}
+
+// Plan is the changes to all the topics of a Config.
+type Plan struct {
+	Topics []*TopicPlan
+}
+
+// HasChanges reports whether any topic differs from its desired state.
+func (p *Plan) HasChanges() bool {
+	for _, t := range p.Topics {
+		if t.HasChanges() {
+			return true
+		}
+	}
+	return false
+}
+
+// HasErrors reports whether the plan can't be applied.
+func (p *Plan) HasErrors() bool {
+	for _, t := range p.Topics {
+		if len(t.Errors) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Write writes the plan as a diff:
+//
+//	topic 123 (ops)
+//	  + account alice
+//	  - group contractors
+//	  + invitation carol@example.com (Member)
+func (p *Plan) Write(w io.Writer) error {
+	var b strings.Builder
+	for _, t := range p.Topics {
+		if !t.HasChanges() && len(t.Errors) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "topic %d", t.TopicID)
+		if t.Name != "" {
+			fmt.Fprintf(&b, " (%s)", t.Name)
+		}
+		b.WriteString("\n")
+		for _, a := range t.AddAccounts {
+			fmt.Fprintf(&b, "  + account %s\n", a.Name)
+		}
+		for _, a := range t.RemoveAccounts {
+			fmt.Fprintf(&b, "  - account %s\n", a.Name)
+		}
+		for _, g := range t.AddGroups {
+			fmt.Fprintf(&b, "  + group %s\n", g.Key)
+		}
+		for _, g := range t.RemoveGroups {
+			fmt.Fprintf(&b, "  - group %s\n", g.Key)
+		}
+		for _, inv := range t.Invite {
+			fmt.Fprintf(&b, "  + invitation %s (%s)\n", inv.Email, inv.Role)
+		}
+		for _, inv := range t.CancelInvitations {
+			fmt.Fprintf(&b, "  - invitation %s\n", inv.Email)
+		}
+		for _, e := range t.Errors {
+			fmt.Fprintf(&b, "  ! %s\n", e)
+		}
+	}
+	if b.Len() == 0 {
+		b.WriteString("no changes\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Syncer plans and applies the membership of topics.
+type Syncer struct {
+	client *v1.Client
+	config *Config
+	spaces map[string]*v1.OrganizationMembers
+	me     int // the account of the client, fetched for the first removal
+}
+
+// NewSyncer returns a Syncer for the desired state in config.
+func NewSyncer(client *v1.Client, config *Config) *Syncer {
+	return &Syncer{client: client, config: config, spaces: map[string]*v1.OrganizationMembers{}}
+}
+
+// Plan compares the desired state with the members of the topics.
+func (s *Syncer) Plan(ctx context.Context) (*Plan, error) {
+	plan := &Plan{}
+	for _, t := range s.config.Topics {
+		details, _, err := s.client.Topics.GetTopicDetails(ctx, t.ID)
+		if err != nil {
+			return nil, fmt.Errorf("membership: getting topic %d: %v", t.ID, err)
+		}
+		spaceKey := s.config.Space
+		if spaceKey == "" && details.MySpace != nil && details.MySpace.Space != nil {
+			spaceKey = details.MySpace.Space.Key
+		}
+		members, err := s.spaceMembers(ctx, spaceKey)
+		if err != nil {
+			return nil, err
+		}
+		if !t.Additive && s.me == 0 {
+			profile, _, err := s.client.Accounts.GetMyProfile(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("membership: getting profile: %v", err)
+			}
+			if profile.Account != nil {
+				s.me = profile.Account.ID
+			}
+		}
+		plan.Topics = append(plan.Topics, diff(t, details, members, s.me))
+	}
+	return plan, nil
+}
+
+func (s *Syncer) spaceMembers(ctx context.Context, spaceKey string) (*v1.OrganizationMembers, error) {
+	if members, ok := s.spaces[spaceKey]; ok {
+		return members, nil
+	}
+	members, _, err := s.client.Organizations.GetOrganizationMembers(ctx, spaceKey)
+	if err != nil {
+		return nil, fmt.Errorf("membership: getting members of space %s: %v", spaceKey, err)
+	}
+	s.spaces[spaceKey] = members
+	return members, nil
+}
+
+// Apply updates the members of the topics with changes. It stops at the
+// first topic that fails.
+func (s *Syncer) Apply(ctx context.Context, plan *Plan) error {
+	if plan.HasErrors() {
+		return fmt.Errorf("membership: the plan has errors")
+	}
+	for _, t := range plan.Topics {
+		if !t.HasChanges() {
+			continue
+		}
+		if _, _, err := s.client.Topics.UpdateTopicMembers(ctx, t.TopicID, t.Options()); err != nil {
+			return fmt.Errorf("membership: updating topic %d: %v", t.TopicID, err)
+		}
+	}
+	return nil
+}
+
+// diff compares a topic with its desired state. The bots and the account me
+// are never removed.
+func diff(t *Topic, details *v1.TopicDetails, members *v1.OrganizationMembers, me int) *TopicPlan {
+	p := &TopicPlan{TopicID: t.ID, Name: t.Name}
+
+	accounts := map[string]*v1.Account{}
+	for _, a := range members.Accounts {
+		accounts[a.Name] = a
+	}
+	current := map[int]bool{}
+	for _, a := range details.Accounts {
+		current[a.ID] = true
+	}
+	desired := map[int]bool{}
+	for _, name := range t.Accounts {
+		a, ok := accounts[name]
+		if !ok {
+			p.Errors = append(p.Errors, fmt.Sprintf("unknown account %s", name))
+			continue
+		}
+		desired[a.ID] = true
+		if !current[a.ID] {
+			p.AddAccounts = append(p.AddAccounts, a)
+		}
+	}
+
+	groups := map[string]*v1.Group{}
+	for _, g := range members.Groups {
+		if g.Group != nil {
+			groups[g.Group.Key] = g.Group
+		}
+	}
+	currentGroups := map[int]bool{}
+	for _, g := range details.Groups {
+		if g.Group != nil {
+			currentGroups[g.Group.ID] = true
+		}
+	}
+	desiredGroups := map[int]bool{}
+	for _, key := range t.Groups {
+		g, ok := groups[key]
+		if !ok {
+			p.Errors = append(p.Errors, fmt.Sprintf("unknown group %s", key))
+			continue
+		}
+		desiredGroups[g.ID] = true
+		if !currentGroups[g.ID] {
+			p.AddGroups = append(p.AddGroups, g)
+		}
+	}
+
+	pending := PendingInvitations(details)
+	invited := map[string]bool{}
+	for _, inv := range pending {
+		invited[strings.ToLower(inv.Email)] = true
+	}
+	desiredInvitations := map[string]bool{}
+	for _, inv := range t.Invitations {
+		email := strings.ToLower(inv.Email)
+		desiredInvitations[email] = true
+		if !invited[email] {
+			p.Invite = append(p.Invite, inv)
+		}
+	}
+
+	if !t.Additive {
+		for _, a := range details.Accounts {
+			if !desired[a.ID] && !a.IsBot && a.ID != me {
+				p.RemoveAccounts = append(p.RemoveAccounts, a)
+			}
+		}
+		for _, g := range details.Groups {
+			if g.Group != nil && !desiredGroups[g.Group.ID] {
+				p.RemoveGroups = append(p.RemoveGroups, g.Group)
+			}
+		}
+		for _, inv := range pending {
+			if desiredInvitations[strings.ToLower(inv.Email)] {
+				continue
+			}
+			if inv.AccountID == 0 {
+				p.Errors = append(p.Errors, fmt.Sprintf("invitation %s can't be cancelled", inv.Email))
+				continue
+			}
+			p.CancelInvitations = append(p.CancelInvitations, inv)
+		}
+	}
+
+	sort.Slice(p.AddAccounts, func(i, j int) bool { return p.AddAccounts[i].Name < p.AddAccounts[j].Name })
+	sort.Slice(p.RemoveAccounts, func(i, j int) bool { return p.RemoveAccounts[i].Name < p.RemoveAccounts[j].Name })
+	sort.Slice(p.AddGroups, func(i, j int) bool { return p.AddGroups[i].Key < p.AddGroups[j].Key })
+	sort.Slice(p.RemoveGroups, func(i, j int) bool { return p.RemoveGroups[i].Key < p.RemoveGroups[j].Key })
+	return p
+}
+
+// PendingInvitations returns the invitations of a topic, both of the
+// accounts and of the email addresses without an account.
+func PendingInvitations(details *v1.TopicDetails) []*PendingInvitation {
+	var pending []*PendingInvitation
+	seen := map[string]bool{}
+	for _, list := range [][]*v1.TopicMemberInvitation{details.InvitingAccounts, details.Invites} {
+		for _, v := range list {
+			inv := &PendingInvitation{Email: v.MailAddress, Role: v.Role}
+			if v.Account != nil {
+				inv.AccountID = v.Account.ID
+				if inv.Email == "" {
+					inv.Email = v.Account.MailAddress
+				}
+			}
+			if inv.Email == "" || seen[strings.ToLower(inv.Email)] {
+				continue
+			}
+			seen[strings.ToLower(inv.Email)] = true
+			pending = append(pending, inv)
+		}
+	}
+	return pending
+}
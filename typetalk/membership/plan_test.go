@@ -0,0 +1,230 @@
+package membership
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	. "github.com/nulab/go-typetalk/v3/typetalk/internal"
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+	v2 "github.com/nulab/go-typetalk/v3/typetalk/v2"
+)
+
+const fixturesPath = "../../testdata/membership/"
+
+var (
+	mux      *http.ServeMux
+	client   *v1.Client
+	clientV2 *v2.Client
+	server   *httptest.Server
+)
+
+func setup() {
+	mux = http.NewServeMux()
+	server = httptest.NewServer(mux)
+
+	client = v1.NewClient(NewTestClient(server)).SetTypetalkToken("DUMMY_TOKEN")
+	clientV2 = v2.NewClient(NewTestClient(server)).SetTypetalkToken("DUMMY_TOKEN")
+}
+
+func teardown() {
+	server.Close()
+}
+
+const spaceMembers = `{
+  "accounts": [
+    {"id": 10, "name": "alice"},
+    {"id": 11, "name": "bob"},
+    {"id": 12, "name": "dave"}
+  ],
+  "groups": [
+    {"group": {"id": 20, "key": "sre"}, "memberCount": 3},
+    {"group": {"id": 21, "key": "contractors"}, "memberCount": 5}
+  ]
+}`
+
+func handleTopics(t *testing.T, details map[int]string) {
+	mux.HandleFunc("/api/v1/spaces/space/members", func(w http.ResponseWriter, r *http.Request) {
+		TestMethod(t, r, "GET")
+		fmt.Fprint(w, spaceMembers)
+	})
+	mux.HandleFunc("/api/v1/profile", func(w http.ResponseWriter, r *http.Request) {
+		TestMethod(t, r, "GET")
+		fmt.Fprint(w, `{"account": {"id": 14, "name": "admin"}}`)
+	})
+	for id, body := range details {
+		body := body
+		mux.HandleFunc(fmt.Sprintf("/api/v1/topics/%d", id), func(w http.ResponseWriter, r *http.Request) {
+			TestMethod(t, r, "GET")
+			fmt.Fprint(w, body)
+		})
+	}
+}
+
+func Test_LoadConfig_should_read_topics(t *testing.T) {
+	config, err := LoadConfig(fixturesPath + "config.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Config{
+		Space: "space",
+		Topics: []*Topic{
+			{
+				ID:          1,
+				Name:        "ops",
+				Accounts:    []string{"alice", "bob"},
+				Groups:      []string{"sre"},
+				Invitations: []*Invitation{{Email: "carol@example.com", Role: "Member"}},
+			},
+			{ID: 2, Name: "announcements", Accounts: []string{"alice"}, Additive: true},
+		},
+	}
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("LoadConfig: got %+v, want %+v", config, want)
+	}
+}
+
+func Test_Syncer_Plan_should_diff_members(t *testing.T) {
+	setup()
+	defer teardown()
+	handleTopics(t, map[int]string{
+		1: `{
+		  "accounts": [{"id": 10, "name": "alice"}, {"id": 12, "name": "dave"}, {"id": 14, "name": "admin"}, {"id": 15, "name": "ci", "isBot": true}],
+		  "groups": [{"group": {"id": 21, "key": "contractors"}}],
+		  "invites": [{"id": 30, "mailAddress": "erin@example.com", "role": "Member", "account": {"id": 13}}]
+		}`,
+		2: `{"accounts": [{"id": 10, "name": "alice"}, {"id": 12, "name": "dave"}]}`,
+	})
+	config, err := LoadConfig(fixturesPath + "config.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := NewSyncer(client, config).Plan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !plan.HasChanges() || plan.HasErrors() {
+		t.Fatalf("HasChanges: %v, HasErrors: %v", plan.HasChanges(), plan.HasErrors())
+	}
+	if plan.Topics[1].HasChanges() {
+		t.Errorf("additive topic should not remove members: %+v", plan.Topics[1])
+	}
+	var b bytes.Buffer
+	if err := plan.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := `topic 1 (ops)
+  + account bob
+  - account dave
+  + group sre
+  - group contractors
+  + invitation carol@example.com (Member)
+  - invitation erin@example.com
+`
+	if b.String() != want {
+		t.Errorf("Write: got\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func Test_Syncer_Plan_should_report_unknown_names(t *testing.T) {
+	setup()
+	defer teardown()
+	handleTopics(t, map[int]string{1: `{"accounts": []}`})
+	config := &Config{Space: "space", Topics: []*Topic{{ID: 1, Accounts: []string{"mallory"}, Groups: []string{"nobody"}}}}
+
+	plan, err := NewSyncer(client, config).Plan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !plan.HasErrors() {
+		t.Fatal("HasErrors: got false")
+	}
+	want := []string{"unknown account mallory", "unknown group nobody"}
+	if !reflect.DeepEqual(plan.Topics[0].Errors, want) {
+		t.Errorf("Errors: got %v, want %v", plan.Topics[0].Errors, want)
+	}
+	if err := NewSyncer(client, config).Apply(context.Background(), plan); err == nil {
+		t.Error("Apply: expected an error")
+	}
+}
+
+func Test_Syncer_Plan_should_not_cancel_invitations_without_account(t *testing.T) {
+	setup()
+	defer teardown()
+	handleTopics(t, map[int]string{1: `{
+	  "accounts": [],
+	  "invites": [{"id": 10, "mailAddress": "erin@example.com", "role": "Member"}]
+	}`})
+	config := &Config{Space: "space", Topics: []*Topic{{ID: 1}}}
+
+	plan, err := NewSyncer(client, config).Plan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Topics[0].CancelInvitations) != 0 {
+		t.Errorf("CancelInvitations: got %+v", plan.Topics[0].CancelInvitations[0])
+	}
+	if want := []string{"invitation erin@example.com can't be cancelled"}; !reflect.DeepEqual(plan.Topics[0].Errors, want) {
+		t.Errorf("Errors: got %v, want %v", plan.Topics[0].Errors, want)
+	}
+}
+
+func Test_Syncer_Apply_should_update_members(t *testing.T) {
+	setup()
+	defer teardown()
+	handleTopics(t, map[int]string{
+		1: `{
+		  "accounts": [{"id": 10, "name": "alice"}, {"id": 12, "name": "dave"}],
+		  "groups": [{"group": {"id": 21, "key": "contractors"}}],
+		  "invitingAccounts": [{"account": {"id": 13, "mailAddress": "erin@example.com"}}]
+		}`,
+		2: `{"accounts": [{"id": 10, "name": "alice"}]}`,
+	})
+	var form url.Values
+	mux.HandleFunc("/api/v1/topics/1/members/update", func(w http.ResponseWriter, r *http.Request) {
+		TestMethod(t, r, "POST")
+		r.ParseForm()
+		form = r.PostForm
+		fmt.Fprint(w, `{}`)
+	})
+	mux.HandleFunc("/api/v1/topics/2/members/update", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("topic 2 has no changes")
+	})
+	config, err := LoadConfig(fixturesPath + "config.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewSyncer(client, config)
+	plan, err := s.Plan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Apply(context.Background(), plan); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"addAccountIds[0]":                        "11",
+		"addGroupIds[0]":                          "20",
+		"invitations[0].email":                    "carol@example.com",
+		"invitations[0].role":                     "Member",
+		"removeAccounts[0].id":                    "12",
+		"removeAccounts[1].id":                    "13",
+		"removeAccounts[1].cancelSpaceInvitation": "true",
+		"removeGroupIds[0]":                       "21",
+	}
+	for k, v := range want {
+		if form.Get(k) != v {
+			t.Errorf("%s: got %q, want %q", k, form.Get(k), v)
+		}
+	}
+	if form.Get("removeAccounts[0].cancelSpaceInvitation") == "true" {
+		t.Error("removed member should not cancel a space invitation")
+	}
+}
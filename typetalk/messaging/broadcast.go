@@ -0,0 +1,141 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+)
+
+// BroadcastOptions configures BroadcastMessage.
+type BroadcastOptions struct {
+	// Parallelism is the number of topics posted to at the same time. It
+	// defaults to 4.
+	Parallelism  int
+	ShowLinkMeta bool
+	// Files are local files attached to every post. File keys are scoped
+	// to a topic, so each file is uploaded once per topic.
+	Files     []string
+	FileURLs  []string
+	FileNames []string
+}
+
+// BroadcastError is returned by BroadcastMessage when posting to some of the
+// topics failed.
+type BroadcastError struct {
+	// Errors maps the failed topics to their errors.
+	Errors map[int]error
+}
+
+// Failed returns the sorted IDs of the failed topics, for example to retry
+// them with BroadcastMessage.
+func (e *BroadcastError) Failed() []int {
+	ids := make([]int, 0, len(e.Errors))
+	for id := range e.Errors {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+func (e *BroadcastError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, id := range e.Failed() {
+		msgs = append(msgs, fmt.Sprintf("topic %d: %v", id, e.Errors[id]))
+	}
+	return fmt.Sprintf("messaging: posting to %d topics failed: %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+// BroadcastMessage posts a message to every topic, several topics at a
+// time. It returns the results of the successful posts keyed by topic ID,
+// and a *BroadcastError naming the failed topics if there are any:
+//
+//	results, err := m.BroadcastMessage(ctx, topicIDs, message, nil)
+//	if e, ok := err.(*messaging.BroadcastError); ok {
+//		retried, err := m.BroadcastMessage(ctx, e.Failed(), message, nil)
+//		...
+//	}
+func (m *Messenger) BroadcastMessage(ctx context.Context, topicIDs []int, message string, opt *BroadcastOptions) (map[int]*v1.PostedMessageResult, error) {
+	if opt == nil {
+		opt = &BroadcastOptions{}
+	}
+	parallelism := opt.Parallelism
+	if parallelism <= 0 {
+		parallelism = 4
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = map[int]*v1.PostedMessageResult{}
+		errs    = map[int]error{}
+		sem     = make(chan struct{}, parallelism)
+		seen    = map[int]bool{}
+	)
+	for _, topicID := range topicIDs {
+		if seen[topicID] {
+			continue
+		}
+		seen[topicID] = true
+		wg.Add(1)
+		go func(topicID int) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				mu.Lock()
+				errs[topicID] = ctx.Err()
+				mu.Unlock()
+				return
+			}
+			result, err := m.postToTopic(ctx, topicID, message, opt)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs[topicID] = err
+			} else {
+				results[topicID] = result
+			}
+		}(topicID)
+	}
+	wg.Wait()
+	if len(errs) > 0 {
+		return results, &BroadcastError{Errors: errs}
+	}
+	return results, nil
+}
+
+func (m *Messenger) postToTopic(ctx context.Context, topicID int, message string, opt *BroadcastOptions) (*v1.PostedMessageResult, error) {
+	postOpt := &v1.PostMessageOptions{
+		ShowLinkMeta: opt.ShowLinkMeta,
+		FileUrls:     opt.FileURLs,
+		FileNames:    opt.FileNames,
+	}
+	for _, name := range opt.Files {
+		key, err := m.upload(ctx, topicID, name)
+		if err != nil {
+			return nil, fmt.Errorf("uploading %s: %v", name, err)
+		}
+		postOpt.FileKeys = append(postOpt.FileKeys, key)
+	}
+	result, _, err := m.client.Messages.PostMessage(ctx, topicID, message, postOpt)
+	return result, err
+}
+
+func (m *Messenger) upload(ctx context.Context, topicID int, name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	file, _, err := m.client.Files.UploadAttachmentFile(ctx, topicID, f)
+	if err != nil {
+		return "", err
+	}
+	return file.FileKey, nil
+}
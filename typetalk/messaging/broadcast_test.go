@@ -0,0 +1,112 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	. "github.com/nulab/go-typetalk/v3/typetalk/internal"
+)
+
+func Test_Messenger_BroadcastMessage_should_post_to_every_topic(t *testing.T) {
+	setup()
+	defer teardown()
+	dir, _ := ioutil.TempDir("", "broadcast")
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "notes.txt")
+	ioutil.WriteFile(name, []byte("notes"), 0600)
+
+	var (
+		mu      sync.Mutex
+		running int
+		maxRun  int
+		uploads = map[int]int{}
+	)
+	for _, id := range []int{1, 2, 3, 4, 5} {
+		topicID := id
+		mux.HandleFunc(fmt.Sprintf("/api/v1/topics/%d/attachments", topicID), func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			uploads[topicID]++
+			mu.Unlock()
+			fmt.Fprintf(w, `{"fileKey": "key-%d"}`, topicID)
+		})
+		mux.HandleFunc(fmt.Sprintf("/api/v1/topics/%d", topicID), func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			running++
+			if running > maxRun {
+				maxRun = running
+			}
+			mu.Unlock()
+			defer func() {
+				mu.Lock()
+				running--
+				mu.Unlock()
+			}()
+			TestFormValues(t, r, Values{"message": "release 1.0", "fileKeys[0]": fmt.Sprintf("key-%d", topicID)})
+			fmt.Fprintf(w, `{"post": {"id": %d}}`, topicID*10)
+		})
+	}
+	m := newTestMessenger(nil)
+
+	results, err := m.BroadcastMessage(context.Background(), []int{1, 2, 3, 4, 5, 1}, "release 1.0", &BroadcastOptions{Parallelism: 2, Files: []string{name}})
+	if err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+	if len(results) != 5 {
+		t.Errorf("Results: got %d, want 5", len(results))
+	}
+	for topicID, result := range results {
+		if result.Post.ID != topicID*10 {
+			t.Errorf("Topic %d: got post %d", topicID, result.Post.ID)
+		}
+		if uploads[topicID] != 1 {
+			t.Errorf("Topic %d: uploaded %d times", topicID, uploads[topicID])
+		}
+	}
+	if maxRun > 2 {
+		t.Errorf("Posted to %d topics at the same time, want at most 2", maxRun)
+	}
+}
+
+func Test_Messenger_BroadcastMessage_should_report_failed_topics(t *testing.T) {
+	setup()
+	defer teardown()
+	down := map[int]bool{2: true, 4: true}
+	var mu sync.Mutex
+	for _, id := range []int{1, 2, 3, 4} {
+		topicID := id
+		mux.HandleFunc(fmt.Sprintf("/api/v1/topics/%d", topicID), func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			if down[topicID] {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			fmt.Fprintf(w, `{"post": {"id": %d}}`, topicID)
+		})
+	}
+	m := newTestMessenger(nil)
+
+	results, err := m.BroadcastMessage(context.Background(), []int{1, 2, 3, 4}, "hello", nil)
+	e, ok := err.(*BroadcastError)
+	if !ok {
+		t.Fatalf("Expected *BroadcastError, got %v", err)
+	}
+	if !reflect.DeepEqual(e.Failed(), []int{2, 4}) || len(results) != 2 {
+		t.Errorf("Failed: got %v, results %v", e.Failed(), results)
+	}
+
+	mu.Lock()
+	down = map[int]bool{}
+	mu.Unlock()
+	results, err = m.BroadcastMessage(context.Background(), e.Failed(), "hello", nil)
+	if err != nil || len(results) != 2 || results[2] == nil || results[4] == nil {
+		t.Errorf("Retry: got %v, %v", results, err)
+	}
+}
@@ -0,0 +1,171 @@
+package messaging
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/nulab/go-typetalk/typetalk/shared"
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+)
+
+// PostMessageIdempotent posts a message unless a message was already posted
+// to the topic with the same key, in which case the existing post is
+// returned. Keys are scoped to the topic and remembered for Options.KeyTTL.
+//
+// An empty key makes the content of the message its key. A message of the
+// client's own account with the same content and reply target, posted
+// within Options.Window among the Options.Lookback recent messages of the
+// topic, is then taken to be the earlier post, so a message is deduplicated
+// even when the store was lost.
+func (m *Messenger) PostMessageIdempotent(ctx context.Context, topicID int, key, message string, opt *v1.PostMessageOptions) (*v1.PostedMessageResult, *shared.Response, error) {
+	if m.opt.KeyTTL > 0 {
+		if _, err := m.opt.Store.Prune(m.now().Add(-m.opt.KeyTTL)); err != nil {
+			return nil, nil, err
+		}
+	}
+	replyTo := 0
+	if opt != nil {
+		replyTo = opt.ReplyTo
+	}
+	byContent := key == ""
+	if byContent {
+		key = fmt.Sprintf("sha256:%x", contentHash(message, replyTo))
+	}
+	storeKey := fmt.Sprintf("%d/%s", topicID, key)
+	unlock := m.locks.lock(storeKey)
+	defer unlock()
+
+	postID, err := m.opt.Store.Get(storeKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	if postID != 0 {
+		msg, resp, err := m.client.Messages.GetMessage(ctx, topicID, postID)
+		if err == nil {
+			return &v1.PostedMessageResult{Topic: msg.Topic, Post: msg.Post}, resp, nil
+		}
+		if !isNotFound(err) {
+			return nil, resp, err
+		}
+		// The post was deleted; post the message again.
+		if err := m.opt.Store.Delete(storeKey); err != nil {
+			return nil, nil, err
+		}
+	} else if byContent && m.opt.Lookback > 0 {
+		result, resp, err := m.findRecent(ctx, topicID, message, replyTo)
+		if err != nil {
+			return nil, resp, err
+		}
+		if result != nil {
+			if err := m.opt.Store.Put(storeKey, result.Post.ID); err != nil {
+				return nil, nil, err
+			}
+			return result, resp, nil
+		}
+	}
+
+	result, resp, err := m.client.Messages.PostMessage(ctx, topicID, message, opt)
+	if err != nil {
+		return nil, resp, err
+	}
+	if err := m.opt.Store.Put(storeKey, result.Post.ID); err != nil {
+		return result, resp, err
+	}
+	return result, resp, nil
+}
+
+func (m *Messenger) findRecent(ctx context.Context, topicID int, message string, replyTo int) (*v1.PostedMessageResult, *shared.Response, error) {
+	recent, resp, err := m.client.Topics.GetTopicMessages(ctx, topicID, &v1.GetTopicMessagesOptions{
+		Count:     m.opt.Lookback,
+		Direction: "backward",
+	})
+	if err != nil {
+		return nil, resp, err
+	}
+	hash := contentHash(message, replyTo)
+	since := m.now().Add(-m.opt.Window)
+	for i := len(recent.Posts) - 1; i >= 0; i-- {
+		p := recent.Posts[i]
+		if p.CreatedAt != nil && p.CreatedAt.Before(since) {
+			continue
+		}
+		if contentHash(p.Message, p.ReplyTo) != hash || p.Account == nil {
+			continue
+		}
+		me, resp, err := m.accountID(ctx)
+		if err != nil {
+			return nil, resp, err
+		}
+		if p.Account.ID == me {
+			return &v1.PostedMessageResult{Topic: recent.Topic, Post: p}, resp, nil
+		}
+	}
+	return nil, resp, nil
+}
+
+// accountID returns the ID of the account of the client. It is fetched once,
+// when a recent message first matches.
+func (m *Messenger) accountID(ctx context.Context) (int, *shared.Response, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.me != 0 {
+		return m.me, nil, nil
+	}
+	profile, resp, err := m.client.Accounts.GetMyProfile(ctx)
+	if err != nil {
+		return 0, resp, err
+	}
+	if profile.Account == nil {
+		return 0, resp, fmt.Errorf("messaging: the profile has no account")
+	}
+	m.me = profile.Account.ID
+	return m.me, resp, nil
+}
+
+func contentHash(message string, replyTo int) [sha256.Size]byte {
+	return sha256.Sum256([]byte(fmt.Sprintf("%d\x00%s", replyTo, strings.TrimSpace(message))))
+}
+
+func isNotFound(err error) bool {
+	e, ok := err.(*shared.ErrorResponse)
+	return ok && e.Response != nil && e.Response.StatusCode == http.StatusNotFound
+}
+
+// keyLocks serializes the posts of the same key.
+type keyLocks struct {
+	mu    sync.Mutex
+	locks map[string]*keyLock
+}
+
+type keyLock struct {
+	sync.Mutex
+	refs int
+}
+
+func (l *keyLocks) lock(key string) (unlock func()) {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = map[string]*keyLock{}
+	}
+	k, ok := l.locks[key]
+	if !ok {
+		k = &keyLock{}
+		l.locks[key] = k
+	}
+	k.refs++
+	l.mu.Unlock()
+
+	k.Lock()
+	return func() {
+		k.Unlock()
+		l.mu.Lock()
+		if k.refs--; k.refs == 0 {
+			delete(l.locks, key)
+		}
+		l.mu.Unlock()
+	}
+}
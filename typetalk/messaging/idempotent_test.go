@@ -0,0 +1,199 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/nulab/go-typetalk/v3/typetalk/internal"
+)
+
+func Test_Messenger_PostMessageIdempotent_should_post_once_per_key(t *testing.T) {
+	setup()
+	defer teardown()
+	posts := 0
+	mux.HandleFunc("/api/v1/topics/1", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			fmt.Fprint(w, `{"topic": {"id": 1}, "posts": []}`)
+		case http.MethodPost:
+			posts++
+			fmt.Fprintf(w, `{"topic": {"id": 1}, "post": {"id": %d, "message": "%s"}}`, 100+posts, r.FormValue("message"))
+		}
+	})
+	mux.HandleFunc("/api/v1/topics/1/posts/101", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"topic": {"id": 1}, "post": {"id": 101, "message": "deployed"}}`)
+	})
+	m := newTestMessenger(nil)
+
+	for i := 0; i < 3; i++ {
+		result, _, err := m.PostMessageIdempotent(context.Background(), 1, "deploy-42", "deployed", nil)
+		if err != nil {
+			t.Fatalf("Returned error: %v", err)
+		}
+		if result.Post.ID != 101 || result.Topic.ID != 1 {
+			t.Errorf("Returned post %d in topic %d", result.Post.ID, result.Topic.ID)
+		}
+	}
+	if posts != 1 {
+		t.Errorf("Posted %d times, want 1", posts)
+	}
+
+	result, _, _ := m.PostMessageIdempotent(context.Background(), 1, "deploy-43", "deployed again", nil)
+	if posts != 2 || result.Post.ID != 102 {
+		t.Errorf("A new key should post again: posted %d times, got post %d", posts, result.Post.ID)
+	}
+}
+
+func Test_Messenger_PostMessageIdempotent_should_find_recent_message_without_key(t *testing.T) {
+	setup()
+	defer teardown()
+	gets, posts := 0, 0
+	mux.HandleFunc("/api/v1/topics/1", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			posts++
+			fmt.Fprint(w, `{"topic": {"id": 1}, "post": {"id": 11}}`)
+			return
+		}
+		gets++
+		if got := r.URL.Query().Get("count"); got != "5" {
+			t.Errorf("count: got %s, want 5", got)
+		}
+		fmt.Fprint(w, `{"topic": {"id": 1}, "posts": [
+			{"id": 7, "message": "deployed", "account": {"id": 100}, "createdAt": "2020-01-01T10:00:00Z"},
+			{"id": 8, "message": "deployed", "replyTo": 3, "account": {"id": 100}, "createdAt": "2020-01-01T11:50:00Z"},
+			{"id": 9, "message": "deployed ", "account": {"id": 100}, "createdAt": "2020-01-01T11:55:00Z"},
+			{"id": 10, "message": "deployed", "account": {"id": 200}, "createdAt": "2020-01-01T11:58:00Z"}
+		]}`)
+	})
+	profiles := 0
+	mux.HandleFunc("/api/v1/profile", func(w http.ResponseWriter, r *http.Request) {
+		profiles++
+		fmt.Fprint(w, `{"account": {"id": 100}}`)
+	})
+	store := NewMemoryStore()
+	m := newTestMessenger(&Options{Store: store, Lookback: 5})
+
+	result, _, err := m.PostMessageIdempotent(context.Background(), 1, "", "deployed", nil)
+	if err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+	if result.Post.ID != 9 || posts != 0 {
+		t.Errorf("Returned post %d after %d posts, want 9 without posting", result.Post.ID, posts)
+	}
+	if id, _ := store.Get(fmt.Sprintf("1/sha256:%x", contentHash("deployed", 0))); id != 9 {
+		t.Errorf("Stored post %d, want 9", id)
+	}
+
+	// A key is never matched by content.
+	result, _, err = m.PostMessageIdempotent(context.Background(), 1, "deploy-43", "deployed", nil)
+	if err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+	if result.Post.ID != 11 || posts != 1 || gets != 1 {
+		t.Errorf("Returned post %d after %d posts and %d gets, want 11, 1 and 1", result.Post.ID, posts, gets)
+	}
+	if profiles != 1 {
+		t.Errorf("Fetched the profile %d times, want 1", profiles)
+	}
+}
+
+func Test_Messenger_PostMessageIdempotent_should_post_when_recent_message_is_of_another_account(t *testing.T) {
+	setup()
+	defer teardown()
+	posted := false
+	mux.HandleFunc("/api/v1/topics/1", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			fmt.Fprint(w, `{"topic": {"id": 1}, "posts": [{"id": 7, "message": "deployed", "account": {"id": 200}, "createdAt": "2020-01-01T11:55:00Z"}]}`)
+			return
+		}
+		posted = true
+		fmt.Fprint(w, `{"post": {"id": 10}}`)
+	})
+	mux.HandleFunc("/api/v1/profile", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"account": {"id": 100}}`)
+	})
+	m := newTestMessenger(nil)
+
+	result, _, err := m.PostMessageIdempotent(context.Background(), 1, "", "deployed", nil)
+	if err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+	if !posted || result.Post.ID != 10 {
+		t.Errorf("Expected the message to be posted, got post %d", result.Post.ID)
+	}
+}
+
+func Test_Messenger_PostMessageIdempotent_should_post_when_recent_message_is_too_old(t *testing.T) {
+	setup()
+	defer teardown()
+	posted := false
+	mux.HandleFunc("/api/v1/topics/1", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			fmt.Fprint(w, `{"topic": {"id": 1}, "posts": [{"id": 7, "message": "deployed", "createdAt": "2020-01-01T10:00:00Z"}]}`)
+			return
+		}
+		posted = true
+		fmt.Fprint(w, `{"post": {"id": 10}}`)
+	})
+	m := newTestMessenger(nil)
+
+	result, _, err := m.PostMessageIdempotent(context.Background(), 1, "", "deployed", nil)
+	if err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+	if !posted || result.Post.ID != 10 {
+		t.Errorf("Expected the message to be posted, got post %d", result.Post.ID)
+	}
+}
+
+func Test_Messenger_PostMessageIdempotent_should_post_again_when_post_was_deleted(t *testing.T) {
+	setup()
+	defer teardown()
+	mux.HandleFunc("/api/v1/topics/1", func(w http.ResponseWriter, r *http.Request) {
+		TestMethod(t, r, http.MethodPost)
+		fmt.Fprint(w, `{"post": {"id": 11}}`)
+	})
+	mux.HandleFunc("/api/v1/topics/1/posts/10", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	store := NewMemoryStore()
+	store.Put("1/deploy-42", 10)
+	m := newTestMessenger(&Options{Store: store, Lookback: -1})
+
+	result, _, err := m.PostMessageIdempotent(context.Background(), 1, "deploy-42", "deployed", nil)
+	if err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+	if id, _ := store.Get("1/deploy-42"); result.Post.ID != 11 || id != 11 {
+		t.Errorf("Returned post %d, stored %d, want 11", result.Post.ID, id)
+	}
+}
+
+func Test_Messenger_PostMessageIdempotent_should_forget_expired_keys(t *testing.T) {
+	setup()
+	defer teardown()
+	mux.HandleFunc("/api/v1/topics/1", func(w http.ResponseWriter, r *http.Request) {
+		TestMethod(t, r, http.MethodPost)
+		fmt.Fprint(w, `{"post": {"id": 11}}`)
+	})
+	store := NewMemoryStore()
+	store.now = func() time.Time { return testNow.Add(-31 * 24 * time.Hour) }
+	store.Put("1/deploy-42", 10)
+	store.Put("1/deploy-41", 9)
+	store.now = func() time.Time { return testNow }
+	m := newTestMessenger(&Options{Store: store})
+
+	result, _, err := m.PostMessageIdempotent(context.Background(), 1, "deploy-42", "deployed", nil)
+	if err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+	if id, _ := store.Get("1/deploy-42"); result.Post.ID != 11 || id != 11 {
+		t.Errorf("Returned post %d, stored %d, want 11", result.Post.ID, id)
+	}
+	if id, _ := store.Get("1/deploy-41"); id != 0 {
+		t.Errorf("Expired key: got %d", id)
+	}
+}
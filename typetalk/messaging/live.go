@@ -0,0 +1,235 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nulab/go-typetalk/typetalk/shared"
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+)
+
+// LiveOptions configures a LiveMessage.
+type LiveOptions struct {
+	// Interval is the minimum time between two updates of the message. It
+	// defaults to two seconds.
+	Interval time.Duration
+	// Reply posts the final summary as a reply too. Updating a message
+	// doesn't notify anyone, but posting a reply does.
+	Reply bool
+	// SuccessPrefix and FailurePrefix are prepended to the final summary.
+	// They default to "(OK) " and "(NG) ".
+	SuccessPrefix string
+	FailurePrefix string
+	// ErrorLog is used to log failed updates. The standard logger is used
+	// when it is nil.
+	ErrorLog *log.Logger
+}
+
+// LiveMessage is a message showing the progress of a long running job. It
+// is posted once and then updated in place.
+//
+// Updates are coalesced: only the latest text is sent, at most once per
+// Options.Interval, and sending is delayed while Typetalk answers 429 Too
+// Many Requests. Succeed or Fail must be called to finish the message.
+type LiveMessage struct {
+	client  *v1.Client
+	topicID int
+	postID  int
+	opt     LiveOptions
+
+	mu       sync.Mutex
+	text     string
+	sent     string
+	wake     chan struct{}
+	cancel   context.CancelFunc
+	done     chan struct{}
+	finished bool
+}
+
+// NewLiveMessage posts the initial text of a live message to the topic.
+func (m *Messenger) NewLiveMessage(ctx context.Context, topicID int, message string, opt *LiveOptions) (*LiveMessage, error) {
+	l := &LiveMessage{
+		client:  m.client,
+		topicID: topicID,
+		text:    message,
+		sent:    message,
+		wake:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	if opt != nil {
+		l.opt = *opt
+	}
+	if l.opt.Interval == 0 {
+		l.opt.Interval = 2 * time.Second
+	}
+	if l.opt.SuccessPrefix == "" {
+		l.opt.SuccessPrefix = "(OK) "
+	}
+	if l.opt.FailurePrefix == "" {
+		l.opt.FailurePrefix = "(NG) "
+	}
+	result, _, err := m.client.Messages.PostMessage(ctx, topicID, message, nil)
+	if err != nil {
+		return nil, err
+	}
+	l.postID = result.Post.ID
+	loopCtx, cancel := context.WithCancel(context.Background())
+	l.cancel = cancel
+	go l.loop(loopCtx)
+	return l, nil
+}
+
+// PostID returns the ID of the post of the message.
+func (l *LiveMessage) PostID() int {
+	return l.postID
+}
+
+// Update replaces the text of the message. It doesn't block; the text is
+// sent in the background.
+func (l *LiveMessage) Update(message string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.finished {
+		return
+	}
+	l.text = message
+	select {
+	case l.wake <- struct{}{}:
+	default:
+	}
+}
+
+// loop sends the updates until ctx is canceled by finish, which also
+// aborts an update in flight.
+func (l *LiveMessage) loop(ctx context.Context) {
+	defer close(l.done)
+	var last time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-l.wake:
+		}
+		if wait := l.opt.Interval - time.Since(last); wait > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+		}
+		l.mu.Lock()
+		text := l.text
+		l.mu.Unlock()
+		if text == l.sent {
+			continue
+		}
+		err := l.update(ctx, text)
+		last = time.Now()
+		if d, ok := retryAfter(err); ok {
+			// Try again with the latest text once the limit is reset.
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(d):
+			}
+			select {
+			case l.wake <- struct{}{}:
+			default:
+			}
+			continue
+		}
+		if err != nil {
+			l.logf("messaging: updating post %d: %v", l.postID, err)
+		}
+	}
+}
+
+func (l *LiveMessage) update(ctx context.Context, text string) error {
+	if _, _, err := l.client.Messages.UpdateMessage(ctx, l.topicID, l.postID, text); err != nil {
+		return err
+	}
+	l.sent = text
+	return nil
+}
+
+// Succeed finishes the message with a success summary.
+func (l *LiveMessage) Succeed(ctx context.Context, summary string) error {
+	return l.finish(ctx, l.opt.SuccessPrefix+summary)
+}
+
+// Fail finishes the message with a failure summary.
+func (l *LiveMessage) Fail(ctx context.Context, summary string) error {
+	return l.finish(ctx, l.opt.FailurePrefix+summary)
+}
+
+func (l *LiveMessage) finish(ctx context.Context, summary string) error {
+	l.mu.Lock()
+	if l.finished {
+		l.mu.Unlock()
+		return errors.New("messaging: live message is already finished")
+	}
+	l.finished = true
+	l.mu.Unlock()
+	l.cancel()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-l.done:
+	}
+
+	for {
+		err := l.update(ctx, summary)
+		d, ok := retryAfter(err)
+		if !ok {
+			if err != nil {
+				return err
+			}
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(d):
+		}
+	}
+	if l.opt.Reply {
+		if _, _, err := l.client.Messages.PostMessage(ctx, l.topicID, summary, &v1.PostMessageOptions{ReplyTo: l.postID}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *LiveMessage) logf(format string, args ...interface{}) {
+	if l.opt.ErrorLog != nil {
+		l.opt.ErrorLog.Printf(format, args...)
+	} else {
+		log.Printf(format, args...)
+	}
+}
+
+// retryAfter reports whether err is a 429 Too Many Requests response and
+// how long to wait before the next request. The wait is read from the
+// Retry-After or X-RateLimit-Reset headers and defaults to ten seconds.
+func retryAfter(err error) (time.Duration, bool) {
+	e, ok := err.(*shared.ErrorResponse)
+	if !ok || e.Response == nil || e.Response.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+	h := e.Response.Header
+	if s, err := strconv.Atoi(h.Get("Retry-After")); err == nil {
+		return time.Duration(s) * time.Second, true
+	}
+	if reset, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		if d := time.Until(time.Unix(reset, 0)); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 10 * time.Second, true
+}
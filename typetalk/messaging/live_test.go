@@ -0,0 +1,159 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/nulab/go-typetalk/v3/typetalk/internal"
+)
+
+type liveServer struct {
+	mu      sync.Mutex
+	updates []string
+	replies []string
+	limited int
+}
+
+func (s *liveServer) handle(t *testing.T) {
+	mux.HandleFunc("/api/v1/topics/1", func(w http.ResponseWriter, r *http.Request) {
+		TestMethod(t, r, http.MethodPost)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if r.FormValue("replyTo") == "" {
+			fmt.Fprint(w, `{"post": {"id": 10}}`)
+			return
+		}
+		s.replies = append(s.replies, r.FormValue("replyTo")+":"+r.FormValue("message"))
+		fmt.Fprint(w, `{"post": {"id": 11}}`)
+	})
+	mux.HandleFunc("/api/v1/topics/1/posts/10", func(w http.ResponseWriter, r *http.Request) {
+		TestMethod(t, r, http.MethodPut)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.limited > 0 {
+			s.limited--
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		s.updates = append(s.updates, r.FormValue("message"))
+		fmt.Fprint(w, `{"post": {"id": 10}}`)
+	})
+}
+
+func (s *liveServer) snapshot() ([]string, []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.updates...), append([]string(nil), s.replies...)
+}
+
+func Test_LiveMessage_should_coalesce_updates(t *testing.T) {
+	setup()
+	defer teardown()
+	s := &liveServer{}
+	s.handle(t)
+	m := newTestMessenger(nil)
+
+	l, err := m.NewLiveMessage(context.Background(), 1, "deploying", &LiveOptions{Interval: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+	if l.PostID() != 10 {
+		t.Errorf("PostID: got %d, want 10", l.PostID())
+	}
+	for i := 1; i <= 20; i++ {
+		l.Update("deploying " + strconv.Itoa(i) + "/20")
+	}
+	time.Sleep(100 * time.Millisecond)
+	if err := l.Succeed(context.Background(), "deployed"); err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+
+	updates, replies := s.snapshot()
+	if len(updates) < 2 || len(updates) > 3 {
+		t.Errorf("Updates were not coalesced: %q", updates)
+	}
+	if last := updates[len(updates)-1]; last != "(OK) deployed" {
+		t.Errorf("Final update: got %q", last)
+	}
+	if updates[len(updates)-2] != "deploying 20/20" {
+		t.Errorf("Latest progress was not sent: %q", updates)
+	}
+	if len(replies) != 0 {
+		t.Errorf("Unexpected replies: %q", replies)
+	}
+
+	l.Update("ignored")
+	if err := l.Fail(context.Background(), "again"); err == nil {
+		t.Error("Expected error to be returned for a finished message")
+	}
+}
+
+func Test_LiveMessage_should_retry_when_rate_limited_and_reply_on_finish(t *testing.T) {
+	setup()
+	defer teardown()
+	s := &liveServer{limited: 2}
+	s.handle(t)
+	m := newTestMessenger(nil)
+
+	l, _ := m.NewLiveMessage(context.Background(), 1, "migrating", &LiveOptions{Interval: time.Millisecond, Reply: true, FailurePrefix: "FAILED: "})
+	l.Update("migrating 1/2")
+	time.Sleep(50 * time.Millisecond)
+	if err := l.Fail(context.Background(), "table users is locked"); err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+
+	updates, replies := s.snapshot()
+	if fmt.Sprint(updates) != "[migrating 1/2 FAILED: table users is locked]" {
+		t.Errorf("Updates: got %q", updates)
+	}
+	if fmt.Sprint(replies) != "[10:FAILED: table users is locked]" {
+		t.Errorf("Replies: got %q", replies)
+	}
+}
+
+func Test_LiveMessage_should_abort_update_in_flight_on_finish(t *testing.T) {
+	setup()
+	defer teardown()
+	release := make(chan struct{})
+	defer close(release)
+	var mu sync.Mutex
+	var updates []string
+	mux.HandleFunc("/api/v1/topics/1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"post": {"id": 10}}`)
+	})
+	mux.HandleFunc("/api/v1/topics/1/posts/10", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("message") == "stuck" {
+			select {
+			case <-r.Context().Done():
+			case <-release:
+			}
+			return
+		}
+		mu.Lock()
+		updates = append(updates, r.FormValue("message"))
+		mu.Unlock()
+		fmt.Fprint(w, `{"post": {"id": 10}}`)
+	})
+	m := newTestMessenger(nil)
+
+	l, _ := m.NewLiveMessage(context.Background(), 1, "deploying", &LiveOptions{Interval: time.Millisecond})
+	l.Update("stuck")
+	time.Sleep(50 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := l.Succeed(ctx, "deployed"); err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if fmt.Sprint(updates) != "[(OK) deployed]" {
+		t.Errorf("Updates: got %q", updates)
+	}
+}
@@ -0,0 +1,60 @@
+// Package messaging provides higher level helpers for posting messages on top
+// of the v1 MessagesService.
+package messaging
+
+import (
+	"sync"
+	"time"
+
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+)
+
+// Options configures a Messenger.
+type Options struct {
+	// Store remembers the posts made for idempotency keys. An in-memory
+	// store is used when it is nil.
+	Store Store
+	// KeyTTL is how long the store remembers a key. Older keys are pruned
+	// from the store by PostMessageIdempotent. It defaults to 30 days; a
+	// negative value keeps keys forever.
+	KeyTTL time.Duration
+	// Lookback is the number of recent topic messages compared with a
+	// message posted without a key. It defaults to 20; a negative value
+	// disables the check.
+	Lookback int
+	// Window limits the comparison to the messages posted within this
+	// duration. It defaults to one hour.
+	Window time.Duration
+}
+
+// Messenger posts messages through a v1 client.
+type Messenger struct {
+	client *v1.Client
+	opt    Options
+	now    func() time.Time
+	locks  keyLocks
+
+	mu sync.Mutex
+	me int // the account of the client, fetched on first use
+}
+
+// New returns a Messenger that posts through the given client.
+func New(client *v1.Client, opt *Options) *Messenger {
+	m := &Messenger{client: client, now: time.Now}
+	if opt != nil {
+		m.opt = *opt
+	}
+	if m.opt.Store == nil {
+		m.opt.Store = NewMemoryStore()
+	}
+	if m.opt.KeyTTL == 0 {
+		m.opt.KeyTTL = 30 * 24 * time.Hour
+	}
+	if m.opt.Lookback == 0 {
+		m.opt.Lookback = 20
+	}
+	if m.opt.Window == 0 {
+		m.opt.Window = time.Hour
+	}
+	return m
+}
@@ -0,0 +1,36 @@
+package messaging
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/nulab/go-typetalk/v3/typetalk/internal"
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+)
+
+var (
+	mux    *http.ServeMux
+	client *v1.Client
+	server *httptest.Server
+)
+
+var testNow = time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func setup() {
+	mux = http.NewServeMux()
+	server = httptest.NewServer(mux)
+
+	client = v1.NewClient(NewTestClient(server))
+	client.SetTypetalkToken("DUMMY_TOKEN")
+}
+
+func teardown() {
+	server.Close()
+}
+
+func newTestMessenger(opt *Options) *Messenger {
+	m := New(client, opt)
+	m.now = func() time.Time { return testNow }
+	return m
+}
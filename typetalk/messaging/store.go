@@ -0,0 +1,117 @@
+package messaging
+
+import (
+	"sync"
+	"time"
+
+	"github.com/nulab/go-typetalk/v3/typetalk/internal"
+)
+
+// Store remembers the IDs of the posts made for idempotency keys.
+type Store interface {
+	// Get returns the post ID of the key, or 0 if there is none.
+	Get(key string) (int, error)
+	Put(key string, postID int) error
+	Delete(key string) error
+	// Prune removes the keys put before the given time and returns how
+	// many were removed.
+	Prune(before time.Time) (int, error)
+}
+
+type storedPost struct {
+	PostID int       `json:"postId"`
+	PutAt  time.Time `json:"putAt"`
+}
+
+// MemoryStore is a Store that keeps keys in memory.
+type MemoryStore struct {
+	mu    sync.Mutex
+	posts map[string]*storedPost
+	now   func() time.Time
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{posts: map[string]*storedPost{}, now: time.Now}
+}
+
+// Get implements Store.
+func (s *MemoryStore) Get(key string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p, ok := s.posts[key]; ok {
+		return p.PostID, nil
+	}
+	return 0, nil
+}
+
+// Put implements Store.
+func (s *MemoryStore) Put(key string, postID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.posts[key] = &storedPost{PostID: postID, PutAt: s.now()}
+	return nil
+}
+
+// Delete implements Store.
+func (s *MemoryStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.posts, key)
+	return nil
+}
+
+// Prune implements Store.
+func (s *MemoryStore) Prune(before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for key, p := range s.posts {
+		if p.PutAt.Before(before) {
+			delete(s.posts, key)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// FileStore is a Store that persists keys to a JSON file.
+type FileStore struct {
+	MemoryStore
+	file internal.JSONFile
+}
+
+// OpenFileStore loads the keys saved at path. The file is created on the
+// first change if it doesn't exist.
+func OpenFileStore(path string) (*FileStore, error) {
+	s := &FileStore{MemoryStore: *NewMemoryStore(), file: internal.JSONFile{Path: path}}
+	if err := s.file.Load(&s.posts); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Put implements Store.
+func (s *FileStore) Put(key string, postID int) error {
+	s.MemoryStore.Put(key, postID)
+	return s.save()
+}
+
+// Delete implements Store.
+func (s *FileStore) Delete(key string) error {
+	s.MemoryStore.Delete(key)
+	return s.save()
+}
+
+// Prune implements Store.
+func (s *FileStore) Prune(before time.Time) (int, error) {
+	n, _ := s.MemoryStore.Prune(before)
+	if n == 0 {
+		return 0, nil
+	}
+	return n, s.save()
+}
+
+func (s *FileStore) save() error {
+	return s.file.Save(&s.mu, s.posts)
+}
@@ -0,0 +1,75 @@
+package messaging
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_FileStore_should_persist_keys(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "messaging")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "keys.json")
+
+	s, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+	s.Put("1/a", 10)
+	s.Put("1/b", 11)
+	s.Delete("1/a")
+
+	reopened, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+	if id, _ := reopened.Get("1/a"); id != 0 {
+		t.Errorf("Deleted key: got %d", id)
+	}
+	if id, _ := reopened.Get("1/b"); id != 11 {
+		t.Errorf("Get: got %d, want 11", id)
+	}
+
+	if n, err := reopened.Prune(time.Now().Add(time.Minute)); n != 1 || err != nil {
+		t.Errorf("Prune: got %d, %v", n, err)
+	}
+	if reopened, _ = OpenFileStore(path); reopened.posts["1/b"] != nil {
+		t.Errorf("Pruned key: got %+v", reopened.posts["1/b"])
+	}
+}
+
+func Test_FileStore_should_keep_every_key_of_concurrent_puts(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "messaging")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "keys.json")
+
+	s, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := s.Put(fmt.Sprintf("1/%d", i), 100+i); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	reopened, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+	for i := 0; i < 50; i++ {
+		if id, _ := reopened.Get(fmt.Sprintf("1/%d", i)); id != 100+i {
+			t.Errorf("key 1/%d: got %d, want %d", i, id, 100+i)
+		}
+	}
+}
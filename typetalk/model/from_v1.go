@@ -0,0 +1,533 @@
+// Code generated by modelgen from v1; DO NOT EDIT.
+
+package model
+
+import (
+	"time"
+
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+)
+
+// FromV1Account converts a v1.Account to the canonical Account.
+func FromV1Account(in *v1.Account) *Account {
+	if in == nil {
+		return nil
+	}
+	out := &Account{
+		ID:             in.ID,
+		Name:           in.Name,
+		FullName:       in.FullName,
+		Suggestion:     in.Suggestion,
+		ImageURL:       in.ImageURL,
+		IsBot:          in.IsBot,
+		Lang:           in.Lang,
+		TimezoneID:     in.TimezoneID,
+		CreatedAt:      in.CreatedAt,
+		UpdatedAt:      in.UpdatedAt,
+		ImageUpdatedAt: in.ImageUpdatedAt,
+	}
+	return out
+}
+
+// FromV1Accounts converts a slice of v1.Account to a slice of Account.
+func FromV1Accounts(in []*v1.Account) []*Account {
+	if in == nil {
+		return nil
+	}
+	out := make([]*Account, len(in))
+	for i, e := range in {
+		out[i] = FromV1Account(e)
+	}
+	return out
+}
+
+// FromV1AccountStatus converts a v1.AccountStatus to the canonical AccountStatus.
+func FromV1AccountStatus(in *v1.AccountStatus) *AccountStatus {
+	if in == nil {
+		return nil
+	}
+	out := &AccountStatus{
+		Account: FromV1Account(in.Account),
+		Status:  FromV1Status(in.Status),
+	}
+	return out
+}
+
+// FromV1AccountStatuses converts a slice of v1.AccountStatus to a slice of AccountStatus.
+func FromV1AccountStatuses(in []*v1.AccountStatus) []*AccountStatus {
+	if in == nil {
+		return nil
+	}
+	out := make([]*AccountStatus, len(in))
+	for i, e := range in {
+		out[i] = FromV1AccountStatus(e)
+	}
+	return out
+}
+
+// FromV1AttachmentFile converts a v1.AttachmentFile to the canonical AttachmentFile.
+func FromV1AttachmentFile(in *v1.AttachmentFile) *AttachmentFile {
+	if in == nil {
+		return nil
+	}
+	out := &AttachmentFile{
+		ContentType: in.ContentType,
+		FileKey:     in.FileKey,
+		FileName:    in.FileName,
+		FileSize:    in.FileSize,
+	}
+	return out
+}
+
+// FromV1AttachmentFiles converts a slice of v1.AttachmentFile to a slice of AttachmentFile.
+func FromV1AttachmentFiles(in []*v1.AttachmentFile) []*AttachmentFile {
+	if in == nil {
+		return nil
+	}
+	out := make([]*AttachmentFile, len(in))
+	for i, e := range in {
+		out[i] = FromV1AttachmentFile(e)
+	}
+	return out
+}
+
+// FromV1Bookmark converts a v1.Bookmark to the canonical Bookmark.
+func FromV1Bookmark(in *v1.Bookmark) *Bookmark {
+	if in == nil {
+		return nil
+	}
+	out := &Bookmark{
+		PostID: in.PostID,
+	}
+	out.UpdatedAt = new(time.Time)
+	*out.UpdatedAt = in.UpdatedAt
+	return out
+}
+
+// FromV1Bookmarks converts a slice of v1.Bookmark to a slice of Bookmark.
+func FromV1Bookmarks(in []*v1.Bookmark) []*Bookmark {
+	if in == nil {
+		return nil
+	}
+	out := make([]*Bookmark, len(in))
+	for i, e := range in {
+		out[i] = FromV1Bookmark(e)
+	}
+	return out
+}
+
+// FromV1DirectMessage converts a v1.DirectMessage to the canonical DirectMessage.
+func FromV1DirectMessage(in *v1.DirectMessage) *DirectMessage {
+	if in == nil {
+		return nil
+	}
+	out := &DirectMessage{
+		Account: FromV1Account(in.Account),
+		Status:  FromV1Status(in.Status),
+	}
+	return out
+}
+
+// FromV1DirectMessages converts a slice of v1.DirectMessage to a slice of DirectMessage.
+func FromV1DirectMessages(in []*v1.DirectMessage) []*DirectMessage {
+	if in == nil {
+		return nil
+	}
+	out := make([]*DirectMessage, len(in))
+	for i, e := range in {
+		out[i] = FromV1DirectMessage(e)
+	}
+	return out
+}
+
+// FromV1Like converts a v1.Like to the canonical Like.
+func FromV1Like(in *v1.Like) *Like {
+	if in == nil {
+		return nil
+	}
+	out := &Like{
+		ID:        in.ID,
+		PostID:    in.PostID,
+		TopicID:   in.TopicID,
+		Comment:   in.Comment,
+		Account:   FromV1Account(in.Account),
+		CreatedAt: in.CreatedAt,
+	}
+	return out
+}
+
+// FromV1Likes converts a slice of v1.Like to a slice of Like.
+func FromV1Likes(in []*v1.Like) []*Like {
+	if in == nil {
+		return nil
+	}
+	out := make([]*Like, len(in))
+	for i, e := range in {
+		out[i] = FromV1Like(e)
+	}
+	return out
+}
+
+// FromV1Link converts a v1.Link to the canonical Link.
+func FromV1Link(in *v1.Link) *Link {
+	if in == nil {
+		return nil
+	}
+	out := &Link{
+		ID:          in.ID,
+		URL:         in.URL,
+		ContentType: in.ContentType,
+		Title:       in.Title,
+		Description: in.Description,
+		ImageURL:    in.ImageURL,
+		Embed:       FromV1LinkEmbed(in.Embed),
+		CreatedAt:   in.CreatedAt,
+		UpdatedAt:   in.UpdatedAt,
+	}
+	return out
+}
+
+// FromV1Links converts a slice of v1.Link to a slice of Link.
+func FromV1Links(in []*v1.Link) []*Link {
+	if in == nil {
+		return nil
+	}
+	out := make([]*Link, len(in))
+	for i, e := range in {
+		out[i] = FromV1Link(e)
+	}
+	return out
+}
+
+// FromV1LinkEmbed converts a v1.LinkEmbed to the canonical LinkEmbed.
+func FromV1LinkEmbed(in *v1.LinkEmbed) *LinkEmbed {
+	if in == nil {
+		return nil
+	}
+	out := &LinkEmbed{
+		Type:         in.Type,
+		Version:      in.Version,
+		ProviderName: in.ProviderName,
+		ProviderURL:  in.ProviderURL,
+		Title:        in.Title,
+		AuthorName:   in.AuthorName,
+		AuthorURL:    in.AuthorURL,
+		HTML:         in.HTML,
+		URL:          in.URL,
+		ThumbnailURL: in.ThumbnailURL,
+		Width:        in.Width,
+		Height:       in.Height,
+	}
+	return out
+}
+
+// FromV1LinkEmbeds converts a slice of v1.LinkEmbed to a slice of LinkEmbed.
+func FromV1LinkEmbeds(in []*v1.LinkEmbed) []*LinkEmbed {
+	if in == nil {
+		return nil
+	}
+	out := make([]*LinkEmbed, len(in))
+	for i, e := range in {
+		out[i] = FromV1LinkEmbed(e)
+	}
+	return out
+}
+
+// FromV1Mention converts a v1.Mention to the canonical Mention.
+func FromV1Mention(in *v1.Mention) *Mention {
+	if in == nil {
+		return nil
+	}
+	out := &Mention{
+		ID:     in.ID,
+		ReadAt: in.ReadAt,
+		Post:   FromV1Post(in.Post),
+	}
+	return out
+}
+
+// FromV1Mentions converts a slice of v1.Mention to a slice of Mention.
+func FromV1Mentions(in []*v1.Mention) []*Mention {
+	if in == nil {
+		return nil
+	}
+	out := make([]*Mention, len(in))
+	for i, e := range in {
+		out[i] = FromV1Mention(e)
+	}
+	return out
+}
+
+// FromV1MyPlan converts a v1.MyPlan to the canonical MyPlan.
+func FromV1MyPlan(in *v1.MyPlan) *MyPlan {
+	if in == nil {
+		return nil
+	}
+	out := &MyPlan{
+		Plan:                FromV1Plan(in.Plan),
+		Enabled:             in.Enabled,
+		Trial:               FromV1Trial(in.Trial),
+		NumberOfUsers:       in.NumberOfUsers,
+		TotalAttachmentSize: in.TotalAttachmentSize,
+		CreatedAt:           in.CreatedAt,
+		UpdatedAt:           in.UpdatedAt,
+	}
+	return out
+}
+
+// FromV1MyPlans converts a slice of v1.MyPlan to a slice of MyPlan.
+func FromV1MyPlans(in []*v1.MyPlan) []*MyPlan {
+	if in == nil {
+		return nil
+	}
+	out := make([]*MyPlan, len(in))
+	for i, e := range in {
+		out[i] = FromV1MyPlan(e)
+	}
+	return out
+}
+
+// FromV1MySpace converts a v1.Organization to the canonical MySpace.
+func FromV1MySpace(in *v1.Organization) *MySpace {
+	if in == nil {
+		return nil
+	}
+	out := &MySpace{
+		Space:          FromV1Space(in.Space),
+		MyRole:         in.MyRole,
+		IsPaymentAdmin: in.IsPaymentAdmin,
+		MyPlan:         FromV1MyPlan(in.MyPlan),
+	}
+	return out
+}
+
+// FromV1MySpaces converts a slice of v1.Organization to a slice of MySpace.
+func FromV1MySpaces(in []*v1.Organization) []*MySpace {
+	if in == nil {
+		return nil
+	}
+	out := make([]*MySpace, len(in))
+	for i, e := range in {
+		out[i] = FromV1MySpace(e)
+	}
+	return out
+}
+
+// FromV1Plan converts a v1.Plan to the canonical Plan.
+func FromV1Plan(in *v1.Plan) *Plan {
+	if in == nil {
+		return nil
+	}
+	out := &Plan{
+		Key:                      in.Key,
+		Name:                     in.Name,
+		LimitNumberOfUsers:       in.LimitNumberOfUsers,
+		LimitTotalAttachmentSize: in.LimitTotalAttachmentSize,
+	}
+	return out
+}
+
+// FromV1Plans converts a slice of v1.Plan to a slice of Plan.
+func FromV1Plans(in []*v1.Plan) []*Plan {
+	if in == nil {
+		return nil
+	}
+	out := make([]*Plan, len(in))
+	for i, e := range in {
+		out[i] = FromV1Plan(e)
+	}
+	return out
+}
+
+// FromV1Post converts a v1.Post to the canonical Post.
+func FromV1Post(in *v1.Post) *Post {
+	if in == nil {
+		return nil
+	}
+	out := &Post{
+		ID:          in.ID,
+		TopicID:     in.TopicID,
+		ReplyTo:     in.ReplyTo,
+		Message:     in.Message,
+		Account:     FromV1Account(in.Account),
+		Mention:     FromV1Mention(in.Mention),
+		Attachments: FromV1AttachmentFiles(in.Attachments),
+		Likes:       FromV1Likes(in.Likes),
+		Talks:       FromV1Talks(in.Talks),
+		Links:       FromV1Links(in.Links),
+		CreatedAt:   in.CreatedAt,
+		UpdatedAt:   in.UpdatedAt,
+	}
+	return out
+}
+
+// FromV1Posts converts a slice of v1.Post to a slice of Post.
+func FromV1Posts(in []*v1.Post) []*Post {
+	if in == nil {
+		return nil
+	}
+	out := make([]*Post, len(in))
+	for i, e := range in {
+		out[i] = FromV1Post(e)
+	}
+	return out
+}
+
+// FromV1Space converts a v1.Space to the canonical Space.
+func FromV1Space(in *v1.Space) *Space {
+	if in == nil {
+		return nil
+	}
+	out := &Space{
+		Key:      in.Key,
+		Name:     in.Name,
+		Enabled:  in.Enabled,
+		ImageURL: in.ImageURL,
+	}
+	return out
+}
+
+// FromV1Spaces converts a slice of v1.Space to a slice of Space.
+func FromV1Spaces(in []*v1.Space) []*Space {
+	if in == nil {
+		return nil
+	}
+	out := make([]*Space, len(in))
+	for i, e := range in {
+		out[i] = FromV1Space(e)
+	}
+	return out
+}
+
+// FromV1Status converts a v1.Status to the canonical Status.
+func FromV1Status(in *v1.Status) *Status {
+	if in == nil {
+		return nil
+	}
+	out := &Status{
+		Presence: in.Presence,
+		Web:      in.Web,
+		Mobile:   in.Mobile,
+	}
+	return out
+}
+
+// FromV1Statuses converts a slice of v1.Status to a slice of Status.
+func FromV1Statuses(in []*v1.Status) []*Status {
+	if in == nil {
+		return nil
+	}
+	out := make([]*Status, len(in))
+	for i, e := range in {
+		out[i] = FromV1Status(e)
+	}
+	return out
+}
+
+// FromV1Talk converts a v1.Talk to the canonical Talk.
+func FromV1Talk(in *v1.Talk) *Talk {
+	if in == nil {
+		return nil
+	}
+	out := &Talk{
+		ID:         in.ID,
+		TopicID:    in.TopicID,
+		Name:       in.Name,
+		Suggestion: in.Suggestion,
+		CreatedAt:  in.CreatedAt,
+		UpdatedAt:  in.UpdatedAt,
+		Backlog:    in.Backlog,
+	}
+	return out
+}
+
+// FromV1Talks converts a slice of v1.Talk to a slice of Talk.
+func FromV1Talks(in []*v1.Talk) []*Talk {
+	if in == nil {
+		return nil
+	}
+	out := make([]*Talk, len(in))
+	for i, e := range in {
+		out[i] = FromV1Talk(e)
+	}
+	return out
+}
+
+// FromV1Topic converts a v1.Topic to the canonical Topic.
+func FromV1Topic(in *v1.Topic) *Topic {
+	if in == nil {
+		return nil
+	}
+	out := &Topic{
+		ID:              in.ID,
+		Name:            in.Name,
+		Description:     in.Description,
+		Suggestion:      in.Suggestion,
+		IsDirectMessage: in.IsDirectMessage,
+		LastPostedAt:    in.LastPostedAt,
+		CreatedAt:       in.CreatedAt,
+		UpdatedAt:       in.UpdatedAt,
+	}
+	return out
+}
+
+// FromV1Topics converts a slice of v1.Topic to a slice of Topic.
+func FromV1Topics(in []*v1.Topic) []*Topic {
+	if in == nil {
+		return nil
+	}
+	out := make([]*Topic, len(in))
+	for i, e := range in {
+		out[i] = FromV1Topic(e)
+	}
+	return out
+}
+
+// FromV1Trial converts a v1.Trial to the canonical Trial.
+func FromV1Trial(in *v1.Trial) *Trial {
+	if in == nil {
+		return nil
+	}
+	out := &Trial{
+		EndDate:  in.EndDate,
+		DaysLeft: in.DaysLeft,
+	}
+	return out
+}
+
+// FromV1Trials converts a slice of v1.Trial to a slice of Trial.
+func FromV1Trials(in []*v1.Trial) []*Trial {
+	if in == nil {
+		return nil
+	}
+	out := make([]*Trial, len(in))
+	for i, e := range in {
+		out[i] = FromV1Trial(e)
+	}
+	return out
+}
+
+// FromV1Unread converts a v1.Unread to the canonical Unread.
+func FromV1Unread(in *v1.Unread) *Unread {
+	if in == nil {
+		return nil
+	}
+	out := &Unread{
+		TopicID: in.TopicID,
+		PostID:  in.PostID,
+		Count:   in.Count,
+	}
+	return out
+}
+
+// FromV1Unreads converts a slice of v1.Unread to a slice of Unread.
+func FromV1Unreads(in []*v1.Unread) []*Unread {
+	if in == nil {
+		return nil
+	}
+	out := make([]*Unread, len(in))
+	for i, e := range in {
+		out[i] = FromV1Unread(e)
+	}
+	return out
+}
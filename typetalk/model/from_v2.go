@@ -0,0 +1,483 @@
+// Code generated by modelgen from v2; DO NOT EDIT.
+
+package model
+
+import (
+	"time"
+
+	v2 "github.com/nulab/go-typetalk/v3/typetalk/v2"
+)
+
+// FromV2Account converts a v2.Account to the canonical Account.
+func FromV2Account(in *v2.Account) *Account {
+	if in == nil {
+		return nil
+	}
+	out := &Account{
+		ID:         in.ID,
+		Name:       in.Name,
+		FullName:   in.FullName,
+		Suggestion: in.Suggestion,
+		ImageURL:   in.ImageURL,
+		IsBot:      in.IsBot,
+		CreatedAt:  in.CreatedAt,
+		UpdatedAt:  in.UpdatedAt,
+	}
+	return out
+}
+
+// FromV2Accounts converts a slice of v2.Account to a slice of Account.
+func FromV2Accounts(in []*v2.Account) []*Account {
+	if in == nil {
+		return nil
+	}
+	out := make([]*Account, len(in))
+	for i, e := range in {
+		out[i] = FromV2Account(e)
+	}
+	return out
+}
+
+// FromV2AttachmentFile converts a v2.AttachmentFile to the canonical AttachmentFile.
+func FromV2AttachmentFile(in *v2.AttachmentFile) *AttachmentFile {
+	if in == nil {
+		return nil
+	}
+	out := &AttachmentFile{
+		ContentType: in.ContentType,
+		FileKey:     in.FileKey,
+		FileName:    in.FileName,
+		FileSize:    in.FileSize,
+	}
+	return out
+}
+
+// FromV2AttachmentFiles converts a slice of v2.AttachmentFile to a slice of AttachmentFile.
+func FromV2AttachmentFiles(in []*v2.AttachmentFile) []*AttachmentFile {
+	if in == nil {
+		return nil
+	}
+	out := make([]*AttachmentFile, len(in))
+	for i, e := range in {
+		out[i] = FromV2AttachmentFile(e)
+	}
+	return out
+}
+
+// FromV2Bookmark converts a v2.Bookmark to the canonical Bookmark.
+func FromV2Bookmark(in *v2.Bookmark) *Bookmark {
+	if in == nil {
+		return nil
+	}
+	out := &Bookmark{
+		PostID: in.PostID,
+	}
+	out.UpdatedAt = new(time.Time)
+	*out.UpdatedAt = in.UpdatedAt
+	return out
+}
+
+// FromV2Bookmarks converts a slice of v2.Bookmark to a slice of Bookmark.
+func FromV2Bookmarks(in []*v2.Bookmark) []*Bookmark {
+	if in == nil {
+		return nil
+	}
+	out := make([]*Bookmark, len(in))
+	for i, e := range in {
+		out[i] = FromV2Bookmark(e)
+	}
+	return out
+}
+
+// FromV2DirectMessage converts a v2.DirectMessage to the canonical DirectMessage.
+func FromV2DirectMessage(in *v2.DirectMessage) *DirectMessage {
+	if in == nil {
+		return nil
+	}
+	out := &DirectMessage{
+		Account: FromV2Account(in.Account),
+		Status:  FromV2Status(in.Status),
+	}
+	return out
+}
+
+// FromV2DirectMessages converts a slice of v2.DirectMessage to a slice of DirectMessage.
+func FromV2DirectMessages(in []*v2.DirectMessage) []*DirectMessage {
+	if in == nil {
+		return nil
+	}
+	out := make([]*DirectMessage, len(in))
+	for i, e := range in {
+		out[i] = FromV2DirectMessage(e)
+	}
+	return out
+}
+
+// FromV2Like converts a v2.Like to the canonical Like.
+func FromV2Like(in *v2.Like) *Like {
+	if in == nil {
+		return nil
+	}
+	out := &Like{
+		ID:        in.ID,
+		PostID:    in.PostID,
+		TopicID:   in.TopicID,
+		Comment:   in.Comment,
+		Account:   FromV2Account(in.Account),
+		CreatedAt: in.CreatedAt,
+	}
+	return out
+}
+
+// FromV2Likes converts a slice of v2.Like to a slice of Like.
+func FromV2Likes(in []*v2.Like) []*Like {
+	if in == nil {
+		return nil
+	}
+	out := make([]*Like, len(in))
+	for i, e := range in {
+		out[i] = FromV2Like(e)
+	}
+	return out
+}
+
+// FromV2Link converts a v2.Link to the canonical Link.
+func FromV2Link(in *v2.Link) *Link {
+	if in == nil {
+		return nil
+	}
+	out := &Link{
+		ID:          in.ID,
+		URL:         in.URL,
+		ContentType: in.ContentType,
+		Title:       in.Title,
+		Description: in.Description,
+		ImageURL:    in.ImageURL,
+		Embed:       FromV2LinkEmbed(in.Embed),
+		CreatedAt:   in.CreatedAt,
+		UpdatedAt:   in.UpdatedAt,
+	}
+	return out
+}
+
+// FromV2Links converts a slice of v2.Link to a slice of Link.
+func FromV2Links(in []*v2.Link) []*Link {
+	if in == nil {
+		return nil
+	}
+	out := make([]*Link, len(in))
+	for i, e := range in {
+		out[i] = FromV2Link(e)
+	}
+	return out
+}
+
+// FromV2LinkEmbed converts a v2.LinkEmbed to the canonical LinkEmbed.
+func FromV2LinkEmbed(in *v2.LinkEmbed) *LinkEmbed {
+	if in == nil {
+		return nil
+	}
+	out := &LinkEmbed{
+		Type:         in.Type,
+		Version:      in.Version,
+		ProviderName: in.ProviderName,
+		ProviderURL:  in.ProviderURL,
+		Title:        in.Title,
+		AuthorName:   in.AuthorName,
+		AuthorURL:    in.AuthorURL,
+		HTML:         in.HTML,
+		URL:          in.URL,
+		ThumbnailURL: in.ThumbnailURL,
+		Width:        in.Width,
+		Height:       in.Height,
+	}
+	return out
+}
+
+// FromV2LinkEmbeds converts a slice of v2.LinkEmbed to a slice of LinkEmbed.
+func FromV2LinkEmbeds(in []*v2.LinkEmbed) []*LinkEmbed {
+	if in == nil {
+		return nil
+	}
+	out := make([]*LinkEmbed, len(in))
+	for i, e := range in {
+		out[i] = FromV2LinkEmbed(e)
+	}
+	return out
+}
+
+// FromV2Mention converts a v2.Mention to the canonical Mention.
+func FromV2Mention(in *v2.Mention) *Mention {
+	if in == nil {
+		return nil
+	}
+	out := &Mention{
+		ID:     in.ID,
+		ReadAt: in.ReadAt,
+		Post:   FromV2Post(in.Post),
+	}
+	return out
+}
+
+// FromV2Mentions converts a slice of v2.Mention to a slice of Mention.
+func FromV2Mentions(in []*v2.Mention) []*Mention {
+	if in == nil {
+		return nil
+	}
+	out := make([]*Mention, len(in))
+	for i, e := range in {
+		out[i] = FromV2Mention(e)
+	}
+	return out
+}
+
+// FromV2MyPlan converts a v2.MyPlan to the canonical MyPlan.
+func FromV2MyPlan(in *v2.MyPlan) *MyPlan {
+	if in == nil {
+		return nil
+	}
+	out := &MyPlan{
+		Plan:                FromV2Plan(in.Plan),
+		Enabled:             in.Enabled,
+		Trial:               FromV2Trial(in.Trial),
+		NumberOfUsers:       in.NumberOfUsers,
+		TotalAttachmentSize: in.TotalAttachmentSize,
+		CreatedAt:           in.CreatedAt,
+		UpdatedAt:           in.UpdatedAt,
+	}
+	return out
+}
+
+// FromV2MyPlans converts a slice of v2.MyPlan to a slice of MyPlan.
+func FromV2MyPlans(in []*v2.MyPlan) []*MyPlan {
+	if in == nil {
+		return nil
+	}
+	out := make([]*MyPlan, len(in))
+	for i, e := range in {
+		out[i] = FromV2MyPlan(e)
+	}
+	return out
+}
+
+// FromV2MySpace converts a v2.MySpace to the canonical MySpace.
+func FromV2MySpace(in *v2.MySpace) *MySpace {
+	if in == nil {
+		return nil
+	}
+	out := &MySpace{
+		Space:          FromV2Space(in.Space),
+		MyRole:         in.MyRole,
+		IsPaymentAdmin: in.IsPaymentAdmin,
+		InvitableRoles: in.InvitableRoles,
+		MyPlan:         FromV2MyPlan(&in.MyPlan),
+	}
+	return out
+}
+
+// FromV2MySpaces converts a slice of v2.MySpace to a slice of MySpace.
+func FromV2MySpaces(in []*v2.MySpace) []*MySpace {
+	if in == nil {
+		return nil
+	}
+	out := make([]*MySpace, len(in))
+	for i, e := range in {
+		out[i] = FromV2MySpace(e)
+	}
+	return out
+}
+
+// FromV2Plan converts a v2.Plan to the canonical Plan.
+func FromV2Plan(in *v2.Plan) *Plan {
+	if in == nil {
+		return nil
+	}
+	out := &Plan{
+		Key:                      in.Key,
+		Name:                     in.Name,
+		LimitNumberOfUsers:       in.LimitNumberOfUsers,
+		LimitTotalAttachmentSize: in.LimitTotalAttachmentSize,
+	}
+	return out
+}
+
+// FromV2Plans converts a slice of v2.Plan to a slice of Plan.
+func FromV2Plans(in []*v2.Plan) []*Plan {
+	if in == nil {
+		return nil
+	}
+	out := make([]*Plan, len(in))
+	for i, e := range in {
+		out[i] = FromV2Plan(e)
+	}
+	return out
+}
+
+// FromV2Post converts a v2.Post to the canonical Post.
+func FromV2Post(in *v2.Post) *Post {
+	if in == nil {
+		return nil
+	}
+	out := &Post{
+		ID:            in.ID,
+		TopicID:       in.TopicID,
+		Topic:         FromV2Topic(&in.Topic),
+		ReplyTo:       in.ReplyTo,
+		Message:       in.Message,
+		Account:       FromV2Account(&in.Account),
+		Attachments:   FromV2AttachmentFiles(in.Attachments),
+		Links:         FromV2Links(in.Links),
+		DirectMessage: FromV2DirectMessage(in.DirectMessage),
+	}
+	out.CreatedAt = new(time.Time)
+	*out.CreatedAt = in.CreatedAt
+	out.UpdatedAt = new(time.Time)
+	*out.UpdatedAt = in.UpdatedAt
+	return out
+}
+
+// FromV2Posts converts a slice of v2.Post to a slice of Post.
+func FromV2Posts(in []*v2.Post) []*Post {
+	if in == nil {
+		return nil
+	}
+	out := make([]*Post, len(in))
+	for i, e := range in {
+		out[i] = FromV2Post(e)
+	}
+	return out
+}
+
+// FromV2Space converts a v2.Space to the canonical Space.
+func FromV2Space(in *v2.Space) *Space {
+	if in == nil {
+		return nil
+	}
+	out := &Space{
+		Key:      in.Key,
+		Name:     in.Name,
+		Enabled:  in.Enabled,
+		ImageURL: in.ImageURL,
+	}
+	return out
+}
+
+// FromV2Spaces converts a slice of v2.Space to a slice of Space.
+func FromV2Spaces(in []*v2.Space) []*Space {
+	if in == nil {
+		return nil
+	}
+	out := make([]*Space, len(in))
+	for i, e := range in {
+		out[i] = FromV2Space(e)
+	}
+	return out
+}
+
+// FromV2Status converts a v2.Status to the canonical Status.
+func FromV2Status(in *v2.Status) *Status {
+	if in == nil {
+		return nil
+	}
+	out := &Status{
+		Presence: in.Presence,
+		Web:      in.Web,
+		Mobile:   in.Mobile,
+	}
+	return out
+}
+
+// FromV2Statuses converts a slice of v2.Status to a slice of Status.
+func FromV2Statuses(in []*v2.Status) []*Status {
+	if in == nil {
+		return nil
+	}
+	out := make([]*Status, len(in))
+	for i, e := range in {
+		out[i] = FromV2Status(e)
+	}
+	return out
+}
+
+// FromV2Topic converts a v2.Topic to the canonical Topic.
+func FromV2Topic(in *v2.Topic) *Topic {
+	if in == nil {
+		return nil
+	}
+	out := &Topic{
+		ID:              in.ID,
+		Name:            in.Name,
+		Description:     in.Description,
+		Suggestion:      in.Suggestion,
+		IsDirectMessage: in.IsDirectMessage,
+	}
+	out.LastPostedAt = new(time.Time)
+	*out.LastPostedAt = in.LastPostedAt
+	out.CreatedAt = new(time.Time)
+	*out.CreatedAt = in.CreatedAt
+	out.UpdatedAt = new(time.Time)
+	*out.UpdatedAt = in.UpdatedAt
+	return out
+}
+
+// FromV2Topics converts a slice of v2.Topic to a slice of Topic.
+func FromV2Topics(in []*v2.Topic) []*Topic {
+	if in == nil {
+		return nil
+	}
+	out := make([]*Topic, len(in))
+	for i, e := range in {
+		out[i] = FromV2Topic(e)
+	}
+	return out
+}
+
+// FromV2Trial converts a v2.Trial to the canonical Trial.
+func FromV2Trial(in *v2.Trial) *Trial {
+	if in == nil {
+		return nil
+	}
+	out := &Trial{
+		EndDate:  in.EndDate,
+		DaysLeft: in.DaysLeft,
+	}
+	return out
+}
+
+// FromV2Trials converts a slice of v2.Trial to a slice of Trial.
+func FromV2Trials(in []*v2.Trial) []*Trial {
+	if in == nil {
+		return nil
+	}
+	out := make([]*Trial, len(in))
+	for i, e := range in {
+		out[i] = FromV2Trial(e)
+	}
+	return out
+}
+
+// FromV2Unread converts a v2.Unread to the canonical Unread.
+func FromV2Unread(in *v2.Unread) *Unread {
+	if in == nil {
+		return nil
+	}
+	out := &Unread{
+		TopicID:          in.TopicID,
+		PostID:           in.PostID,
+		Count:            in.Count,
+		IsOverCountLimit: in.IsOverCountLimit,
+	}
+	return out
+}
+
+// FromV2Unreads converts a slice of v2.Unread to a slice of Unread.
+func FromV2Unreads(in []*v2.Unread) []*Unread {
+	if in == nil {
+		return nil
+	}
+	out := make([]*Unread, len(in))
+	for i, e := range in {
+		out[i] = FromV2Unread(e)
+	}
+	return out
+}
@@ -0,0 +1,63 @@
+// Code generated by modelgen from v3; DO NOT EDIT.
+
+package model
+
+import (
+	v3 "github.com/nulab/go-typetalk/v3/typetalk/v3"
+)
+
+// FromV3Account converts a v3.Account to the canonical Account.
+func FromV3Account(in *v3.Account) *Account {
+	if in == nil {
+		return nil
+	}
+	out := &Account{
+		ID:         in.ID,
+		Name:       in.Name,
+		FullName:   in.FullName,
+		Suggestion: in.Suggestion,
+		ImageURL:   in.ImageURL,
+		IsBot:      in.IsBot,
+		CreatedAt:  in.CreatedAt,
+		UpdatedAt:  in.UpdatedAt,
+	}
+	return out
+}
+
+// FromV3Accounts converts a slice of v3.Account to a slice of Account.
+func FromV3Accounts(in []*v3.Account) []*Account {
+	if in == nil {
+		return nil
+	}
+	out := make([]*Account, len(in))
+	for i, e := range in {
+		out[i] = FromV3Account(e)
+	}
+	return out
+}
+
+// FromV3Space converts a v3.Space to the canonical Space.
+func FromV3Space(in *v3.Space) *Space {
+	if in == nil {
+		return nil
+	}
+	out := &Space{
+		Key:      in.Key,
+		Name:     in.Name,
+		Enabled:  in.Enabled,
+		ImageURL: in.ImageURL,
+	}
+	return out
+}
+
+// FromV3Spaces converts a slice of v3.Space to a slice of Space.
+func FromV3Spaces(in []*v3.Space) []*Space {
+	if in == nil {
+		return nil
+	}
+	out := make([]*Space, len(in))
+	for i, e := range in {
+		out[i] = FromV3Space(e)
+	}
+	return out
+}
@@ -0,0 +1,86 @@
+// Code generated by modelgen from v4; DO NOT EDIT.
+
+package model
+
+import (
+	v4 "github.com/nulab/go-typetalk/v3/typetalk/v4"
+)
+
+// FromV4Account converts a v4.Account to the canonical Account.
+func FromV4Account(in *v4.Account) *Account {
+	if in == nil {
+		return nil
+	}
+	out := &Account{
+		ID:         in.ID,
+		Name:       in.Name,
+		FullName:   in.FullName,
+		Suggestion: in.Suggestion,
+		ImageURL:   in.ImageURL,
+		IsBot:      in.IsBot,
+		CreatedAt:  in.CreatedAt,
+		UpdatedAt:  in.UpdatedAt,
+	}
+	return out
+}
+
+// FromV4Accounts converts a slice of v4.Account to a slice of Account.
+func FromV4Accounts(in []*v4.Account) []*Account {
+	if in == nil {
+		return nil
+	}
+	out := make([]*Account, len(in))
+	for i, e := range in {
+		out[i] = FromV4Account(e)
+	}
+	return out
+}
+
+// FromV4AccountStatus converts a v4.AccountStatus to the canonical AccountStatus.
+func FromV4AccountStatus(in *v4.AccountStatus) *AccountStatus {
+	if in == nil {
+		return nil
+	}
+	out := &AccountStatus{
+		Account: FromV4Account(in.Account),
+		Status:  FromV4Status(in.Status),
+	}
+	return out
+}
+
+// FromV4AccountStatuses converts a slice of v4.AccountStatus to a slice of AccountStatus.
+func FromV4AccountStatuses(in []*v4.AccountStatus) []*AccountStatus {
+	if in == nil {
+		return nil
+	}
+	out := make([]*AccountStatus, len(in))
+	for i, e := range in {
+		out[i] = FromV4AccountStatus(e)
+	}
+	return out
+}
+
+// FromV4Status converts a v4.Status to the canonical Status.
+func FromV4Status(in *v4.Status) *Status {
+	if in == nil {
+		return nil
+	}
+	out := &Status{
+		Presence: in.Presence,
+		Web:      in.Web,
+		Mobile:   in.Mobile,
+	}
+	return out
+}
+
+// FromV4Statuses converts a slice of v4.Status to a slice of Status.
+func FromV4Statuses(in []*v4.Status) []*Status {
+	if in == nil {
+		return nil
+	}
+	out := make([]*Status, len(in))
+	for i, e := range in {
+		out[i] = FromV4Status(e)
+	}
+	return out
+}
@@ -0,0 +1,141 @@
+// Code generated by modelgen from v5; DO NOT EDIT.
+
+package model
+
+import (
+	v5 "github.com/nulab/go-typetalk/v3/typetalk/v5"
+)
+
+// FromV5MyPlan converts a v5.MyPlan to the canonical MyPlan.
+func FromV5MyPlan(in *v5.MyPlan) *MyPlan {
+	if in == nil {
+		return nil
+	}
+	out := &MyPlan{
+		Plan:                     FromV5Plan(in.Plan),
+		Enabled:                  in.Enabled,
+		Trial:                    FromV5Trial(in.Trial),
+		NumberOfUsers:            in.NumberOfUsers,
+		NumberOfAllowedAddresses: in.NumberOfAllowedAddresses,
+		TotalAttachmentSize:      in.TotalAttachmentSize,
+		CreatedAt:                in.CreatedAt,
+		UpdatedAt:                in.UpdatedAt,
+	}
+	return out
+}
+
+// FromV5MyPlans converts a slice of v5.MyPlan to a slice of MyPlan.
+func FromV5MyPlans(in []*v5.MyPlan) []*MyPlan {
+	if in == nil {
+		return nil
+	}
+	out := make([]*MyPlan, len(in))
+	for i, e := range in {
+		out[i] = FromV5MyPlan(e)
+	}
+	return out
+}
+
+// FromV5MySpace converts a v5.MySpace to the canonical MySpace.
+func FromV5MySpace(in *v5.MySpace) *MySpace {
+	if in == nil {
+		return nil
+	}
+	out := &MySpace{
+		Space:          FromV5Space(in.Space),
+		MyRole:         in.MyRole,
+		IsPaymentAdmin: in.IsPaymentAdmin,
+		InvitableRoles: in.InvitableRoles,
+		MyPlan:         FromV5MyPlan(&in.MyPlan),
+	}
+	return out
+}
+
+// FromV5MySpaces converts a slice of v5.MySpace to a slice of MySpace.
+func FromV5MySpaces(in []*v5.MySpace) []*MySpace {
+	if in == nil {
+		return nil
+	}
+	out := make([]*MySpace, len(in))
+	for i, e := range in {
+		out[i] = FromV5MySpace(e)
+	}
+	return out
+}
+
+// FromV5Plan converts a v5.Plan to the canonical Plan.
+func FromV5Plan(in *v5.Plan) *Plan {
+	if in == nil {
+		return nil
+	}
+	out := &Plan{
+		Key:                           in.Key,
+		Name:                          in.Name,
+		LimitNumberOfUsers:            in.LimitNumberOfUsers,
+		LimitNumberOfAllowedAddresses: in.LimitNumberOfAllowedAddresses,
+		LimitTotalAttachmentSize:      in.LimitTotalAttachmentSize,
+	}
+	return out
+}
+
+// FromV5Plans converts a slice of v5.Plan to a slice of Plan.
+func FromV5Plans(in []*v5.Plan) []*Plan {
+	if in == nil {
+		return nil
+	}
+	out := make([]*Plan, len(in))
+	for i, e := range in {
+		out[i] = FromV5Plan(e)
+	}
+	return out
+}
+
+// FromV5Space converts a v5.Space to the canonical Space.
+func FromV5Space(in *v5.Space) *Space {
+	if in == nil {
+		return nil
+	}
+	out := &Space{
+		Key:      in.Key,
+		Name:     in.Name,
+		Enabled:  in.Enabled,
+		ImageURL: in.ImageURL,
+	}
+	return out
+}
+
+// FromV5Spaces converts a slice of v5.Space to a slice of Space.
+func FromV5Spaces(in []*v5.Space) []*Space {
+	if in == nil {
+		return nil
+	}
+	out := make([]*Space, len(in))
+	for i, e := range in {
+		out[i] = FromV5Space(e)
+	}
+	return out
+}
+
+// FromV5Trial converts a v5.Trial to the canonical Trial.
+func FromV5Trial(in *v5.Trial) *Trial {
+	if in == nil {
+		return nil
+	}
+	out := &Trial{
+		EndDate:  in.EndDate,
+		DaysLeft: in.DaysLeft,
+	}
+	return out
+}
+
+// FromV5Trials converts a slice of v5.Trial to a slice of Trial.
+func FromV5Trials(in []*v5.Trial) []*Trial {
+	if in == nil {
+		return nil
+	}
+	out := make([]*Trial, len(in))
+	for i, e := range in {
+		out[i] = FromV5Trial(e)
+	}
+	return out
+}
@@ -0,0 +1,213 @@
+// Package model provides canonical types of the Typetalk API, which merge
+// the slightly different types that the v1 to v5 packages declare, so that
+// code mixing API versions can work on a single set of types.
+//
+// The converters from the versioned types are generated:
+//
+//	posts := model.FromV1Posts(messages.Posts)
+//	posts = append(posts, model.FromV2Posts(result.Posts)...)
+//
+// A converter returns nil for nil, and leaves the fields that its version
+// does not have to their zero values.
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+//go:generate go run ../internal/modelgen -o from_v1.go -rename Organization=MySpace v1
+//go:generate go run ../internal/modelgen -o from_v2.go v2
+//go:generate go run ../internal/modelgen -o from_v3.go v3
+//go:generate go run ../internal/modelgen -o from_v4.go v4
+//go:generate go run ../internal/modelgen -o from_v5.go v5
+
+// Account represents a Typetalk account.
+type Account struct {
+	ID             int        `json:"id"`
+	Name           string     `json:"name"`
+	FullName       string     `json:"fullName"`
+	Suggestion     string     `json:"suggestion"`
+	ImageURL       string     `json:"imageUrl"`
+	IsBot          bool       `json:"isBot"`
+	Lang           string     `json:"lang"`
+	TimezoneID     string     `json:"timezoneId"`
+	CreatedAt      *time.Time `json:"createdAt"`
+	UpdatedAt      *time.Time `json:"updatedAt"`
+	ImageUpdatedAt *time.Time `json:"imageUpdatedAt"`
+}
+
+// Status represents the online status of an account. Web and Mobile are
+// untyped, as no captured response shows their shape.
+type Status struct {
+	Presence *string     `json:"presence"`
+	Web      interface{} `json:"web"`
+	Mobile   interface{} `json:"mobile"`
+}
+
+// AccountStatus contains an account and its status.
+type AccountStatus struct {
+	Account *Account `json:"account"`
+	Status  *Status  `json:"status"`
+}
+
+// Space represents a space, called an organization in v1.
+type Space struct {
+	Key      string `json:"key"`
+	Name     string `json:"name"`
+	Enabled  bool   `json:"enabled"`
+	ImageURL string `json:"imageUrl"`
+}
+
+// MySpace represents a space of the user with its role and plan.
+type MySpace struct {
+	Space          *Space   `json:"space"`
+	MyRole         string   `json:"myRole"`
+	IsPaymentAdmin bool     `json:"isPaymentAdmin"`
+	InvitableRoles []string `json:"invitableRoles"`
+	MyPlan         *MyPlan  `json:"myPlan"`
+}
+
+// MyPlan represents the plan of a space and its usage.
+type MyPlan struct {
+	Plan                     *Plan      `json:"plan"`
+	Enabled                  bool       `json:"enabled"`
+	Trial                    *Trial     `json:"trial"`
+	NumberOfUsers            int        `json:"numberOfUsers"`
+	NumberOfAllowedAddresses int        `json:"numberOfAllowedAddresses"`
+	TotalAttachmentSize      int        `json:"totalAttachmentSize"`
+	CreatedAt                *time.Time `json:"createdAt"`
+	UpdatedAt                *time.Time `json:"updatedAt"`
+}
+
+// Plan represents a plan and its limits.
+type Plan struct {
+	Key                           string `json:"key"`
+	Name                          string `json:"name"`
+	LimitNumberOfUsers            int    `json:"limitNumberOfUsers"`
+	LimitNumberOfAllowedAddresses int    `json:"limitNumberOfAllowedAddresses"`
+	LimitTotalAttachmentSize      int    `json:"limitTotalAttachmentSize"`
+}
+
+// Trial represents the trial of a plan.
+type Trial struct {
+	EndDate  string `json:"endDate"`
+	DaysLeft int    `json:"daysLeft"`
+}
+
+// Topic represents a topic.
+type Topic struct {
+	ID              int        `json:"id"`
+	Name            string     `json:"name"`
+	Description     string     `json:"description"`
+	Suggestion      string     `json:"suggestion"`
+	IsDirectMessage bool       `json:"isDirectMessage"`
+	LastPostedAt    *time.Time `json:"lastPostedAt"`
+	CreatedAt       *time.Time `json:"createdAt"`
+	UpdatedAt       *time.Time `json:"updatedAt"`
+}
+
+// Bookmark represents the last read post of a topic.
+type Bookmark struct {
+	PostID    int        `json:"postId"`
+	UpdatedAt *time.Time `json:"updatedAt"`
+}
+
+// Unread represents the unread posts of a topic.
+type Unread struct {
+	TopicID          int  `json:"topicId"`
+	PostID           int  `json:"postId"`
+	Count            int  `json:"count"`
+	IsOverCountLimit bool `json:"isOverCountLimit"`
+}
+
+// Post represents a message posted to a topic. Topic is only set by v2,
+// and Mention, Likes and Talks only by v1.
+type Post struct {
+	ID            int               `json:"id"`
+	TopicID       int               `json:"topicId"`
+	Topic         *Topic            `json:"topic"`
+	ReplyTo       int               `json:"replyTo"`
+	Message       string            `json:"message"`
+	Account       *Account          `json:"account"`
+	Mention       *Mention          `json:"mention"`
+	Attachments   []*AttachmentFile `json:"attachments"`
+	Likes         []*Like           `json:"likes"`
+	Talks         []*Talk           `json:"talks"`
+	Links         []*Link           `json:"links"`
+	DirectMessage *DirectMessage    `json:"directMessage"`
+	CreatedAt     *time.Time        `json:"createdAt"`
+	UpdatedAt     *time.Time        `json:"updatedAt"`
+}
+
+// DirectMessage represents the partner of a direct message.
+type DirectMessage struct {
+	Account *Account `json:"account"`
+	Status  *Status  `json:"status"`
+}
+
+// Mention represents a mention of the user in a post.
+type Mention struct {
+	ID     int        `json:"id"`
+	ReadAt *time.Time `json:"readAt"`
+	Post   *Post      `json:"post"`
+}
+
+// Like represents a like of a post.
+type Like struct {
+	ID        int        `json:"id"`
+	PostID    int        `json:"postId"`
+	TopicID   int        `json:"topicId"`
+	Comment   string     `json:"comment"`
+	Account   *Account   `json:"account"`
+	CreatedAt *time.Time `json:"createdAt"`
+}
+
+// AttachmentFile represents a file attached to a post.
+type AttachmentFile struct {
+	ContentType string `json:"contentType"`
+	FileKey     string `json:"fileKey"`
+	FileName    string `json:"fileName"`
+	FileSize    int    `json:"fileSize"`
+}
+
+// Link represents a link of a post.
+type Link struct {
+	ID          int        `json:"id"`
+	URL         string     `json:"url"`
+	ContentType string     `json:"contentType"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	ImageURL    string     `json:"imageUrl"`
+	Embed       *LinkEmbed `json:"embed"`
+	CreatedAt   *time.Time `json:"createdAt"`
+	UpdatedAt   *time.Time `json:"updatedAt"`
+}
+
+// LinkEmbed represents the oEmbed information of a link.
+type LinkEmbed struct {
+	Type         string      `json:"type"`
+	Version      json.Number `json:"version"`
+	ProviderName string      `json:"provider_name"`
+	ProviderURL  string      `json:"provider_url"`
+	Title        string      `json:"title"`
+	AuthorName   string      `json:"author_name"`
+	AuthorURL    string      `json:"author_url"`
+	HTML         string      `json:"html"`
+	URL          string      `json:"url"`
+	ThumbnailURL string      `json:"thumbnail_url"`
+	Width        int         `json:"width"`
+	Height       int         `json:"height"`
+}
+
+// Talk represents a talk, a named group of posts of a topic. Backlog is
+// untyped, as no captured response shows the issue of a talk.
+type Talk struct {
+	ID         int         `json:"id"`
+	TopicID    int         `json:"topicId"`
+	Name       string      `json:"name"`
+	Suggestion string      `json:"suggestion"`
+	CreatedAt  *time.Time  `json:"createdAt"`
+	UpdatedAt  *time.Time  `json:"updatedAt"`
+	Backlog    interface{} `json:"backlog"`
+}
@@ -0,0 +1,150 @@
+package model
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"reflect"
+	"testing"
+	"time"
+
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+	v2 "github.com/nulab/go-typetalk/v3/typetalk/v2"
+	v5 "github.com/nulab/go-typetalk/v3/typetalk/v5"
+)
+
+const (
+	fixturesPath = "../../testdata/"
+)
+
+func decode(t *testing.T, fixture string, v interface{}) {
+	b, err := ioutil.ReadFile(fixturesPath + fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type spaceCount struct {
+	MySpace *MySpace `json:"mySpace"`
+}
+
+type spaceCounts struct {
+	Statuses []*spaceCount `json:"statuses"`
+}
+
+// authors is code that works on the posts of any version.
+func authors(posts []*Post) map[string]int {
+	m := map[string]int{}
+	for _, p := range posts {
+		m[p.Account.Name]++
+	}
+	return m
+}
+
+func Test_FromV1Posts_and_FromV2Posts_should_feed_the_same_code(t *testing.T) {
+	messages := &v1.TopicMessages{}
+	decode(t, "v1/get-topic-messages.json", messages)
+	result := &v2.SearchMessagesResult{}
+	decode(t, "v2/search-messages.json", result)
+
+	posts := FromV1Posts(messages.Posts)
+	posts = append(posts, FromV2Posts(result.Posts)...)
+	if len(posts) != len(messages.Posts)+len(result.Posts) {
+		t.Fatalf("got %d posts", len(posts))
+	}
+	counts := authors(posts)
+	want := map[string]int{}
+	for _, p := range messages.Posts {
+		want[p.Account.Name]++
+	}
+	for _, p := range result.Posts {
+		want[p.Account.Name]++
+	}
+	if !reflect.DeepEqual(counts, want) {
+		t.Errorf("authors: got %v, want %v", counts, want)
+	}
+
+	last := posts[len(posts)-1]
+	if last.Topic == nil || last.Topic.ID != result.Posts[0].Topic.ID {
+		t.Errorf("the v2 post has the topic %+v", last.Topic)
+	}
+	if last.CreatedAt == nil || !last.CreatedAt.Equal(result.Posts[0].CreatedAt) {
+		t.Errorf("the v2 post was created at %v", last.CreatedAt)
+	}
+}
+
+func Test_converters_should_match_the_canonical_JSON(t *testing.T) {
+	cases := []struct {
+		fixture   string
+		versioned interface{}
+		convert   func(interface{}) interface{}
+		canonical interface{}
+	}{
+		{
+			"v1/get-topic-messages.json", &v1.TopicMessages{},
+			func(v interface{}) interface{} {
+				m := v.(*v1.TopicMessages)
+				return &struct {
+					MySpace  *MySpace  `json:"mySpace"`
+					Topic    *Topic    `json:"topic"`
+					Bookmark *Bookmark `json:"bookmark"`
+					Posts    []*Post   `json:"posts"`
+				}{FromV1MySpace(m.MySpace), FromV1Topic(m.Topic), FromV1Bookmark(m.Bookmark), FromV1Posts(m.Posts)}
+			},
+			&struct {
+				MySpace  *MySpace  `json:"mySpace"`
+				Topic    *Topic    `json:"topic"`
+				Bookmark *Bookmark `json:"bookmark"`
+				Posts    []*Post   `json:"posts"`
+			}{},
+		},
+		{
+			"v2/search-messages.json", &v2.SearchMessagesResult{},
+			func(v interface{}) interface{} {
+				return &struct {
+					Posts []*Post `json:"posts"`
+				}{FromV2Posts(v.(*v2.SearchMessagesResult).Posts)}
+			},
+			&struct {
+				Posts []*Post `json:"posts"`
+			}{},
+		},
+		{
+			"v5/get-notification-count.json", &v5.NotificationCount{},
+			func(v interface{}) interface{} {
+				counts := &spaceCounts{}
+				for _, s := range v.(*v5.NotificationCount).Statuses {
+					counts.Statuses = append(counts.Statuses, &spaceCount{FromV5MySpace(s.MySpace)})
+				}
+				return counts
+			},
+			&spaceCounts{},
+		},
+	}
+	for _, c := range cases {
+		decode(t, c.fixture, c.versioned)
+		decode(t, c.fixture, c.canonical)
+		if got := c.convert(c.versioned); !reflect.DeepEqual(got, c.canonical) {
+			g, _ := json.Marshal(got)
+			w, _ := json.Marshal(c.canonical)
+			t.Errorf("%s:\n got  %s,\n want %s", c.fixture, g, w)
+		}
+	}
+}
+
+func Test_converters_should_copy_the_values(t *testing.T) {
+	if FromV1Post(nil) != nil || FromV2Posts(nil) != nil {
+		t.Error("want nil for nil")
+	}
+	bookmark := &v2.Bookmark{PostID: 1, UpdatedAt: time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)}
+	got := FromV2Bookmark(bookmark)
+	bookmark.UpdatedAt = time.Time{}
+	if got.PostID != 1 || got.UpdatedAt == nil || got.UpdatedAt.Year() != 2020 {
+		t.Errorf("FromV2Bookmark: got %+v", got)
+	}
+	if s := FromV1DirectMessage(&v1.DirectMessage{Account: &v1.Account{Name: "alice"}}); s.Account.Name != "alice" || s.Status != nil {
+		t.Errorf("FromV1DirectMessage: got %+v", s)
+	}
+}
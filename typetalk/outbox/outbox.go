@@ -0,0 +1,500 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nulab/go-typetalk/typetalk/shared"
+	"github.com/nulab/go-typetalk/v3/typetalk/internal"
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+)
+
+// Entry states.
+const (
+	StatePending = "pending"
+	StateSent    = "sent"
+	StateDead    = "dead"
+)
+
+// PostOptions configures a queued post. It mirrors v1.PostMessageOptions,
+// with Files naming local files that are uploaded when the post is sent.
+type PostOptions struct {
+	ReplyTo      int      `json:"replyTo,omitempty"`
+	ShowLinkMeta bool     `json:"showLinkMeta,omitempty"`
+	TalkIDs      []int    `json:"talkIds,omitempty"`
+	FileURLs     []string `json:"fileUrls,omitempty"`
+	FileNames    []string `json:"fileNames,omitempty"`
+	// Files are copied into the outbox when the post is enqueued, so they
+	// may be removed afterwards.
+	Files []string `json:"files,omitempty"`
+}
+
+// Entry is a post in the outbox.
+type Entry struct {
+	ID        string       `json:"id"`
+	TopicID   int          `json:"topicId"`
+	Message   string       `json:"message"`
+	Options   *PostOptions `json:"options,omitempty"`
+	State     string       `json:"state"`
+	CreatedAt time.Time    `json:"createdAt"`
+	// Attempts is the number of failed deliveries.
+	Attempts    int       `json:"attempts"`
+	LastError   string    `json:"lastError,omitempty"`
+	NextAttempt time.Time `json:"nextAttempt,omitempty"`
+	// PostID and SentAt are set once the post is sent.
+	PostID int       `json:"postId,omitempty"`
+	SentAt time.Time `json:"sentAt,omitempty"`
+}
+
+// Status counts the entries in each state.
+type Status struct {
+	Pending int `json:"pending"`
+	Sent    int `json:"sent"`
+	Dead    int `json:"dead"`
+}
+
+// Options configures an Outbox.
+type Options struct {
+	// MaxAttempts is the number of failed deliveries after which an entry
+	// is moved to the dead letters. It defaults to 10.
+	MaxAttempts int
+	// Backoff returns the delay before retrying an entry that failed the
+	// given number of times. DefaultBackoff is used when it is nil.
+	Backoff func(attempts int) time.Duration
+	// ErrorLog is used to log failed deliveries. The standard logger is
+	// used when it is nil.
+	ErrorLog *log.Logger
+}
+
+// DefaultBackoff doubles the delay from one second up to five minutes.
+func DefaultBackoff(attempts int) time.Duration {
+	d := time.Second
+	for i := 1; i < attempts && d < 5*time.Minute; i++ {
+		d *= 2
+	}
+	if d > 5*time.Minute {
+		d = 5 * time.Minute
+	}
+	return d
+}
+
+// Outbox is a durable queue of posts. Every entry is persisted as a JSON
+// record in a directory before it is sent, so pending posts survive
+// failures of Typetalk and restarts of the process.
+//
+// Posts to the same topic are delivered in the order they were enqueued: a
+// failed entry holds back the entries behind it until it is sent. A dead
+// letter keeps holding them back until it is retried or discarded. Client
+// errors other than 429 Too Many Requests are not retried.
+type Outbox struct {
+	dir     string
+	client  *v1.Client
+	opt     Options
+	now     func() time.Time
+	flushMu sync.Mutex
+	mu      sync.Mutex
+	entries map[string]*Entry
+	seq     int
+}
+
+// Open opens the outbox stored in dir, creating the directory if needed. A
+// directory must not be used by more than one Outbox at a time.
+func Open(dir string, client *v1.Client, opt *Options) (*Outbox, error) {
+	o := &Outbox{dir: dir, client: client, now: time.Now, entries: map[string]*Entry{}}
+	if opt != nil {
+		o.opt = *opt
+	}
+	if o.opt.MaxAttempts == 0 {
+		o.opt.MaxAttempts = 10
+	}
+	if o.opt.Backoff == nil {
+		o.opt.Backoff = DefaultBackoff
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "entries"), 0700); err != nil {
+		return nil, err
+	}
+	names, err := filepath.Glob(filepath.Join(dir, "entries", "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		e := &Entry{}
+		if err := readJSON(name, e); err != nil {
+			return nil, fmt.Errorf("outbox: reading %s: %v", name, err)
+		}
+		o.entries[e.ID] = e
+		if n, err := strconv.Atoi(e.ID); err == nil && n > o.seq {
+			o.seq = n
+		}
+	}
+	return o, nil
+}
+
+// Enqueue persists a post to be sent to the topic and returns its entry.
+func (o *Outbox) Enqueue(topicID int, message string, opt *PostOptions) (*Entry, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.seq++
+	e := &Entry{
+		ID:        fmt.Sprintf("%012d", o.seq),
+		TopicID:   topicID,
+		Message:   message,
+		State:     StatePending,
+		CreatedAt: o.now(),
+	}
+	if opt != nil {
+		copied := *opt
+		copied.Files = nil
+		for _, name := range opt.Files {
+			path, err := o.copyFile(e.ID, name)
+			if err != nil {
+				os.RemoveAll(o.filesDir(e.ID))
+				return nil, err
+			}
+			copied.Files = append(copied.Files, path)
+		}
+		e.Options = &copied
+	}
+	if err := o.save(e); err != nil {
+		os.RemoveAll(o.filesDir(e.ID))
+		return nil, err
+	}
+	o.entries[e.ID] = e
+	return copyEntry(e), nil
+}
+
+func (o *Outbox) filesDir(id string) string {
+	return filepath.Join(o.dir, "files", id)
+}
+
+func (o *Outbox) copyFile(id, name string) (string, error) {
+	src, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+	dir := o.filesDir(id)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, filepath.Base(name))
+	dst, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return "", err
+	}
+	return path, dst.Close()
+}
+
+// Get returns the entry with the given ID, or nil if there is none.
+func (o *Outbox) Get(id string) *Entry {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if e, ok := o.entries[id]; ok {
+		return copyEntry(e)
+	}
+	return nil
+}
+
+// Entries returns the entries in the given state in the order they were
+// enqueued. All entries are returned when state is empty.
+func (o *Outbox) Entries(state string) []*Entry {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var entries []*Entry
+	for _, e := range o.sorted() {
+		if state == "" || e.State == state {
+			entries = append(entries, copyEntry(e))
+		}
+	}
+	return entries
+}
+
+// DeadLetters returns the entries that were given up on.
+func (o *Outbox) DeadLetters() []*Entry {
+	return o.Entries(StateDead)
+}
+
+// Status counts the entries in each state.
+func (o *Outbox) Status() Status {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var s Status
+	for _, e := range o.entries {
+		switch e.State {
+		case StatePending:
+			s.Pending++
+		case StateSent:
+			s.Sent++
+		case StateDead:
+			s.Dead++
+		}
+	}
+	return s
+}
+
+// Retry moves a dead letter back to the pending entries. It keeps its place
+// in the order of its topic, so it is delivered before the entries enqueued
+// after it, which are held back while it is dead.
+func (o *Outbox) Retry(id string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	e, ok := o.entries[id]
+	if !ok {
+		return fmt.Errorf("outbox: no entry %s", id)
+	}
+	if e.State != StateDead {
+		return fmt.Errorf("outbox: entry %s is %s", id, e.State)
+	}
+	retried := *e
+	retried.State = StatePending
+	retried.Attempts = 0
+	retried.NextAttempt = time.Time{}
+	if err := o.save(&retried); err != nil {
+		return err
+	}
+	*e = retried
+	return nil
+}
+
+// Discard removes a dead letter, releasing the entries of its topic that
+// were held back behind it.
+func (o *Outbox) Discard(id string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	e, ok := o.entries[id]
+	if !ok {
+		return fmt.Errorf("outbox: no entry %s", id)
+	}
+	if e.State != StateDead {
+		return fmt.Errorf("outbox: entry %s is %s", id, e.State)
+	}
+	if err := os.Remove(o.entryPath(id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	os.RemoveAll(o.filesDir(id))
+	delete(o.entries, id)
+	return nil
+}
+
+// Prune removes the records of the entries sent before the given time and
+// returns how many were removed.
+func (o *Outbox) Prune(before time.Time) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	n := 0
+	for id, e := range o.entries {
+		if e.State != StateSent || !e.SentAt.Before(before) {
+			continue
+		}
+		if err := os.Remove(o.entryPath(id)); err != nil && !os.IsNotExist(err) {
+			return n, err
+		}
+		delete(o.entries, id)
+		n++
+	}
+	return n, nil
+}
+
+// Flush tries to deliver the pending entries that are due. It returns the
+// number of posts sent. An error is returned only when the outbox can't be
+// written; failed deliveries are recorded on their entries.
+//
+// Entries can be enqueued while a flush is in progress; they are delivered
+// by the next one.
+func (o *Outbox) Flush(ctx context.Context) (int, error) {
+	o.flushMu.Lock()
+	defer o.flushMu.Unlock()
+
+	blocked := map[int]bool{}
+	o.mu.Lock()
+	var pending []*Entry
+	for _, e := range o.sorted() {
+		switch {
+		case e.State == StateDead:
+			blocked[e.TopicID] = true
+		case e.State == StatePending && !blocked[e.TopicID]:
+			pending = append(pending, copyEntry(e))
+		}
+	}
+	o.mu.Unlock()
+
+	sent := 0
+	for _, e := range pending {
+		if blocked[e.TopicID] {
+			continue
+		}
+		if ctx.Err() != nil {
+			return sent, ctx.Err()
+		}
+		if o.now().Before(e.NextAttempt) {
+			blocked[e.TopicID] = true
+			continue
+		}
+		postID, err := o.deliver(ctx, e)
+		if err == nil {
+			e.State = StateSent
+			e.PostID = postID
+			e.SentAt = o.now()
+			e.LastError = ""
+			e.NextAttempt = time.Time{}
+		} else {
+			o.logf("outbox: sending %s to topic %d: %v", e.ID, e.TopicID, err)
+			e.Attempts++
+			e.LastError = err.Error()
+			if permanent(err) || e.Attempts >= o.opt.MaxAttempts {
+				e.State = StateDead
+			} else {
+				e.NextAttempt = o.now().Add(o.opt.Backoff(e.Attempts))
+			}
+			blocked[e.TopicID] = true
+		}
+		o.mu.Lock()
+		err = o.save(e)
+		if err == nil {
+			o.entries[e.ID] = e
+		}
+		o.mu.Unlock()
+		if err != nil {
+			return sent, err
+		}
+		if e.State == StateSent {
+			sent++
+			os.RemoveAll(o.filesDir(e.ID))
+		}
+	}
+	return sent, nil
+}
+
+// Run flushes the outbox at the given interval until ctx is done.
+func (o *Outbox) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := o.Flush(ctx); err != nil && ctx.Err() == nil {
+			o.logf("outbox: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (o *Outbox) deliver(ctx context.Context, e *Entry) (int, error) {
+	opt := &v1.PostMessageOptions{}
+	if e.Options != nil {
+		opt.ReplyTo = e.Options.ReplyTo
+		opt.ShowLinkMeta = e.Options.ShowLinkMeta
+		opt.TalkIds = e.Options.TalkIDs
+		opt.FileUrls = e.Options.FileURLs
+		opt.FileNames = e.Options.FileNames
+		for _, name := range e.Options.Files {
+			key, err := o.upload(ctx, e.TopicID, name)
+			if err != nil {
+				return 0, err
+			}
+			opt.FileKeys = append(opt.FileKeys, key)
+		}
+	}
+	result, _, err := o.client.Messages.PostMessage(ctx, e.TopicID, e.Message, opt)
+	if err != nil {
+		return 0, err
+	}
+	return result.Post.ID, nil
+}
+
+func (o *Outbox) upload(ctx context.Context, topicID int, name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	file, _, err := o.client.Files.UploadAttachmentFile(ctx, topicID, f)
+	if err != nil {
+		return "", err
+	}
+	return file.FileKey, nil
+}
+
+// permanent reports whether retrying a request that failed with err is
+// pointless.
+func permanent(err error) bool {
+	if os.IsNotExist(err) {
+		return true
+	}
+	e, ok := err.(*shared.ErrorResponse)
+	if !ok || e.Response == nil {
+		return false
+	}
+	code := e.Response.StatusCode
+	return code >= 400 && code < 500 && code != http.StatusTooManyRequests
+}
+
+func (o *Outbox) logf(format string, args ...interface{}) {
+	if o.opt.ErrorLog != nil {
+		o.opt.ErrorLog.Printf(format, args...)
+	} else {
+		log.Printf(format, args...)
+	}
+}
+
+func (o *Outbox) sorted() []*Entry {
+	entries := make([]*Entry, 0, len(o.entries))
+	for _, e := range o.entries {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+	return entries
+}
+
+func (o *Outbox) entryPath(id string) string {
+	return filepath.Join(o.dir, "entries", id+".json")
+}
+
+func (o *Outbox) save(e *Entry) error {
+	return writeJSON(o.entryPath(e.ID), e)
+}
+
+func copyEntry(e *Entry) *Entry {
+	c := *e
+	if e.Options != nil {
+		opt := *e.Options
+		c.Options = &opt
+	}
+	return &c
+}
+
+func readJSON(name string, v interface{}) error {
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// writeJSON replaces the file atomically, so a crash never leaves a
+// truncated record behind.
+func writeJSON(name string, v interface{}) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return internal.WriteFileAtomic(name, b)
+}
@@ -0,0 +1,313 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/nulab/go-typetalk/v3/typetalk/internal"
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+)
+
+var (
+	mux    *http.ServeMux
+	client *v1.Client
+	server *httptest.Server
+	dir    string
+)
+
+func setup() {
+	mux = http.NewServeMux()
+	server = httptest.NewServer(mux)
+
+	client = v1.NewClient(NewTestClient(server))
+	client.SetTypetalkToken("DUMMY_TOKEN")
+
+	dir, _ = ioutil.TempDir("", "outbox")
+}
+
+func teardown() {
+	server.Close()
+	os.RemoveAll(dir)
+}
+
+type clock struct {
+	t time.Time
+}
+
+func (c *clock) now() time.Time {
+	return c.t
+}
+
+func openTestOutbox(t *testing.T, opt *Options) (*Outbox, *clock) {
+	if opt == nil {
+		opt = &Options{}
+	}
+	opt.ErrorLog = log.New(ioutil.Discard, "", 0)
+	o, err := Open(dir, client, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &clock{t: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	o.now = c.now
+	return o, c
+}
+
+func Test_Outbox_should_deliver_entries_in_order_and_record_post_ids(t *testing.T) {
+	setup()
+	defer teardown()
+	var got []string
+	mux.HandleFunc("/api/v1/topics/1", func(w http.ResponseWriter, r *http.Request) {
+		TestMethod(t, r, http.MethodPost)
+		got = append(got, r.FormValue("message"))
+		fmt.Fprintf(w, `{"post": {"id": %d}}`, 100+len(got))
+	})
+	o, _ := openTestOutbox(t, nil)
+	for _, m := range []string{"first", "second", "third"} {
+		if _, err := o.Enqueue(1, m, nil); err != nil {
+			t.Fatalf("Returned error: %v", err)
+		}
+	}
+	if s := o.Status(); s != (Status{Pending: 3}) {
+		t.Errorf("Status: got %+v", s)
+	}
+
+	sent, err := o.Flush(context.Background())
+	if err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+	if sent != 3 || fmt.Sprint(got) != "[first second third]" {
+		t.Errorf("Sent %d messages: %v", sent, got)
+	}
+	for i, e := range o.Entries("") {
+		if e.State != StateSent || e.PostID != 101+i {
+			t.Errorf("Entry %s: state %s, post %d", e.ID, e.State, e.PostID)
+		}
+	}
+}
+
+func Test_Outbox_should_keep_pending_entries_across_restarts(t *testing.T) {
+	setup()
+	defer teardown()
+	o, _ := openTestOutbox(t, nil)
+	o.Enqueue(1, "first", &PostOptions{ReplyTo: 5, TalkIDs: []int{7}})
+	o.Enqueue(1, "second", nil)
+
+	reopened, _ := openTestOutbox(t, nil)
+	entries := reopened.Entries(StatePending)
+	if len(entries) != 2 || entries[0].Message != "first" || entries[0].Options.ReplyTo != 5 {
+		t.Fatalf("Entries: got %+v", entries)
+	}
+	e, _ := reopened.Enqueue(1, "third", nil)
+	if e.ID <= entries[1].ID {
+		t.Errorf("ID %s should sort after %s", e.ID, entries[1].ID)
+	}
+}
+
+func Test_Outbox_should_retry_with_backoff_and_hold_back_the_topic(t *testing.T) {
+	setup()
+	defer teardown()
+	fail := true
+	var topic1 []string
+	mux.HandleFunc("/api/v1/topics/1", func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		topic1 = append(topic1, r.FormValue("message"))
+		fmt.Fprint(w, `{"post": {"id": 1}}`)
+	})
+	mux.HandleFunc("/api/v1/topics/2", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"post": {"id": 2}}`)
+	})
+	o, c := openTestOutbox(t, nil)
+	o.Enqueue(1, "first", nil)
+	o.Enqueue(1, "second", nil)
+	o.Enqueue(2, "other", nil)
+
+	sent, _ := o.Flush(context.Background())
+	if sent != 1 {
+		t.Errorf("Sent: got %d, want 1", sent)
+	}
+	first := o.Entries(StatePending)[0]
+	if first.Attempts != 1 || first.LastError == "" || !first.NextAttempt.Equal(c.t.Add(time.Second)) {
+		t.Errorf("Failed entry: got %+v", first)
+	}
+
+	fail = false
+	if sent, _ := o.Flush(context.Background()); sent != 0 {
+		t.Errorf("Entries were sent before the backoff elapsed")
+	}
+	c.t = c.t.Add(time.Second)
+	o.Flush(context.Background())
+	if fmt.Sprint(topic1) != "[first second]" {
+		t.Errorf("Delivered: got %v", topic1)
+	}
+}
+
+func Test_Outbox_should_move_rejected_entries_to_dead_letters(t *testing.T) {
+	setup()
+	defer teardown()
+	mux.HandleFunc("/api/v1/topics/1", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("message") == "bad" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, `{"post": {"id": 1}}`)
+	})
+	mux.HandleFunc("/api/v1/topics/2", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	o, c := openTestOutbox(t, &Options{MaxAttempts: 2})
+	bad, _ := o.Enqueue(1, "bad", nil)
+	o.Enqueue(1, "good", nil)
+	down, _ := o.Enqueue(2, "down", nil)
+
+	o.Flush(context.Background())
+	c.t = c.t.Add(time.Hour)
+	o.Flush(context.Background())
+
+	if s := o.Status(); s != (Status{Pending: 1, Dead: 2}) {
+		t.Errorf("Status: got %+v", s)
+	}
+	dead := o.DeadLetters()
+	if len(dead) != 2 || dead[0].ID != bad.ID || dead[1].ID != down.ID || dead[1].Attempts != 2 {
+		t.Errorf("DeadLetters: got %+v", dead)
+	}
+
+	if err := o.Retry(bad.ID); err != nil {
+		t.Errorf("Returned error: %v", err)
+	}
+	if e := o.Get(bad.ID); e.State != StatePending || e.Attempts != 0 {
+		t.Errorf("Retried entry: got %+v", e)
+	}
+	if err := o.Retry(bad.ID); err == nil {
+		t.Error("Expected error to be returned for a pending entry")
+	}
+}
+
+func Test_Outbox_should_hold_back_the_topic_of_a_dead_letter(t *testing.T) {
+	setup()
+	defer teardown()
+	reject := true
+	var delivered []string
+	mux.HandleFunc("/api/v1/topics/1", func(w http.ResponseWriter, r *http.Request) {
+		if reject && r.FormValue("message") == "bad" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		delivered = append(delivered, r.FormValue("message"))
+		fmt.Fprint(w, `{"post": {"id": 1}}`)
+	})
+	o, _ := openTestOutbox(t, nil)
+	bad, _ := o.Enqueue(1, "bad", nil)
+	o.Enqueue(1, "first", nil)
+
+	o.Flush(context.Background())
+	if sent, _ := o.Flush(context.Background()); sent != 0 || len(delivered) != 0 {
+		t.Errorf("Sent %d behind a dead letter: %v", sent, delivered)
+	}
+
+	reject = false
+	o.Retry(bad.ID)
+	if sent, _ := o.Flush(context.Background()); sent != 2 {
+		t.Errorf("Sent: got %d, want 2", sent)
+	}
+	if fmt.Sprint(delivered) != "[bad first]" {
+		t.Errorf("Delivered: got %v", delivered)
+	}
+
+	reject = true
+	bad, _ = o.Enqueue(1, "bad", nil)
+	o.Enqueue(1, "second", nil)
+	o.Flush(context.Background())
+	if err := o.Discard(bad.ID); err != nil {
+		t.Errorf("Returned error: %v", err)
+	}
+	if sent, _ := o.Flush(context.Background()); sent != 1 || o.Get(bad.ID) != nil {
+		t.Errorf("Sent: got %d after discarding, entry %+v", sent, o.Get(bad.ID))
+	}
+	if fmt.Sprint(delivered) != "[bad first second]" {
+		t.Errorf("Delivered: got %v", delivered)
+	}
+	if err := o.Discard(bad.ID); err == nil {
+		t.Error("Expected error to be returned for a discarded entry")
+	}
+}
+
+func Test_Outbox_should_upload_copied_files(t *testing.T) {
+	setup()
+	defer teardown()
+	mux.HandleFunc("/api/v1/topics/1/attachments", func(w http.ResponseWriter, r *http.Request) {
+		f, h, err := r.FormFile("file")
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := ioutil.ReadAll(f)
+		if h.Filename != "report.txt" || string(b) != "report" {
+			t.Errorf("Uploaded %s: %q", h.Filename, b)
+		}
+		fmt.Fprint(w, `{"fileKey": "KEY", "fileName": "report.txt"}`)
+	})
+	mux.HandleFunc("/api/v1/topics/1", func(w http.ResponseWriter, r *http.Request) {
+		TestFormValues(t, r, Values{"message": "see attached", "fileKeys[0]": "KEY"})
+		fmt.Fprint(w, `{"post": {"id": 1}}`)
+	})
+	src, _ := ioutil.TempDir("", "outbox-src")
+	defer os.RemoveAll(src)
+	name := filepath.Join(src, "report.txt")
+	ioutil.WriteFile(name, []byte("report"), 0600)
+
+	o, _ := openTestOutbox(t, nil)
+	e, err := o.Enqueue(1, "see attached", &PostOptions{Files: []string{name}})
+	if err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+	os.Remove(name)
+
+	if sent, _ := o.Flush(context.Background()); sent != 1 {
+		t.Fatalf("Sent: got %d, want 1", sent)
+	}
+	if _, err := os.Stat(e.Options.Files[0]); !os.IsNotExist(err) {
+		t.Errorf("Copied file should be removed once sent")
+	}
+}
+
+func Test_Outbox_Prune(t *testing.T) {
+	setup()
+	defer teardown()
+	mux.HandleFunc("/api/v1/topics/1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"post": {"id": 1}}`)
+	})
+	o, c := openTestOutbox(t, nil)
+	o.Enqueue(1, "old", nil)
+	o.Flush(context.Background())
+	c.t = c.t.Add(time.Hour)
+	o.Enqueue(1, "new", nil)
+	o.Flush(context.Background())
+
+	n, err := o.Prune(c.t)
+	if err != nil || n != 1 {
+		t.Errorf("Prune: got %d, %v", n, err)
+	}
+	reopened, _ := openTestOutbox(t, nil)
+	if entries := reopened.Entries(""); len(entries) != 1 || entries[0].Message != "new" {
+		t.Errorf("Entries: got %+v", entries)
+	}
+}
+
+func Test_DefaultBackoff(t *testing.T) {
+	tests := map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 4: 8 * time.Second, 20: 5 * time.Minute}
+	for attempts, want := range tests {
+		if got := DefaultBackoff(attempts); got != want {
+			t.Errorf("DefaultBackoff(%d): got %v, want %v", attempts, got, want)
+		}
+	}
+}
@@ -0,0 +1,305 @@
+// Package provision creates topics from a template, so that every new
+// project starts with the same topics, members and talks.
+package provision
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/nulab/go-typetalk/v3/typetalk/membership"
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+	v2 "github.com/nulab/go-typetalk/v3/typetalk/v2"
+	"gopkg.in/yaml.v2"
+)
+
+// TopicSpec is a topic of a template.
+type TopicSpec struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	// Accounts are the names of the member accounts.
+	Accounts []string `yaml:"accounts"`
+	// Groups are the keys of the member groups.
+	Groups []string `yaml:"groups"`
+	// Talks are the names of the standard talks.
+	Talks []string `yaml:"talks"`
+	// Welcome is posted when the topic is created and kept in the pinned
+	// talk.
+	Welcome string `yaml:"welcome"`
+}
+
+// Spec is a template of topics.
+type Spec struct {
+	// Space is the key of the space the topics are created in.
+	Space  string       `yaml:"space"`
+	Topics []*TopicSpec `yaml:"topics"`
+}
+
+// LoadSpec reads a Spec from a YAML file. ${name} in names, descriptions and
+// welcome messages is replaced with vars["name"]. Other uses of $, such as
+// $name or ${name} of a name that is not in vars, are kept as is:
+//
+//	space: abcdefghij
+//	topics:
+//	  - name: ${project}-dev
+//	    description: Development of ${project}
+//	    accounts: [alice]
+//	    groups: [developers]
+//	    talks: [Decisions, Releases]
+//	    welcome: Welcome to ${project}!
+func LoadSpec(name string, vars map[string]string) (*Spec, error) {
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	spec := &Spec{}
+	if err := yaml.UnmarshalStrict(b, spec); err != nil {
+		return nil, fmt.Errorf("provision: parsing %s: %v", name, err)
+	}
+	oldnew := make([]string, 0, 2*len(vars))
+	for k, v := range vars {
+		oldnew = append(oldnew, "${"+k+"}", v)
+	}
+	expand := strings.NewReplacer(oldnew...).Replace
+	for _, t := range spec.Topics {
+		t.Name = expand(t.Name)
+		t.Description = expand(t.Description)
+		t.Welcome = expand(t.Welcome)
+		if t.Name == "" {
+			return nil, fmt.Errorf("provision: %s: a topic has no name", name)
+		}
+		if len(t.Talks) > 0 && t.Welcome == "" {
+			return nil, fmt.Errorf("provision: %s: topic %s has talks but no welcome message", name, t.Name)
+		}
+	}
+	return spec, nil
+}
+
+// Options configures a Provisioner.
+type Options struct {
+	// PinnedTalk is the name of the talk holding the welcome message, as
+	// Typetalk has no pinned messages. It defaults to "Pinned".
+	PinnedTalk string
+	// CloneMessage is posted to cloned topics to seed their talks, as a talk
+	// can't be created without a post. ${topic} is replaced with the name of
+	// the source topic; the message is not a format string, so a % in it is
+	// posted as is. It defaults to "This topic was cloned from ${topic}.".
+	CloneMessage string
+}
+
+// Provisioner creates and updates topics.
+type Provisioner struct {
+	client *v1.Client
+	v2     *v2.Client
+	opt    Options
+}
+
+// New returns a Provisioner that lists the topics of a space through the v2
+// client and makes every other request through the v1 client.
+func New(clientV1 *v1.Client, clientV2 *v2.Client, opt *Options) *Provisioner {
+	p := &Provisioner{client: clientV1, v2: clientV2}
+	if opt != nil {
+		p.opt = *opt
+	}
+	if p.opt.PinnedTalk == "" {
+		p.opt.PinnedTalk = "Pinned"
+	}
+	if p.opt.CloneMessage == "" {
+		p.opt.CloneMessage = "This topic was cloned from ${topic}."
+	}
+	return p
+}
+
+// Result describes what Provision did to a topic.
+type Result struct {
+	TopicID int    `json:"topicId"`
+	Name    string `json:"name"`
+	Created bool   `json:"created"`
+	// Updated is set when the description or the members of an existing
+	// topic changed.
+	Updated bool `json:"updated"`
+	// Talks are the talks created.
+	Talks []string `json:"talks"`
+}
+
+// Provision creates the topics of the spec that don't exist, and brings the
+// existing ones up to date. Topics are matched by name among the topics of
+// the user in the space. Members are only added and talks only created, so
+// members and talks added by hand are kept.
+//
+// Talks can't be empty, so the welcome message is posted first and added
+// to every standard talk as well as to the pinned talk. The welcome
+// message is posted to an existing topic only when some of its talks are
+// missing.
+func (p *Provisioner) Provision(ctx context.Context, spec *Spec) ([]*Result, error) {
+	mine, _, err := p.v2.Topics.GetMyTopics(ctx, spec.Space)
+	if err != nil {
+		return nil, fmt.Errorf("provision: getting topics: %v", err)
+	}
+	existing := map[string]*v1.Topic{}
+	for _, t := range mine {
+		existing[t.Topic.Name] = &v1.Topic{ID: t.Topic.ID, Name: t.Topic.Name, Description: t.Topic.Description}
+	}
+
+	var results []*Result
+	for _, t := range spec.Topics {
+		r, err := p.provision(ctx, spec.Space, t, existing[t.Name])
+		if r != nil {
+			results = append(results, r)
+		}
+		if err != nil {
+			return results, fmt.Errorf("provision: topic %s: %v", t.Name, err)
+		}
+	}
+	return results, nil
+}
+
+func (p *Provisioner) provision(ctx context.Context, spaceKey string, t *TopicSpec, topic *v1.Topic) (*Result, error) {
+	r := &Result{Name: t.Name}
+	if topic == nil {
+		details, _, err := p.client.Topics.CreateTopic(ctx, &v1.CreateTopicOptions{Name: t.Name, SpaceKey: spaceKey})
+		if err != nil {
+			return nil, err
+		}
+		topic = details.Topic
+		r.Created = true
+	}
+	r.TopicID = topic.ID
+	if t.Description != "" && t.Description != topic.Description {
+		if _, _, err := p.client.Topics.UpdateTopic(ctx, topic.ID, &v1.UpdateTopicOptions{Name: t.Name, Description: t.Description}); err != nil {
+			return r, err
+		}
+		r.Updated = !r.Created
+	}
+
+	changed, err := p.addMembers(ctx, spaceKey, topic.ID, t.Accounts, t.Groups)
+	if err != nil {
+		return r, err
+	}
+	r.Updated = r.Updated || changed && !r.Created
+
+	talks := t.Talks
+	if t.Welcome != "" {
+		talks = append([]string{p.opt.PinnedTalk}, talks...)
+	}
+	r.Talks, err = p.createTalks(ctx, topic.ID, talks, func() (int, error) {
+		if t.Welcome == "" {
+			return 0, fmt.Errorf("talks need a welcome message to be created")
+		}
+		posted, _, err := p.client.Messages.PostMessage(ctx, topic.ID, t.Welcome, nil)
+		if err != nil {
+			return 0, err
+		}
+		return posted.Post.ID, nil
+	})
+	return r, err
+}
+
+// addMembers adds the accounts and groups missing from a topic. It reports
+// whether any were added.
+func (p *Provisioner) addMembers(ctx context.Context, spaceKey string, topicID int, accounts, groups []string) (bool, error) {
+	if len(accounts) == 0 && len(groups) == 0 {
+		return false, nil
+	}
+	config := &membership.Config{
+		Space:  spaceKey,
+		Topics: []*membership.Topic{{ID: topicID, Accounts: accounts, Groups: groups, Additive: true}},
+	}
+	s := membership.NewSyncer(p.client, config)
+	plan, err := s.Plan(ctx)
+	if err != nil {
+		return false, err
+	}
+	if plan.HasErrors() {
+		return false, fmt.Errorf("%s", strings.Join(plan.Topics[0].Errors, ", "))
+	}
+	return plan.HasChanges(), s.Apply(ctx, plan)
+}
+
+// createTalks creates the talks missing from a topic. The post they start
+// with is made by seed, only if a talk has to be created.
+func (p *Provisioner) createTalks(ctx context.Context, topicID int, names []string, seed func() (int, error)) ([]string, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	existing, _, err := p.client.Talks.GetTalkList(ctx, topicID)
+	if err != nil {
+		return nil, err
+	}
+	have := map[string]bool{}
+	for _, talk := range existing {
+		have[talk.Name] = true
+	}
+	var created []string
+	postID := 0
+	for _, name := range names {
+		if have[name] {
+			continue
+		}
+		if postID == 0 {
+			if postID, err = seed(); err != nil {
+				return created, err
+			}
+		}
+		if _, _, err := p.client.Talks.CreateTalk(ctx, topicID, name, postID); err != nil {
+			return created, err
+		}
+		have[name] = true
+		created = append(created, name)
+	}
+	return created, nil
+}
+
+// CloneTopic creates a topic named newName with the description, members
+// and talk names of the source topic. The talks of the new topic hold a
+// single post naming the source, not the posts of the source talks.
+func (p *Provisioner) CloneTopic(ctx context.Context, sourceID int, newName string) (*Result, error) {
+	source, _, err := p.client.Topics.GetTopicDetails(ctx, sourceID)
+	if err != nil {
+		return nil, fmt.Errorf("provision: getting topic %d: %v", sourceID, err)
+	}
+	if source.MySpace == nil || source.MySpace.Space == nil || source.Topic == nil {
+		return nil, fmt.Errorf("provision: topic %d has no space", sourceID)
+	}
+	talks, _, err := p.client.Talks.GetTalkList(ctx, sourceID)
+	if err != nil {
+		return nil, fmt.Errorf("provision: getting talks of topic %d: %v", sourceID, err)
+	}
+
+	opt := &v1.CreateTopicOptions{Name: newName, SpaceKey: source.MySpace.Space.Key}
+	for _, a := range source.Accounts {
+		opt.AddAccountIds = append(opt.AddAccountIds, a.ID)
+	}
+	for _, g := range source.Groups {
+		if g.Group != nil {
+			opt.AddGroupIds = append(opt.AddGroupIds, g.Group.ID)
+		}
+	}
+	details, _, err := p.client.Topics.CreateTopic(ctx, opt)
+	if err != nil {
+		return nil, fmt.Errorf("provision: creating topic %s: %v", newName, err)
+	}
+	r := &Result{TopicID: details.Topic.ID, Name: newName, Created: true}
+	if source.Topic.Description != "" {
+		if _, _, err := p.client.Topics.UpdateTopic(ctx, r.TopicID, &v1.UpdateTopicOptions{Name: newName, Description: source.Topic.Description}); err != nil {
+			return r, fmt.Errorf("provision: updating topic %s: %v", newName, err)
+		}
+	}
+
+	var names []string
+	for _, talk := range talks {
+		names = append(names, talk.Name)
+	}
+	r.Talks, err = p.createTalks(ctx, r.TopicID, names, func() (int, error) {
+		posted, _, err := p.client.Messages.PostMessage(ctx, r.TopicID, strings.Replace(p.opt.CloneMessage, "${topic}", source.Topic.Name, -1), nil)
+		if err != nil {
+			return 0, err
+		}
+		return posted.Post.ID, nil
+	})
+	if err != nil {
+		return r, fmt.Errorf("provision: creating talks of topic %s: %v", newName, err)
+	}
+	return r, nil
+}
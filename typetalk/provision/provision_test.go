@@ -0,0 +1,326 @@
+package provision
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	. "github.com/nulab/go-typetalk/v3/typetalk/internal"
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+	v2 "github.com/nulab/go-typetalk/v3/typetalk/v2"
+)
+
+const fixturesPath = "../../testdata/provision/"
+
+var (
+	mux      *http.ServeMux
+	clientV1 *v1.Client
+	clientV2 *v2.Client
+	server   *httptest.Server
+)
+
+func setup() {
+	mux = http.NewServeMux()
+	server = httptest.NewServer(mux)
+
+	clientV1 = v1.NewClient(NewTestClient(server)).SetTypetalkToken("DUMMY_TOKEN")
+	clientV2 = v2.NewClient(NewTestClient(server)).SetTypetalkToken("DUMMY_TOKEN")
+}
+
+func teardown() {
+	server.Close()
+}
+
+type fakeTopic struct {
+	ID          int      `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	accounts    []int    // member account IDs
+	groups      []int    // member group IDs
+	talks       []string // talk names
+	posts       []string
+	talkPosts   map[string][]int
+}
+
+// fakeSpace serves the endpoints used by a Provisioner from memory.
+type fakeSpace struct {
+	mu     sync.Mutex
+	topics map[int]*fakeTopic
+	nextID int
+}
+
+func newFakeSpace(t *testing.T, topics ...*fakeTopic) *fakeSpace {
+	f := &fakeSpace{topics: map[int]*fakeTopic{}, nextID: 100}
+	for _, topic := range topics {
+		topic.talkPosts = map[string][]int{}
+		f.topics[topic.ID] = topic
+	}
+	write := func(w http.ResponseWriter, v interface{}) {
+		b, _ := json.Marshal(v)
+		w.Write(b)
+	}
+	mux.HandleFunc("/api/v1/spaces/space/members", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"accounts": [{"id": 10, "name": "alice"}], "groups": [{"group": {"id": 20, "key": "developers"}}]}`)
+	})
+	mux.HandleFunc("/api/v2/topics", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("spaceKey") != "space" {
+			t.Errorf("spaceKey: got %q", r.URL.Query().Get("spaceKey"))
+		}
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		var list []interface{}
+		for _, topic := range f.topics {
+			list = append(list, map[string]interface{}{"topic": topic})
+		}
+		write(w, map[string]interface{}{"topics": list})
+	})
+	mux.HandleFunc("/api/v1/topics", func(w http.ResponseWriter, r *http.Request) {
+		TestMethod(t, r, "POST")
+		r.ParseForm()
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		topic := &fakeTopic{ID: f.nextID, Name: r.PostForm.Get("name"), talkPosts: map[string][]int{}}
+		f.nextID++
+		for i := 0; r.PostForm.Get(fmt.Sprintf("addAccountIds[%d]", i)) != ""; i++ {
+			id, _ := strconv.Atoi(r.PostForm.Get(fmt.Sprintf("addAccountIds[%d]", i)))
+			topic.accounts = append(topic.accounts, id)
+		}
+		for i := 0; r.PostForm.Get(fmt.Sprintf("addGroupIds[%d]", i)) != ""; i++ {
+			id, _ := strconv.Atoi(r.PostForm.Get(fmt.Sprintf("addGroupIds[%d]", i)))
+			topic.groups = append(topic.groups, id)
+		}
+		f.topics[topic.ID] = topic
+		write(w, map[string]interface{}{"topic": topic})
+	})
+	mux.HandleFunc("/api/v1/topics/", func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v1/topics/"), "/")
+		id, _ := strconv.Atoi(parts[0])
+		r.ParseForm()
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		topic, ok := f.topics[id]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		switch path := strings.Join(parts[1:], "/"); {
+		case path == "" && r.Method == "GET":
+			details := map[string]interface{}{"topic": topic, "mySpace": map[string]interface{}{"space": map[string]string{"key": "space"}}}
+			var accounts, groups []interface{}
+			for _, a := range topic.accounts {
+				accounts = append(accounts, map[string]interface{}{"id": a})
+			}
+			for _, g := range topic.groups {
+				groups = append(groups, map[string]interface{}{"group": map[string]interface{}{"id": g}})
+			}
+			details["accounts"] = accounts
+			details["groups"] = groups
+			write(w, details)
+		case path == "" && r.Method == "PUT":
+			topic.Name = r.PostForm.Get("name")
+			topic.Description = r.PostForm.Get("description")
+			write(w, map[string]interface{}{"topic": topic})
+		case path == "" && r.Method == "POST":
+			topic.posts = append(topic.posts, r.PostForm.Get("message"))
+			write(w, map[string]interface{}{"post": map[string]int{"id": id*1000 + len(topic.posts)}})
+		case path == "members/update":
+			for i := 0; r.PostForm.Get(fmt.Sprintf("addAccountIds[%d]", i)) != ""; i++ {
+				a, _ := strconv.Atoi(r.PostForm.Get(fmt.Sprintf("addAccountIds[%d]", i)))
+				topic.accounts = append(topic.accounts, a)
+			}
+			for i := 0; r.PostForm.Get(fmt.Sprintf("addGroupIds[%d]", i)) != ""; i++ {
+				g, _ := strconv.Atoi(r.PostForm.Get(fmt.Sprintf("addGroupIds[%d]", i)))
+				topic.groups = append(topic.groups, g)
+			}
+			write(w, map[string]interface{}{"topic": topic})
+		case path == "talks" && r.Method == "GET":
+			var talks []interface{}
+			for i, name := range topic.talks {
+				talks = append(talks, map[string]interface{}{"id": i + 1, "name": name})
+			}
+			write(w, map[string]interface{}{"talks": talks})
+		case path == "talks" && r.Method == "POST":
+			name := r.PostForm.Get("talkName")
+			post, _ := strconv.Atoi(r.PostForm.Get("postIds[0]"))
+			if post == 0 {
+				http.Error(w, `{"errors": "postIds is required"}`, http.StatusBadRequest)
+				return
+			}
+			topic.talks = append(topic.talks, name)
+			topic.talkPosts[name] = append(topic.talkPosts[name], post)
+			write(w, map[string]interface{}{"talk": map[string]interface{}{"id": len(topic.talks), "name": name}})
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	})
+	return f
+}
+
+func (f *fakeSpace) byName(name string) *fakeTopic {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, topic := range f.topics {
+		if topic.Name == name {
+			return topic
+		}
+	}
+	return nil
+}
+
+func Test_LoadSpec_should_expand_variables(t *testing.T) {
+	spec, err := LoadSpec(fixturesPath+"spec.yaml", map[string]string{"project": "apollo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &TopicSpec{
+		Name:        "apollo-dev",
+		Description: "Development of apollo",
+		Accounts:    []string{"alice"},
+		Groups:      []string{"developers"},
+		Talks:       []string{"Decisions", "Releases"},
+		Welcome:     "Welcome to apollo!",
+	}
+	if !reflect.DeepEqual(spec.Topics[0], want) {
+		t.Errorf("LoadSpec: got %+v, want %+v", spec.Topics[0], want)
+	}
+	if spec.Topics[1].Name != "apollo-random" {
+		t.Errorf("Name: got %q", spec.Topics[1].Name)
+	}
+}
+
+func Test_LoadSpec_should_keep_other_dollar_signs(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "provision")
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "spec.yaml")
+	ioutil.WriteFile(name, []byte("topics:\n  - name: ${project}-budget\n    description: Budget $100 for $project and ${other}\n"), 0600)
+
+	spec, err := LoadSpec(name, map[string]string{"project": "apollo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := spec.Topics[0].Description, "Budget $100 for $project and ${other}"; got != want {
+		t.Errorf("Description: got %q, want %q", got, want)
+	}
+	if got := spec.Topics[0].Name; got != "apollo-budget" {
+		t.Errorf("Name: got %q", got)
+	}
+}
+
+func Test_Provisioner_Provision_should_create_topics(t *testing.T) {
+	setup()
+	defer teardown()
+	f := newFakeSpace(t)
+	spec, err := LoadSpec(fixturesPath+"spec.yaml", map[string]string{"project": "apollo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := New(clientV1, clientV2, nil).Provision(context.Background(), spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || !results[0].Created || !results[1].Created {
+		t.Fatalf("Provision: got %+v", results)
+	}
+	dev := f.byName("apollo-dev")
+	if dev == nil || dev.Description != "Development of apollo" {
+		t.Fatalf("topic: got %+v", dev)
+	}
+	if !reflect.DeepEqual(dev.accounts, []int{10}) || !reflect.DeepEqual(dev.groups, []int{20}) {
+		t.Errorf("members: got %v %v", dev.accounts, dev.groups)
+	}
+	if want := []string{"Pinned", "Decisions", "Releases"}; !reflect.DeepEqual(dev.talks, want) {
+		t.Errorf("talks: got %v, want %v", dev.talks, want)
+	}
+	if !reflect.DeepEqual(dev.posts, []string{"Welcome to apollo!"}) {
+		t.Errorf("posts: got %v", dev.posts)
+	}
+	if f.byName("apollo-random").talks != nil {
+		t.Error("a topic without talks should not get talks")
+	}
+}
+
+func Test_Provisioner_Provision_should_update_existing_topics(t *testing.T) {
+	setup()
+	defer teardown()
+	f := newFakeSpace(t, &fakeTopic{ID: 1, Name: "apollo-dev", Description: "old", groups: []int{20}, talks: []string{"Pinned", "Decisions"}})
+	spec, err := LoadSpec(fixturesPath+"spec.yaml", map[string]string{"project": "apollo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec.Topics = spec.Topics[:1]
+
+	results, err := New(clientV1, clientV2, nil).Provision(context.Background(), spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*Result{{TopicID: 1, Name: "apollo-dev", Updated: true, Talks: []string{"Releases"}}}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("Provision: got %+v, want %+v", results[0], want[0])
+	}
+	dev := f.topics[1]
+	if dev.Description != "Development of apollo" || !reflect.DeepEqual(dev.accounts, []int{10}) {
+		t.Errorf("topic: got %+v", dev)
+	}
+
+	// A second run finds nothing to do.
+	results, err = New(clientV1, clientV2, nil).Provision(context.Background(), spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := results[0]; r.Created || r.Updated || len(r.Talks) > 0 {
+		t.Errorf("second Provision: got %+v", r)
+	}
+	if len(dev.posts) != 1 {
+		t.Errorf("posts: got %v", dev.posts)
+	}
+}
+
+func Test_Provisioner_CloneTopic(t *testing.T) {
+	setup()
+	defer teardown()
+	f := newFakeSpace(t, &fakeTopic{
+		ID:          1,
+		Name:        "apollo-dev",
+		Description: "Development of apollo",
+		accounts:    []int{10, 11},
+		groups:      []int{20},
+		talks:       []string{"Decisions", "Releases"},
+	})
+
+	r, err := New(clientV1, clientV2, nil).CloneTopic(context.Background(), 1, "gemini-dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	clone := f.topics[r.TopicID]
+	if clone == nil || clone.Name != "gemini-dev" || clone.Description != "Development of apollo" {
+		t.Fatalf("clone: got %+v", clone)
+	}
+	if !reflect.DeepEqual(clone.accounts, []int{10, 11}) || !reflect.DeepEqual(clone.groups, []int{20}) {
+		t.Errorf("members: got %v %v", clone.accounts, clone.groups)
+	}
+	if !reflect.DeepEqual(clone.talks, []string{"Decisions", "Releases"}) || !reflect.DeepEqual(r.Talks, clone.talks) {
+		t.Errorf("talks: got %v, result %v", clone.talks, r.Talks)
+	}
+	if !reflect.DeepEqual(clone.posts, []string{"This topic was cloned from apollo-dev."}) {
+		t.Errorf("posts: got %v", clone.posts)
+	}
+
+	r, err = New(clientV1, clientV2, &Options{CloneMessage: "100% of ${topic}"}).CloneTopic(context.Background(), 1, "gemini-ops")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if posts := f.topics[r.TopicID].posts; !reflect.DeepEqual(posts, []string{"100% of apollo-dev"}) {
+		t.Errorf("posts of a custom message: got %v", posts)
+	}
+}
@@ -0,0 +1,68 @@
+package retention
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/nulab/go-typetalk/v3/typetalk/internal"
+)
+
+// Progress keeps the posts selected for deletion that are not deleted yet.
+type Progress interface {
+	// Get returns the pending posts of the topic.
+	Get(topicID int) ([]int, error)
+	// Put replaces the pending posts of the topic.
+	Put(topicID int, postIDs []int) error
+}
+
+// MemoryProgress is a Progress kept in memory.
+type MemoryProgress struct {
+	mu      sync.Mutex
+	pending map[string][]int
+}
+
+// NewMemoryProgress returns an empty MemoryProgress.
+func NewMemoryProgress() *MemoryProgress {
+	return &MemoryProgress{pending: map[string][]int{}}
+}
+
+// Get implements Progress.
+func (p *MemoryProgress) Get(topicID int) ([]int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]int(nil), p.pending[strconv.Itoa(topicID)]...), nil
+}
+
+// Put implements Progress.
+func (p *MemoryProgress) Put(topicID int, postIDs []int) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(postIDs) == 0 {
+		delete(p.pending, strconv.Itoa(topicID))
+	} else {
+		p.pending[strconv.Itoa(topicID)] = append([]int(nil), postIDs...)
+	}
+	return nil
+}
+
+// FileProgress is a Progress persisted to a JSON file.
+type FileProgress struct {
+	MemoryProgress
+	file internal.JSONFile
+}
+
+// OpenFileProgress loads the progress saved at path. The file is created on
+// the first change if it doesn't exist.
+func OpenFileProgress(path string) (*FileProgress, error) {
+	p := &FileProgress{MemoryProgress: *NewMemoryProgress(), file: internal.JSONFile{Path: path}}
+	if err := p.file.Load(&p.pending); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Put implements Progress.
+func (p *FileProgress) Put(topicID int, postIDs []int) error {
+	p.MemoryProgress.Put(topicID, postIDs)
+	return p.file.Save(&p.mu, p.pending)
+}
@@ -0,0 +1,246 @@
+// Package retention deletes the messages of topics that are older than a
+// retention policy.
+package retention
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/nulab/go-typetalk/typetalk/shared"
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+)
+
+// Policy is the retention policy of a topic.
+type Policy struct {
+	TopicID int `json:"topicId"`
+	// MaxAgeDays is the number of days posts are kept.
+	MaxAgeDays int `json:"maxAgeDays"`
+	// KeepTalks keeps the posts that are part of a talk.
+	KeepTalks bool `json:"keepTalks"`
+	// KeepLiked keeps the posts that have likes.
+	KeepLiked bool `json:"keepLiked"`
+}
+
+func (p *Policy) keeps(post *v1.Post, cutoff time.Time) bool {
+	if post.CreatedAt == nil || !post.CreatedAt.Before(cutoff) {
+		return true
+	}
+	return p.KeepTalks && len(post.Talks) > 0 || p.KeepLiked && len(post.Likes) > 0
+}
+
+// AuditRecord is written to the audit log for every deleted post.
+type AuditRecord struct {
+	Time      time.Time  `json:"time"`
+	TopicID   int        `json:"topicId"`
+	PostID    int        `json:"postId"`
+	AccountID int        `json:"accountId,omitempty"`
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+}
+
+// TopicReport describes what was done to a topic.
+type TopicReport struct {
+	TopicID int `json:"topicId"`
+	// Cutoff is the time posts must be older than to be deleted.
+	Cutoff time.Time `json:"cutoff"`
+	// Examined is the number of posts scanned.
+	Examined int `json:"examined"`
+	// Selected are the posts the policy deletes. In a dry run nothing is
+	// deleted.
+	Selected []int `json:"selected"`
+	// Deleted are the posts actually deleted.
+	Deleted []int `json:"deleted"`
+	// Error is set when the topic could not be processed completely.
+	Error string `json:"error,omitempty"`
+}
+
+// Report describes an enforcement run.
+type Report struct {
+	DryRun bool           `json:"dryRun"`
+	Topics []*TopicReport `json:"topics"`
+}
+
+// Options configures an Enforcer.
+type Options struct {
+	Policies []*Policy
+	// DryRun only reports the posts that would be deleted.
+	DryRun bool
+	// PageSize is the number of posts fetched at a time. It defaults to
+	// 100.
+	PageSize int
+	// AuditLog receives an AuditRecord per deleted post as a line of JSON.
+	AuditLog io.Writer
+	// Progress keeps the posts left to delete, so an interrupted run is
+	// resumed by the next one. An in-memory progress is used when it is
+	// nil.
+	Progress Progress
+}
+
+// Enforcer applies retention policies.
+//
+// A topic is scanned from its oldest post until a post newer than the
+// cutoff is found, then the selected posts are deleted. The selected posts
+// are saved to the Progress before deletion starts, so a run that stops
+// midway deletes the remaining posts first when it is started again.
+type Enforcer struct {
+	client *v1.Client
+	opt    Options
+	now    func() time.Time
+	mu     sync.Mutex
+}
+
+// NewEnforcer returns an Enforcer that uses the given client.
+func NewEnforcer(client *v1.Client, opt *Options) *Enforcer {
+	e := &Enforcer{client: client, now: time.Now}
+	if opt != nil {
+		e.opt = *opt
+	}
+	if e.opt.PageSize == 0 {
+		e.opt.PageSize = 100
+	}
+	if e.opt.Progress == nil {
+		e.opt.Progress = NewMemoryProgress()
+	}
+	return e
+}
+
+// Enforce applies every policy and returns a report. Errors of a topic are
+// recorded in its report; an error is returned only when the progress or
+// the audit log can't be written.
+func (e *Enforcer) Enforce(ctx context.Context) (*Report, error) {
+	report := &Report{DryRun: e.opt.DryRun}
+	for _, p := range e.opt.Policies {
+		r, err := e.enforce(ctx, p)
+		report.Topics = append(report.Topics, r)
+		if err != nil {
+			return report, err
+		}
+		if ctx.Err() != nil {
+			return report, ctx.Err()
+		}
+	}
+	return report, nil
+}
+
+func (e *Enforcer) enforce(ctx context.Context, p *Policy) (*TopicReport, error) {
+	r := &TopicReport{
+		TopicID: p.TopicID,
+		Cutoff:  e.now().AddDate(0, 0, -p.MaxAgeDays),
+	}
+	if p.MaxAgeDays <= 0 {
+		r.Error = "maxAgeDays must be positive"
+		return r, nil
+	}
+
+	if !e.opt.DryRun {
+		// Finish the deletions of an interrupted run first.
+		pending, err := e.opt.Progress.Get(p.TopicID)
+		if err != nil {
+			return r, err
+		}
+		r.Selected = append(r.Selected, pending...)
+		if ok, err := e.delete(ctx, p, r, pending, nil); !ok || err != nil {
+			return r, err
+		}
+	}
+
+	selected, posts, err := e.scan(ctx, p, r)
+	if err != nil {
+		r.Error = err.Error()
+		return r, nil
+	}
+	r.Selected = append(r.Selected, selected...)
+	if e.opt.DryRun || len(selected) == 0 {
+		return r, nil
+	}
+	if err := e.opt.Progress.Put(p.TopicID, selected); err != nil {
+		return r, err
+	}
+	_, err = e.delete(ctx, p, r, selected, posts)
+	return r, err
+}
+
+// delete deletes the pending posts, keeping the progress up to date. It
+// reports whether all of them were deleted; a failed deletion is recorded in
+// the report.
+func (e *Enforcer) delete(ctx context.Context, p *Policy, r *TopicReport, pending []int, posts map[int]*v1.Post) (bool, error) {
+	for i, postID := range pending {
+		deleted, _, err := e.client.Messages.DeleteMessage(ctx, p.TopicID, postID)
+		if err != nil && !isNotFound(err) {
+			r.Error = fmt.Sprintf("deleting post %d: %v", postID, err)
+			return false, nil
+		}
+		if err == nil {
+			r.Deleted = append(r.Deleted, postID)
+			if err := e.audit(p.TopicID, postID, deleted, posts[postID]); err != nil {
+				return false, err
+			}
+		}
+		if err := e.opt.Progress.Put(p.TopicID, pending[i+1:]); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// scan returns the IDs of the posts the policy deletes, oldest first.
+func (e *Enforcer) scan(ctx context.Context, p *Policy, r *TopicReport) ([]int, map[int]*v1.Post, error) {
+	var selected []int
+	posts := map[int]*v1.Post{}
+	opt := &v1.GetTopicMessagesOptions{Count: e.opt.PageSize, Direction: "forward"}
+	for {
+		page, _, err := e.client.Topics.GetTopicMessages(ctx, p.TopicID, opt)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, post := range page.Posts {
+			r.Examined++
+			if post.CreatedAt != nil && !post.CreatedAt.Before(r.Cutoff) {
+				return selected, posts, nil
+			}
+			if !p.keeps(post, r.Cutoff) {
+				selected = append(selected, post.ID)
+				posts[post.ID] = post
+			}
+		}
+		if !page.HasNext || len(page.Posts) == 0 {
+			return selected, posts, nil
+		}
+		opt.From = page.Posts[len(page.Posts)-1].ID
+	}
+}
+
+func (e *Enforcer) audit(topicID, postID int, deleted, scanned *v1.Post) error {
+	if e.opt.AuditLog == nil {
+		return nil
+	}
+	rec := &AuditRecord{Time: e.now(), TopicID: topicID, PostID: postID}
+	for _, post := range []*v1.Post{deleted, scanned} {
+		if post == nil {
+			continue
+		}
+		if post.Account != nil {
+			rec.AccountID = post.Account.ID
+		}
+		if post.CreatedAt != nil {
+			rec.CreatedAt = post.CreatedAt
+		}
+	}
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, err = e.opt.AuditLog.Write(append(b, '\n'))
+	return err
+}
+
+func isNotFound(err error) bool {
+	e, ok := err.(*shared.ErrorResponse)
+	return ok && e.Response != nil && e.Response.StatusCode == http.StatusNotFound
+}
@@ -0,0 +1,204 @@
+package retention
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/nulab/go-typetalk/v3/typetalk/internal"
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+)
+
+var (
+	mux    *http.ServeMux
+	client *v1.Client
+	server *httptest.Server
+)
+
+var testNow = time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+
+func setup() {
+	mux = http.NewServeMux()
+	server = httptest.NewServer(mux)
+
+	client = v1.NewClient(NewTestClient(server))
+	client.SetTypetalkToken("DUMMY_TOKEN")
+}
+
+func teardown() {
+	server.Close()
+}
+
+// fakeTopic serves the posts of topic 1, oldest first.
+type fakeTopic struct {
+	mu       sync.Mutex
+	posts    []*v1.Post
+	failOn   int
+	deleted  []int
+	pageSize int
+}
+
+func newFakeTopic(t *testing.T, days ...int) *fakeTopic {
+	f := &fakeTopic{}
+	for i, d := range days {
+		created := testNow.AddDate(0, 0, -d)
+		f.posts = append(f.posts, &v1.Post{ID: i + 1, TopicID: 1, Account: &v1.Account{ID: 100 + i}, CreatedAt: &created})
+	}
+	mux.HandleFunc("/api/v1/topics/1", func(w http.ResponseWriter, r *http.Request) {
+		TestMethod(t, r, http.MethodGet)
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		q := r.URL.Query()
+		if q.Get("direction") != "forward" {
+			t.Errorf("direction: got %q", q.Get("direction"))
+		}
+		count, _ := strconv.Atoi(q.Get("count"))
+		from, _ := strconv.Atoi(q.Get("from"))
+		f.pageSize = count
+		var page []*v1.Post
+		for _, p := range f.posts {
+			if p.ID > from {
+				page = append(page, p)
+			}
+		}
+		hasNext := len(page) > count
+		if hasNext {
+			page = page[:count]
+		}
+		json.NewEncoder(w).Encode(&v1.TopicMessages{Posts: page, HasNext: hasNext})
+	})
+	mux.HandleFunc("/api/v1/topics/1/posts/", func(w http.ResponseWriter, r *http.Request) {
+		TestMethod(t, r, http.MethodDelete)
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		id, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/api/v1/topics/1/posts/"))
+		if id == f.failOn {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		for i, p := range f.posts {
+			if p.ID == id {
+				f.posts = append(f.posts[:i], f.posts[i+1:]...)
+				f.deleted = append(f.deleted, id)
+				json.NewEncoder(w).Encode(p)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	})
+	return f
+}
+
+func newTestEnforcer(opt *Options) *Enforcer {
+	e := NewEnforcer(client, opt)
+	e.now = func() time.Time { return testNow }
+	return e
+}
+
+func Test_Enforcer_should_delete_posts_older_than_policy(t *testing.T) {
+	setup()
+	defer teardown()
+	f := newFakeTopic(t, 200, 120, 95, 91, 90, 30, 1)
+	f.posts[1].Talks = []*v1.Talk{{ID: 1}}
+	f.posts[2].Likes = []*v1.Like{{ID: 1}}
+	var audit bytes.Buffer
+	e := newTestEnforcer(&Options{
+		Policies: []*Policy{{TopicID: 1, MaxAgeDays: 90, KeepTalks: true, KeepLiked: true}},
+		PageSize: 2,
+		AuditLog: &audit,
+	})
+
+	report, err := e.Enforce(context.Background())
+	if err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+	r := report.Topics[0]
+	if !reflect.DeepEqual(r.Deleted, []int{1, 4}) || !reflect.DeepEqual(f.deleted, []int{1, 4}) {
+		t.Errorf("Deleted: report %v, server %v", r.Deleted, f.deleted)
+	}
+	if r.Examined != 5 || f.pageSize != 2 {
+		t.Errorf("Examined %d posts in pages of %d", r.Examined, f.pageSize)
+	}
+
+	lines := strings.Split(strings.TrimSpace(audit.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Audit log: got %q", audit.String())
+	}
+	rec := &AuditRecord{}
+	json.Unmarshal([]byte(lines[1]), rec)
+	if rec.TopicID != 1 || rec.PostID != 4 || rec.AccountID != 103 || !rec.Time.Equal(testNow) {
+		t.Errorf("Audit record: got %+v", rec)
+	}
+}
+
+func Test_Enforcer_should_only_report_in_dry_run(t *testing.T) {
+	setup()
+	defer teardown()
+	f := newFakeTopic(t, 100, 95, 10)
+	e := newTestEnforcer(&Options{Policies: []*Policy{{TopicID: 1, MaxAgeDays: 90}}, DryRun: true})
+
+	report, err := e.Enforce(context.Background())
+	if err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+	if r := report.Topics[0]; !report.DryRun || !reflect.DeepEqual(r.Selected, []int{1, 2}) || len(r.Deleted) != 0 {
+		t.Errorf("Report: got %+v", r)
+	}
+	if len(f.deleted) != 0 {
+		t.Errorf("Deleted in dry run: %v", f.deleted)
+	}
+}
+
+func Test_Enforcer_should_resume_interrupted_run(t *testing.T) {
+	setup()
+	defer teardown()
+	f := newFakeTopic(t, 100, 99, 98, 10)
+	f.failOn = 2
+	dir, _ := ioutil.TempDir("", "retention")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "progress.json")
+	progress, _ := OpenFileProgress(path)
+	opt := &Options{Policies: []*Policy{{TopicID: 1, MaxAgeDays: 90}}, Progress: progress}
+
+	report, _ := newTestEnforcer(opt).Enforce(context.Background())
+	if r := report.Topics[0]; r.Error == "" || !reflect.DeepEqual(r.Deleted, []int{1}) {
+		t.Errorf("Failed run: got %+v", r)
+	}
+
+	f.failOn = 0
+	// The post is deleted by someone else in the meantime.
+	f.posts = f.posts[1:]
+	opt.Progress, _ = OpenFileProgress(path)
+	if pending, _ := opt.Progress.Get(1); !reflect.DeepEqual(pending, []int{2, 3}) {
+		t.Fatalf("Saved progress: got %v", pending)
+	}
+	report, err := newTestEnforcer(opt).Enforce(context.Background())
+	if err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+	if r := report.Topics[0]; r.Error != "" || !reflect.DeepEqual(r.Deleted, []int{3}) {
+		t.Errorf("Resumed run: got %+v", r)
+	}
+	if pending, _ := opt.Progress.Get(1); len(pending) != 0 {
+		t.Errorf("Progress should be empty, got %v", pending)
+	}
+}
+
+func Test_Enforcer_should_report_invalid_policy(t *testing.T) {
+	e := newTestEnforcer(&Options{Policies: []*Policy{{TopicID: 1}}})
+	report, err := e.Enforce(context.Background())
+	if err != nil || report.Topics[0].Error == "" {
+		t.Errorf("Enforce: got %+v, %v", report.Topics[0], err)
+	}
+}
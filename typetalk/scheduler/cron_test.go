@@ -0,0 +1,50 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_Cron_Next(t *testing.T) {
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	newYork, _ := time.LoadLocation("America/New_York")
+	base := time.Date(2020, 1, 1, 10, 30, 15, 0, time.UTC) // Wednesday
+	tests := []struct {
+		spec string
+		from time.Time
+		want time.Time
+	}{
+		{"* * * * *", base, time.Date(2020, 1, 1, 10, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", base, time.Date(2020, 1, 1, 10, 45, 0, 0, time.UTC)},
+		{"55 9 * * MON-FRI", base, time.Date(2020, 1, 2, 9, 55, 0, 0, time.UTC)},
+		{"0 10 * * 1", base, time.Date(2020, 1, 6, 10, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", base, time.Date(2020, 1, 5, 0, 0, 0, 0, time.UTC)},
+		{"0 12 1,15 * *", base, time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)},
+		{"0 0 13 * FRI", base, time.Date(2020, 1, 3, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", base, time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", base, time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", base, time.Date(2020, 1, 1, 11, 0, 0, 0, time.UTC)},
+		{"0 9 * dec *", base, time.Date(2020, 12, 1, 9, 0, 0, 0, time.UTC)},
+		{"0 9 * * *", base.In(tokyo), time.Date(2020, 1, 2, 9, 0, 0, 0, tokyo)},
+		{"30 2 * * *", time.Date(2020, 3, 8, 0, 0, 0, 0, newYork), time.Date(2020, 3, 9, 2, 30, 0, 0, newYork)},
+		{"0 0 30 2 *", base, time.Time{}},
+	}
+	for _, tt := range tests {
+		c, err := ParseCron(tt.spec)
+		if err != nil {
+			t.Errorf("ParseCron(%q) returned error: %v", tt.spec, err)
+			continue
+		}
+		if got := c.Next(tt.from); !got.Equal(tt.want) {
+			t.Errorf("Next(%q, %v): got %v, want %v", tt.spec, tt.from, got, tt.want)
+		}
+	}
+}
+
+func Test_ParseCron_should_return_error_for_invalid_expression(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "5-1 * * * *", "*/0 * * * *", "x * * * *"} {
+		if _, err := ParseCron(spec); err == nil {
+			t.Errorf("ParseCron(%q): expected error to be returned", spec)
+		}
+	}
+}
@@ -0,0 +1,329 @@
+package scheduler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+)
+
+// Catch-up policies decide what happens to the runs of a schedule that were
+// missed, for example because the scheduler was not running.
+const (
+	// CatchUpSkip drops missed runs. Only runs due within the grace period
+	// are posted.
+	CatchUpSkip = "skip"
+	// CatchUpOnce posts once for any number of missed runs.
+	CatchUpOnce = "once"
+	// CatchUpAll posts once for every missed run.
+	CatchUpAll = "all"
+)
+
+// maxRetryDelay caps the delay before the retry of a failed post.
+const maxRetryDelay = time.Hour
+
+// Schedule is a scheduled post. It is either a one-shot post at a given time
+// or a recurring post following a cron expression.
+type Schedule struct {
+	ID      string `json:"id"`
+	TopicID int    `json:"topicId"`
+	Message string `json:"message"`
+	// At is the time of a one-shot post.
+	At time.Time `json:"at,omitempty"`
+	// Cron is the expression of a recurring post. See ParseCron.
+	Cron string `json:"cron,omitempty"`
+	// Timezone is the IANA time zone the cron expression is evaluated in.
+	// When it is empty, Add uses the time zone of the account of the
+	// client.
+	Timezone string `json:"timezone,omitempty"`
+	// CatchUp overrides Options.CatchUp for this schedule.
+	CatchUp string `json:"catchUp,omitempty"`
+	// Next is the time of the next run.
+	Next      time.Time `json:"next"`
+	CreatedAt time.Time `json:"createdAt"`
+	// LastRun, LastPostID and LastError describe the previous run.
+	LastRun    time.Time `json:"lastRun,omitempty"`
+	LastPostID int       `json:"lastPostId,omitempty"`
+	LastError  string    `json:"lastError,omitempty"`
+	// Failures counts the failed posts since the last successful one, and
+	// RetryAt is the earliest time the failed run is posted again.
+	Failures int       `json:"failures,omitempty"`
+	RetryAt  time.Time `json:"retryAt,omitempty"`
+}
+
+// Location returns the time zone of the schedule.
+func (s *Schedule) Location() (*time.Location, error) {
+	return time.LoadLocation(s.Timezone)
+}
+
+func (s *Schedule) next(after time.Time) (time.Time, error) {
+	if s.Cron == "" {
+		return time.Time{}, nil
+	}
+	c, err := ParseCron(s.Cron)
+	if err != nil {
+		return time.Time{}, err
+	}
+	loc, err := s.Location()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return c.Next(after.In(loc)), nil
+}
+
+// Options configures a Scheduler.
+type Options struct {
+	// Store keeps the schedules. An in-memory store is used when it is nil.
+	Store Store
+	// CatchUp is the default catch-up policy. It defaults to CatchUpOnce.
+	CatchUp string
+	// Grace is how late a run may be and still count as on time. It
+	// defaults to five minutes.
+	Grace time.Duration
+	// RetryDelay is how long a failed post waits before it is retried. It
+	// doubles with every further failure, up to an hour, and defaults to
+	// one minute.
+	RetryDelay time.Duration
+	// ErrorLog is used to log failed posts. The standard logger is used
+	// when it is nil.
+	ErrorLog *log.Logger
+}
+
+// Scheduler posts scheduled messages through MessagesService.PostMessage.
+type Scheduler struct {
+	client *v1.Client
+	opt    Options
+	now    func() time.Time
+}
+
+// New returns a Scheduler that posts through the given client.
+func New(client *v1.Client, opt *Options) *Scheduler {
+	s := &Scheduler{client: client, now: time.Now}
+	if opt != nil {
+		s.opt = *opt
+	}
+	if s.opt.Store == nil {
+		s.opt.Store = NewMemoryStore()
+	}
+	if s.opt.CatchUp == "" {
+		s.opt.CatchUp = CatchUpOnce
+	}
+	if s.opt.Grace == 0 {
+		s.opt.Grace = 5 * time.Minute
+	}
+	if s.opt.RetryDelay == 0 {
+		s.opt.RetryDelay = time.Minute
+	}
+	return s
+}
+
+// Add validates and stores a new schedule and returns it with its ID and
+// next run set. The time zone of the account of the client is used when the
+// schedule has none.
+func (s *Scheduler) Add(ctx context.Context, schedule *Schedule) (*Schedule, error) {
+	sched := *schedule
+	if sched.TopicID == 0 || sched.Message == "" {
+		return nil, errors.New("scheduler: topic id and message are required")
+	}
+	if sched.At.IsZero() == (sched.Cron == "") {
+		return nil, errors.New("scheduler: either a time or a cron expression is required")
+	}
+	switch sched.CatchUp {
+	case "", CatchUpSkip, CatchUpOnce, CatchUpAll:
+	default:
+		return nil, fmt.Errorf("scheduler: unknown catch-up policy %q", sched.CatchUp)
+	}
+	if sched.Timezone == "" && sched.Cron != "" {
+		profile, _, err := s.client.Accounts.GetMyProfile(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("scheduler: getting the time zone of the account: %v", err)
+		}
+		sched.Timezone = AccountTimezone(profile.Account)
+	}
+	if _, err := sched.Location(); err != nil {
+		return nil, fmt.Errorf("scheduler: %v", err)
+	}
+	now := s.now()
+	if sched.Cron != "" {
+		next, err := sched.next(now)
+		if err != nil {
+			return nil, err
+		}
+		if next.IsZero() {
+			return nil, fmt.Errorf("scheduler: cron expression %q never matches", sched.Cron)
+		}
+		sched.Next = next
+	} else {
+		sched.Next = sched.At
+	}
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	sched.ID = hex.EncodeToString(id)
+	sched.CreatedAt = now
+	if err := s.opt.Store.Put(&sched); err != nil {
+		return nil, err
+	}
+	return &sched, nil
+}
+
+// AccountTimezone returns the time zone of the account, or "UTC" when it has
+// none.
+func AccountTimezone(account *v1.Account) string {
+	if account == nil || account.TimezoneID == "" {
+		return "UTC"
+	}
+	return account.TimezoneID
+}
+
+// Cancel removes a schedule.
+func (s *Scheduler) Cancel(id string) error {
+	sched, err := s.opt.Store.Get(id)
+	if err != nil {
+		return err
+	}
+	if sched == nil {
+		return fmt.Errorf("scheduler: no schedule %s", id)
+	}
+	return s.opt.Store.Delete(id)
+}
+
+// List returns the schedules.
+func (s *Scheduler) List() ([]*Schedule, error) {
+	return s.opt.Store.List()
+}
+
+// RunDue posts the schedules that are due and returns the number of posts.
+// A schedule whose post fails is retried after Options.RetryDelay, which
+// doubles with every further failure; one-shot schedules are removed once
+// posted.
+func (s *Scheduler) RunDue(ctx context.Context) (int, error) {
+	schedules, err := s.opt.Store.List()
+	if err != nil {
+		return 0, err
+	}
+	posted := 0
+	for _, sched := range schedules {
+		now := s.now()
+		if sched.Next.IsZero() || sched.Next.After(now) || sched.RetryAt.After(now) {
+			continue
+		}
+		n, err := s.run(ctx, sched, now)
+		posted += n
+		if err != nil {
+			return posted, err
+		}
+	}
+	return posted, nil
+}
+
+func (s *Scheduler) run(ctx context.Context, sched *Schedule, now time.Time) (int, error) {
+	runs, next, err := s.missedRuns(sched, now)
+	if err != nil {
+		return 0, err
+	}
+	posted := 0
+	for _, run := range runs {
+		result, _, err := s.client.Messages.PostMessage(ctx, sched.TopicID, sched.Message, nil)
+		if err != nil {
+			s.logf("scheduler: posting schedule %s to topic %d: %v", sched.ID, sched.TopicID, err)
+			sched.LastError = err.Error()
+			sched.Failures++
+			sched.RetryAt = now.Add(s.retryDelay(sched.Failures))
+			// Runs already posted are not posted again.
+			sched.Next = run
+			return posted, s.opt.Store.Update(sched)
+		}
+		posted++
+		sched.LastRun = run
+		sched.LastPostID = result.Post.ID
+		sched.LastError = ""
+		sched.Failures = 0
+		sched.RetryAt = time.Time{}
+	}
+	if sched.Cron == "" {
+		return posted, s.opt.Store.Delete(sched.ID)
+	}
+	sched.Next = next
+	// Update doesn't store the schedule again if it was cancelled while it
+	// was posted.
+	return posted, s.opt.Store.Update(sched)
+}
+
+// retryDelay returns the delay before the retry after the given number of
+// failures.
+func (s *Scheduler) retryDelay(failures int) time.Duration {
+	d := s.opt.RetryDelay
+	for i := 1; i < failures && d < maxRetryDelay; i++ {
+		d *= 2
+	}
+	if d > maxRetryDelay {
+		d = maxRetryDelay
+	}
+	return d
+}
+
+// missedRuns returns the runs of the schedule to post now according to its
+// catch-up policy, and the run after now.
+func (s *Scheduler) missedRuns(sched *Schedule, now time.Time) ([]time.Time, time.Time, error) {
+	due := []time.Time{sched.Next}
+	next := time.Time{}
+	if sched.Cron != "" {
+		t := sched.Next
+		for {
+			var err error
+			if t, err = sched.next(t); err != nil {
+				return nil, time.Time{}, err
+			}
+			if t.IsZero() || t.After(now) {
+				next = t
+				break
+			}
+			due = append(due, t)
+		}
+	}
+	policy := sched.CatchUp
+	if policy == "" {
+		policy = s.opt.CatchUp
+	}
+	last := due[len(due)-1]
+	switch policy {
+	case CatchUpAll:
+		return due, next, nil
+	case CatchUpSkip:
+		if now.Sub(last) > s.opt.Grace {
+			return nil, next, nil
+		}
+	}
+	return []time.Time{last}, next, nil
+}
+
+// Run calls RunDue at the given interval until ctx is done.
+func (s *Scheduler) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.RunDue(ctx); err != nil && ctx.Err() == nil {
+			s.logf("scheduler: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) logf(format string, args ...interface{}) {
+	if s.opt.ErrorLog != nil {
+		s.opt.ErrorLog.Printf(format, args...)
+	} else {
+		log.Printf(format, args...)
+	}
+}
@@ -0,0 +1,243 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/nulab/go-typetalk/v3/typetalk/internal"
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+)
+
+var (
+	mux    *http.ServeMux
+	client *v1.Client
+	server *httptest.Server
+)
+
+func setup() {
+	mux = http.NewServeMux()
+	server = httptest.NewServer(mux)
+
+	client = v1.NewClient(NewTestClient(server))
+	client.SetTypetalkToken("DUMMY_TOKEN")
+}
+
+func teardown() {
+	server.Close()
+}
+
+type clock struct {
+	t time.Time
+}
+
+func (c *clock) now() time.Time {
+	return c.t
+}
+
+func newTestScheduler(opt *Options) (*Scheduler, *clock) {
+	if opt == nil {
+		opt = &Options{}
+	}
+	opt.ErrorLog = log.New(ioutil.Discard, "", 0)
+	s := New(client, opt)
+	c := &clock{t: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	s.now = c.now
+	return s, c
+}
+
+func handlePosts(t *testing.T, topicID int) *[]string {
+	var posts []string
+	mux.HandleFunc(fmt.Sprintf("/api/v1/topics/%d", topicID), func(w http.ResponseWriter, r *http.Request) {
+		TestMethod(t, r, http.MethodPost)
+		posts = append(posts, r.FormValue("message"))
+		fmt.Fprintf(w, `{"post": {"id": %d}}`, len(posts))
+	})
+	return &posts
+}
+
+func Test_Scheduler_Add_should_use_account_timezone(t *testing.T) {
+	setup()
+	defer teardown()
+	mux.HandleFunc("/api/v1/profile", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"account": {"id": 1, "timezoneId": "Asia/Tokyo"}}`)
+	})
+	s, _ := newTestScheduler(nil)
+
+	sched, err := s.Add(context.Background(), &Schedule{TopicID: 1, Message: "standup in 5 minutes", Cron: "55 9 * * MON-FRI"})
+	if err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+	if sched.ID == "" || sched.Timezone != "Asia/Tokyo" {
+		t.Errorf("Returned schedule: %+v", sched)
+	}
+	// 2020-01-01 is a Wednesday; 09:55 in Tokyo is 00:55 UTC.
+	if want := time.Date(2020, 1, 1, 0, 55, 0, 0, time.UTC); !sched.Next.Equal(want) {
+		t.Errorf("Next: got %v, want %v", sched.Next, want)
+	}
+	if list, _ := s.List(); len(list) != 1 || list[0].ID != sched.ID {
+		t.Errorf("List: got %+v", list)
+	}
+}
+
+func Test_Scheduler_Add_should_validate_schedule(t *testing.T) {
+	s, _ := newTestScheduler(nil)
+	at := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	schedules := []*Schedule{
+		{Message: "x", At: at},
+		{TopicID: 1, At: at},
+		{TopicID: 1, Message: "x"},
+		{TopicID: 1, Message: "x", At: at, Cron: "* * * * *"},
+		{TopicID: 1, Message: "x", Cron: "* * *", Timezone: "UTC"},
+		{TopicID: 1, Message: "x", Cron: "* * * * *", Timezone: "Mars/Olympus"},
+		{TopicID: 1, Message: "x", At: at, CatchUp: "later"},
+		{TopicID: 1, Message: "x", Cron: "0 0 30 2 *", Timezone: "UTC"},
+	}
+	for i, sched := range schedules {
+		if _, err := s.Add(context.Background(), sched); err == nil {
+			t.Errorf("schedule %d: expected error to be returned", i)
+		}
+	}
+}
+
+func Test_Scheduler_RunDue_should_post_one_shot_schedule_once(t *testing.T) {
+	setup()
+	defer teardown()
+	posts := handlePosts(t, 1)
+	s, c := newTestScheduler(nil)
+	s.Add(context.Background(), &Schedule{TopicID: 1, Message: "release", At: c.t.Add(time.Hour)})
+
+	if n, _ := s.RunDue(context.Background()); n != 0 {
+		t.Errorf("Posted %d before the schedule was due", n)
+	}
+	c.t = c.t.Add(time.Hour)
+	if n, err := s.RunDue(context.Background()); n != 1 || err != nil {
+		t.Errorf("RunDue: got %d, %v", n, err)
+	}
+	s.RunDue(context.Background())
+	if len(*posts) != 1 {
+		t.Errorf("Posted %v", *posts)
+	}
+	if list, _ := s.List(); len(list) != 0 {
+		t.Errorf("One-shot schedule should be removed, got %+v", list)
+	}
+}
+
+func Test_Scheduler_RunDue_should_apply_catch_up_policy(t *testing.T) {
+	tests := []struct {
+		policy string
+		want   int
+	}{
+		{CatchUpAll, 3},
+		{CatchUpOnce, 1},
+		{CatchUpSkip, 0},
+	}
+	for _, tt := range tests {
+		setup()
+		posts := handlePosts(t, 1)
+		s, c := newTestScheduler(&Options{CatchUp: tt.policy})
+		sched, _ := s.Add(context.Background(), &Schedule{TopicID: 1, Message: "hourly", Cron: "@hourly", Timezone: "UTC"})
+
+		// Down for three runs: 01:00, 02:00 and 03:00.
+		c.t = time.Date(2020, 1, 1, 3, 30, 0, 0, time.UTC)
+		s.RunDue(context.Background())
+		if len(*posts) != tt.want {
+			t.Errorf("%s: posted %d, want %d", tt.policy, len(*posts), tt.want)
+		}
+		got, _ := s.opt.Store.Get(sched.ID)
+		if want := time.Date(2020, 1, 1, 4, 0, 0, 0, time.UTC); !got.Next.Equal(want) {
+			t.Errorf("%s: next %v, want %v", tt.policy, got.Next, want)
+		}
+
+		// A run within the grace period is posted by every policy.
+		c.t = time.Date(2020, 1, 1, 4, 2, 0, 0, time.UTC)
+		s.RunDue(context.Background())
+		if len(*posts) != tt.want+1 {
+			t.Errorf("%s: on time run was not posted", tt.policy)
+		}
+		teardown()
+	}
+}
+
+func Test_Scheduler_RunDue_should_retry_failed_run(t *testing.T) {
+	setup()
+	defer teardown()
+	fail := true
+	mux.HandleFunc("/api/v1/topics/1", func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"post": {"id": 5}}`)
+	})
+	s, c := newTestScheduler(nil)
+	sched, _ := s.Add(context.Background(), &Schedule{TopicID: 1, Message: "daily", Cron: "@daily", Timezone: "UTC"})
+
+	c.t = time.Date(2020, 1, 2, 0, 0, 30, 0, time.UTC)
+	s.RunDue(context.Background())
+	got, _ := s.opt.Store.Get(sched.ID)
+	if got.LastError == "" || !got.Next.Equal(time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Failed run: got %+v", got)
+	}
+
+	// The second failure doubles the delay before the next retry.
+	c.t = c.t.Add(time.Minute)
+	s.RunDue(context.Background())
+	got, _ = s.opt.Store.Get(sched.ID)
+	if want := c.t.Add(2 * time.Minute); got.Failures != 2 || !got.RetryAt.Equal(want) {
+		t.Errorf("Second failure: got %d failures, retry at %v, want 2, %v", got.Failures, got.RetryAt, want)
+	}
+
+	fail = false
+	c.t = c.t.Add(time.Minute)
+	if n, _ := s.RunDue(context.Background()); n != 0 {
+		t.Errorf("Posted %d before the retry delay", n)
+	}
+	c.t = c.t.Add(time.Minute)
+	if n, _ := s.RunDue(context.Background()); n != 1 {
+		t.Errorf("Posted %d, want 1", n)
+	}
+	got, _ = s.opt.Store.Get(sched.ID)
+	if got.LastError != "" || got.LastPostID != 5 || got.Failures != 0 || !got.RetryAt.IsZero() ||
+		!got.Next.Equal(time.Date(2020, 1, 3, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Retried run: got %+v", got)
+	}
+}
+
+func Test_Scheduler_RunDue_should_not_restore_schedule_cancelled_while_posting(t *testing.T) {
+	setup()
+	defer teardown()
+	var s *Scheduler
+	var id string
+	mux.HandleFunc("/api/v1/topics/1", func(w http.ResponseWriter, r *http.Request) {
+		s.Cancel(id)
+		fmt.Fprint(w, `{"post": {"id": 5}}`)
+	})
+	s, c := newTestScheduler(nil)
+	sched, _ := s.Add(context.Background(), &Schedule{TopicID: 1, Message: "daily", Cron: "@daily", Timezone: "UTC"})
+	id = sched.ID
+
+	c.t = time.Date(2020, 1, 2, 0, 0, 30, 0, time.UTC)
+	if n, err := s.RunDue(context.Background()); n != 1 || err != nil {
+		t.Errorf("RunDue: got %d, %v", n, err)
+	}
+	if list, _ := s.List(); len(list) != 0 {
+		t.Errorf("Cancelled schedule was stored again: %+v", list)
+	}
+}
+
+func Test_Scheduler_Cancel(t *testing.T) {
+	s, c := newTestScheduler(nil)
+	sched, _ := s.Add(context.Background(), &Schedule{TopicID: 1, Message: "x", At: c.t.Add(time.Hour)})
+	if err := s.Cancel(sched.ID); err != nil {
+		t.Errorf("Returned error: %v", err)
+	}
+	if err := s.Cancel(sched.ID); err == nil {
+		t.Error("Expected error to be returned for an unknown schedule")
+	}
+}
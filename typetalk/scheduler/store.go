@@ -0,0 +1,172 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"github.com/nulab/go-typetalk/v3/typetalk/internal"
+)
+
+// Store keeps scheduled posts.
+type Store interface {
+	// List returns the schedules sorted by ID.
+	List() ([]*Schedule, error)
+	// Get returns the schedule, or nil if there is none.
+	Get(id string) (*Schedule, error)
+	Put(schedule *Schedule) error
+	// Update replaces the stored schedule of the same ID in one step. It
+	// does nothing when there is none, such as when the schedule was
+	// cancelled.
+	Update(schedule *Schedule) error
+	Delete(id string) error
+}
+
+// MemoryStore is a Store that keeps schedules in memory.
+type MemoryStore struct {
+	mu        sync.Mutex
+	schedules map[string]*Schedule
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{schedules: map[string]*Schedule{}}
+}
+
+// List implements Store.
+func (s *MemoryStore) List() ([]*Schedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return sortSchedules(s.schedules), nil
+}
+
+// Get implements Store.
+func (s *MemoryStore) Get(id string) (*Schedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sched, ok := s.schedules[id]; ok {
+		c := *sched
+		return &c, nil
+	}
+	return nil, nil
+}
+
+// Put implements Store.
+func (s *MemoryStore) Put(schedule *Schedule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := *schedule
+	s.schedules[schedule.ID] = &c
+	return nil
+}
+
+// Update implements Store.
+func (s *MemoryStore) Update(schedule *Schedule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.schedules[schedule.ID]; ok {
+		c := *schedule
+		s.schedules[schedule.ID] = &c
+	}
+	return nil
+}
+
+// Delete implements Store.
+func (s *MemoryStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.schedules, id)
+	return nil
+}
+
+// FileStore is a Store that keeps schedules in a JSON file. The file is read
+// on every call, so schedules added by another process, such as the
+// typetalk-scheduler command, are picked up by a running Scheduler.
+type FileStore struct {
+	mu   sync.Mutex
+	file internal.JSONFile
+}
+
+// NewFileStore returns a FileStore for the file at path. The file is created
+// on the first change if it doesn't exist.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{file: internal.JSONFile{Path: path}}
+}
+
+func (s *FileStore) load() (map[string]*Schedule, error) {
+	schedules := map[string]*Schedule{}
+	if err := s.file.Load(&schedules); err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+func (s *FileStore) update(f func(map[string]*Schedule)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	schedules, err := s.load()
+	if err != nil {
+		return err
+	}
+	f(schedules)
+	b, err := json.MarshalIndent(schedules, "", "  ")
+	if err != nil {
+		return err
+	}
+	return internal.WriteFileAtomic(s.file.Path, b)
+}
+
+// List implements Store.
+func (s *FileStore) List() ([]*Schedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	schedules, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return sortSchedules(schedules), nil
+}
+
+// Get implements Store.
+func (s *FileStore) Get(id string) (*Schedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	schedules, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return schedules[id], nil
+}
+
+// Put implements Store.
+func (s *FileStore) Put(schedule *Schedule) error {
+	return s.update(func(schedules map[string]*Schedule) {
+		schedules[schedule.ID] = schedule
+	})
+}
+
+// Update implements Store.
+func (s *FileStore) Update(schedule *Schedule) error {
+	return s.update(func(schedules map[string]*Schedule) {
+		if _, ok := schedules[schedule.ID]; ok {
+			schedules[schedule.ID] = schedule
+		}
+	})
+}
+
+// Delete implements Store.
+func (s *FileStore) Delete(id string) error {
+	return s.update(func(schedules map[string]*Schedule) {
+		delete(schedules, id)
+	})
+}
+
+func sortSchedules(m map[string]*Schedule) []*Schedule {
+	schedules := make([]*Schedule, 0, len(m))
+	for _, sched := range m {
+		c := *sched
+		schedules = append(schedules, &c)
+	}
+	sort.Slice(schedules, func(i, j int) bool { return schedules[i].ID < schedules[j].ID })
+	return schedules
+}
@@ -0,0 +1,38 @@
+package scheduler
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_FileStore_should_share_schedules_through_the_file(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "scheduler")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "schedules.json")
+
+	s := NewFileStore(path)
+	if list, err := s.List(); err != nil || len(list) != 0 {
+		t.Fatalf("List of missing file: got %v, %v", list, err)
+	}
+	s.Put(&Schedule{ID: "b", TopicID: 1, Message: "x", Cron: "@daily"})
+	s.Put(&Schedule{ID: "a", TopicID: 2, Message: "y", At: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)})
+
+	other := NewFileStore(path)
+	other.Delete("b")
+	s.Update(&Schedule{ID: "b", TopicID: 1, Message: "z", Cron: "@daily"})
+	s.Update(&Schedule{ID: "a", TopicID: 2, Message: "w", At: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)})
+
+	list, err := s.List()
+	if err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+	if len(list) != 1 || list[0].ID != "a" || list[0].TopicID != 2 || list[0].Message != "w" {
+		t.Errorf("List: got %+v", list)
+	}
+	if sched, _ := s.Get("b"); sched != nil {
+		t.Errorf("Get of deleted schedule: got %+v", sched)
+	}
+}
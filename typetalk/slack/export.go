@@ -0,0 +1,215 @@
+package slack
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// User represents a member listed in users.json of a Slack export.
+type User struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	RealName string `json:"real_name"`
+	Deleted  bool   `json:"deleted"`
+	IsBot    bool   `json:"is_bot"`
+	Profile  struct {
+		Email       string `json:"email"`
+		RealName    string `json:"real_name"`
+		DisplayName string `json:"display_name"`
+	} `json:"profile"`
+}
+
+// DisplayName returns the most readable name of the user.
+func (u *User) DisplayName() string {
+	switch {
+	case u.Profile.DisplayName != "":
+		return u.Profile.DisplayName
+	case u.Profile.RealName != "":
+		return u.Profile.RealName
+	case u.RealName != "":
+		return u.RealName
+	}
+	return u.Name
+}
+
+// ChannelValue represents the topic or the purpose of a channel.
+type ChannelValue struct {
+	Value   string `json:"value"`
+	Creator string `json:"creator"`
+}
+
+// Channel represents a public channel listed in channels.json of a Slack export.
+type Channel struct {
+	ID         string        `json:"id"`
+	Name       string        `json:"name"`
+	Created    int64         `json:"created"`
+	Creator    string        `json:"creator"`
+	IsArchived bool          `json:"is_archived"`
+	Members    []string      `json:"members"`
+	Topic      *ChannelValue `json:"topic"`
+	Purpose    *ChannelValue `json:"purpose"`
+}
+
+// File represents a file shared in a message.
+type File struct {
+	ID                 string `json:"id"`
+	Name               string `json:"name"`
+	Title              string `json:"title"`
+	Mimetype           string `json:"mimetype"`
+	URLPrivate         string `json:"url_private"`
+	URLPrivateDownload string `json:"url_private_download"`
+}
+
+// URL returns the URL the file is downloaded from.
+func (f *File) URL() string {
+	if f.URLPrivateDownload != "" {
+		return f.URLPrivateDownload
+	}
+	return f.URLPrivate
+}
+
+// Message represents a message in a channel's daily history file.
+type Message struct {
+	Type     string  `json:"type"`
+	Subtype  string  `json:"subtype"`
+	User     string  `json:"user"`
+	BotID    string  `json:"bot_id"`
+	Username string  `json:"username"`
+	Text     string  `json:"text"`
+	TS       string  `json:"ts"`
+	ThreadTS string  `json:"thread_ts"`
+	Files    []*File `json:"files"`
+}
+
+// IsReply reports whether the message is a reply in a thread.
+func (m *Message) IsReply() bool {
+	return m.ThreadTS != "" && m.ThreadTS != m.TS
+}
+
+// Time returns the time the message was posted.
+func (m *Message) Time() time.Time {
+	return ParseTimestamp(m.TS)
+}
+
+// Export represents the content of a standard Slack workspace export.
+type Export struct {
+	Users    []*User
+	Channels []*Channel
+	// Messages holds the messages of each channel keyed by the channel name,
+	// sorted in the order they were posted.
+	Messages map[string][]*Message
+}
+
+// User returns the user with the given ID, or nil if there is no such user.
+func (e *Export) User(id string) *User {
+	for _, u := range e.Users {
+		if u.ID == id {
+			return u
+		}
+	}
+	return nil
+}
+
+// OpenExport reads a Slack workspace export ZIP file.
+func OpenExport(name string) (*Export, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return ReadExport(f, stat.Size())
+}
+
+// ReadExport reads a Slack workspace export from the ZIP archive in r.
+func ReadExport(r io.ReaderAt, size int64) (*Export, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	export := &Export{Messages: map[string][]*Message{}}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		name := strings.TrimPrefix(path.Clean(f.Name), "/")
+		dir, base := path.Split(name)
+		dir = strings.TrimSuffix(dir, "/")
+		switch {
+		case dir == "" && base == "users.json":
+			err = decodeZipFile(f, &export.Users)
+		case dir == "" && base == "channels.json":
+			err = decodeZipFile(f, &export.Channels)
+		case dir != "" && !strings.Contains(dir, "/") && path.Ext(base) == ".json":
+			var messages []*Message
+			if err = decodeZipFile(f, &messages); err == nil {
+				export.Messages[dir] = append(export.Messages[dir], messages...)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("slack: reading %s: %v", f.Name, err)
+		}
+	}
+	for _, messages := range export.Messages {
+		sort.SliceStable(messages, func(i, j int) bool {
+			return compareTimestamps(messages[i].TS, messages[j].TS) < 0
+		})
+	}
+	return export, nil
+}
+
+func decodeZipFile(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return json.NewDecoder(rc).Decode(v)
+}
+
+// ParseTimestamp converts a Slack message timestamp such as
+// "1577836800.000200" into a time.Time.
+func ParseTimestamp(ts string) time.Time {
+	sec, usec := splitTimestamp(ts)
+	return time.Unix(sec, usec*int64(time.Microsecond))
+}
+
+func splitTimestamp(ts string) (int64, int64) {
+	parts := strings.SplitN(ts, ".", 2)
+	sec, _ := strconv.ParseInt(parts[0], 10, 64)
+	var usec int64
+	if len(parts) == 2 {
+		frac := (parts[1] + "000000")[:6]
+		usec, _ = strconv.ParseInt(frac, 10, 64)
+	}
+	return sec, usec
+}
+
+func compareTimestamps(a, b string) int {
+	as, au := splitTimestamp(a)
+	bs, bu := splitTimestamp(b)
+	switch {
+	case as != bs:
+		if as < bs {
+			return -1
+		}
+		return 1
+	case au != bu:
+		if au < bu {
+			return -1
+		}
+		return 1
+	}
+	return 0
+}
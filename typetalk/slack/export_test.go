@@ -0,0 +1,72 @@
+package slack
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func Test_ReadExport_should_read_users_channels_and_messages(t *testing.T) {
+	export, err := readFixtureExport()
+	if err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+	if len(export.Users) != 3 {
+		t.Errorf("Users: got %d, want 3", len(export.Users))
+	}
+	if len(export.Channels) != 2 {
+		t.Errorf("Channels: got %d, want 2", len(export.Channels))
+	}
+	general := export.Messages["general"]
+	if len(general) != 5 {
+		t.Fatalf("general messages: got %d, want 5", len(general))
+	}
+	for i := 1; i < len(general); i++ {
+		if compareTimestamps(general[i-1].TS, general[i].TS) > 0 {
+			t.Errorf("messages are not sorted: %s before %s", general[i-1].TS, general[i].TS)
+		}
+	}
+	if !general[2].IsReply() || general[1].IsReply() {
+		t.Errorf("IsReply: got %v and %v, want false and true", general[1].IsReply(), general[2].IsReply())
+	}
+	if u := export.User("U02"); u == nil || u.DisplayName() != "Bob Builder" {
+		t.Errorf("User(U02): got %v", u)
+	}
+}
+
+func Test_OpenExport_should_read_a_zip_file(t *testing.T) {
+	b, err := zipExport(fixturesPath + "export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := ioutil.TempFile("", "slack-export-*.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.Write(b)
+	f.Close()
+
+	export, err := OpenExport(f.Name())
+	if err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+	if len(export.Messages["random"]) != 1 {
+		t.Errorf("random messages: got %d, want 1", len(export.Messages["random"]))
+	}
+}
+
+func Test_ReadExport_should_return_error_for_broken_archive(t *testing.T) {
+	if _, err := OpenExport(fixturesPath + "export/users.json"); err == nil {
+		t.Error("Expected error to be returned")
+	}
+}
+
+func Test_ParseTimestamp(t *testing.T) {
+	got := ParseTimestamp("1577836860.000200")
+	want := time.Date(2020, 1, 1, 0, 1, 0, 200000, time.UTC)
+	if !got.Equal(want) {
+		t.Errorf("ParseTimestamp: got %v, want %v", got, want)
+	}
+}
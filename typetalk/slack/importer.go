@@ -0,0 +1,383 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nulab/go-typetalk/typetalk/shared"
+	"github.com/nulab/go-typetalk/v3/typetalk/internal"
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+)
+
+// ImportOptions controls how a Slack export is imported.
+type ImportOptions struct {
+	// SpaceKey is the key of the space the topics are created in.
+	SpaceKey string
+	// Channels limits the import to the channels with the given names.
+	// All channels are imported when it is empty.
+	Channels []string
+	// TopicName returns the name of the topic created for a channel.
+	// The channel name is used when it is nil.
+	TopicName func(channel *Channel) string
+	// Location is the time zone used for the timestamps written into the
+	// imported messages. UTC is used when it is nil.
+	Location *time.Location
+	// SkipFiles disables importing the files shared in Slack messages.
+	SkipFiles bool
+	// SlackToken is a Slack token with the files:read scope. The files of
+	// an export can only be downloaded with it, so the shared files are
+	// downloaded and uploaded as attachments when it is set. Otherwise their
+	// names and Slack URLs are written at the end of the messages, and the
+	// links only open for members of the Slack workspace.
+	SlackToken string
+	// HTTPClient downloads the files from Slack. http.DefaultClient is used
+	// when it is nil.
+	HTTPClient *http.Client
+	// ProgressFile is the path of a JSON file recording the topics created
+	// and the messages posted. An import that failed can be run again with
+	// the same file: it reuses the topics and skips the messages posted by
+	// the earlier run. Without it, running an import again creates new
+	// topics.
+	ProgressFile string
+}
+
+// ImportResult summarizes an import.
+type ImportResult struct {
+	// Topics maps channel names to the IDs of the created topics, including
+	// the topics reused from the progress file.
+	Topics map[string]int
+	// Posts is the number of messages posted by this run.
+	Posts int
+	// Accounts maps Slack user IDs to Typetalk accounts.
+	Accounts map[string]*v1.Account
+	// UnmappedUsers lists the Slack user IDs no Typetalk account was found for.
+	UnmappedUsers []string
+}
+
+// Importer imports a Slack workspace export into Typetalk.
+//
+// Channels become topics, users are mapped to accounts by their email
+// address and thread replies become reply chains. Messages are posted by the
+// account the client is authorized as, so the original author and time are
+// written at the top of each message.
+type Importer struct {
+	client *v1.Client
+}
+
+// NewImporter returns an Importer that creates topics and posts messages
+// through the given client.
+func NewImporter(client *v1.Client) *Importer {
+	return &Importer{client: client}
+}
+
+var skippedSubtypes = map[string]bool{
+	"channel_join":    true,
+	"channel_leave":   true,
+	"channel_topic":   true,
+	"channel_purpose": true,
+	"channel_name":    true,
+	"channel_archive": true,
+	"bot_add":         true,
+	"bot_remove":      true,
+	"pinned_item":     true,
+}
+
+// Import imports the export into the space given by opt.SpaceKey.
+func (im *Importer) Import(ctx context.Context, export *Export, opt *ImportOptions) (*ImportResult, error) {
+	if opt == nil || opt.SpaceKey == "" {
+		return nil, fmt.Errorf("slack: space key is required")
+	}
+	result := &ImportResult{
+		Topics:   map[string]int{},
+		Accounts: map[string]*v1.Account{},
+	}
+	progress, err := loadProgress(opt.ProgressFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := im.mapUsers(ctx, export, result); err != nil {
+		return result, err
+	}
+	for _, channel := range im.channels(export, opt) {
+		cp := progress.Channels[channel.Name]
+		if cp == nil {
+			if cp, err = im.createTopic(ctx, channel, opt, result, progress); err != nil {
+				return result, err
+			}
+		}
+		result.Topics[channel.Name] = cp.TopicID
+		if err := im.postMessages(ctx, channel, cp, export, opt, result, progress); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// importProgress is the content of ImportOptions.ProgressFile.
+type importProgress struct {
+	mu       sync.Mutex
+	file     *internal.JSONFile
+	Channels map[string]*channelProgress
+}
+
+// channelProgress is the progress of the import of a channel.
+type channelProgress struct {
+	TopicID int `json:"topicId"`
+	// LastTS is the timestamp of the last message posted.
+	LastTS string `json:"lastTs,omitempty"`
+	// Threads maps the timestamps of the parent messages to the ID of the
+	// last post of their thread, so that each reply answers the previous
+	// one.
+	Threads map[string]int `json:"threads,omitempty"`
+}
+
+func loadProgress(path string) (*importProgress, error) {
+	p := &importProgress{Channels: map[string]*channelProgress{}}
+	if path == "" {
+		return p, nil
+	}
+	p.file = &internal.JSONFile{Path: path}
+	if err := p.file.Load(&p.Channels); err != nil {
+		return nil, fmt.Errorf("slack: reading the progress file: %v", err)
+	}
+	return p, nil
+}
+
+func (p *importProgress) save() error {
+	if p.file == nil {
+		return nil
+	}
+	if err := p.file.Save(&p.mu, p.Channels); err != nil {
+		return fmt.Errorf("slack: writing the progress file: %v", err)
+	}
+	return nil
+}
+
+func (im *Importer) mapUsers(ctx context.Context, export *Export, result *ImportResult) error {
+	for _, u := range export.Users {
+		if u.Profile.Email == "" {
+			result.UnmappedUsers = append(result.UnmappedUsers, u.ID)
+			continue
+		}
+		account, _, err := im.client.Accounts.SearchAccounts(ctx, u.Profile.Email)
+		if err != nil {
+			if e, ok := err.(*shared.ErrorResponse); ok && e.Response.StatusCode == http.StatusNotFound {
+				result.UnmappedUsers = append(result.UnmappedUsers, u.ID)
+				continue
+			}
+			return fmt.Errorf("slack: searching account of %s: %v", u.Name, err)
+		}
+		if account == nil || account.ID == 0 {
+			result.UnmappedUsers = append(result.UnmappedUsers, u.ID)
+			continue
+		}
+		result.Accounts[u.ID] = account
+	}
+	return nil
+}
+
+func (im *Importer) channels(export *Export, opt *ImportOptions) []*Channel {
+	wanted := map[string]bool{}
+	for _, name := range opt.Channels {
+		wanted[name] = true
+	}
+	var channels []*Channel
+	for _, c := range export.Channels {
+		if len(wanted) == 0 || wanted[c.Name] {
+			channels = append(channels, c)
+		}
+	}
+	sort.Slice(channels, func(i, j int) bool { return channels[i].Name < channels[j].Name })
+	return channels
+}
+
+// createTopic creates the topic of the channel and records it in progress
+// as soon as it exists.
+func (im *Importer) createTopic(ctx context.Context, channel *Channel, opt *ImportOptions, result *ImportResult, progress *importProgress) (*channelProgress, error) {
+	name := channel.Name
+	if opt.TopicName != nil {
+		name = opt.TopicName(channel)
+	}
+	var accountIDs []int
+	for _, member := range channel.Members {
+		if a, ok := result.Accounts[member]; ok {
+			accountIDs = append(accountIDs, a.ID)
+		}
+	}
+	details, _, err := im.client.Topics.CreateTopic(ctx, &v1.CreateTopicOptions{
+		Name:          name,
+		SpaceKey:      opt.SpaceKey,
+		AddAccountIds: accountIDs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("slack: creating topic for #%s: %v", channel.Name, err)
+	}
+	cp := &channelProgress{TopicID: details.Topic.ID, Threads: map[string]int{}}
+	progress.mu.Lock()
+	progress.Channels[channel.Name] = cp
+	progress.mu.Unlock()
+	if err := progress.save(); err != nil {
+		return nil, err
+	}
+	if channel.Purpose != nil && channel.Purpose.Value != "" {
+		_, _, err := im.client.Topics.UpdateTopic(ctx, cp.TopicID, &v1.UpdateTopicOptions{
+			Name:        name,
+			Description: channel.Purpose.Value,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("slack: updating topic for #%s: %v", channel.Name, err)
+		}
+	}
+	return cp, nil
+}
+
+// postMessages posts the messages of the channel after cp.LastTS and records
+// every post in progress.
+func (im *Importer) postMessages(ctx context.Context, channel *Channel, cp *channelProgress, export *Export, opt *ImportOptions, result *ImportResult, progress *importProgress) error {
+	converter := &Mrkdwn{
+		MentionName: func(id string) string {
+			if a, ok := result.Accounts[id]; ok {
+				return a.Name
+			}
+			return ""
+		},
+		UserName: func(id string) string {
+			if u := export.User(id); u != nil {
+				return u.Name
+			}
+			return ""
+		},
+	}
+	loc := opt.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	if cp.Threads == nil {
+		cp.Threads = map[string]int{}
+	}
+	for _, m := range export.Messages[channel.Name] {
+		if m.Type != "message" || skippedSubtypes[m.Subtype] {
+			continue
+		}
+		if cp.LastTS != "" && compareTimestamps(m.TS, cp.LastTS) <= 0 {
+			continue
+		}
+		text := im.header(export, m, loc) + "\n" + converter.Convert(m.Text)
+		postOpt := &v1.PostMessageOptions{}
+		if m.IsReply() {
+			postOpt.ReplyTo = cp.Threads[m.ThreadTS]
+		}
+		if !opt.SkipFiles {
+			for _, f := range m.Files {
+				url := f.URL()
+				if url == "" {
+					continue
+				}
+				if opt.SlackToken == "" {
+					text += "\n" + f.Name + ": " + url
+					continue
+				}
+				key, err := im.attach(ctx, cp.TopicID, f, opt)
+				if err != nil {
+					return fmt.Errorf("slack: importing message %s of #%s: %v", m.TS, channel.Name, err)
+				}
+				postOpt.FileKeys = append(postOpt.FileKeys, key)
+			}
+		}
+		posted, _, err := im.client.Messages.PostMessage(ctx, cp.TopicID, text, postOpt)
+		if err != nil {
+			return fmt.Errorf("slack: importing message %s of #%s: %v", m.TS, channel.Name, err)
+		}
+		result.Posts++
+		progress.mu.Lock()
+		cp.LastTS = m.TS
+		if m.IsReply() {
+			if _, ok := cp.Threads[m.ThreadTS]; ok {
+				cp.Threads[m.ThreadTS] = posted.Post.ID
+			}
+		} else {
+			cp.Threads[m.TS] = posted.Post.ID
+		}
+		progress.mu.Unlock()
+		if err := progress.save(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// attach downloads a file from Slack and uploads it to the topic. It returns
+// the key of the uploaded file.
+func (im *Importer) attach(ctx context.Context, topicID int, f *File, opt *ImportOptions) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, f.URL(), nil)
+	if err != nil {
+		return "", err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Authorization", "Bearer "+opt.SlackToken)
+	httpClient := opt.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("downloading %s: %v", f.Name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("downloading %s: %s", f.Name, resp.Status)
+	}
+	// Slack answers a request it doesn't authorize with its login page.
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") && !strings.HasPrefix(f.Mimetype, "text/html") {
+		return "", fmt.Errorf("downloading %s: the Slack token was not accepted", f.Name)
+	}
+
+	// The upload is named after the path of the file, so the file is written
+	// under its own name in a directory of its own.
+	dir, err := ioutil.TempDir("", "slack")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+	name := filepath.Base(f.Name)
+	if name == "." || name == string(filepath.Separator) {
+		name = f.ID
+	}
+	file, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	if _, err := io.Copy(file, resp.Body); err != nil {
+		return "", fmt.Errorf("downloading %s: %v", f.Name, err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	attached, _, err := im.client.Files.UploadAttachmentFile(ctx, topicID, file)
+	if err != nil {
+		return "", fmt.Errorf("uploading %s: %v", f.Name, err)
+	}
+	return attached.FileKey, nil
+}
+
+func (im *Importer) header(export *Export, m *Message, loc *time.Location) string {
+	name := m.Username
+	if u := export.User(m.User); u != nil {
+		name = u.DisplayName()
+	}
+	if name == "" {
+		name = "unknown"
+	}
+	name = strings.Replace(name, "*", "", -1)
+	return fmt.Sprintf("**%s** %s", name, m.Time().In(loc).Format("2006-01-02 15:04"))
+}
@@ -0,0 +1,293 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	. "github.com/nulab/go-typetalk/v3/typetalk/internal"
+)
+
+type postedForm struct {
+	path string
+	form map[string][]string
+}
+
+func handleImport(t *testing.T) *[]postedForm {
+	var (
+		mu     sync.Mutex
+		posted []postedForm
+		postID = 100
+	)
+	accounts := map[string]string{
+		"alice@example.com": `{"id": 1, "name": "alice"}`,
+		"bob@example.com":   `{"id": 2, "name": "bobby"}`,
+	}
+	mux.HandleFunc("/api/v1/search/accounts", func(w http.ResponseWriter, r *http.Request) {
+		TestMethod(t, r, http.MethodGet)
+		account, ok := accounts[r.URL.Query().Get("nameOrEmailAddress")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, account)
+	})
+	mux.HandleFunc("/api/v1/topics", func(w http.ResponseWriter, r *http.Request) {
+		TestMethod(t, r, http.MethodPost)
+		r.ParseForm()
+		mu.Lock()
+		posted = append(posted, postedForm{r.URL.Path, r.PostForm})
+		mu.Unlock()
+		id := 10
+		if r.PostForm.Get("name") == "slack-random" {
+			id = 11
+		}
+		fmt.Fprintf(w, `{"topic": {"id": %d, "name": %q}}`, id, r.PostForm.Get("name"))
+	})
+	mux.HandleFunc("/api/v1/topics/", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		mu.Lock()
+		defer mu.Unlock()
+		posted = append(posted, postedForm{r.URL.Path, r.PostForm})
+		switch r.Method {
+		case http.MethodPut:
+			fmt.Fprint(w, `{"topic": {"id": 10}}`)
+		case http.MethodPost:
+			postID++
+			fmt.Fprintf(w, `{"post": {"id": %d}}`, postID)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	})
+	return &posted
+}
+
+func Test_Importer_Import_should_create_topics_and_post_messages(t *testing.T) {
+	setup()
+	defer teardown()
+	posted := handleImport(t)
+	export, err := readFixtureExport()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := NewImporter(client).Import(context.Background(), export, &ImportOptions{
+		SpaceKey:  "qwerty",
+		TopicName: func(c *Channel) string { return "slack-" + c.Name },
+	})
+	if err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+	if result.Topics["general"] != 10 || result.Topics["random"] != 11 {
+		t.Errorf("Topics: got %v", result.Topics)
+	}
+	if result.Posts != 5 {
+		t.Errorf("Posts: got %d, want 5", result.Posts)
+	}
+	if len(result.UnmappedUsers) != 1 || result.UnmappedUsers[0] != "U03" {
+		t.Errorf("UnmappedUsers: got %v, want [U03]", result.UnmappedUsers)
+	}
+
+	requests := *posted
+	if len(requests) != 8 {
+		t.Fatalf("requests: got %d, want 8", len(requests))
+	}
+	create := requests[0]
+	if create.form["name"][0] != "slack-general" || create.form["spaceKey"][0] != "qwerty" ||
+		create.form["addAccountIds[0]"][0] != "1" || create.form["addAccountIds[1]"][0] != "2" ||
+		create.form["addAccountIds[2]"] != nil {
+		t.Errorf("create topic parameters: got %v", create.form)
+	}
+	if got := requests[1].form["description"][0]; got != "Company wide announcements" {
+		t.Errorf("description: got %q", got)
+	}
+
+	messages := requests[2:6]
+	if got, want := messages[0].form["message"][0], "**alice** 2020-01-01 00:01\n**Welcome** @bobby! See [the guide](https://example.com/guide)"; got != want {
+		t.Errorf("message:\n got  %q,\n want %q", got, want)
+	}
+	if messages[0].form["replyTo"] != nil {
+		t.Errorf("parent must not be a reply: %v", messages[0].form)
+	}
+	if got := messages[1].form["replyTo"][0]; got != "101" {
+		t.Errorf("first reply: got replyTo %s, want 101", got)
+	}
+	if got := messages[2].form["replyTo"][0]; got != "102" {
+		t.Errorf("second reply: got replyTo %s, want 102", got)
+	}
+	if !strings.Contains(messages[2].form["message"][0], "Late reply & ~~old~~ news") {
+		t.Errorf("message: got %q", messages[2].form["message"][0])
+	}
+	if got := messages[3].form["message"][0]; !strings.HasSuffix(got, "\nreport.pdf: https://files.slack.com/files-pri/T01-F01/download/report.pdf") {
+		t.Errorf("message with a file: got %q", got)
+	}
+	if messages[3].form["attachments[0].fileUrl"] != nil {
+		t.Errorf("attachments: got %v", messages[3].form)
+	}
+	if requests[6].form["name"][0] != "slack-random" {
+		t.Errorf("second topic: got %v", requests[6].form)
+	}
+}
+
+func Test_Importer_Import_should_upload_files_with_slack_token(t *testing.T) {
+	setup()
+	defer teardown()
+	posted := handleImport(t)
+	mux.HandleFunc("/files-pri/T01-F01/download/report.pdf", func(w http.ResponseWriter, r *http.Request) {
+		TestMethod(t, r, http.MethodGet)
+		if got := r.Header.Get("Authorization"); got != "Bearer xoxp-TOKEN" {
+			t.Errorf("Authorization: got %q", got)
+		}
+		w.Header().Set("Content-Type", "application/pdf")
+		fmt.Fprint(w, "%PDF-1.4")
+	})
+	mux.HandleFunc("/api/v1/topics/10/attachments", func(w http.ResponseWriter, r *http.Request) {
+		TestMethod(t, r, http.MethodPost)
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		b, _ := ioutil.ReadAll(file)
+		if filepath.Base(header.Filename) != "report.pdf" || string(b) != "%PDF-1.4" {
+			t.Errorf("upload: got %s %q", header.Filename, b)
+		}
+		fmt.Fprint(w, `{"fileKey": "KEY", "fileName": "report.pdf"}`)
+	})
+	export, err := readFixtureExport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range export.Messages["general"] {
+		for _, f := range m.Files {
+			f.URLPrivateDownload = strings.Replace(f.URLPrivateDownload, "https://files.slack.com", server.URL, 1)
+		}
+	}
+
+	_, err = NewImporter(client).Import(context.Background(), export, &ImportOptions{
+		SpaceKey:   "qwerty",
+		Channels:   []string{"general"},
+		SlackToken: "xoxp-TOKEN",
+	})
+	if err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+	requests := *posted
+	message := requests[len(requests)-1]
+	if got := message.form["fileKeys[0]"]; len(got) != 1 || got[0] != "KEY" {
+		t.Errorf("fileKeys: got %v", message.form)
+	}
+	if strings.Contains(message.form["message"][0], "report.pdf") {
+		t.Errorf("message: got %q", message.form["message"][0])
+	}
+}
+
+func Test_Importer_Import_should_limit_channels(t *testing.T) {
+	setup()
+	defer teardown()
+	posted := handleImport(t)
+	export, err := readFixtureExport()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := NewImporter(client).Import(context.Background(), export, &ImportOptions{
+		SpaceKey:  "qwerty",
+		Channels:  []string{"random"},
+		SkipFiles: true,
+	})
+	if err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+	if len(result.Topics) != 1 || result.Posts != 1 {
+		t.Errorf("result: got %+v", result)
+	}
+	if got := (*posted)[1].form["message"][0]; !strings.HasSuffix(got, "```\nfmt.Println(\"*not bold*\")\n```") {
+		t.Errorf("message: got %q", got)
+	}
+}
+
+func Test_Importer_Import_should_resume_with_progress_file(t *testing.T) {
+	setup()
+	defer teardown()
+	mux.HandleFunc("/api/v1/search/accounts", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	var created []string
+	mux.HandleFunc("/api/v1/topics", func(w http.ResponseWriter, r *http.Request) {
+		created = append(created, r.FormValue("name"))
+		fmt.Fprintf(w, `{"topic": {"id": %d}}`, 9+len(created))
+	})
+	failing := true
+	var replies []string
+	postID := 100
+	mux.HandleFunc("/api/v1/topics/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			fmt.Fprint(w, `{"topic": {"id": 10}}`)
+			return
+		}
+		if failing && strings.Contains(r.FormValue("message"), "Late reply") {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		postID++
+		replies = append(replies, r.FormValue("replyTo"))
+		fmt.Fprintf(w, `{"post": {"id": %d}}`, postID)
+	})
+	export, err := readFixtureExport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, _ := ioutil.TempDir("", "slack")
+	defer os.RemoveAll(dir)
+	opt := &ImportOptions{SpaceKey: "qwerty", ProgressFile: filepath.Join(dir, "progress.json")}
+
+	_, err = NewImporter(client).Import(context.Background(), export, opt)
+	if err == nil || !strings.Contains(err.Error(), "message 1577923200.000100 of #general") {
+		t.Fatalf("Returned error: %v", err)
+	}
+
+	failing = false
+	result, err := NewImporter(client).Import(context.Background(), export, opt)
+	if err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+	if want := []string{"general", "random"}; !reflect.DeepEqual(created, want) {
+		t.Errorf("created topics: got %v, want %v", created, want)
+	}
+	if result.Topics["general"] != 10 || result.Topics["random"] != 11 || result.Posts != 3 {
+		t.Errorf("result: got %+v", result)
+	}
+	// The late reply answers the reply posted by the first run.
+	if want := []string{"", "101", "102", "", ""}; !reflect.DeepEqual(replies, want) {
+		t.Errorf("replyTo: got %v, want %v", replies, want)
+	}
+}
+
+func Test_Importer_Import_should_require_space_key(t *testing.T) {
+	if _, err := NewImporter(client).Import(context.Background(), &Export{}, nil); err == nil {
+		t.Error("Expected error to be returned")
+	}
+}
+
+func Test_Importer_Import_errorResponse(t *testing.T) {
+	setup()
+	defer teardown()
+	mux.HandleFunc("/api/v1/search/accounts", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	export, err := readFixtureExport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewImporter(client).Import(context.Background(), export, &ImportOptions{SpaceKey: "qwerty"}); err == nil {
+		t.Error("Expected error to be returned")
+	}
+}
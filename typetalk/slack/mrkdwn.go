@@ -0,0 +1,191 @@
+package slack
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Mrkdwn converts Slack mrkdwn into Typetalk message text.
+type Mrkdwn struct {
+	// MentionName maps a Slack user ID to a Typetalk account name. When it
+	// is nil or returns an empty string the mention is kept as plain text.
+	MentionName func(userID string) string
+	// UserName maps a Slack user ID to a readable name used when the user
+	// can't be mentioned.
+	UserName func(userID string) string
+}
+
+// ConvertMrkdwn converts Slack mrkdwn into Typetalk message text without
+// resolving user mentions.
+func ConvertMrkdwn(text string) string {
+	return (&Mrkdwn{}).Convert(text)
+}
+
+var (
+	codeBlockPattern  = regexp.MustCompile("(?s)```(.*?)```")
+	inlineCodePattern = regexp.MustCompile("`[^`\n]+`")
+	entityPattern     = regexp.MustCompile(`<([^<>\n]+)>`)
+	htmlUnescaper     = strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&")
+)
+
+// Convert converts Slack mrkdwn into Typetalk message text.
+//
+// Bold, italic and strikethrough are rewritten into their Typetalk markdown
+// equivalents, links and mentions are expanded and code is left untouched.
+func (m *Mrkdwn) Convert(text string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range codeBlockPattern.FindAllStringSubmatchIndex(text, -1) {
+		b.WriteString(m.convertInline(text[last:loc[0]]))
+		code := strings.Trim(htmlUnescaper.Replace(text[loc[2]:loc[3]]), "\n")
+		if loc[0] > 0 && text[loc[0]-1] != '\n' {
+			b.WriteString("\n")
+		}
+		b.WriteString("```\n" + code + "\n```")
+		if loc[1] < len(text) && text[loc[1]] != '\n' {
+			b.WriteString("\n")
+		}
+		last = loc[1]
+	}
+	b.WriteString(m.convertInline(text[last:]))
+	return b.String()
+}
+
+func (m *Mrkdwn) convertInline(text string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range inlineCodePattern.FindAllStringIndex(text, -1) {
+		b.WriteString(m.convertText(text[last:loc[0]]))
+		b.WriteString(htmlUnescaper.Replace(text[loc[0]:loc[1]]))
+		last = loc[1]
+	}
+	b.WriteString(m.convertText(text[last:]))
+	return b.String()
+}
+
+func (m *Mrkdwn) convertText(text string) string {
+	// Entities are swapped for placeholders first so that formatting
+	// characters in URLs and names are not mistaken for markup.
+	var entities []string
+	text = entityPattern.ReplaceAllStringFunc(text, func(s string) string {
+		entities = append(entities, m.convertEntity(s[1:len(s)-1]))
+		return "\x00" + strconv.Itoa(len(entities)-1) + "\x00"
+	})
+	text = convertEmphasis(text, '*', "**")
+	text = convertEmphasis(text, '_', "*")
+	text = convertEmphasis(text, '~', "~~")
+	text = htmlUnescaper.Replace(text)
+	for i, e := range entities {
+		text = strings.Replace(text, "\x00"+strconv.Itoa(i)+"\x00", e, 1)
+	}
+	return text
+}
+
+func (m *Mrkdwn) convertEntity(s string) string {
+	body, label := s, ""
+	if i := strings.Index(s, "|"); i >= 0 {
+		body, label = s[:i], s[i+1:]
+	}
+	switch {
+	case strings.HasPrefix(body, "@"):
+		id := body[1:]
+		if m.MentionName != nil {
+			if name := m.MentionName(id); name != "" {
+				return "@" + name
+			}
+		}
+		if label != "" {
+			return "@" + label
+		}
+		if m.UserName != nil {
+			if name := m.UserName(id); name != "" {
+				return "@" + name
+			}
+		}
+		return "@" + id
+	case strings.HasPrefix(body, "#"):
+		if label != "" {
+			return "#" + label
+		}
+		return body
+	case strings.HasPrefix(body, "!"):
+		cmd := body[1:]
+		switch {
+		case cmd == "here" || cmd == "channel" || cmd == "everyone":
+			return "@" + cmd
+		case label != "":
+			return htmlUnescaper.Replace(label)
+		}
+		return "@" + strings.SplitN(cmd, "^", 2)[0]
+	}
+	url := htmlUnescaper.Replace(body)
+	if strings.HasPrefix(url, "mailto:") {
+		if label != "" {
+			return htmlUnescaper.Replace(label)
+		}
+		return strings.TrimPrefix(url, "mailto:")
+	}
+	if label == "" || label == url {
+		return url
+	}
+	return "[" + htmlUnescaper.Replace(label) + "](" + url + ")"
+}
+
+// convertEmphasis rewrites text surrounded by delim, such as *bold*, into
+// text surrounded by repl. Like Slack, the delimiters must not be part of a
+// word and the enclosed text must not start or end with a space.
+func convertEmphasis(text string, delim byte, repl string) string {
+	var b strings.Builder
+	i := 0
+	for i < len(text) {
+		if text[i] != delim || !isBoundary(text, i-1, delim, true) {
+			b.WriteByte(text[i])
+			i++
+			continue
+		}
+		end := findClosing(text, i, delim)
+		if end < 0 {
+			b.WriteByte(text[i])
+			i++
+			continue
+		}
+		b.WriteString(repl)
+		b.WriteString(text[i+1 : end])
+		b.WriteString(repl)
+		i = end + 1
+	}
+	return b.String()
+}
+
+func findClosing(text string, start int, delim byte) int {
+	if start+1 >= len(text) || text[start+1] == ' ' || text[start+1] == delim {
+		return -1
+	}
+	for j := start + 1; j < len(text); j++ {
+		switch text[j] {
+		case '\n':
+			return -1
+		case delim:
+			if text[j-1] != ' ' && isBoundary(text, j+1, delim, false) {
+				return j
+			}
+		}
+	}
+	return -1
+}
+
+func isBoundary(text string, i int, delim byte, before bool) bool {
+	if i < 0 || i >= len(text) {
+		return true
+	}
+	var r rune
+	if before {
+		r, _ = utf8.DecodeLastRuneInString(text[:i+1])
+	} else {
+		r, _ = utf8.DecodeRuneInString(text[i:])
+	}
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != rune(delim)
+}
@@ -0,0 +1,48 @@
+package slack
+
+import "testing"
+
+func Test_Mrkdwn_Convert(t *testing.T) {
+	m := &Mrkdwn{
+		MentionName: func(id string) string {
+			if id == "U01" {
+				return "alice"
+			}
+			return ""
+		},
+		UserName: func(id string) string {
+			if id == "U02" {
+				return "bob"
+			}
+			return ""
+		},
+	}
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"*bold* and _italic_ and ~strike~", "**bold** and *italic* and ~~strike~~"},
+		{"*_both_*", "***both***"},
+		{"snake_case_name and 2*3*4", "snake_case_name and 2*3*4"},
+		{"* not bold *", "* not bold *"},
+		{"hi <@U01>, <@U02> and <@U09|carol>", "hi @alice, @bob and @carol"},
+		{"<!here> see <#C01|general>", "@here see #general"},
+		{"<https://example.com/a_b_c|a *link*>", "[a *link*](https://example.com/a_b_c)"},
+		{"<https://example.com/a_b_c>", "https://example.com/a_b_c"},
+		{"<mailto:bob@example.com|bob@example.com>", "bob@example.com"},
+		{"&gt; quoted &amp; escaped &lt;tag&gt;", "> quoted & escaped <tag>"},
+		{"run `*x*` now", "run `*x*` now"},
+		{"see ```a *b*``` end", "see \n```\na *b*\n```\n end"},
+	}
+	for _, tt := range tests {
+		if got := m.Convert(tt.in); got != tt.want {
+			t.Errorf("Convert(%q):\n got  %q,\n want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func Test_ConvertMrkdwn_should_keep_unknown_mentions(t *testing.T) {
+	if got, want := ConvertMrkdwn("<@U01>"), "@U01"; got != want {
+		t.Errorf("ConvertMrkdwn: got %q, want %q", got, want)
+	}
+}
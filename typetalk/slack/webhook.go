@@ -0,0 +1,363 @@
+package slack
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+)
+
+// WebhookMessage represents the payload of a Slack incoming webhook.
+type WebhookMessage struct {
+	Text        string        `json:"text"`
+	Username    string        `json:"username"`
+	Mrkdwn      *bool         `json:"mrkdwn"`
+	Attachments []*Attachment `json:"attachments"`
+	Blocks      []*Block      `json:"blocks"`
+}
+
+// Attachment represents a legacy message attachment.
+type Attachment struct {
+	Fallback   string             `json:"fallback"`
+	Color      string             `json:"color"`
+	Pretext    string             `json:"pretext"`
+	AuthorName string             `json:"author_name"`
+	AuthorLink string             `json:"author_link"`
+	Title      string             `json:"title"`
+	TitleLink  string             `json:"title_link"`
+	Text       string             `json:"text"`
+	Fields     []*AttachmentField `json:"fields"`
+	ImageURL   string             `json:"image_url"`
+	Footer     string             `json:"footer"`
+	TS         json.Number        `json:"ts"`
+	Blocks     []*Block           `json:"blocks"`
+}
+
+// AttachmentField represents a field of an attachment.
+type AttachmentField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+// TextObject represents a Block Kit text object.
+type TextObject struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// BlockElement represents an element of a context block. It is either a
+// text object or an image element.
+type BlockElement struct {
+	Type     string `json:"type"`
+	Text     string `json:"text"`
+	ImageURL string `json:"image_url"`
+	AltText  string `json:"alt_text"`
+}
+
+// Block represents a Block Kit layout block. The section, header, context,
+// divider and image blocks are supported; other blocks are ignored.
+type Block struct {
+	Type     string          `json:"type"`
+	Text     *TextObject     `json:"text"`
+	Fields   []*TextObject   `json:"fields"`
+	Elements []*BlockElement `json:"elements"`
+	ImageURL string          `json:"image_url"`
+	AltText  string          `json:"alt_text"`
+	Title    *TextObject     `json:"title"`
+}
+
+// Typetalk renders the webhook payload as Typetalk message text.
+//
+// When blocks are present the top-level text is treated as the
+// notification fallback and is not rendered, as Slack does.
+func (m *WebhookMessage) Typetalk(conv *Mrkdwn) string {
+	if conv == nil {
+		conv = &Mrkdwn{}
+	}
+	var parts []string
+	if len(m.Blocks) > 0 {
+		parts = append(parts, renderBlocks(m.Blocks, conv)...)
+	} else if m.Text != "" {
+		if m.Mrkdwn != nil && !*m.Mrkdwn {
+			parts = append(parts, htmlUnescaper.Replace(m.Text))
+		} else {
+			parts = append(parts, conv.Convert(m.Text))
+		}
+	}
+	for _, a := range m.Attachments {
+		if s := renderAttachment(a, conv); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+func renderText(t *TextObject, conv *Mrkdwn) string {
+	if t == nil {
+		return ""
+	}
+	if t.Type == "mrkdwn" {
+		return conv.Convert(t.Text)
+	}
+	return t.Text
+}
+
+func renderBlocks(blocks []*Block, conv *Mrkdwn) []string {
+	var parts []string
+	for _, b := range blocks {
+		var lines []string
+		switch b.Type {
+		case "header":
+			lines = append(lines, "**"+renderText(b.Text, conv)+"**")
+		case "section":
+			if s := renderText(b.Text, conv); s != "" {
+				lines = append(lines, s)
+			}
+			for _, f := range b.Fields {
+				lines = append(lines, renderText(f, conv))
+			}
+		case "context":
+			var elements []string
+			for _, e := range b.Elements {
+				if e.Type == "image" {
+					continue
+				}
+				elements = append(elements, renderText(&TextObject{Type: e.Type, Text: e.Text}, conv))
+			}
+			if len(elements) > 0 {
+				lines = append(lines, strings.Join(elements, " | "))
+			}
+		case "divider":
+			lines = append(lines, "----")
+		case "image":
+			if s := renderText(b.Title, conv); s != "" {
+				lines = append(lines, s)
+			}
+			lines = append(lines, b.ImageURL)
+		}
+		if len(lines) > 0 {
+			parts = append(parts, strings.Join(lines, "\n"))
+		}
+	}
+	return parts
+}
+
+func renderAttachment(a *Attachment, conv *Mrkdwn) string {
+	var lines []string
+	if a.Pretext != "" {
+		lines = append(lines, conv.Convert(a.Pretext))
+	}
+	if a.AuthorName != "" {
+		if a.AuthorLink != "" {
+			lines = append(lines, "["+a.AuthorName+"]("+a.AuthorLink+")")
+		} else {
+			lines = append(lines, a.AuthorName)
+		}
+	}
+	if a.Title != "" {
+		if a.TitleLink != "" {
+			lines = append(lines, "**["+a.Title+"]("+a.TitleLink+")**")
+		} else {
+			lines = append(lines, "**"+a.Title+"**")
+		}
+	}
+	if a.Text != "" {
+		lines = append(lines, conv.Convert(a.Text))
+	}
+	for _, f := range a.Fields {
+		lines = append(lines, "**"+f.Title+"**: "+conv.Convert(f.Value))
+	}
+	if len(a.Blocks) > 0 {
+		lines = append(lines, renderBlocks(a.Blocks, conv)...)
+	}
+	if a.ImageURL != "" {
+		lines = append(lines, a.ImageURL)
+	}
+	footer := a.Footer
+	if ts, err := a.TS.Int64(); err == nil && ts > 0 {
+		if footer != "" {
+			footer += " | "
+		}
+		footer += time.Unix(ts, 0).UTC().Format("2006-01-02 15:04 MST")
+	}
+	if footer != "" {
+		lines = append(lines, footer)
+	}
+	if len(lines) == 0 && a.Fallback != "" {
+		lines = append(lines, a.Fallback)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// WebhookTopic configures a topic messages can be posted to.
+type WebhookTopic struct {
+	TopicID int `json:"topicId"`
+	// Token is the Typetalk Token of the bot that posts to the topic.
+	Token string `json:"token"`
+	// Path is the URL path the webhook is served under. "/<topicId>" is
+	// used when it is empty.
+	Path string `json:"path"`
+	// Secret is the last segment of the URL path, "/<path>/<secret>", so
+	// that the URL of a topic can't be guessed from its ID. It must be at
+	// least MinWebhookSecretLength characters long; NewWebhookSecret
+	// returns a random one.
+	Secret string `json:"secret"`
+}
+
+// MinWebhookSecretLength is the minimum length of WebhookTopic.Secret.
+const MinWebhookSecretLength = 16
+
+// NewWebhookSecret returns a random secret for WebhookTopic.Secret.
+func NewWebhookSecret() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// WebhookConfig configures a WebhookHandler.
+type WebhookConfig struct {
+	Topics []*WebhookTopic `json:"topics"`
+}
+
+// LoadWebhookConfig reads a WebhookConfig from a JSON file.
+func LoadWebhookConfig(name string) (*WebhookConfig, error) {
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	config := &WebhookConfig{}
+	if err := json.Unmarshal(b, config); err != nil {
+		return nil, fmt.Errorf("slack: parsing %s: %v", name, err)
+	}
+	return config, nil
+}
+
+// DefaultMaxPayloadSize is the size limit of a webhook payload when
+// WebhookHandler.MaxPayloadSize is zero. Slack truncates the text of a
+// message at 40,000 characters, so a payload Slack would post fits in it.
+const DefaultMaxPayloadSize = 1 << 20
+
+type webhookTarget struct {
+	topicID int
+	client  *v1.Client
+}
+
+// WebhookHandler is an http.Handler that accepts Slack incoming webhook
+// payloads and posts them to the topic chosen by the URL path. Requests to
+// a path without the secret of its topic are answered with 404 Not Found.
+type WebhookHandler struct {
+	targets map[string]*webhookTarget
+	// ErrorLog is used to log failed posts. The standard logger is used
+	// when it is nil.
+	ErrorLog *log.Logger
+	// MaxPayloadSize is the size limit of a request body in bytes. Larger
+	// bodies are rejected with 413 Request Entity Too Large.
+	// DefaultMaxPayloadSize is used when it is zero.
+	MaxPayloadSize int64
+}
+
+// NewWebhookHandler returns a WebhookHandler for the configured topics.
+// httpClient is used to call the Typetalk API; http.DefaultClient is used
+// when it is nil.
+func NewWebhookHandler(config *WebhookConfig, httpClient *http.Client) (*WebhookHandler, error) {
+	h := &WebhookHandler{targets: map[string]*webhookTarget{}}
+	for _, t := range config.Topics {
+		if t.TopicID == 0 || t.Token == "" {
+			return nil, fmt.Errorf("slack: topic id and token are required (topic %d)", t.TopicID)
+		}
+		if len(t.Secret) < MinWebhookSecretLength || strings.Contains(t.Secret, "/") {
+			return nil, fmt.Errorf("slack: topic %d needs a secret of at least %d characters without /", t.TopicID, MinWebhookSecretLength)
+		}
+		p := t.Path
+		if p == "" {
+			p = strconv.Itoa(t.TopicID)
+		}
+		p = strings.Trim(p, "/") + "/" + t.Secret
+		if _, ok := h.targets[p]; ok {
+			return nil, fmt.Errorf("slack: topic %d has the path and secret of another topic", t.TopicID)
+		}
+		h.targets[p] = &webhookTarget{
+			topicID: t.TopicID,
+			client:  v1.NewClient(httpClient).SetTypetalkToken(t.Token),
+		}
+	}
+	return h, nil
+}
+
+// ServeHTTP posts the webhook payload in r. It answers like Slack does:
+// "ok" on success and a short error code otherwise.
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "invalid_method", http.StatusMethodNotAllowed)
+		return
+	}
+	target, ok := h.targets[strings.Trim(r.URL.Path, "/")]
+	if !ok {
+		http.Error(w, "no_service", http.StatusNotFound)
+		return
+	}
+	limit := h.MaxPayloadSize
+	if limit == 0 {
+		limit = DefaultMaxPayloadSize
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, limit))
+	if err != nil {
+		if int64(len(body)) >= limit {
+			http.Error(w, "payload_too_large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "invalid_payload", http.StatusBadRequest)
+		return
+	}
+	m, err := parseWebhookMessage(r.Header.Get("Content-Type"), body)
+	if err != nil {
+		http.Error(w, "invalid_payload", http.StatusBadRequest)
+		return
+	}
+	text := m.Typetalk(nil)
+	if text == "" {
+		http.Error(w, "no_text", http.StatusBadRequest)
+		return
+	}
+	if _, _, err := target.client.Messages.PostMessage(r.Context(), target.topicID, text, nil); err != nil {
+		h.logf("slack: posting to topic %d: %v", target.topicID, err)
+		http.Error(w, "posting_failed", http.StatusBadGateway)
+		return
+	}
+	fmt.Fprint(w, "ok")
+}
+
+func (h *WebhookHandler) logf(format string, args ...interface{}) {
+	if h.ErrorLog != nil {
+		h.ErrorLog.Printf(format, args...)
+	} else {
+		log.Printf(format, args...)
+	}
+}
+
+func parseWebhookMessage(contentType string, body []byte) (*WebhookMessage, error) {
+	if strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil, err
+		}
+		body = []byte(form.Get("payload"))
+	}
+	m := &WebhookMessage{}
+	if err := json.Unmarshal(body, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
@@ -0,0 +1,194 @@
+package slack
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	. "github.com/nulab/go-typetalk/v3/typetalk/internal"
+)
+
+func Test_WebhookMessage_Typetalk_should_render_attachments(t *testing.T) {
+	b, _ := ioutil.ReadFile(fixturesPath + "webhook-attachments.json")
+	var m *WebhookMessage
+	if err := json.Unmarshal(b, &m); err != nil {
+		t.Fatal(err)
+	}
+	want := "Build [#42](https://ci.example.com/builds/42) **failed**\n\n" +
+		"ci-bot\n" +
+		"**[go-typetalk master](https://ci.example.com/builds/42)**\n" +
+		"`go test ./...` exited with *1*\n" +
+		"**Branch**: master\n" +
+		"**Commit**: [abc](https://example.com/c/abc)\n" +
+		"CI | 2020-01-01 00:00 UTC"
+	if got := m.Typetalk(nil); got != want {
+		t.Errorf("Typetalk:\n got  %q,\n want %q", got, want)
+	}
+}
+
+func Test_WebhookMessage_Typetalk_should_render_blocks(t *testing.T) {
+	b, _ := ioutil.ReadFile(fixturesPath + "webhook-blocks.json")
+	var m *WebhookMessage
+	if err := json.Unmarshal(b, &m); err != nil {
+		t.Fatal(err)
+	}
+	want := "**Deploy finished**\n\n" +
+		"**api** was deployed to *production*\n**Version**\nv1.2.3\nby *alice*\n\n" +
+		"----\n\n" +
+		"took ~~5~~ 3 minutes\n\n" +
+		"Latency\nhttps://example.com/graph.png"
+	if got := m.Typetalk(nil); got != want {
+		t.Errorf("Typetalk:\n got  %q,\n want %q", got, want)
+	}
+}
+
+func Test_WebhookMessage_Typetalk_should_not_convert_when_mrkdwn_is_disabled(t *testing.T) {
+	disabled := false
+	m := &WebhookMessage{Text: "*as is* &amp;", Mrkdwn: &disabled}
+	if got, want := m.Typetalk(nil), "*as is* &"; got != want {
+		t.Errorf("Typetalk: got %q, want %q", got, want)
+	}
+}
+
+func newTestWebhookHandler(t *testing.T) *WebhookHandler {
+	h, err := NewWebhookHandler(&WebhookConfig{Topics: []*WebhookTopic{
+		{TopicID: 1, Token: "TOKEN1", Secret: "0123456789abcdef"},
+		{TopicID: 2, Token: "TOKEN2", Path: "/hooks/deploy/", Secret: "fedcba9876543210"},
+	}}, NewTestClient(server))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func Test_WebhookHandler_should_post_to_the_topic_of_the_path(t *testing.T) {
+	setup()
+	defer teardown()
+	mux.HandleFunc("/api/v1/topics/2", func(w http.ResponseWriter, r *http.Request) {
+		TestMethod(t, r, http.MethodPost)
+		TestHeader(t, r, "X-Typetalk-Token", "TOKEN2")
+		TestFormValues(t, r, Values{"message": "**hello**"})
+		fmt.Fprint(w, `{"post": {"id": 1}}`)
+	})
+	h := newTestWebhookHandler(t)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/hooks/deploy/fedcba9876543210", strings.NewReader(`{"text": "*hello*"}`)))
+	if w.Code != http.StatusOK || w.Body.String() != "ok" {
+		t.Errorf("response: got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func Test_WebhookHandler_should_accept_form_payload(t *testing.T) {
+	setup()
+	defer teardown()
+	mux.HandleFunc("/api/v1/topics/1", func(w http.ResponseWriter, r *http.Request) {
+		TestHeader(t, r, "X-Typetalk-Token", "TOKEN1")
+		TestFormValues(t, r, Values{"message": "hi"})
+		fmt.Fprint(w, `{"post": {"id": 1}}`)
+	})
+	h := newTestWebhookHandler(t)
+
+	form := url.Values{"payload": {`{"text": "hi"}`}}
+	req := httptest.NewRequest(http.MethodPost, "/1/0123456789abcdef", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("response: got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func Test_WebhookHandler_should_reject_bad_requests(t *testing.T) {
+	setup()
+	defer teardown()
+	mux.HandleFunc("/api/v1/topics/1", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	h := newTestWebhookHandler(t)
+	h.ErrorLog = log.New(ioutil.Discard, "", 0)
+
+	tests := []struct {
+		method, path, body string
+		want               int
+	}{
+		{http.MethodGet, "/1/0123456789abcdef", "", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/3", `{"text": "hi"}`, http.StatusNotFound},
+		{http.MethodPost, "/1", `{"text": "hi"}`, http.StatusNotFound},
+		{http.MethodPost, "/1/fedcba9876543210", `{"text": "hi"}`, http.StatusNotFound},
+		{http.MethodPost, "/1/0123456789abcdef", `{`, http.StatusBadRequest},
+		{http.MethodPost, "/1/0123456789abcdef", `{}`, http.StatusBadRequest},
+		{http.MethodPost, "/1/0123456789abcdef", `{"text": "hi"}`, http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+		if w.Code != tt.want {
+			t.Errorf("%s %s %s: got %d, want %d", tt.method, tt.path, tt.body, w.Code, tt.want)
+		}
+	}
+
+	h.MaxPayloadSize = 10
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/1/0123456789abcdef", strings.NewReader(`{"text": "hello"}`)))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("POST /1 of a large payload: got %d, want %d", w.Code, http.StatusRequestEntityTooLarge)
+	}
+}
+
+func Test_NewWebhookHandler_should_validate_config(t *testing.T) {
+	configs := []*WebhookConfig{
+		{Topics: []*WebhookTopic{{TopicID: 1, Secret: "0123456789abcdef"}}},
+		{Topics: []*WebhookTopic{{TopicID: 1, Token: "a"}}},
+		{Topics: []*WebhookTopic{{TopicID: 1, Token: "a", Secret: "short"}}},
+		{Topics: []*WebhookTopic{{TopicID: 1, Token: "a", Secret: "0123456789/abcdef"}}},
+		{Topics: []*WebhookTopic{
+			{TopicID: 1, Token: "a", Secret: "0123456789abcdef"},
+			{TopicID: 2, Token: "b", Path: "1", Secret: "0123456789abcdef"},
+		}},
+	}
+	for _, c := range configs {
+		if _, err := NewWebhookHandler(c, nil); err == nil {
+			t.Errorf("Expected error to be returned for %+v", c.Topics)
+		}
+	}
+}
+
+func Test_NewWebhookSecret(t *testing.T) {
+	a, err := NewWebhookSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := NewWebhookSecret()
+	if len(a) < MinWebhookSecretLength || a == b {
+		t.Errorf("NewWebhookSecret: got %q and %q", a, b)
+	}
+}
+
+func Test_LoadWebhookConfig(t *testing.T) {
+	f, err := ioutil.TempFile("", "webhook-*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	fmt.Fprint(f, `{"topics": [{"topicId": 1, "token": "TOKEN", "path": "hooks/a", "secret": "0123456789abcdef"}]}`)
+	f.Close()
+
+	config, err := LoadWebhookConfig(f.Name())
+	if err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+	if len(config.Topics) != 1 || config.Topics[0].Path != "hooks/a" || config.Topics[0].Secret != "0123456789abcdef" {
+		t.Errorf("config: got %+v", config.Topics)
+	}
+	if _, err := LoadWebhookConfig(fixturesPath + "export/general"); err == nil {
+		t.Error("Expected error to be returned")
+	}
+}
@@ -0,0 +1,304 @@
+package typetalktest
+
+import (
+	"fmt"
+	"time"
+
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+	v2 "github.com/nulab/go-typetalk/v3/typetalk/v2"
+	v4 "github.com/nulab/go-typetalk/v3/typetalk/v4"
+	v5 "github.com/nulab/go-typetalk/v3/typetalk/v5"
+)
+
+// The responses are built from the types of the client packages, so that
+// they always decode into them.
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func (st *state) v1Account(id int) *v1.Account {
+	a := st.accounts[id]
+	if a == nil {
+		return nil
+	}
+	return &v1.Account{
+		ID:         a.id,
+		Name:       a.name,
+		FullName:   a.fullName,
+		Suggestion: a.fullName,
+		ImageURL:   fmt.Sprintf("https://typetalk.com/accounts/%d/profile_image.png", a.id),
+		IsBot:      a.isBot,
+		Lang:       a.lang,
+		TimezoneID: a.timezone,
+		CreatedAt:  timePtr(a.createdAt),
+		UpdatedAt:  timePtr(a.createdAt),
+	}
+}
+
+func (st *state) v2Account(id int) *v2.Account {
+	a := st.v1Account(id)
+	if a == nil {
+		return nil
+	}
+	return &v2.Account{
+		ID:         a.ID,
+		Name:       a.Name,
+		FullName:   a.FullName,
+		Suggestion: a.Suggestion,
+		ImageURL:   a.ImageURL,
+		IsBot:      a.IsBot,
+		CreatedAt:  a.CreatedAt,
+		UpdatedAt:  a.UpdatedAt,
+	}
+}
+
+func (st *state) v1Status(id int) *v1.Status {
+	presence, ok := st.presences[id]
+	if !ok {
+		return &v1.Status{}
+	}
+	return &v1.Status{Presence: &presence}
+}
+
+func (st *state) v2Status(id int) *v2.Status {
+	return &v2.Status{Presence: st.v1Status(id).Presence}
+}
+
+func (st *state) v4AccountStatus(id int) *v4.AccountStatus {
+	a := st.v2Account(id)
+	if a == nil {
+		return nil
+	}
+	return &v4.AccountStatus{
+		Account: &v4.Account{
+			ID:         a.ID,
+			Name:       a.Name,
+			FullName:   a.FullName,
+			Suggestion: a.Suggestion,
+			ImageURL:   a.ImageURL,
+			IsBot:      a.IsBot,
+			CreatedAt:  a.CreatedAt,
+			UpdatedAt:  a.UpdatedAt,
+		},
+		Status: &v4.Status{Presence: st.v1Status(id).Presence},
+	}
+}
+
+func (st *state) v1AccountStatus(id int) *v1.AccountStatus {
+	return &v1.AccountStatus{Account: st.v1Account(id), Status: st.v1Status(id)}
+}
+
+func (st *state) v1Space(key string) *v1.Space {
+	s := st.space(key)
+	if s == nil {
+		return nil
+	}
+	return &v1.Space{Key: s.key, Name: s.name, Enabled: true}
+}
+
+func (st *state) v2Space(key string) *v2.Space {
+	s := st.v1Space(key)
+	if s == nil {
+		return nil
+	}
+	return &v2.Space{Key: s.Key, Name: s.Name, Enabled: s.Enabled}
+}
+
+func (st *state) v5Space(key string) *v5.Space {
+	s := st.v1Space(key)
+	if s == nil {
+		return nil
+	}
+	return &v5.Space{Key: s.Key, Name: s.Name, Enabled: s.Enabled}
+}
+
+func (st *state) organization(key string) *v1.Organization {
+	return &v1.Organization{Space: st.v1Space(key), MyRole: "ADMIN", MyPlan: &v1.MyPlan{Enabled: true}}
+}
+
+func (st *state) v1Group(id int) *v1.Group {
+	g := st.groups[id]
+	return &v1.Group{
+		ID:         g.id,
+		Key:        g.key,
+		Name:       g.name,
+		Suggestion: g.name,
+		CreatedAt:  timePtr(g.createdAt),
+		UpdatedAt:  timePtr(g.createdAt),
+	}
+}
+
+func (st *state) v1Topic(t *topic) *v1.Topic {
+	if t == nil {
+		return nil
+	}
+	return &v1.Topic{
+		ID:              t.id,
+		Name:            t.name,
+		Description:     t.description,
+		Suggestion:      t.name,
+		IsDirectMessage: t.dm,
+		LastPostedAt:    t.lastPostedAt,
+		CreatedAt:       timePtr(t.createdAt),
+		UpdatedAt:       timePtr(t.updatedAt),
+	}
+}
+
+func (st *state) v2Topic(t *topic) *v2.Topic {
+	v := &v2.Topic{
+		ID:              t.id,
+		Name:            t.name,
+		Description:     t.description,
+		Suggestion:      t.name,
+		IsDirectMessage: t.dm,
+		CreatedAt:       t.createdAt,
+		UpdatedAt:       t.updatedAt,
+	}
+	if t.lastPostedAt != nil {
+		v.LastPostedAt = *t.lastPostedAt
+	}
+	return v
+}
+
+func v1Attachment(a *attachment) *v1.AttachmentFile {
+	return &v1.AttachmentFile{ContentType: a.contentType, FileKey: a.key, FileName: a.name, FileSize: len(a.data)}
+}
+
+func (st *state) v1Like(l *like) *v1.Like {
+	return &v1.Like{
+		ID:        l.id,
+		PostID:    l.postID,
+		TopicID:   l.topicID,
+		Comment:   l.comment,
+		Account:   st.v1Account(l.accountID),
+		CreatedAt: timePtr(l.createdAt),
+	}
+}
+
+func (st *state) v1Talk(t *talk) *v1.Talk {
+	return &v1.Talk{
+		ID:         t.id,
+		TopicID:    t.topicID,
+		Name:       t.name,
+		Suggestion: t.name,
+		CreatedAt:  timePtr(t.createdAt),
+		UpdatedAt:  timePtr(t.updatedAt),
+	}
+}
+
+func (st *state) v1Post(p *post) *v1.Post {
+	if p == nil {
+		return nil
+	}
+	v := &v1.Post{
+		ID:          p.id,
+		TopicID:     p.topicID,
+		ReplyTo:     p.replyTo,
+		Message:     p.message,
+		Account:     st.v1Account(p.accountID),
+		Attachments: []*v1.AttachmentFile{},
+		Likes:       []*v1.Like{},
+		Talks:       []*v1.Talk{},
+		Links:       []*v1.Link{},
+		CreatedAt:   timePtr(p.createdAt),
+		UpdatedAt:   timePtr(p.updatedAt),
+	}
+	for _, a := range p.attachments {
+		v.Attachments = append(v.Attachments, v1Attachment(a))
+	}
+	for _, l := range st.postLikes(p.id) {
+		v.Likes = append(v.Likes, st.v1Like(l))
+	}
+	for _, t := range st.postTalks(p.id) {
+		v.Talks = append(v.Talks, st.v1Talk(t))
+	}
+	return v
+}
+
+func (st *state) v1Posts(posts []*post) []*v1.Post {
+	out := []*v1.Post{}
+	for _, p := range posts {
+		out = append(out, st.v1Post(p))
+	}
+	return out
+}
+
+func (st *state) v2Post(p *post, me int) *v2.Post {
+	t := st.topics[p.topicID]
+	v := &v2.Post{
+		ID:          p.id,
+		TopicID:     p.topicID,
+		Topic:       *st.v2Topic(t),
+		ReplyTo:     p.replyTo,
+		Message:     p.message,
+		Account:     *st.v2Account(p.accountID),
+		Attachments: []*v2.AttachmentFile{},
+		Links:       []*v2.Link{},
+		CreatedAt:   p.createdAt,
+		UpdatedAt:   p.updatedAt,
+	}
+	for _, a := range p.attachments {
+		v.Attachments = append(v.Attachments, &v2.AttachmentFile{ContentType: a.contentType, FileKey: a.key, FileName: a.name, FileSize: len(a.data)})
+	}
+	if t.dm {
+		v.DirectMessage = &v2.DirectMessage{Account: st.v2Account(st.otherMember(t, me))}
+	}
+	return v
+}
+
+func (st *state) v1Mention(m *mention) *v1.Mention {
+	return &v1.Mention{ID: m.id, ReadAt: m.readAt, Post: st.v1Post(st.posts[m.postID])}
+}
+
+func (st *state) v2Mention(m *mention) *v2.Mention {
+	return &v2.Mention{ID: m.id, ReadAt: m.readAt, Post: st.v2Post(st.posts[m.postID], m.accountID)}
+}
+
+// otherMember returns the member of a direct message topic that is not me.
+func (st *state) otherMember(t *topic, me int) int {
+	for _, id := range t.members {
+		if id != me {
+			return id
+		}
+	}
+	return me
+}
+
+func (st *state) v1DirectMessage(t *topic, me int) *v1.DirectMessage {
+	s := st.v1AccountStatus(st.otherMember(t, me))
+	return (*v1.DirectMessage)(s)
+}
+
+// topicDetails returns the details of a topic, as TopicDetails.
+func (st *state) topicDetails(t *topic) *v1.TopicDetails {
+	d := &v1.TopicDetails{
+		Topic:            st.v1Topic(t),
+		MySpace:          st.organization(t.space),
+		Teams:            []interface{}{},
+		Accounts:         []*v1.Account{},
+		InvitingAccounts: []*v1.TopicMemberInvitation{},
+		Invites:          []*v1.TopicMemberInvitation{},
+		AccountsForAPI:   []interface{}{},
+		Integrations:     []interface{}{},
+	}
+	for _, id := range t.members {
+		d.Accounts = append(d.Accounts, st.v1Account(id))
+	}
+	for _, id := range t.groups {
+		g := st.groups[id]
+		d.Groups = append(d.Groups, &struct {
+			Group       *v1.Group `json:"group"`
+			MemberCount int       `json:"memberCount"`
+		}{st.v1Group(id), len(g.members)})
+	}
+	for _, inv := range t.invites {
+		if inv.accountID != 0 {
+			account := &v1.InvitedAccount{Account: *st.v1Account(inv.accountID), MailAddress: st.accounts[inv.accountID].email}
+			d.InvitingAccounts = append(d.InvitingAccounts, &v1.TopicMemberInvitation{Account: account, Role: inv.role})
+		} else {
+			d.Invites = append(d.Invites, &v1.TopicMemberInvitation{MailAddress: inv.email, Role: inv.role})
+		}
+	}
+	return d
+}
@@ -0,0 +1,538 @@
+// Package typetalktest provides an in-memory fake of the Typetalk API for
+// tests of code built on this library.
+//
+// The fake keeps state: a message posted through the client shows up in the
+// topic, can be liked, added to a talk and found by a search.
+//
+//	s := typetalktest.NewServer()
+//	defer s.Close()
+//	topicID := s.AddTopic("ops")
+//	client := v1.NewClient(s.Client()).SetTypetalkToken("token")
+//	client.Messages.PostMessage(ctx, topicID, "hello", nil)
+//	s.AssertPosted(t, topicID, "hello")
+//
+// Any non-empty token authenticates as the account returned by Me, unless it
+// is bound to another account with SetToken.
+package typetalktest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nulab/go-typetalk/v3/typetalk/internal"
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+)
+
+// DefaultSpace is the key of the space created with the server.
+const DefaultSpace = "space"
+
+// Request is a request received by the server.
+type Request struct {
+	Method string
+	// Path is the path of the request, such as "/api/v1/topics/1".
+	Path string
+	// Form holds the query and the form parameters.
+	Form url.Values
+	// AccountID is the account the request is authenticated as.
+	AccountID int
+}
+
+// Server is a fake Typetalk API server.
+type Server struct {
+	*httptest.Server
+
+	// Now returns the time of new data. It defaults to time.Now.
+	Now func() time.Time
+	// SearchLimit is the number of posts a search returns at most. It
+	// defaults to 100.
+	SearchLimit int
+
+	mu       sync.Mutex
+	st       *state
+	me       int
+	routes   []*route
+	requests []*Request
+	failures []*failure
+}
+
+type failure struct {
+	method string
+	path   string
+	status int
+}
+
+// NewServer starts a fake server with the space DefaultSpace and the account
+// "me". The caller should Close it when finished.
+func NewServer() *Server {
+	s := &Server{Now: time.Now, SearchLimit: 100, st: newState()}
+	s.routes = append(s.v1Routes(), s.v2Routes()...)
+	s.routes = append(s.routes, s.v4Routes()...)
+	s.routes = append(s.routes, s.v5Routes()...)
+	s.st.spaces = append(s.st.spaces, &space{key: DefaultSpace, name: DefaultSpace, statuses: map[int]*userStatus{}})
+	s.me = s.addAccount(DefaultSpace, "me", false)
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client returns an http.Client that sends the requests of the API clients
+// to the server.
+func (s *Server) Client() *http.Client {
+	return internal.NewTestClient(s.Server)
+}
+
+// Me returns the ID of the account "me".
+func (s *Server) Me() int {
+	return s.me
+}
+
+// SetToken makes the token authenticate as the account.
+func (s *Server) SetToken(token string, accountID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.st.tokens[token] = accountID
+}
+
+// FailNext makes the next request with the method and path fail with the
+// status, such as http.StatusTooManyRequests.
+func (s *Server) FailNext(method, path string, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, &failure{method, path, status})
+}
+
+// Requests returns the requests received so far.
+func (s *Server) Requests() []*Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*Request(nil), s.requests...)
+}
+
+// AssertRequested reports an error if no request with the method and path
+// was received.
+func (s *Server) AssertRequested(t testing.TB, method, path string) {
+	t.Helper()
+	for _, r := range s.Requests() {
+		if r.Method == method && r.Path == path {
+			return
+		}
+	}
+	t.Errorf("typetalktest: no %s %s request", method, path)
+}
+
+// AssertPosted reports an error if the topic has no post with the message.
+func (s *Server) AssertPosted(t testing.TB, topicID int, message string) {
+	t.Helper()
+	for _, p := range s.Posts(topicID) {
+		if p.Message == message {
+			return
+		}
+	}
+	t.Errorf("typetalktest: %q was not posted to topic %d", message, topicID)
+}
+
+// AddSpace adds a space that "me" is a member of.
+func (s *Server) AddSpace(key, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.st.spaces = append(s.st.spaces, &space{key: key, name: name, members: []int{s.me}, statuses: map[int]*userStatus{}})
+}
+
+// JoinSpace makes the account a member of the space.
+func (s *Server) JoinSpace(spaceKey string, accountID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sp := s.st.space(spaceKey); sp != nil && !contains(sp.members, accountID) {
+		sp.members = append(sp.members, accountID)
+	}
+}
+
+// AddAccount adds an account to DefaultSpace and returns its ID.
+func (s *Server) AddAccount(name string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addAccount(DefaultSpace, name, false)
+}
+
+// AddBot adds a bot account to DefaultSpace and returns its ID.
+func (s *Server) AddBot(name string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addAccount(DefaultSpace, name, true)
+}
+
+// AddGuest adds an account that is not a member of any space, so it only has
+// access to the topics it is added to, and returns its ID.
+func (s *Server) AddGuest(name string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addAccount("", name, false)
+}
+
+func (s *Server) addAccount(spaceKey, name string, bot bool) int {
+	a := &account{
+		id:        s.st.id(),
+		name:      name,
+		fullName:  name,
+		email:     name + "@example.com",
+		isBot:     bot,
+		lang:      "en",
+		timezone:  "UTC",
+		createdAt: s.Now(),
+	}
+	s.st.accounts[a.id] = a
+	if sp := s.st.space(spaceKey); sp != nil {
+		sp.members = append(sp.members, a.id)
+	}
+	return a.id
+}
+
+// SetPresence sets the online status of the account, such as "online".
+func (s *Server) SetPresence(accountID int, presence string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.st.presences[accountID] = presence
+}
+
+// AddGroup adds a group of accounts to DefaultSpace and returns its ID.
+func (s *Server) AddGroup(key string, memberIDs ...int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	g := &group{id: s.st.id(), key: key, name: key, members: memberIDs, createdAt: s.Now()}
+	s.st.groups[g.id] = g
+	sp := s.st.space(DefaultSpace)
+	sp.groups = append(sp.groups, g.id)
+	return g.id
+}
+
+// AddTopic adds a topic to DefaultSpace with "me" and the accounts as
+// members, and returns its ID.
+func (s *Server) AddTopic(name string, memberIDs ...int) int {
+	return s.AddTopicToSpace(DefaultSpace, name, memberIDs...)
+}
+
+// AddTopicToSpace adds a topic to the space with "me" and the accounts as
+// members, and returns its ID.
+func (s *Server) AddTopicToSpace(spaceKey, name string, memberIDs ...int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addTopic(spaceKey, name, append([]int{s.me}, memberIDs...), false).id
+}
+
+func (s *Server) addTopic(spaceKey, name string, memberIDs []int, dm bool) *topic {
+	now := s.Now()
+	t := &topic{
+		id:        s.st.id(),
+		space:     spaceKey,
+		name:      name,
+		dm:        dm,
+		favorites: map[int]bool{},
+		bookmarks: map[int]int{},
+		createdAt: now,
+		updatedAt: now,
+	}
+	for _, id := range memberIDs {
+		if !contains(t.members, id) {
+			t.members = append(t.members, id)
+		}
+	}
+	s.st.topics[t.id] = t
+	return t
+}
+
+// AddPost adds a post of the account to the topic and returns its ID.
+func (s *Server) AddPost(topicID, accountID int, message string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.st.topics[topicID]
+	if t == nil {
+		panic(fmt.Sprintf("typetalktest: no topic %d", topicID))
+	}
+	return s.addPost(t, accountID, message, 0, nil).id
+}
+
+func (s *Server) addPost(t *topic, accountID int, message string, replyTo int, attachments []*attachment) *post {
+	now := s.Now()
+	p := &post{
+		id:          s.st.id(),
+		topicID:     t.id,
+		replyTo:     replyTo,
+		accountID:   accountID,
+		message:     message,
+		attachments: attachments,
+		createdAt:   now,
+		updatedAt:   now,
+	}
+	s.st.posts[p.id] = p
+	t.lastPostedAt = &now
+	// The author has read the topic up to the post.
+	t.bookmarks[accountID] = p.id
+	for _, m := range mentionPattern.FindAllStringSubmatch(message, -1) {
+		a := s.st.accountByName(m[1])
+		if a == nil || a.id == accountID || !s.st.isMember(t, a.id) {
+			continue
+		}
+		id := s.st.id()
+		s.st.mentions[id] = &mention{id: id, postID: p.id, accountID: a.id}
+	}
+	for _, id := range t.members {
+		if id != accountID {
+			s.st.accounts[id].unopened++
+		}
+	}
+	return p
+}
+
+// AddTalk adds a talk with the posts to the topic and returns its ID.
+func (s *Server) AddTalk(topicID int, name string, postIDs ...int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addTalk(topicID, name, postIDs).id
+}
+
+func (s *Server) addTalk(topicID int, name string, postIDs []int) *talk {
+	now := s.Now()
+	t := &talk{id: s.st.id(), topicID: topicID, name: name, posts: postIDs, createdAt: now, updatedAt: now}
+	s.st.talks[t.id] = t
+	return t
+}
+
+// Topic returns the details of a topic, or nil if there is no such topic.
+func (s *Server) Topic(id int) *v1.TopicDetails {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.st.topics[id]
+	if t == nil {
+		return nil
+	}
+	return s.st.topicDetails(t)
+}
+
+// Posts returns the posts of a topic, oldest first.
+func (s *Server) Posts(topicID int) []*v1.Post {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.st.v1Posts(s.st.topicPosts(topicID))
+}
+
+// Talks returns the talks of a topic.
+func (s *Server) Talks(topicID int) []*v1.Talk {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	talks := []*v1.Talk{}
+	for _, t := range s.st.topicTalks(topicID) {
+		talks = append(talks, s.st.v1Talk(t))
+	}
+	return talks
+}
+
+type httpError struct {
+	status  int
+	message string
+}
+
+func (e *httpError) Error() string {
+	return e.message
+}
+
+func notFound(format string, a ...interface{}) error {
+	return &httpError{http.StatusNotFound, fmt.Sprintf(format, a...)}
+}
+
+func badRequest(format string, a ...interface{}) error {
+	return &httpError{http.StatusBadRequest, fmt.Sprintf(format, a...)}
+}
+
+func forbidden(format string, a ...interface{}) error {
+	return &httpError{http.StatusForbidden, fmt.Sprintf(format, a...)}
+}
+
+// A route matches a method and the segments of a path. A "*" segment matches
+// any segment, and a "@*" one any segment starting with "@".
+type route struct {
+	method   string
+	segments []string
+	handle   func(c *call) (interface{}, error)
+}
+
+func newRoute(method, pattern string, handle func(c *call) (interface{}, error)) *route {
+	return &route{method, strings.Split(pattern, "/"), handle}
+}
+
+func (r *route) match(method string, segments []string) ([]string, bool) {
+	if r.method != method || len(r.segments) != len(segments) {
+		return nil, false
+	}
+	var args []string
+	for i, seg := range r.segments {
+		switch {
+		case seg == "*":
+			args = append(args, segments[i])
+		case seg == "@*" && strings.HasPrefix(segments[i], "@"):
+			args = append(args, segments[i][1:])
+		case seg != segments[i]:
+			return nil, false
+		}
+	}
+	return args, true
+}
+
+// call is a request being handled.
+type call struct {
+	s    *Server
+	r    *http.Request
+	form url.Values
+	args []string
+	me   int
+}
+
+// arg returns the wildcard segment i of the path.
+func (c *call) arg(i int) string {
+	return c.args[i]
+}
+
+// id returns the wildcard segment i of the path as an ID.
+func (c *call) id(i int) (int, error) {
+	id, err := strconv.Atoi(c.args[i])
+	if err != nil {
+		return 0, badRequest("invalid id %q", c.args[i])
+	}
+	return id, nil
+}
+
+func (c *call) int(key string) int {
+	n, _ := strconv.Atoi(c.form.Get(key))
+	return n
+}
+
+func (c *call) bool(key string) bool {
+	b, _ := strconv.ParseBool(c.form.Get(key))
+	return b
+}
+
+// list returns the values of an indexed parameter such as "postIds[%d]".
+func (c *call) list(format string) []string {
+	var values []string
+	for i := 0; ; i++ {
+		v, ok := c.form[fmt.Sprintf(format, i)]
+		if !ok {
+			return values
+		}
+		values = append(values, v...)
+	}
+}
+
+func (c *call) ints(format string) []int {
+	var ids []int
+	for _, v := range c.list(format) {
+		if id, err := strconv.Atoi(v); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		r.ParseMultipartForm(32 << 20)
+	} else {
+		r.ParseForm()
+	}
+	me, ok := s.authenticate(r)
+	s.requests = append(s.requests, &Request{Method: r.Method, Path: r.URL.Path, Form: r.Form, AccountID: me})
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_request", error_description="The access token is not found"`)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	for i, f := range s.failures {
+		if f.method == r.Method && f.path == r.URL.Path {
+			s.failures = append(s.failures[:i], s.failures[i+1:]...)
+			writeError(w, &httpError{f.status, http.StatusText(f.status)})
+			return
+		}
+	}
+
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/"), "/"), "/")
+	for _, route := range s.routes {
+		if args, ok := route.match(r.Method, segments); ok {
+			v, err := route.handle(&call{s: s, r: r, form: r.Form, args: args, me: me})
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+			if b, ok := v.([]byte); ok {
+				w.Header().Set("Content-Type", "application/octet-stream")
+				w.Write(b)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(v)
+			return
+		}
+	}
+	writeError(w, notFound("no such endpoint: %s %s", r.Method, r.URL.Path))
+}
+
+func (s *Server) authenticate(r *http.Request) (int, bool) {
+	token := r.Header.Get("X-Typetalk-Token")
+	if token == "" {
+		token = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	}
+	if token == "" {
+		token = r.Form.Get("typetalkToken")
+	}
+	if token == "" {
+		return 0, false
+	}
+	if id, ok := s.st.tokens[token]; ok {
+		return id, true
+	}
+	return s.me, true
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	if e, ok := err.(*httpError); ok {
+		status = e.status
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+// readUpload reads the file of a multipart upload.
+func readUpload(r *http.Request) (string, string, []byte, error) {
+	if r.MultipartForm == nil {
+		return "", "", nil, badRequest("no file")
+	}
+	headers := r.MultipartForm.File["file"]
+	if len(headers) == 0 {
+		return "", "", nil, badRequest("no file")
+	}
+	f, err := headers[0].Open()
+	if err != nil {
+		return "", "", nil, err
+	}
+	defer f.Close()
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		return "", "", nil, err
+	}
+	contentType := headers[0].Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = internal.DefaultMediaType
+	}
+	return headers[0].Filename, contentType, data, nil
+}
@@ -0,0 +1,316 @@
+package typetalktest
+
+import (
+	"regexp"
+	"sort"
+	"time"
+)
+
+type space struct {
+	key      string
+	name     string
+	members  []int
+	groups   []int
+	statuses map[int]*userStatus
+}
+
+type account struct {
+	id        int
+	name      string
+	fullName  string
+	email     string
+	isBot     bool
+	lang      string
+	timezone  string
+	createdAt time.Time
+
+	// unopened counts the notifications received since the last
+	// ReadNotification.
+	unopened int
+	// readLikeID is the last received like marked as read.
+	readLikeID int
+}
+
+type group struct {
+	id        int
+	key       string
+	name      string
+	members   []int
+	createdAt time.Time
+}
+
+type invite struct {
+	email     string
+	role      string
+	accountID int
+}
+
+type topic struct {
+	id           int
+	space        string
+	name         string
+	description  string
+	members      []int
+	groups       []int
+	invites      []*invite
+	dm           bool
+	favorites    map[int]bool
+	bookmarks    map[int]int
+	createdAt    time.Time
+	updatedAt    time.Time
+	lastPostedAt *time.Time
+}
+
+type attachment struct {
+	key         string
+	name        string
+	contentType string
+	data        []byte
+	url         string
+}
+
+type post struct {
+	id          int
+	topicID     int
+	replyTo     int
+	accountID   int
+	message     string
+	attachments []*attachment
+	createdAt   time.Time
+	updatedAt   time.Time
+}
+
+type talk struct {
+	id        int
+	topicID   int
+	name      string
+	posts     []int
+	createdAt time.Time
+	updatedAt time.Time
+}
+
+type like struct {
+	id        int
+	postID    int
+	topicID   int
+	accountID int
+	comment   string
+	createdAt time.Time
+}
+
+type mention struct {
+	id        int
+	postID    int
+	accountID int
+	readAt    *time.Time
+}
+
+type userStatus struct {
+	id                     int
+	emoji                  string
+	message                string
+	clearAt                time.Time
+	isNotificationDisabled bool
+	createdAt              time.Time
+	updatedAt              time.Time
+}
+
+// state is the data of a Server. It is guarded by Server.mu.
+type state struct {
+	nextID    int
+	spaces    []*space
+	accounts  map[int]*account
+	groups    map[int]*group
+	topics    map[int]*topic
+	posts     map[int]*post
+	talks     map[int]*talk
+	likes     map[int]*like
+	mentions  map[int]*mention
+	uploads   map[string]*attachment
+	tokens    map[string]int
+	presences map[int]string
+}
+
+func newState() *state {
+	return &state{
+		nextID:    1,
+		accounts:  map[int]*account{},
+		groups:    map[int]*group{},
+		topics:    map[int]*topic{},
+		posts:     map[int]*post{},
+		talks:     map[int]*talk{},
+		likes:     map[int]*like{},
+		mentions:  map[int]*mention{},
+		uploads:   map[string]*attachment{},
+		tokens:    map[string]int{},
+		presences: map[int]string{},
+	}
+}
+
+func (st *state) id() int {
+	id := st.nextID
+	st.nextID++
+	return id
+}
+
+func (st *state) space(key string) *space {
+	for _, s := range st.spaces {
+		if s.key == key {
+			return s
+		}
+	}
+	return nil
+}
+
+func (st *state) accountByName(name string) *account {
+	for _, a := range st.accounts {
+		if a.name == name {
+			return a
+		}
+	}
+	return nil
+}
+
+func (st *state) groupByKey(key string) *group {
+	for _, g := range st.groups {
+		if g.key == key {
+			return g
+		}
+	}
+	return nil
+}
+
+// isMember reports whether the account belongs to the topic, directly or
+// through a group.
+func (st *state) isMember(t *topic, accountID int) bool {
+	if contains(t.members, accountID) {
+		return true
+	}
+	for _, id := range t.groups {
+		if g := st.groups[id]; g != nil && contains(g.members, accountID) {
+			return true
+		}
+	}
+	return false
+}
+
+// spacesOf returns the spaces the account is a member of.
+func (st *state) spacesOf(accountID int) []*space {
+	var spaces []*space
+	for _, s := range st.spaces {
+		if contains(s.members, accountID) {
+			spaces = append(spaces, s)
+		}
+	}
+	return spaces
+}
+
+// topicPosts returns the posts of a topic, oldest first.
+func (st *state) topicPosts(topicID int) []*post {
+	var posts []*post
+	for _, p := range st.posts {
+		if p.topicID == topicID {
+			posts = append(posts, p)
+		}
+	}
+	sort.Slice(posts, func(i, j int) bool { return posts[i].id < posts[j].id })
+	return posts
+}
+
+// postTalks returns the talks holding a post.
+func (st *state) postTalks(postID int) []*talk {
+	var talks []*talk
+	for _, t := range st.talks {
+		if contains(t.posts, postID) {
+			talks = append(talks, t)
+		}
+	}
+	sort.Slice(talks, func(i, j int) bool { return talks[i].id < talks[j].id })
+	return talks
+}
+
+// topicTalks returns the talks of a topic.
+func (st *state) topicTalks(topicID int) []*talk {
+	var talks []*talk
+	for _, t := range st.talks {
+		if t.topicID == topicID {
+			talks = append(talks, t)
+		}
+	}
+	sort.Slice(talks, func(i, j int) bool { return talks[i].id < talks[j].id })
+	return talks
+}
+
+// postLikes returns the likes of a post, oldest first.
+func (st *state) postLikes(postID int) []*like {
+	var likes []*like
+	for _, l := range st.likes {
+		if l.postID == postID {
+			likes = append(likes, l)
+		}
+	}
+	sort.Slice(likes, func(i, j int) bool { return likes[i].id < likes[j].id })
+	return likes
+}
+
+// dmTopic returns the direct message topic of two accounts in a space.
+func (st *state) dmTopic(spaceKey string, a, b int) *topic {
+	for _, t := range st.topics {
+		if t.dm && t.space == spaceKey && contains(t.members, a) && contains(t.members, b) {
+			return t
+		}
+	}
+	return nil
+}
+
+// unread returns the ID of the last post of a topic and the number of posts
+// newer than the bookmark of the account.
+func (st *state) unread(t *topic, accountID int) (int, int) {
+	last, count := 0, 0
+	for _, p := range st.topicPosts(t.id) {
+		last = p.id
+		if p.id > t.bookmarks[accountID] {
+			count++
+		}
+	}
+	return last, count
+}
+
+var mentionPattern = regexp.MustCompile(`@([A-Za-z0-9_.\-]+)`)
+
+// deletePost removes a post with its likes, mentions and talk entries.
+func (st *state) deletePost(postID int) {
+	delete(st.posts, postID)
+	for id, l := range st.likes {
+		if l.postID == postID {
+			delete(st.likes, id)
+		}
+	}
+	for id, m := range st.mentions {
+		if m.postID == postID {
+			delete(st.mentions, id)
+		}
+	}
+	for _, t := range st.talks {
+		t.posts = remove(t.posts, postID)
+	}
+}
+
+func contains(ids []int, id int) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+func remove(ids []int, id int) []int {
+	out := ids[:0]
+	for _, v := range ids {
+		if v != id {
+			out = append(out, v)
+		}
+	}
+	return out
+}
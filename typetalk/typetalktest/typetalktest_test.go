@@ -0,0 +1,380 @@
+package typetalktest
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nulab/go-typetalk/typetalk/shared"
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+	v2 "github.com/nulab/go-typetalk/v3/typetalk/v2"
+	v3 "github.com/nulab/go-typetalk/v3/typetalk/v3"
+	v4 "github.com/nulab/go-typetalk/v3/typetalk/v4"
+	v5 "github.com/nulab/go-typetalk/v3/typetalk/v5"
+)
+
+var (
+	server   *Server
+	clientV1 *v1.Client
+	clientV2 *v2.Client
+)
+
+func setup() {
+	server = NewServer()
+	clientV1 = v1.NewClient(server.Client()).SetTypetalkToken("DUMMY_TOKEN")
+	clientV2 = v2.NewClient(server.Client()).SetTypetalkToken("DUMMY_TOKEN")
+}
+
+func teardown() {
+	server.Close()
+}
+
+func Test_Server_should_keep_posts_replies_and_mentions(t *testing.T) {
+	setup()
+	defer teardown()
+	alice := server.AddAccount("alice")
+	topicID := server.AddTopic("ops", alice)
+	ctx := context.Background()
+
+	posted, _, err := clientV1.Messages.PostMessage(ctx, topicID, "hello @alice", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(posted.Mentions) != 1 || posted.Post.Account.Name != "me" {
+		t.Errorf("PostMessage: got %+v", posted)
+	}
+	if _, _, err := clientV1.Messages.PostMessage(ctx, topicID, "reply", &v1.PostMessageOptions{ReplyTo: posted.Post.ID}); err != nil {
+		t.Fatal(err)
+	}
+	server.AssertPosted(t, topicID, "hello @alice")
+	server.AssertRequested(t, "POST", fmt.Sprintf("/api/v1/topics/%d", topicID))
+
+	message, _, err := clientV1.Messages.GetMessage(ctx, topicID, posted.Post.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(message.Replies) != 1 || message.Replies[0].Message != "reply" {
+		t.Errorf("GetMessage: got replies %+v", message.Replies)
+	}
+
+	messages, _, err := clientV1.Topics.GetTopicMessages(ctx, topicID, &v1.GetTopicMessagesOptions{Count: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages.Posts) != 1 || messages.Posts[0].Message != "reply" || !messages.HasNext {
+		t.Errorf("GetTopicMessages: got %+v", messages)
+	}
+	details, _, err := clientV1.Topics.GetTopicDetails(ctx, topicID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(details.Accounts) != 2 {
+		t.Errorf("GetTopicDetails: got accounts %+v", details.Accounts)
+	}
+
+	server.SetToken("ALICE_TOKEN", alice)
+	aliceClient := v1.NewClient(server.Client()).SetTypetalkToken("ALICE_TOKEN")
+	mentions, _, err := aliceClient.Mentions.GetMentionList(ctx, &v1.GetMentionListOptions{Unread: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mentions) != 1 || mentions[0].Post.ID != posted.Post.ID {
+		t.Fatalf("GetMentionList: got %+v", mentions)
+	}
+	if _, _, err := aliceClient.Mentions.ReadMention(ctx, mentions[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if mentions, _, _ := aliceClient.Mentions.GetMentionList(ctx, &v1.GetMentionListOptions{Unread: true}); len(mentions) != 0 {
+		t.Errorf("GetMentionList after ReadMention: got %+v", mentions)
+	}
+	if _, _, err := aliceClient.Messages.UpdateMessage(ctx, topicID, posted.Post.ID, "changed"); err == nil {
+		t.Error("UpdateMessage of a post of another account should fail")
+	}
+}
+
+func Test_Server_should_keep_talks_and_likes(t *testing.T) {
+	setup()
+	defer teardown()
+	alice := server.AddAccount("alice")
+	topicID := server.AddTopic("ops", alice)
+	postID := server.AddPost(topicID, alice, "deploy done")
+	ctx := context.Background()
+
+	created, _, err := clientV1.Talks.CreateTalk(ctx, topicID, "deploys", postID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if talks := server.Talks(topicID); len(talks) != 1 || talks[0].Name != "deploys" {
+		t.Errorf("Talks: got %+v", talks)
+	}
+	inTalk, _, err := clientV1.Talks.GetMessagesInTalk(ctx, topicID, created.Talk.ID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inTalk.Posts) != 1 || inTalk.Posts[0].ID != postID {
+		t.Errorf("GetMessagesInTalk: got %+v", inTalk.Posts)
+	}
+
+	if _, _, err := clientV1.Messages.LikeMessage(ctx, topicID, postID); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := clientV1.Messages.LikeMessage(ctx, topicID, postID); err == nil {
+		t.Error("LikeMessage twice should fail")
+	}
+	given, _, err := clientV2.Likes.GetLikesGive(ctx, DefaultSpace, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(given) != 1 || given[0].Post.ID != postID || given[0].MyLike == nil {
+		t.Errorf("GetLikesGive: got %+v", given)
+	}
+	if posts := server.Posts(topicID); len(posts[0].Likes) != 1 || len(posts[0].Talks) != 1 {
+		t.Errorf("Posts: got %+v", posts[0])
+	}
+
+	if _, _, err := clientV1.Talks.DeleteTalk(ctx, topicID, created.Talk.ID); err != nil {
+		t.Fatal(err)
+	}
+	if talks := server.Talks(topicID); len(talks) != 0 {
+		t.Errorf("Talks after DeleteTalk: got %+v", talks)
+	}
+}
+
+func Test_Server_should_keep_direct_messages_and_unreads(t *testing.T) {
+	setup()
+	defer teardown()
+	alice := server.AddAccount("alice")
+	topicID := server.AddTopic("ops", alice)
+	server.AddPost(topicID, alice, "one")
+	last := server.AddPost(topicID, alice, "two")
+	ctx := context.Background()
+
+	topics, _, err := clientV2.Topics.GetMyTopics(ctx, DefaultSpace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(topics) != 1 || topics[0].Unread.Count != 2 || topics[0].Unread.PostID != last {
+		t.Fatalf("GetMyTopics: got %+v", topics)
+	}
+	unread, _, err := clientV1.Topics.ReadMessagesInTopic(ctx, topicID, last)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unread.Count != 0 {
+		t.Errorf("ReadMessagesInTopic: got %+v", unread)
+	}
+
+	if _, _, err := clientV2.Messages.PostDirectMessage(ctx, DefaultSpace, "alice", "hi", nil); err != nil {
+		t.Fatal(err)
+	}
+	dms, _, err := clientV2.Messages.GetDirectMessages(ctx, DefaultSpace, "alice", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dms.Posts) != 1 || dms.Posts[0].Message != "hi" || dms.DirectMessage.Account.ID != alice {
+		t.Errorf("GetDirectMessages: got %+v", dms)
+	}
+	dmTopics, _, err := clientV2.Messages.GetMyDirectMessageTopics(ctx, DefaultSpace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dmTopics) != 1 || dmTopics[0].DirectMessage.Account.Name != "alice" {
+		t.Errorf("GetMyDirectMessageTopics: got %+v", dmTopics)
+	}
+
+	count, _, err := v5.NewClient(server.Client()).SetTypetalkToken("DUMMY_TOKEN").Notifications.GetNotificationCount(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(count.Statuses) != 1 || count.Statuses[0].Access.Unopened != 2 {
+		t.Errorf("GetNotificationCount: got %+v", count.Statuses[0])
+	}
+	read, _, err := v3.NewClient(server.Client()).SetTypetalkToken("DUMMY_TOKEN").Notifications.ReadNotification(ctx, DefaultSpace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read.Access.Unopened != 0 || read.Space.Key != DefaultSpace {
+		t.Errorf("ReadNotification: got %+v", read)
+	}
+}
+
+func Test_Server_should_answer_friends_and_notifications_in_the_shape_of_each_version(t *testing.T) {
+	setup()
+	defer teardown()
+	alice := server.AddAccount("alice")
+	topicID := server.AddTopic("ops", alice)
+	server.AddPost(topicID, alice, "deployed")
+	ctx := context.Background()
+	strict := func(req *http.Request, unknown []string) {
+		t.Errorf("%s: unknown fields %v", req.URL.Path, unknown)
+	}
+
+	clientV4 := v4.NewClient(server.Client()).SetTypetalkToken("DUMMY_TOKEN").SetStrict(strict)
+	friends, _, err := clientV4.Accounts.GetMyFriends(ctx, DefaultSpace, "alice", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if friends.Count != 1 || friends.Accounts[0].Account.ID != alice || friends.Accounts[0].Status == nil {
+		t.Errorf("GetMyFriends: got %+v", friends)
+	}
+	if _, resp, err := clientV4.Accounts.GetMyFriends(ctx, "nowhere", "alice", nil); err == nil || resp.StatusCode != http.StatusForbidden {
+		t.Errorf("GetMyFriends of another space: got %v", err)
+	}
+
+	count, _, err := v5.NewClient(server.Client()).SetTypetalkToken("DUMMY_TOKEN").SetStrict(strict).Notifications.GetNotificationCount(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(count.Statuses[0].Unreads.TopicIds) != fmt.Sprint([]int{topicID}) || count.Statuses[0].MySpace.Space.Key != DefaultSpace {
+		t.Errorf("GetNotificationCount: got %+v", count.Statuses[0])
+	}
+	if settings := count.NotificationSettings; settings == nil || settings.DoNotDisturb == nil || settings.DoNotDisturb.IsSuppressed {
+		t.Errorf("GetNotificationCount: got settings %+v", settings)
+	}
+
+	countV2, _, err := v2.NewClient(server.Client()).SetTypetalkToken("DUMMY_TOKEN").SetStrict(strict).Notifications.GetNotificationCount(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if countV2.DoNotDisturb == nil || countV2.Statuses[0].DirectMessage == nil {
+		t.Errorf("GetNotificationCount of v2: got %+v", countV2)
+	}
+}
+
+func Test_Server_should_search_posts(t *testing.T) {
+	setup()
+	defer teardown()
+	alice := server.AddAccount("alice")
+	ops := server.AddTopic("ops", alice)
+	random := server.AddTopic("random", alice)
+	server.AddPost(ops, alice, "deploy started")
+	server.AddPost(random, alice, "lunch?")
+	server.AddPost(random, server.Me(), "deploy later")
+	server.SearchLimit = 1
+
+	result, _, err := clientV2.Messages.SearchMessages(context.Background(), DefaultSpace, "deploy", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Count != 2 || len(result.Posts) != 1 || !result.IsLimited || result.Posts[0].Message != "deploy later" {
+		t.Errorf("SearchMessages: got %+v", result)
+	}
+}
+
+func Test_Server_should_manage_topics_and_members(t *testing.T) {
+	setup()
+	defer teardown()
+	alice := server.AddAccount("alice")
+	bob := server.AddAccount("bob")
+	sre := server.AddGroup("sre", bob)
+	ctx := context.Background()
+
+	details, _, err := clientV1.Topics.CreateTopic(ctx, &v1.CreateTopicOptions{Name: "ops", SpaceKey: DefaultSpace, AddAccountIds: []int{alice}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	topicID := details.Topic.ID
+	_, _, err = clientV1.Topics.UpdateTopicMembers(ctx, topicID, &v1.UpdateTopicMembersOptions{
+		AddGroupIds:                         []int{sre},
+		InvitationsEmail:                    []string{"carol@example.com"},
+		InvitationsRole:                     []string{"Guest"},
+		RemoveAccountsID:                    []int{alice},
+		RemoveAccountsCancelSpaceInvitation: []bool{false},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := server.Topic(topicID)
+	if len(got.Accounts) != 1 || len(got.Groups) != 1 || got.Groups[0].Group.Key != "sre" || len(got.Invites) != 1 {
+		t.Errorf("Topic: got %+v", got)
+	}
+
+	server.SetToken("BOB_TOKEN", bob)
+	bobClient := v1.NewClient(server.Client()).SetTypetalkToken("BOB_TOKEN")
+	if _, _, err := bobClient.Messages.PostMessage(ctx, topicID, "in through the group", nil); err != nil {
+		t.Errorf("PostMessage of a group member: %v", err)
+	}
+	server.SetToken("ALICE_TOKEN", alice)
+	aliceClient := v1.NewClient(server.Client()).SetTypetalkToken("ALICE_TOKEN")
+	_, _, err = aliceClient.Messages.PostMessage(ctx, topicID, "not a member", nil)
+	if e, ok := err.(*shared.ErrorResponse); !ok || e.Response.StatusCode != http.StatusForbidden {
+		t.Errorf("PostMessage of a removed account: got %v", err)
+	}
+
+	members, _, err := clientV1.Organizations.GetOrganizationMembers(ctx, DefaultSpace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members.Accounts) != 3 || len(members.Groups) != 1 {
+		t.Errorf("GetOrganizationMembers: got %+v", members)
+	}
+}
+
+func Test_Server_should_upload_and_download_attachments(t *testing.T) {
+	setup()
+	defer teardown()
+	topicID := server.AddTopic("ops")
+	ctx := context.Background()
+
+	dir, err := ioutil.TempDir("", "typetalktest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "report.txt")
+	if err := ioutil.WriteFile(name, []byte("all green"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uploaded, _, err := clientV1.Files.UploadAttachmentFile(ctx, topicID, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	posted, _, err := clientV1.Messages.PostMessage(ctx, topicID, "report", &v1.PostMessageOptions{FileKeys: []string{uploaded.FileKey}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(posted.Post.Attachments) != 1 || posted.Post.Attachments[0].FileName != "report.txt" {
+		t.Fatalf("PostMessage: got attachments %+v", posted.Post.Attachments)
+	}
+
+	r, err := clientV1.Files.DownloadAttachmentFile(ctx, topicID, posted.Post.ID, 0, "report.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if b, _ := ioutil.ReadAll(r); string(b) != "all green" {
+		t.Errorf("DownloadAttachmentFile: got %q", b)
+	}
+}
+
+func Test_Server_should_fail_requests(t *testing.T) {
+	setup()
+	defer teardown()
+	ctx := context.Background()
+
+	_, _, err := v1.NewClient(server.Client()).Accounts.GetMyProfile(ctx)
+	if e, ok := err.(*shared.ErrorResponse); !ok || e.Response.StatusCode != http.StatusUnauthorized {
+		t.Errorf("GetMyProfile without a token: got %v", err)
+	}
+
+	server.FailNext("GET", "/api/v1/profile", http.StatusTooManyRequests)
+	_, _, err = clientV1.Accounts.GetMyProfile(ctx)
+	if e, ok := err.(*shared.ErrorResponse); !ok || e.Response.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("GetMyProfile after FailNext: got %v", err)
+	}
+	profile, _, err := clientV1.Accounts.GetMyProfile(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.Account.ID != server.Me() {
+		t.Errorf("GetMyProfile: got %+v", profile.Account)
+	}
+}
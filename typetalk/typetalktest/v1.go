@@ -0,0 +1,1071 @@
+package typetalktest
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+)
+
+func (s *Server) v1Routes() []*route {
+	return []*route{
+		newRoute("GET", "v1/profile", s.getMyProfile),
+		newRoute("GET", "v1/profile/*", s.getProfile),
+		newRoute("GET", "v1/search/friends", s.getMyFriends),
+		newRoute("GET", "v1/search/accounts", s.searchAccounts),
+		newRoute("GET", "v1/accounts/status", s.getOnlineStatus),
+
+		newRoute("POST", "v1/topics/*/attachments", s.uploadAttachment),
+		newRoute("GET", "v1/topics/*/posts/*/attachments/*/*", s.downloadAttachment),
+
+		newRoute("GET", "v1/likes/receive", s.getLikesReceive),
+		newRoute("GET", "v1/likes/give", s.getLikesGive),
+		newRoute("GET", "v1/likes/discover", s.getLikesDiscover),
+		newRoute("POST", "v1/likes/receive/bookmark/save", s.readReceivedLikes),
+
+		newRoute("PUT", "v1/mentions/*", s.readMention),
+		newRoute("GET", "v1/mentions", s.getMentionList),
+
+		newRoute("POST", "v1/topics/*", s.postMessage),
+		newRoute("GET", "v1/topics/*/posts/*", s.getMessage),
+		newRoute("PUT", "v1/topics/*/posts/*", s.updateMessage),
+		newRoute("DELETE", "v1/topics/*/posts/*", s.deleteMessage),
+		newRoute("POST", "v1/topics/*/posts/*/like", s.likeMessage),
+		newRoute("DELETE", "v1/topics/*/posts/*/like", s.unlikeMessage),
+		newRoute("GET", "v1/messages/@*", s.getDirectMessages),
+		newRoute("POST", "v1/messages/@*", s.postDirectMessage),
+		newRoute("GET", "v1/messages", s.getMyDirectMessageTopics),
+
+		newRoute("GET", "v1/notifications", s.getNotificationList),
+		newRoute("GET", "v1/notifications/status", s.getNotificationCount),
+		newRoute("PUT", "v1/notifications", s.readNotification),
+
+		newRoute("GET", "v1/spaces", s.getMyOrganizations),
+		newRoute("GET", "v1/spaces/*/members", s.getOrganizationMembers),
+		newRoute("POST", "v1/spaces/*/userStatuses", s.saveUserStatus),
+
+		newRoute("POST", "v1/topics/*/talks", s.createTalk),
+		newRoute("GET", "v1/topics/*/talks", s.getTalkList),
+		newRoute("PUT", "v1/topics/*/talks/*", s.updateTalk),
+		newRoute("DELETE", "v1/topics/*/talks/*", s.deleteTalk),
+		newRoute("GET", "v1/topics/*/talks/*/posts", s.getMessagesInTalk),
+		newRoute("POST", "v1/topics/*/talks/*/posts", s.addMessagesToTalk),
+		newRoute("DELETE", "v1/topics/*/talks/*/posts", s.removeMessagesFromTalk),
+
+		newRoute("POST", "v1/topics", s.createTopic),
+		newRoute("GET", "v1/topics", s.getMyTopics),
+		newRoute("GET", "v1/topics/*", s.getTopic),
+		newRoute("PUT", "v1/topics/*", s.updateTopic),
+		newRoute("DELETE", "v1/topics/*", s.deleteTopic),
+		newRoute("POST", "v1/topics/*/members/update", s.updateTopicMembers),
+		newRoute("POST", "v1/topics/*/favorite", s.favoriteTopic),
+		newRoute("DELETE", "v1/topics/*/favorite", s.unfavoriteTopic),
+		newRoute("PUT", "v1/bookmarks", s.readMessagesInTopic),
+	}
+}
+
+// topic returns the topic of the wildcard segment i, which the caller must
+// have access to.
+func (c *call) topic(i int) (*topic, error) {
+	id, err := c.id(i)
+	if err != nil {
+		return nil, err
+	}
+	t := c.s.st.topics[id]
+	if t == nil {
+		return nil, notFound("no topic %d", id)
+	}
+	if !c.s.st.isMember(t, c.me) {
+		return nil, forbidden("not a member of topic %d", id)
+	}
+	return t, nil
+}
+
+// post returns the post of the wildcard segment i in the topic.
+func (c *call) post(t *topic, i int) (*post, error) {
+	id, err := c.id(i)
+	if err != nil {
+		return nil, err
+	}
+	p := c.s.st.posts[id]
+	if p == nil || p.topicID != t.id {
+		return nil, notFound("no post %d in topic %d", id, t.id)
+	}
+	return p, nil
+}
+
+// talk returns the talk of the wildcard segment i in the topic.
+func (c *call) talk(t *topic, i int) (*talk, error) {
+	id, err := c.id(i)
+	if err != nil {
+		return nil, err
+	}
+	k := c.s.st.talks[id]
+	if k == nil || k.topicID != t.id {
+		return nil, notFound("no talk %d in topic %d", id, t.id)
+	}
+	return k, nil
+}
+
+// page returns the posts selected by the count, from and direction
+// parameters, oldest first, and whether there are more.
+func (c *call) page(posts []*post) ([]*post, bool) {
+	count, from, forward := c.int("count"), c.int("from"), c.form.Get("direction") == "forward"
+	if count <= 0 {
+		count = 20
+	}
+	var selected []*post
+	for _, p := range posts {
+		if from == 0 || (forward && p.id > from) || (!forward && p.id < from) {
+			selected = append(selected, p)
+		}
+	}
+	if len(selected) <= count {
+		return selected, false
+	}
+	if forward {
+		return selected[:count], true
+	}
+	return selected[len(selected)-count:], true
+}
+
+func (s *Server) getMyProfile(c *call) (interface{}, error) {
+	a := s.st.accounts[c.me]
+	return &v1.MyProfile{Account: s.st.v1Account(c.me), Lang: a.lang, Theme: &v1.Theme{}}, nil
+}
+
+func (s *Server) getProfile(c *call) (interface{}, error) {
+	a := s.st.accountByName(c.arg(0))
+	if a == nil {
+		return nil, notFound("no account %q", c.arg(0))
+	}
+	return (*v1.Profile)(s.st.v1AccountStatus(a.id)), nil
+}
+
+// friends returns the accounts sharing a space with the caller that match
+// the q parameter, restricted to the spaceKey parameter if given.
+func (c *call) friends() []int {
+	q := strings.ToLower(c.form.Get("q"))
+	seen := map[int]bool{}
+	var ids []int
+	for _, sp := range c.s.st.spacesOf(c.me) {
+		if key := c.form.Get("spaceKey"); key != "" && sp.key != key {
+			continue
+		}
+		for _, id := range sp.members {
+			a := c.s.st.accounts[id]
+			if seen[id] || !(strings.Contains(strings.ToLower(a.name), q) || strings.Contains(strings.ToLower(a.fullName), q) || strings.Contains(a.email, q)) {
+				continue
+			}
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	offset, count := c.int("offset"), c.int("count")
+	if offset > len(ids) {
+		offset = len(ids)
+	}
+	ids = ids[offset:]
+	if count > 0 && count < len(ids) {
+		ids = ids[:count]
+	}
+	return ids
+}
+
+func (s *Server) getMyFriends(c *call) (interface{}, error) {
+	friends := &v1.Friends{Accounts: []*v1.AccountStatus{}}
+	for _, id := range c.friends() {
+		friends.Accounts = append(friends.Accounts, s.st.v1AccountStatus(id))
+	}
+	friends.Count = len(friends.Accounts)
+	return friends, nil
+}
+
+func (s *Server) searchAccounts(c *call) (interface{}, error) {
+	q := c.form.Get("nameOrEmailAddress")
+	for _, a := range s.st.accounts {
+		if a.name == q || a.email == q {
+			return s.st.v1Account(a.id), nil
+		}
+	}
+	return nil, notFound("no account %q", q)
+}
+
+func (s *Server) getOnlineStatus(c *call) (interface{}, error) {
+	status := &v1.OnlineStatus{Accounts: []*v1.AccountStatus{}}
+	for _, id := range c.ints("accountIds[%d]") {
+		if s.st.accounts[id] != nil {
+			status.Accounts = append(status.Accounts, s.st.v1AccountStatus(id))
+		}
+	}
+	return status, nil
+}
+
+func (s *Server) uploadAttachment(c *call) (interface{}, error) {
+	if _, err := c.topic(0); err != nil {
+		return nil, err
+	}
+	name, contentType, data, err := readUpload(c.r)
+	if err != nil {
+		return nil, err
+	}
+	a := &attachment{key: "file-" + strconv.Itoa(s.st.id()), name: name, contentType: contentType, data: data}
+	s.st.uploads[a.key] = a
+	return v1Attachment(a), nil
+}
+
+func (s *Server) downloadAttachment(c *call) (interface{}, error) {
+	t, err := c.topic(0)
+	if err != nil {
+		return nil, err
+	}
+	p, err := c.post(t, 1)
+	if err != nil {
+		return nil, err
+	}
+	i, err := c.id(2)
+	if err != nil {
+		return nil, err
+	}
+	if i < 0 || i >= len(p.attachments) || p.attachments[i].name != c.arg(3) {
+		return nil, notFound("no attachment %d %q", i, c.arg(3))
+	}
+	return p.attachments[i].data, nil
+}
+
+// likedPosts groups the likes selected by keep by post, newest post first,
+// limited to the posts older than the from parameter and to the spaceKey
+// parameter if given.
+func (c *call) likedPosts(keep func(l *like) bool) []*post {
+	from, spaceKey := c.int("from"), c.form.Get("spaceKey")
+	seen := map[int]bool{}
+	var posts []*post
+	for _, l := range c.s.st.likes {
+		p := c.s.st.posts[l.postID]
+		t := c.s.st.topics[l.topicID]
+		if seen[p.id] || !keep(l) || !c.s.st.isMember(t, c.me) || (from > 0 && p.id >= from) || (spaceKey != "" && t.space != spaceKey) {
+			continue
+		}
+		seen[p.id] = true
+		posts = append(posts, p)
+	}
+	sort.Slice(posts, func(i, j int) bool { return posts[i].id > posts[j].id })
+	return posts
+}
+
+func (s *Server) v1LikedPost(p *post, me int) *v1.LikedPost {
+	lp := &v1.LikedPost{Post: s.st.v1Post(p), Likes: []*v1.Like{}}
+	for _, l := range s.st.postLikes(p.id) {
+		lp.Likes = append(lp.Likes, s.st.v1Like(l))
+	}
+	if t := s.st.topics[p.topicID]; t.dm {
+		lp.DirectMessage = s.st.v1DirectMessage(t, me)
+	}
+	return lp
+}
+
+func (c *call) received(l *like) bool {
+	return c.s.st.posts[l.postID].accountID == c.me && l.accountID != c.me
+}
+
+func (s *Server) getLikesReceive(c *call) (interface{}, error) {
+	liked := []*v1.ReceiveLikedPost{}
+	for _, p := range c.likedPosts(c.received) {
+		liked = append(liked, &v1.ReceiveLikedPost{LikedPost: s.v1LikedPost(p, c.me)})
+	}
+	return map[string]interface{}{"likedPosts": liked}, nil
+}
+
+func (s *Server) getLikesGive(c *call) (interface{}, error) {
+	liked := []*v1.GiveLikedPost{}
+	for _, p := range c.likedPosts(func(l *like) bool { return l.accountID == c.me }) {
+		g := &v1.GiveLikedPost{ReceiveLikedPost: &v1.ReceiveLikedPost{LikedPost: s.v1LikedPost(p, c.me)}}
+		for _, l := range s.st.postLikes(p.id) {
+			if l.accountID == c.me {
+				g.MyLike = &v1.MyLike{ID: l.id, Comment: l.comment, CreatedAt: l.createdAt}
+			}
+		}
+		liked = append(liked, g)
+	}
+	return map[string]interface{}{"likedPosts": liked}, nil
+}
+
+func (s *Server) getLikesDiscover(c *call) (interface{}, error) {
+	liked := []*v1.DiscoverLikedPost{}
+	for _, p := range c.likedPosts(func(l *like) bool { return true }) {
+		liked = append(liked, &v1.DiscoverLikedPost{LikedPost: s.v1LikedPost(p, c.me)})
+	}
+	return map[string]interface{}{"likedPosts": liked}, nil
+}
+
+func (s *Server) readReceivedLikes(c *call) (interface{}, error) {
+	a := s.st.accounts[c.me]
+	if id := c.int("likeId"); id > a.readLikeID {
+		a.readLikeID = id
+	}
+	result := &v1.ReadReceivedLikesResult{}
+	result.Like.Receive.HasUnread = s.hasUnreadLikes(c.me)
+	result.Like.Receive.ReadLikeID = a.readLikeID
+	return result, nil
+}
+
+func (s *Server) hasUnreadLikes(accountID int) bool {
+	for _, l := range s.st.likes {
+		if s.st.posts[l.postID].accountID == accountID && l.accountID != accountID && l.id > s.st.accounts[accountID].readLikeID {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) readMention(c *call) (interface{}, error) {
+	id, err := c.id(0)
+	if err != nil {
+		return nil, err
+	}
+	m := s.st.mentions[id]
+	if m == nil || m.accountID != c.me {
+		return nil, notFound("no mention %d", id)
+	}
+	if m.readAt == nil {
+		m.readAt = timePtr(s.Now())
+	}
+	return map[string]interface{}{"mention": s.st.v1Mention(m)}, nil
+}
+
+// mentions returns the mentions of the caller selected by the from, unread
+// and spaceKey parameters, newest first.
+func (c *call) mentions() []*mention {
+	from, unread, spaceKey := c.int("from"), c.bool("unread"), c.form.Get("spaceKey")
+	var mentions []*mention
+	for _, m := range c.s.st.mentions {
+		if m.accountID != c.me || (from > 0 && m.id >= from) || (unread && m.readAt != nil) {
+			continue
+		}
+		if spaceKey != "" && c.s.st.topics[c.s.st.posts[m.postID].topicID].space != spaceKey {
+			continue
+		}
+		mentions = append(mentions, m)
+	}
+	sort.Slice(mentions, func(i, j int) bool { return mentions[i].id > mentions[j].id })
+	return mentions
+}
+
+func (s *Server) getMentionList(c *call) (interface{}, error) {
+	mentions := []*v1.Mention{}
+	for _, m := range c.mentions() {
+		mentions = append(mentions, s.st.v1Mention(m))
+	}
+	return map[string]interface{}{"mentions": mentions}, nil
+}
+
+// addPost adds a post of the caller to the topic from the parameters of
+// PostMessageOptions, and returns it with the mentions it made.
+func (c *call) addPost(t *topic) (*post, []*mention, error) {
+	message := c.form.Get("message")
+	var attachments []*attachment
+	for _, key := range c.list("fileKeys[%d]") {
+		a := c.s.st.uploads[key]
+		if a == nil {
+			return nil, nil, badRequest("no file %q", key)
+		}
+		delete(c.s.st.uploads, key)
+		attachments = append(attachments, a)
+	}
+	names := c.list("attachments[%d].fileName")
+	for i, u := range c.list("attachments[%d].fileUrl") {
+		a := &attachment{key: "file-" + strconv.Itoa(c.s.st.id()), url: u, contentType: "application/octet-stream"}
+		if i < len(names) {
+			a.name = names[i]
+		}
+		attachments = append(attachments, a)
+	}
+	if message == "" && len(attachments) == 0 {
+		return nil, nil, badRequest("message is required")
+	}
+	replyTo := c.int("replyTo")
+	if replyTo != 0 {
+		if r := c.s.st.posts[replyTo]; r == nil || r.topicID != t.id {
+			return nil, nil, badRequest("no post %d to reply to", replyTo)
+		}
+	}
+	talkIDs := c.ints("talkIds[%d]")
+	for _, id := range talkIDs {
+		if k := c.s.st.talks[id]; k == nil || k.topicID != t.id {
+			return nil, nil, badRequest("no talk %d", id)
+		}
+	}
+	before := c.s.st.nextID
+	p := c.s.addPost(t, c.me, message, replyTo, attachments)
+	for _, id := range talkIDs {
+		k := c.s.st.talks[id]
+		k.posts = append(k.posts, p.id)
+		k.updatedAt = p.createdAt
+	}
+	var mentions []*mention
+	for _, m := range c.s.st.mentions {
+		if m.postID == p.id && m.id >= before {
+			mentions = append(mentions, m)
+		}
+	}
+	sort.Slice(mentions, func(i, j int) bool { return mentions[i].id < mentions[j].id })
+	return p, mentions, nil
+}
+
+func (s *Server) v1PostedMessage(t *topic, p *post, mentions []*mention, me int) *v1.PostedMessageResult {
+	result := &v1.PostedMessageResult{Space: s.st.v1Space(t.space), Topic: s.st.v1Topic(t), Post: s.st.v1Post(p), Mentions: []*v1.Mention{}}
+	for _, m := range mentions {
+		result.Mentions = append(result.Mentions, s.st.v1Mention(m))
+	}
+	if t.dm {
+		result.DirectMessage = s.st.v1DirectMessage(t, me)
+	}
+	return result
+}
+
+func (s *Server) postMessage(c *call) (interface{}, error) {
+	t, err := c.topic(0)
+	if err != nil {
+		return nil, err
+	}
+	p, mentions, err := c.addPost(t)
+	if err != nil {
+		return nil, err
+	}
+	return s.v1PostedMessage(t, p, mentions, c.me), nil
+}
+
+func (s *Server) getMessage(c *call) (interface{}, error) {
+	t, err := c.topic(0)
+	if err != nil {
+		return nil, err
+	}
+	p, err := c.post(t, 1)
+	if err != nil {
+		return nil, err
+	}
+	var replies []*post
+	for _, r := range s.st.topicPosts(t.id) {
+		if r.replyTo == p.id {
+			replies = append(replies, r)
+		}
+	}
+	return &v1.Message{MySpace: s.st.organization(t.space), Topic: s.st.v1Topic(t), Post: s.st.v1Post(p), Replies: s.st.v1Posts(replies)}, nil
+}
+
+func (s *Server) updateMessage(c *call) (interface{}, error) {
+	t, err := c.topic(0)
+	if err != nil {
+		return nil, err
+	}
+	p, err := c.post(t, 1)
+	if err != nil {
+		return nil, err
+	}
+	if p.accountID != c.me {
+		return nil, forbidden("post %d is not yours", p.id)
+	}
+	if c.form.Get("message") == "" {
+		return nil, badRequest("message is required")
+	}
+	p.message = c.form.Get("message")
+	p.updatedAt = s.Now()
+	return (*v1.UpdatedMessageResult)(s.v1PostedMessage(t, p, nil, c.me)), nil
+}
+
+func (s *Server) deleteMessage(c *call) (interface{}, error) {
+	t, err := c.topic(0)
+	if err != nil {
+		return nil, err
+	}
+	p, err := c.post(t, 1)
+	if err != nil {
+		return nil, err
+	}
+	if p.accountID != c.me {
+		return nil, forbidden("post %d is not yours", p.id)
+	}
+	v := s.st.v1Post(p)
+	s.st.deletePost(p.id)
+	return v, nil
+}
+
+func (s *Server) likeMessage(c *call) (interface{}, error) {
+	t, err := c.topic(0)
+	if err != nil {
+		return nil, err
+	}
+	p, err := c.post(t, 1)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range s.st.postLikes(p.id) {
+		if l.accountID == c.me {
+			return nil, badRequest("post %d is already liked", p.id)
+		}
+	}
+	l := &like{id: s.st.id(), postID: p.id, topicID: t.id, accountID: c.me, comment: c.form.Get("comment"), createdAt: s.Now()}
+	s.st.likes[l.id] = l
+	result := &v1.LikedMessageResult{Like: s.st.v1Like(l), Post: s.st.v1Post(p), Topic: s.st.v1Topic(t)}
+	if t.dm {
+		result.DirectMessage = s.st.v1DirectMessage(t, c.me)
+	}
+	return result, nil
+}
+
+func (s *Server) unlikeMessage(c *call) (interface{}, error) {
+	t, err := c.topic(0)
+	if err != nil {
+		return nil, err
+	}
+	p, err := c.post(t, 1)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range s.st.postLikes(p.id) {
+		if l.accountID == c.me {
+			v := s.st.v1Like(l)
+			delete(s.st.likes, l.id)
+			return map[string]interface{}{"like": v}, nil
+		}
+	}
+	return nil, notFound("post %d is not liked", p.id)
+}
+
+// directMessageTopic returns the direct message topic of the caller with the
+// account in the space, creating it if create is set. The space defaults to
+// one the caller shares with the account.
+func (c *call) directMessageTopic(spaceKey, name string, create bool) (*topic, *account, error) {
+	a := c.s.st.accountByName(name)
+	if a == nil {
+		return nil, nil, notFound("no account %q", name)
+	}
+	if spaceKey == "" {
+		spaceKey = DefaultSpace
+		for _, sp := range c.s.st.spacesOf(c.me) {
+			if contains(sp.members, a.id) {
+				spaceKey = sp.key
+				break
+			}
+		}
+	}
+	if c.s.st.space(spaceKey) == nil {
+		return nil, nil, notFound("no space %q", spaceKey)
+	}
+	t := c.s.st.dmTopic(spaceKey, c.me, a.id)
+	if t == nil && create {
+		t = c.s.addTopic(spaceKey, "", []int{c.me, a.id}, true)
+	}
+	return t, a, nil
+}
+
+func (s *Server) getDirectMessages(c *call) (interface{}, error) {
+	t, a, err := c.directMessageTopic("", c.arg(0), false)
+	if err != nil {
+		return nil, err
+	}
+	result := &v1.DirectMessages{DirectMessage: (*v1.DirectMessage)(s.st.v1AccountStatus(a.id)), Posts: []*v1.Post{}}
+	if t != nil {
+		posts, hasNext := c.page(s.st.topicPosts(t.id))
+		result.Topic = s.st.v1Topic(t)
+		result.Bookmark = &v1.Bookmark{PostID: t.bookmarks[c.me], UpdatedAt: t.updatedAt}
+		result.Posts = s.st.v1Posts(posts)
+		result.HasNext = hasNext
+	}
+	return result, nil
+}
+
+func (s *Server) postDirectMessage(c *call) (interface{}, error) {
+	t, _, err := c.directMessageTopic("", c.arg(0), true)
+	if err != nil {
+		return nil, err
+	}
+	p, mentions, err := c.addPost(t)
+	if err != nil {
+		return nil, err
+	}
+	return s.v1PostedMessage(t, p, mentions, c.me), nil
+}
+
+// directMessageTopics returns the direct message topics of the caller in the
+// space, or in all spaces if spaceKey is empty.
+func (c *call) directMessageTopics(spaceKey string) []*topic {
+	var topics []*topic
+	for _, t := range c.s.st.topics {
+		if t.dm && contains(t.members, c.me) && (spaceKey == "" || t.space == spaceKey) {
+			topics = append(topics, t)
+		}
+	}
+	sort.Slice(topics, func(i, j int) bool { return topics[i].id < topics[j].id })
+	return topics
+}
+
+func (s *Server) v1Unread(t *topic, me int) *v1.Unread {
+	last, count := s.st.unread(t, me)
+	return &v1.Unread{TopicID: t.id, PostID: last, Count: count}
+}
+
+func (s *Server) getMyDirectMessageTopics(c *call) (interface{}, error) {
+	topics := []*v1.DirectMessageTopic{}
+	for _, t := range c.directMessageTopics("") {
+		topics = append(topics, &v1.DirectMessageTopic{Topic: s.st.v1Topic(t), Unread: s.v1Unread(t, c.me), DirectMessage: s.st.v1DirectMessage(t, c.me)})
+	}
+	return map[string]interface{}{"topics": topics}, nil
+}
+
+func (s *Server) getNotificationList(c *call) (interface{}, error) {
+	list := &v1.NotificationList{Mentions: []*v1.Mention{}, Invites: &v1.Invites{Teams: []interface{}{}, Topics: []*v1.TopicInvitation{}}}
+	for _, m := range c.mentions() {
+		list.Mentions = append(list.Mentions, s.st.v1Mention(m))
+	}
+	return list, nil
+}
+
+// unreadMentions returns the number of unread mentions of the account.
+func (s *Server) unreadMentions(accountID int) int {
+	n := 0
+	for _, m := range s.st.mentions {
+		if m.accountID == accountID && m.readAt == nil {
+			n++
+		}
+	}
+	return n
+}
+
+// unreadTopics returns the IDs of the topics, or of the direct message topics
+// if dm is set, of the account with unread posts in the space, or in all
+// spaces if spaceKey is empty.
+func (s *Server) unreadTopics(accountID int, spaceKey string, dm bool) []int {
+	ids := []int{}
+	for _, t := range s.st.topics {
+		if t.dm != dm || !s.st.isMember(t, accountID) || (spaceKey != "" && t.space != spaceKey) {
+			continue
+		}
+		if _, count := s.st.unread(t, accountID); count > 0 {
+			ids = append(ids, t.id)
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+func (s *Server) getNotificationCount(c *call) (interface{}, error) {
+	// NotificationCount is made of anonymous structs, so the response is
+	// built as a map.
+	return map[string]interface{}{
+		"mention": map[string]interface{}{"unread": s.unreadMentions(c.me)},
+		"access":  &v1.Access{Unopened: s.st.accounts[c.me].unopened},
+		"invite": map[string]interface{}{
+			"team":  map[string]interface{}{"pending": 0},
+			"topic": map[string]interface{}{"pending": 0},
+		},
+		"like": map[string]interface{}{"receive": map[string]interface{}{
+			"hasUnread":  s.hasUnreadLikes(c.me),
+			"readLikeId": s.st.accounts[c.me].readLikeID,
+		}},
+		"directMessage": map[string]interface{}{"unreadTopics": len(s.unreadTopics(c.me, "", true))},
+	}, nil
+}
+
+func (s *Server) readNotification(c *call) (interface{}, error) {
+	s.st.accounts[c.me].unopened = 0
+	return map[string]interface{}{"access": &v1.Access{}}, nil
+}
+
+func (s *Server) getMyOrganizations(c *call) (interface{}, error) {
+	spaces := []*v1.Organization{}
+	for _, sp := range s.st.spacesOf(c.me) {
+		spaces = append(spaces, s.st.organization(sp.key))
+	}
+	return map[string]interface{}{"mySpaces": spaces}, nil
+}
+
+// space returns the space of the wildcard segment i, which the caller must
+// be a member of.
+func (c *call) space(i int) (*space, error) {
+	sp := c.s.st.space(c.arg(i))
+	if sp == nil {
+		return nil, notFound("no space %q", c.arg(i))
+	}
+	if !contains(sp.members, c.me) {
+		return nil, forbidden("not a member of space %q", sp.key)
+	}
+	return sp, nil
+}
+
+func (s *Server) getOrganizationMembers(c *call) (interface{}, error) {
+	sp, err := c.space(0)
+	if err != nil {
+		return nil, err
+	}
+	// The groups are listed the way TopicDetails lists them.
+	details := s.st.topicDetails(&topic{space: sp.key, members: sp.members, groups: sp.groups})
+	return &v1.OrganizationMembers{Accounts: details.Accounts, Groups: details.Groups}, nil
+}
+
+func (s *Server) saveUserStatus(c *call) (interface{}, error) {
+	sp, err := c.space(0)
+	if err != nil {
+		return nil, err
+	}
+	if c.form.Get("emoji") == "" {
+		return nil, badRequest("emoji is required")
+	}
+	now := s.Now()
+	us := sp.statuses[c.me]
+	if us == nil {
+		us = &userStatus{id: s.st.id(), createdAt: now}
+		sp.statuses[c.me] = us
+	}
+	us.emoji = c.form.Get("emoji")
+	us.message = c.form.Get("message")
+	us.clearAt, _ = time.Parse(time.RFC3339, c.form.Get("clearAt"))
+	us.isNotificationDisabled = c.bool("isNotificationDisabled")
+	us.updatedAt = now
+	return &v1.SaveUserStatusResult{UserStatus: &v1.UserStatus{
+		ID:                     us.id,
+		AccountID:              c.me,
+		Emoji:                  us.emoji,
+		Message:                us.message,
+		ClearAt:                us.clearAt,
+		IsNotificationDisabled: us.isNotificationDisabled,
+		CreatedAt:              us.createdAt,
+		UpdatedAt:              us.updatedAt,
+	}}, nil
+}
+
+// postIDs returns the postIds parameter, which must name posts of the topic.
+func (c *call) postIDs(t *topic) ([]int, error) {
+	ids := c.ints("postIds[%d]")
+	if len(ids) == 0 {
+		return nil, badRequest("postIds is required")
+	}
+	for _, id := range ids {
+		if p := c.s.st.posts[id]; p == nil || p.topicID != t.id {
+			return nil, badRequest("no post %d in topic %d", id, t.id)
+		}
+	}
+	return ids, nil
+}
+
+func (s *Server) createTalk(c *call) (interface{}, error) {
+	t, err := c.topic(0)
+	if err != nil {
+		return nil, err
+	}
+	name := c.form.Get("talkName")
+	if name == "" {
+		return nil, badRequest("talkName is required")
+	}
+	ids, err := c.postIDs(t)
+	if err != nil {
+		return nil, err
+	}
+	k := s.addTalk(t.id, name, ids)
+	return &v1.CreatedTalkResult{Topic: s.st.v1Topic(t), Talk: s.st.v1Talk(k), PostIds: ids}, nil
+}
+
+func (s *Server) getTalkList(c *call) (interface{}, error) {
+	t, err := c.topic(0)
+	if err != nil {
+		return nil, err
+	}
+	talks := []*v1.Talk{}
+	for _, k := range s.st.topicTalks(t.id) {
+		talks = append(talks, s.st.v1Talk(k))
+	}
+	return map[string]interface{}{"talks": talks}, nil
+}
+
+func (s *Server) updateTalk(c *call) (interface{}, error) {
+	t, err := c.topic(0)
+	if err != nil {
+		return nil, err
+	}
+	k, err := c.talk(t, 1)
+	if err != nil {
+		return nil, err
+	}
+	if name := c.form.Get("talkName"); name != "" {
+		k.name = name
+		k.updatedAt = s.Now()
+	}
+	return &v1.UpdatedTalkResult{Topic: s.st.v1Topic(t), Talk: s.st.v1Talk(k)}, nil
+}
+
+func (s *Server) deleteTalk(c *call) (interface{}, error) {
+	t, err := c.topic(0)
+	if err != nil {
+		return nil, err
+	}
+	k, err := c.talk(t, 1)
+	if err != nil {
+		return nil, err
+	}
+	delete(s.st.talks, k.id)
+	return &v1.DeletedTalkResult{Topic: s.st.v1Topic(t), Talk: s.st.v1Talk(k), PostIds: k.posts}, nil
+}
+
+func (s *Server) messagesInTalk(c *call, t *topic, k *talk) *v1.MessagesInTalk {
+	var posts []*post
+	for _, p := range s.st.topicPosts(t.id) {
+		if contains(k.posts, p.id) {
+			posts = append(posts, p)
+		}
+	}
+	posts, hasNext := c.page(posts)
+	return &v1.MessagesInTalk{
+		MySpace: s.st.organization(t.space),
+		Topic:   s.st.v1Topic(t),
+		Talk:    s.st.v1Talk(k),
+		Posts:   s.st.v1Posts(posts),
+		HasNext: hasNext,
+	}
+}
+
+func (s *Server) getMessagesInTalk(c *call) (interface{}, error) {
+	t, err := c.topic(0)
+	if err != nil {
+		return nil, err
+	}
+	k, err := c.talk(t, 1)
+	if err != nil {
+		return nil, err
+	}
+	return s.messagesInTalk(c, t, k), nil
+}
+
+func (s *Server) addMessagesToTalk(c *call) (interface{}, error) {
+	t, err := c.topic(0)
+	if err != nil {
+		return nil, err
+	}
+	k, err := c.talk(t, 1)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := c.postIDs(t)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		if !contains(k.posts, id) {
+			k.posts = append(k.posts, id)
+		}
+	}
+	k.updatedAt = s.Now()
+	return s.messagesInTalk(c, t, k), nil
+}
+
+func (s *Server) removeMessagesFromTalk(c *call) (interface{}, error) {
+	t, err := c.topic(0)
+	if err != nil {
+		return nil, err
+	}
+	k, err := c.talk(t, 1)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := c.postIDs(t)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		k.posts = remove(k.posts, id)
+	}
+	k.updatedAt = s.Now()
+	return &v1.RemovedMessagesResult{Topic: s.st.v1Topic(t), Talk: s.st.v1Talk(k), PostIds: ids}, nil
+}
+
+func (s *Server) createTopic(c *call) (interface{}, error) {
+	name := c.form.Get("name")
+	if name == "" {
+		return nil, badRequest("name is required")
+	}
+	spaceKey := c.form.Get("spaceKey")
+	if spaceKey == "" {
+		return nil, badRequest("spaceKey is required")
+	}
+	if sp := s.st.space(spaceKey); sp == nil || !contains(sp.members, c.me) {
+		return nil, forbidden("not a member of space %q", spaceKey)
+	}
+	t := s.addTopic(spaceKey, name, append([]int{c.me}, c.ints("addAccountIds[%d]")...), false)
+	for _, id := range c.ints("addGroupIds[%d]") {
+		if s.st.groups[id] != nil && !contains(t.groups, id) {
+			t.groups = append(t.groups, id)
+		}
+	}
+	return s.st.topicDetails(t), nil
+}
+
+// myTopics returns the topics of the caller in the space, or in all spaces
+// if spaceKey is empty, as FavoriteTopicWithUnread.
+func (c *call) myTopics(spaceKey string) []*v1.FavoriteTopicWithUnread {
+	var ids []int
+	for _, t := range c.s.st.topics {
+		if !t.dm && c.s.st.isMember(t, c.me) && (spaceKey == "" || t.space == spaceKey) {
+			ids = append(ids, t.id)
+		}
+	}
+	sort.Ints(ids)
+	topics := []*v1.FavoriteTopicWithUnread{}
+	for _, id := range ids {
+		t := c.s.st.topics[id]
+		topics = append(topics, &v1.FavoriteTopicWithUnread{
+			FavoriteTopic: v1.FavoriteTopic{Topic: c.s.st.v1Topic(t), Favorite: t.favorites[c.me]},
+			Unread:        *c.s.v1Unread(t, c.me),
+		})
+	}
+	return topics
+}
+
+func (s *Server) getMyTopics(c *call) (interface{}, error) {
+	return map[string]interface{}{"topics": c.myTopics("")}, nil
+}
+
+// getTopic serves both GetTopicDetails and GetTopicMessages, which share the
+// path, so the response has the fields of TopicDetails and TopicMessages.
+func (s *Server) getTopic(c *call) (interface{}, error) {
+	t, err := c.topic(0)
+	if err != nil {
+		return nil, err
+	}
+	posts, hasNext := c.page(s.st.topicPosts(t.id))
+	messages := &v1.TopicMessages{
+		MySpace:  s.st.organization(t.space),
+		Topic:    s.st.v1Topic(t),
+		Bookmark: &v1.Bookmark{PostID: t.bookmarks[c.me], UpdatedAt: t.updatedAt},
+		Posts:    s.st.v1Posts(posts),
+		HasNext:  hasNext,
+	}
+	result := map[string]json.RawMessage{}
+	for _, v := range []interface{}{s.st.topicDetails(t), messages} {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(b, &result); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func (s *Server) updateTopic(c *call) (interface{}, error) {
+	t, err := c.topic(0)
+	if err != nil {
+		return nil, err
+	}
+	if name := c.form.Get("name"); name != "" {
+		t.name = name
+	}
+	if description, ok := c.form["description"]; ok {
+		t.description = description[0]
+	}
+	t.updatedAt = s.Now()
+	return s.st.topicDetails(t), nil
+}
+
+func (s *Server) deleteTopic(c *call) (interface{}, error) {
+	t, err := c.topic(0)
+	if err != nil {
+		return nil, err
+	}
+	v := s.st.v1Topic(t)
+	for _, p := range s.st.topicPosts(t.id) {
+		s.st.deletePost(p.id)
+	}
+	for _, k := range s.st.topicTalks(t.id) {
+		delete(s.st.talks, k.id)
+	}
+	delete(s.st.topics, t.id)
+	return v, nil
+}
+
+func (s *Server) updateTopicMembers(c *call) (interface{}, error) {
+	t, err := c.topic(0)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range c.ints("addAccountIds[%d]") {
+		if s.st.accounts[id] == nil {
+			return nil, badRequest("no account %d", id)
+		}
+		if !contains(t.members, id) {
+			t.members = append(t.members, id)
+		}
+	}
+	for _, id := range c.ints("addGroupIds[%d]") {
+		if s.st.groups[id] == nil {
+			return nil, badRequest("no group %d", id)
+		}
+		if !contains(t.groups, id) {
+			t.groups = append(t.groups, id)
+		}
+	}
+	roles := c.list("invitations[%d].role")
+	for i, email := range c.list("invitations[%d].email") {
+		inv := &invite{email: email}
+		if i < len(roles) {
+			inv.role = roles[i]
+		}
+		for _, a := range s.st.accounts {
+			if a.email == email {
+				inv.accountID = a.id
+			}
+		}
+		t.invites = append(t.invites, inv)
+	}
+	// A removed account may be a member or a pending invitation.
+	for _, id := range c.ints("removeAccounts[%d].id") {
+		t.members = remove(t.members, id)
+		invites := t.invites[:0]
+		for _, inv := range t.invites {
+			if inv.accountID != id {
+				invites = append(invites, inv)
+			}
+		}
+		t.invites = invites
+	}
+	for _, id := range c.ints("removeGroupIds[%d]") {
+		t.groups = remove(t.groups, id)
+	}
+	t.updatedAt = s.Now()
+	return s.st.topicDetails(t), nil
+}
+
+func (s *Server) favoriteTopic(c *call) (interface{}, error) {
+	return c.setFavorite(true)
+}
+
+func (s *Server) unfavoriteTopic(c *call) (interface{}, error) {
+	return c.setFavorite(false)
+}
+
+func (c *call) setFavorite(favorite bool) (interface{}, error) {
+	t, err := c.topic(0)
+	if err != nil {
+		return nil, err
+	}
+	t.favorites[c.me] = favorite
+	return &v1.FavoriteTopic{Topic: c.s.st.v1Topic(t), Favorite: favorite}, nil
+}
+
+func (s *Server) readMessagesInTopic(c *call) (interface{}, error) {
+	t := s.st.topics[c.int("topicId")]
+	if t == nil || !s.st.isMember(t, c.me) {
+		return nil, notFound("no topic %d", c.int("topicId"))
+	}
+	postID := c.int("postId")
+	if postID == 0 {
+		postID, _ = s.st.unread(t, c.me)
+	}
+	if postID > t.bookmarks[c.me] {
+		t.bookmarks[c.me] = postID
+	}
+	return map[string]interface{}{"unread": s.v1Unread(t, c.me)}, nil
+}
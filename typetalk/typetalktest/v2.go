@@ -0,0 +1,286 @@
+package typetalktest
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	v2 "github.com/nulab/go-typetalk/v3/typetalk/v2"
+)
+
+// v2Routes returns the routes of the v2 API and of the few endpoints of v3.
+func (s *Server) v2Routes() []*route {
+	return []*route{
+		newRoute("GET", "v2/likes/receive", s.getLikesReceiveV2),
+		newRoute("GET", "v2/likes/give", s.getLikesGiveV2),
+		newRoute("GET", "v2/likes/discover", s.getLikesDiscoverV2),
+		newRoute("POST", "v2/likes/receive/bookmark/save", s.readReceivedLikes),
+		newRoute("GET", "v2/mentions", s.getMentionListV2),
+		newRoute("GET", "v2/spaces/*/messages/@*", s.getDirectMessagesV2),
+		newRoute("POST", "v2/spaces/*/messages/@*", s.postDirectMessageV2),
+		newRoute("GET", "v2/search/posts", s.searchMessages),
+		newRoute("GET", "v2/notifications/status", s.getNotificationCountV2),
+		newRoute("PUT", "v2/notifications", s.readNotificationV2),
+		newRoute("GET", "v2/topics", s.getMyTopicsV2),
+		newRoute("GET", "v2/messages", s.getMyDirectMessageTopicsV2),
+
+		newRoute("GET", "v3/search/friends", s.getMyFriendsV3),
+		newRoute("PUT", "v3/notifications", s.readNotificationV2),
+	}
+}
+
+// spaceKey returns the spaceKey parameter, which must name a space of the
+// caller.
+func (c *call) spaceKey() (string, error) {
+	key := c.form.Get("spaceKey")
+	if key == "" {
+		return "", badRequest("spaceKey is required")
+	}
+	if sp := c.s.st.space(key); sp == nil || !contains(sp.members, c.me) {
+		return "", forbidden("not a member of space %q", key)
+	}
+	return key, nil
+}
+
+func (s *Server) v2LikedPost(p *post, me int) *v2.LikedPost {
+	lp := &v2.LikedPost{Post: s.st.v2Post(p, me), Likes: []*v2.Like{}}
+	for _, l := range s.st.postLikes(p.id) {
+		lp.Likes = append(lp.Likes, &v2.Like{
+			ID:        l.id,
+			PostID:    l.postID,
+			TopicID:   l.topicID,
+			Comment:   l.comment,
+			Account:   s.st.v2Account(l.accountID),
+			CreatedAt: timePtr(l.createdAt),
+		})
+	}
+	lp.DirectMessage = lp.Post.DirectMessage
+	return lp
+}
+
+func (s *Server) getLikesReceiveV2(c *call) (interface{}, error) {
+	if _, err := c.spaceKey(); err != nil {
+		return nil, err
+	}
+	liked := []*v2.ReceiveLikedPost{}
+	for _, p := range c.likedPosts(c.received) {
+		liked = append(liked, &v2.ReceiveLikedPost{LikedPost: s.v2LikedPost(p, c.me)})
+	}
+	return map[string]interface{}{"likedPosts": liked}, nil
+}
+
+func (s *Server) getLikesGiveV2(c *call) (interface{}, error) {
+	if _, err := c.spaceKey(); err != nil {
+		return nil, err
+	}
+	liked := []*v2.GiveLikedPost{}
+	for _, p := range c.likedPosts(func(l *like) bool { return l.accountID == c.me }) {
+		g := &v2.GiveLikedPost{ReceiveLikedPost: &v2.ReceiveLikedPost{LikedPost: s.v2LikedPost(p, c.me)}}
+		for _, l := range s.st.postLikes(p.id) {
+			if l.accountID == c.me {
+				g.MyLike = &v2.MyLike{ID: l.id, Comment: l.comment, CreatedAt: l.createdAt}
+			}
+		}
+		liked = append(liked, g)
+	}
+	return map[string]interface{}{"likedPosts": liked}, nil
+}
+
+func (s *Server) getLikesDiscoverV2(c *call) (interface{}, error) {
+	if _, err := c.spaceKey(); err != nil {
+		return nil, err
+	}
+	liked := []*v2.DiscoverLikedPost{}
+	for _, p := range c.likedPosts(func(l *like) bool { return true }) {
+		liked = append(liked, &v2.DiscoverLikedPost{LikedPost: s.v2LikedPost(p, c.me)})
+	}
+	return map[string]interface{}{"likedPosts": liked}, nil
+}
+
+func (s *Server) getMentionListV2(c *call) (interface{}, error) {
+	if _, err := c.spaceKey(); err != nil {
+		return nil, err
+	}
+	mentions := []*v2.Mention{}
+	for _, m := range c.mentions() {
+		mentions = append(mentions, s.st.v2Mention(m))
+	}
+	return map[string]interface{}{"mentions": mentions}, nil
+}
+
+func (s *Server) getDirectMessagesV2(c *call) (interface{}, error) {
+	if _, err := c.space(0); err != nil {
+		return nil, err
+	}
+	t, a, err := c.directMessageTopic(c.arg(0), c.arg(1), false)
+	if err != nil {
+		return nil, err
+	}
+	result := &v2.DirectMessages{DirectMessage: &v2.DirectMessage{Account: s.st.v2Account(a.id), Status: s.st.v2Status(a.id)}, Posts: []*v2.Post{}}
+	if t != nil {
+		posts, hasNext := c.page(s.st.topicPosts(t.id))
+		result.Topic = s.st.v2Topic(t)
+		result.Bookmark = &v2.Bookmark{PostID: t.bookmarks[c.me], UpdatedAt: t.updatedAt}
+		for _, p := range posts {
+			result.Posts = append(result.Posts, s.st.v2Post(p, c.me))
+		}
+		result.HasNext = hasNext
+	}
+	return result, nil
+}
+
+func (s *Server) postDirectMessageV2(c *call) (interface{}, error) {
+	if _, err := c.space(0); err != nil {
+		return nil, err
+	}
+	t, _, err := c.directMessageTopic(c.arg(0), c.arg(1), true)
+	if err != nil {
+		return nil, err
+	}
+	p, mentions, err := c.addPost(t)
+	if err != nil {
+		return nil, err
+	}
+	result := &v2.PostedMessageResult{Space: s.st.v2Space(t.space), Topic: s.st.v2Topic(t), Post: s.st.v2Post(p, c.me), Mentions: []*v2.Mention{}}
+	for _, m := range mentions {
+		result.Mentions = append(result.Mentions, s.st.v2Mention(m))
+	}
+	result.DirectMessage = result.Post.DirectMessage
+	return result, nil
+}
+
+// searchIDs returns the IDs of a list parameter of SearchMessagesOptions.
+// The client does not index those lists, so every parameter named after the
+// list is read.
+func (c *call) searchIDs(name string) []int {
+	var ids []int
+	for k, values := range c.form {
+		if !strings.HasPrefix(k, name) {
+			continue
+		}
+		for _, v := range values {
+			if id, err := strconv.Atoi(v); err == nil {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+func (s *Server) searchMessages(c *call) (interface{}, error) {
+	spaceKey, err := c.spaceKey()
+	if err != nil {
+		return nil, err
+	}
+	q := strings.ToLower(c.form.Get("q"))
+	if q == "" {
+		return nil, badRequest("q is required")
+	}
+	topicIDs, accountIDs := c.searchIDs("topicIds"), c.searchIDs("accountIds")
+	from, _ := time.Parse(time.RFC3339, c.form.Get("from"))
+	to, _ := time.Parse(time.RFC3339, c.form.Get("to"))
+	var posts []*post
+	for _, p := range s.st.posts {
+		t := s.st.topics[p.topicID]
+		switch {
+		case t.space != spaceKey || !s.st.isMember(t, c.me):
+		case !strings.Contains(strings.ToLower(p.message), q):
+		case len(topicIDs) > 0 && !contains(topicIDs, t.id):
+		case len(accountIDs) > 0 && !contains(accountIDs, p.accountID):
+		case c.bool("hasAttachments") && len(p.attachments) == 0:
+		case !from.IsZero() && p.createdAt.Before(from):
+		case !to.IsZero() && p.createdAt.After(to):
+		default:
+			posts = append(posts, p)
+		}
+	}
+	sort.Slice(posts, func(i, j int) bool { return posts[i].id > posts[j].id })
+	result := &v2.SearchMessagesResult{Count: len(posts), Posts: []*v2.Post{}}
+	if len(posts) > s.SearchLimit {
+		posts = posts[:s.SearchLimit]
+		result.IsLimited = true
+	}
+	for _, p := range posts {
+		result.Posts = append(result.Posts, s.st.v2Post(p, c.me))
+	}
+	return result, nil
+}
+
+func (s *Server) getNotificationCountV2(c *call) (interface{}, error) {
+	// NotificationCount is made of anonymous structs, so the response is
+	// built as a map.
+	a := s.st.accounts[c.me]
+	statuses := []interface{}{}
+	for _, sp := range s.st.spacesOf(c.me) {
+		space := s.st.v2Space(sp.key)
+		statuses = append(statuses, map[string]interface{}{
+			"mySpace": &v2.MySpace{Space: space, MyRole: "ADMIN", MyPlan: v2.MyPlan{Enabled: true}},
+			"space":   space,
+			"access":  &v2.Access{Unopened: a.unopened},
+			"like": map[string]interface{}{"receive": map[string]interface{}{
+				"hasUnread":  s.hasUnreadLikes(c.me),
+				"readLikeId": a.readLikeID,
+			}},
+			"directMessage": map[string]interface{}{"unreadTopics": len(s.unreadTopics(c.me, sp.key, true))},
+		})
+	}
+	return map[string]interface{}{
+		"statuses":     statuses,
+		"doNotDisturb": &v2.DoNotDisturb{Manual: &v2.Manual{}, Scheduled: &v2.Scheduled{}},
+	}, nil
+}
+
+func (s *Server) readNotificationV2(c *call) (interface{}, error) {
+	s.st.accounts[c.me].unopened = 0
+	result := &v2.ReadNotificationResult{Access: &v2.Access{}}
+	if key := c.form.Get("spaceKey"); key != "" {
+		result.Space = s.st.v2Space(key)
+	}
+	return result, nil
+}
+
+func (s *Server) getMyTopicsV2(c *call) (interface{}, error) {
+	spaceKey, err := c.spaceKey()
+	if err != nil {
+		return nil, err
+	}
+	topics := []*v2.FavoriteTopicWithUnread{}
+	for _, t := range c.myTopics(spaceKey) {
+		topics = append(topics, &v2.FavoriteTopicWithUnread{
+			Topic:    *s.st.v2Topic(s.st.topics[t.Topic.ID]),
+			Favorite: t.Favorite,
+			Unread:   v2.Unread{TopicID: t.Unread.TopicID, PostID: t.Unread.PostID, Count: t.Unread.Count},
+		})
+	}
+	return map[string]interface{}{"topics": topics}, nil
+}
+
+func (s *Server) getMyDirectMessageTopicsV2(c *call) (interface{}, error) {
+	spaceKey, err := c.spaceKey()
+	if err != nil {
+		return nil, err
+	}
+	topics := []*v2.DirectMessageTopic{}
+	for _, t := range c.directMessageTopics(spaceKey) {
+		other := s.st.otherMember(t, c.me)
+		last, count := s.st.unread(t, c.me)
+		topics = append(topics, &v2.DirectMessageTopic{
+			Topic:         s.st.v2Topic(t),
+			Unread:        &v2.Unread{TopicID: t.id, PostID: last, Count: count},
+			DirectMessage: &v2.DirectMessage{Account: s.st.v2Account(other), Status: s.st.v2Status(other)},
+		})
+	}
+	return map[string]interface{}{"topics": topics}, nil
+}
+
+func (s *Server) getMyFriendsV3(c *call) (interface{}, error) {
+	if _, err := c.spaceKey(); err != nil {
+		return nil, err
+	}
+	accounts := []*v2.Account{}
+	for _, id := range c.friends() {
+		accounts = append(accounts, s.st.v2Account(id))
+	}
+	return map[string]interface{}{"accounts": accounts}, nil
+}
@@ -0,0 +1,24 @@
+package typetalktest
+
+import (
+	v4 "github.com/nulab/go-typetalk/v3/typetalk/v4"
+)
+
+// v4Routes returns the routes of the v4 API.
+func (s *Server) v4Routes() []*route {
+	return []*route{
+		newRoute("GET", "v4/search/friends", s.getMyFriendsV4),
+	}
+}
+
+func (s *Server) getMyFriendsV4(c *call) (interface{}, error) {
+	if _, err := c.spaceKey(); err != nil {
+		return nil, err
+	}
+	friends := &v4.Friends{Accounts: []*v4.AccountStatus{}}
+	for _, id := range c.friends() {
+		friends.Accounts = append(friends.Accounts, s.st.v4AccountStatus(id))
+	}
+	friends.Count = len(friends.Accounts)
+	return friends, nil
+}
@@ -0,0 +1,41 @@
+package typetalktest
+
+import (
+	v5 "github.com/nulab/go-typetalk/v3/typetalk/v5"
+)
+
+// v5Routes returns the routes of the v5 API.
+func (s *Server) v5Routes() []*route {
+	return []*route{
+		newRoute("GET", "v5/notifications/status", s.getNotificationCountV5),
+	}
+}
+
+func (s *Server) getNotificationCountV5(c *call) (interface{}, error) {
+	// NotificationCount is made of anonymous structs, so the response is
+	// built as a map. Unlike v2, the unread topics are listed and the
+	// notification settings are apart from the statuses.
+	a := s.st.accounts[c.me]
+	statuses := []interface{}{}
+	for _, sp := range s.st.spacesOf(c.me) {
+		statuses = append(statuses, map[string]interface{}{
+			"mySpace": &v5.MySpace{Space: s.st.v5Space(sp.key), MyRole: "ADMIN", MyPlan: v5.MyPlan{Enabled: true}},
+			"access":  &v5.Access{Unopened: a.unopened},
+			"like": map[string]interface{}{"receive": map[string]interface{}{
+				"hasUnread":  s.hasUnreadLikes(c.me),
+				"readLikeId": a.readLikeID,
+			}},
+			"unreads": map[string]interface{}{
+				"topicIds":   s.unreadTopics(c.me, sp.key, false),
+				"dmTopicIds": s.unreadTopics(c.me, sp.key, true),
+			},
+		})
+	}
+	return map[string]interface{}{
+		"statuses": statuses,
+		"notificationSettings": map[string]interface{}{
+			"favoriteTopicMobile": false,
+			"doNotDisturb":        &v5.DoNotDisturb{Manual: &v5.Manual{}, Scheduled: &v5.Scheduled{}},
+		},
+	}, nil
+}
@@ -33,7 +33,8 @@ type Theme struct {
 	Name   string `json:"name"`
 }
 
-// Status represents online status of the user.
+// Status represents online status of the user. Web and Mobile are untyped,
+// as no captured response shows their shape.
 type Status struct {
 	Presence *string     `json:"presence"`
 	Web      interface{} `json:"web"`
@@ -51,6 +52,8 @@ type Profile AccountStatus
 
 // MyProfile represents the user's information.
 type MyProfile struct {
+	RawJSON
+
 	Account *Account `json:"account"`
 	Lang    string   `json:"lang"`
 	Theme   *Theme   `json:"theme"`
@@ -62,12 +65,16 @@ type MyProfile struct {
 
 // Friends represents accounts search result.
 type Friends struct {
+	RawJSON
+
 	Count    int              `json:"count"`
 	Accounts []*AccountStatus `json:"accounts"`
 }
 
 // OnlineStatus contains accounts information.
 type OnlineStatus struct {
+	RawJSON
+
 	Accounts []*AccountStatus `json:"accounts"`
 }
 
@@ -0,0 +1,110 @@
+package v1
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/nulab/go-typetalk/typetalk/shared"
+)
+
+//go:generate go run ../internal/mockgen -o mocks/mocks.go api.go
+
+// AccountsAPI is the interface of AccountsService.
+type AccountsAPI interface {
+	GetMyProfile(ctx context.Context) (*MyProfile, *shared.Response, error)
+	GetFriendProfile(ctx context.Context, accountName string) (*Profile, *shared.Response, error)
+	GetMyFriends(ctx context.Context, opt *GetMyFriendsOptions) (*Friends, *shared.Response, error)
+	SearchAccounts(ctx context.Context, nameOrEmailAddress string) (*Account, *shared.Response, error)
+	GetOnlineStatus(ctx context.Context, accountIds ...int) (*OnlineStatus, *shared.Response, error)
+}
+
+// FilesAPI is the interface of FilesService.
+type FilesAPI interface {
+	UploadAttachmentFile(ctx context.Context, topicID int, file *os.File) (*AttachmentFile, *shared.Response, error)
+	DownloadAttachmentFile(ctx context.Context, topicID, postID, attachmentID int, filename string) (io.ReadCloser, error)
+}
+
+// LikesAPI is the interface of LikesService.
+type LikesAPI interface {
+	GetLikesReceive(ctx context.Context, opt *GetLikesOptions) ([]*ReceiveLikedPost, *shared.Response, error)
+	GetLikesGive(ctx context.Context, opt *GetLikesOptions) ([]*GiveLikedPost, *shared.Response, error)
+	GetLikesDiscover(ctx context.Context, opt *GetLikesOptions) ([]*DiscoverLikedPost, *shared.Response, error)
+	ReadReceivedLikes(ctx context.Context, likeID int) (*ReadReceivedLikesResult, *shared.Response, error)
+}
+
+// MentionsAPI is the interface of MentionsService.
+type MentionsAPI interface {
+	ReadMention(ctx context.Context, mentionID int) (*Mention, *shared.Response, error)
+	GetMentionList(ctx context.Context, opt *GetMentionListOptions) ([]*Mention, *shared.Response, error)
+}
+
+// MessagesAPI is the interface of MessagesService.
+type MessagesAPI interface {
+	PostMessage(ctx context.Context, topicID int, message string, opt *PostMessageOptions) (*PostedMessageResult, *shared.Response, error)
+	UpdateMessage(ctx context.Context, topicID, postID int, message string) (*UpdatedMessageResult, *shared.Response, error)
+	DeleteMessage(ctx context.Context, topicID, postID int) (*Post, *shared.Response, error)
+	GetMessage(ctx context.Context, topicID, postID int) (*Message, *shared.Response, error)
+	LikeMessage(ctx context.Context, topicID, postID int) (*LikedMessageResult, *shared.Response, error)
+	UnlikeMessage(ctx context.Context, topicID, postID int) (*Like, *shared.Response, error)
+	PostDirectMessage(ctx context.Context, accountName, message string, opt *PostMessageOptions) (*PostedMessageResult, *shared.Response, error)
+	GetDirectMessages(ctx context.Context, accountName string, opt *GetMessagesOptions) (*DirectMessages, *shared.Response, error)
+	GetMyDirectMessageTopics(ctx context.Context) ([]*DirectMessageTopic, *shared.Response, error)
+}
+
+// NotificationsAPI is the interface of NotificationsService.
+type NotificationsAPI interface {
+	GetNotificationList(ctx context.Context) (*NotificationList, *shared.Response, error)
+	GetNotificationCount(ctx context.Context) (*NotificationCount, *shared.Response, error)
+	ReadNotification(ctx context.Context) (*Access, *shared.Response, error)
+}
+
+// OrganizationsAPI is the interface of OrganizationsService.
+type OrganizationsAPI interface {
+	GetMyOrganizations(ctx context.Context, excludesGuest bool) ([]*Organization, *shared.Response, error)
+	GetOrganizationMembers(ctx context.Context, spaceKey string) (*OrganizationMembers, *shared.Response, error)
+}
+
+// StatusesAPI is the interface of StatusesService.
+type StatusesAPI interface {
+	SaveUserStatus(ctx context.Context, spaceKey, emoji string, opt *SaveUserStatusOptions) (*SaveUserStatusResult, *shared.Response, error)
+}
+
+// TalksAPI is the interface of TalksService.
+type TalksAPI interface {
+	CreateTalk(ctx context.Context, topicID int, talkName string, postIds ...int) (*CreatedTalkResult, *shared.Response, error)
+	UpdateTalk(ctx context.Context, topicID, talkID int, talkName string) (*UpdatedTalkResult, *shared.Response, error)
+	DeleteTalk(ctx context.Context, topicID, talkID int) (*DeletedTalkResult, *shared.Response, error)
+	GetTalkList(ctx context.Context, topicID int) ([]*Talk, *shared.Response, error)
+	GetMessagesInTalk(ctx context.Context, topicID, talkID int, opt *GetMessagesOptions) (*MessagesInTalk, *shared.Response, error)
+	AddMessagesToTalk(ctx context.Context, topicID, talkID int, postIds ...int) (*MessagesInTalk, *shared.Response, error)
+	RemoveMessagesFromTalk(ctx context.Context, topicID, talkID int, postIds ...int) (*RemovedMessagesResult, *shared.Response, error)
+}
+
+// TopicsAPI is the interface of TopicsService.
+type TopicsAPI interface {
+	CreateTopic(ctx context.Context, opt *CreateTopicOptions) (*TopicDetails, *shared.Response, error)
+	UpdateTopic(ctx context.Context, topicID int, opt *UpdateTopicOptions) (*TopicDetails, *shared.Response, error)
+	DeleteTopic(ctx context.Context, topicID int) (*Topic, *shared.Response, error)
+	GetTopicDetails(ctx context.Context, topicID int) (*TopicDetails, *shared.Response, error)
+	GetTopicMessages(ctx context.Context, topicID int, opt *GetTopicMessagesOptions) (*TopicMessages, *shared.Response, error)
+	UpdateTopicMembers(ctx context.Context, topicID int, opt *UpdateTopicMembersOptions) (*TopicDetails, *shared.Response, error)
+	FavoriteTopic(ctx context.Context, topicID int) (*FavoriteTopic, *shared.Response, error)
+	UnfavoriteTopic(ctx context.Context, topicID int) (*FavoriteTopic, *shared.Response, error)
+	ReadMessagesInTopic(ctx context.Context, topicID, postID int) (*Unread, *shared.Response, error)
+	// Deprecated: Use GetMyTopics v2
+	GetMyTopics(ctx context.Context) ([]*FavoriteTopicWithUnread, *shared.Response, error)
+}
+
+var (
+	_ AccountsAPI      = (*AccountsService)(nil)
+	_ FilesAPI         = (*FilesService)(nil)
+	_ LikesAPI         = (*LikesService)(nil)
+	_ MentionsAPI      = (*MentionsService)(nil)
+	_ MessagesAPI      = (*MessagesService)(nil)
+	_ NotificationsAPI = (*NotificationsService)(nil)
+	_ OrganizationsAPI = (*OrganizationsService)(nil)
+	_ StatusesAPI      = (*StatusesService)(nil)
+	_ TalksAPI         = (*TalksService)(nil)
+	_ TopicsAPI        = (*TopicsService)(nil)
+)
@@ -62,6 +62,9 @@ func (s *FilesService) DownloadAttachmentFile(ctx context.Context, topicID, post
 		return nil, err
 	}
 	req.Header.Set("Accept", internal.DefaultMediaType)
+	if err := s.client.Authorize(ctx, req); err != nil {
+		return nil, err
+	}
 
 	resp, err := s.client.Client.Do(req)
 	if err != nil {
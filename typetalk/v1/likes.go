@@ -106,6 +106,8 @@ type readReceivedLikesOptions struct {
 
 // ReadReceivedLikesResult represents a like that is marked as read.
 type ReadReceivedLikesResult struct {
+	RawJSON
+
 	Like struct {
 		Receive struct {
 			HasUnread  bool `json:"hasUnread"`
@@ -2,6 +2,7 @@ package v1
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -21,7 +22,7 @@ type Post struct {
 	Attachments []*AttachmentFile `json:"attachments"`
 	Likes       []*Like           `json:"likes"`
 	Talks       []*Talk           `json:"talks"`
-	Links       []interface{}     `json:"links"`
+	Links       []*Link           `json:"links"`
 	CreatedAt   *time.Time        `json:"createdAt"`
 	UpdatedAt   *time.Time        `json:"updatedAt"`
 }
@@ -36,6 +37,8 @@ type Like struct {
 }
 
 type PostedMessageResult struct {
+	RawJSON
+
 	Space                  *Space         `json:"space"`
 	Topic                  *Topic         `json:"topic"`
 	Post                   *Post          `json:"post"`
@@ -46,7 +49,39 @@ type PostedMessageResult struct {
 
 type UpdatedMessageResult PostedMessageResult
 
+// Link represents the metadata of a URL in a message.
+type Link struct {
+	ID          int        `json:"id"`
+	URL         string     `json:"url"`
+	ContentType string     `json:"contentType"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	ImageURL    string     `json:"imageUrl"`
+	Embed       *LinkEmbed `json:"embed"`
+	CreatedAt   *time.Time `json:"createdAt"`
+	UpdatedAt   *time.Time `json:"updatedAt"`
+}
+
+// LinkEmbed represents the oEmbed information of a link.
+type LinkEmbed struct {
+	Type         string      `json:"type"`
+	Version      json.Number `json:"version"`
+	ProviderName string      `json:"provider_name"`
+	ProviderURL  string      `json:"provider_url"`
+	Title        string      `json:"title"`
+	AuthorName   string      `json:"author_name"`
+	AuthorURL    string      `json:"author_url"`
+	HTML         string      `json:"html"`
+	URL          string      `json:"url"`
+	ThumbnailURL string      `json:"thumbnail_url"`
+	Width        int         `json:"width"`
+	Height       int         `json:"height"`
+}
+
+// Team is untyped, as no captured response shows the team of a topic.
 type Message struct {
+	RawJSON
+
 	MySpace                *Organization `json:"mySpace"`
 	Team                   interface{}   `json:"team"`
 	Topic                  *Topic        `json:"topic"`
@@ -56,6 +91,8 @@ type Message struct {
 }
 
 type LikedMessageResult struct {
+	RawJSON
+
 	Like          *Like          `json:"like"`
 	Post          *Post          `json:"post"`
 	Topic         *Topic         `json:"topic"`
@@ -65,6 +102,8 @@ type LikedMessageResult struct {
 type DirectMessage AccountStatus
 
 type DirectMessages struct {
+	RawJSON
+
 	Topic         *Topic         `json:"topic"`
 	DirectMessage *DirectMessage `json:"directMessage"`
 	Bookmark      *Bookmark      `json:"bookmark"`
@@ -0,0 +1,639 @@
+// Code generated by mockgen from api.go; DO NOT EDIT.
+
+// Package mocks provides mocks of the services of v1.Client, which record
+// their calls and return the results of programmable functions.
+package mocks
+
+import (
+	"context"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/nulab/go-typetalk/typetalk/shared"
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+)
+
+// Call is a call made to a mock.
+type Call struct {
+	// Method is the name of the method.
+	Method string
+	// Args are the arguments of the call, without the context.
+	Args []interface{}
+}
+
+type recorder struct {
+	mu    sync.Mutex
+	calls []*Call
+}
+
+func (r *recorder) record(method string, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, &Call{Method: method, Args: args})
+}
+
+// Calls returns the calls made to the mock.
+func (r *recorder) Calls() []*Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*Call(nil), r.calls...)
+}
+
+// CallsTo returns the calls made to a method of the mock.
+func (r *recorder) CallsTo(method string) []*Call {
+	var calls []*Call
+	for _, c := range r.Calls() {
+		if c.Method == method {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// Client holds a mock of every service of v1.Client.
+type Client struct {
+	Accounts      *AccountsAPI
+	Files         *FilesAPI
+	Mentions      *MentionsAPI
+	Messages      *MessagesAPI
+	Notifications *NotificationsAPI
+	Organizations *OrganizationsAPI
+	Talks         *TalksAPI
+	Topics        *TopicsAPI
+	Likes         *LikesAPI
+	Statuses      *StatusesAPI
+}
+
+// NewClient returns a Client with a new mock of every service.
+func NewClient() *Client {
+	return &Client{
+		Accounts:      &AccountsAPI{},
+		Files:         &FilesAPI{},
+		Mentions:      &MentionsAPI{},
+		Messages:      &MessagesAPI{},
+		Notifications: &NotificationsAPI{},
+		Organizations: &OrganizationsAPI{},
+		Talks:         &TalksAPI{},
+		Topics:        &TopicsAPI{},
+		Likes:         &LikesAPI{},
+		Statuses:      &StatusesAPI{},
+	}
+}
+
+// Client returns a v1.Client whose services are the mocks. It is made by
+// v1.NewClient, so its setters, such as SetTypetalkToken, can be called.
+func (c *Client) Client() *v1.Client {
+	client := v1.NewClient(nil)
+	client.Accounts = c.Accounts
+	client.Files = c.Files
+	client.Mentions = c.Mentions
+	client.Messages = c.Messages
+	client.Notifications = c.Notifications
+	client.Organizations = c.Organizations
+	client.Talks = c.Talks
+	client.Topics = c.Topics
+	client.Likes = c.Likes
+	client.Statuses = c.Statuses
+	return client
+}
+
+// AccountsAPI is a mock of v1.AccountsAPI. A method returns the results of the
+// matching Func field, or zero values when the field is nil.
+type AccountsAPI struct {
+	recorder
+
+	GetMyProfileFunc     func(ctx context.Context) (*v1.MyProfile, *shared.Response, error)
+	GetFriendProfileFunc func(ctx context.Context, accountName string) (*v1.Profile, *shared.Response, error)
+	GetMyFriendsFunc     func(ctx context.Context, opt *v1.GetMyFriendsOptions) (*v1.Friends, *shared.Response, error)
+	SearchAccountsFunc   func(ctx context.Context, nameOrEmailAddress string) (*v1.Account, *shared.Response, error)
+	GetOnlineStatusFunc  func(ctx context.Context, accountIds ...int) (*v1.OnlineStatus, *shared.Response, error)
+}
+
+var _ v1.AccountsAPI = (*AccountsAPI)(nil)
+
+// GetMyProfile records the call and calls GetMyProfileFunc.
+func (m *AccountsAPI) GetMyProfile(ctx context.Context) (r0 *v1.MyProfile, r1 *shared.Response, r2 error) {
+	m.record("GetMyProfile")
+	if m.GetMyProfileFunc != nil {
+		return m.GetMyProfileFunc(ctx)
+	}
+	return
+}
+
+// GetFriendProfile records the call and calls GetFriendProfileFunc.
+func (m *AccountsAPI) GetFriendProfile(ctx context.Context, accountName string) (r0 *v1.Profile, r1 *shared.Response, r2 error) {
+	m.record("GetFriendProfile", accountName)
+	if m.GetFriendProfileFunc != nil {
+		return m.GetFriendProfileFunc(ctx, accountName)
+	}
+	return
+}
+
+// GetMyFriends records the call and calls GetMyFriendsFunc.
+func (m *AccountsAPI) GetMyFriends(ctx context.Context, opt *v1.GetMyFriendsOptions) (r0 *v1.Friends, r1 *shared.Response, r2 error) {
+	m.record("GetMyFriends", opt)
+	if m.GetMyFriendsFunc != nil {
+		return m.GetMyFriendsFunc(ctx, opt)
+	}
+	return
+}
+
+// SearchAccounts records the call and calls SearchAccountsFunc.
+func (m *AccountsAPI) SearchAccounts(ctx context.Context, nameOrEmailAddress string) (r0 *v1.Account, r1 *shared.Response, r2 error) {
+	m.record("SearchAccounts", nameOrEmailAddress)
+	if m.SearchAccountsFunc != nil {
+		return m.SearchAccountsFunc(ctx, nameOrEmailAddress)
+	}
+	return
+}
+
+// GetOnlineStatus records the call and calls GetOnlineStatusFunc.
+func (m *AccountsAPI) GetOnlineStatus(ctx context.Context, accountIds ...int) (r0 *v1.OnlineStatus, r1 *shared.Response, r2 error) {
+	m.record("GetOnlineStatus", accountIds)
+	if m.GetOnlineStatusFunc != nil {
+		return m.GetOnlineStatusFunc(ctx, accountIds...)
+	}
+	return
+}
+
+// FilesAPI is a mock of v1.FilesAPI. A method returns the results of the
+// matching Func field, or zero values when the field is nil.
+type FilesAPI struct {
+	recorder
+
+	UploadAttachmentFileFunc   func(ctx context.Context, topicID int, file *os.File) (*v1.AttachmentFile, *shared.Response, error)
+	DownloadAttachmentFileFunc func(ctx context.Context, topicID int, postID int, attachmentID int, filename string) (io.ReadCloser, error)
+}
+
+var _ v1.FilesAPI = (*FilesAPI)(nil)
+
+// UploadAttachmentFile records the call and calls UploadAttachmentFileFunc.
+func (m *FilesAPI) UploadAttachmentFile(ctx context.Context, topicID int, file *os.File) (r0 *v1.AttachmentFile, r1 *shared.Response, r2 error) {
+	m.record("UploadAttachmentFile", topicID, file)
+	if m.UploadAttachmentFileFunc != nil {
+		return m.UploadAttachmentFileFunc(ctx, topicID, file)
+	}
+	return
+}
+
+// DownloadAttachmentFile records the call and calls DownloadAttachmentFileFunc.
+func (m *FilesAPI) DownloadAttachmentFile(ctx context.Context, topicID int, postID int, attachmentID int, filename string) (r0 io.ReadCloser, r1 error) {
+	m.record("DownloadAttachmentFile", topicID, postID, attachmentID, filename)
+	if m.DownloadAttachmentFileFunc != nil {
+		return m.DownloadAttachmentFileFunc(ctx, topicID, postID, attachmentID, filename)
+	}
+	return
+}
+
+// LikesAPI is a mock of v1.LikesAPI. A method returns the results of the
+// matching Func field, or zero values when the field is nil.
+type LikesAPI struct {
+	recorder
+
+	GetLikesReceiveFunc   func(ctx context.Context, opt *v1.GetLikesOptions) ([]*v1.ReceiveLikedPost, *shared.Response, error)
+	GetLikesGiveFunc      func(ctx context.Context, opt *v1.GetLikesOptions) ([]*v1.GiveLikedPost, *shared.Response, error)
+	GetLikesDiscoverFunc  func(ctx context.Context, opt *v1.GetLikesOptions) ([]*v1.DiscoverLikedPost, *shared.Response, error)
+	ReadReceivedLikesFunc func(ctx context.Context, likeID int) (*v1.ReadReceivedLikesResult, *shared.Response, error)
+}
+
+var _ v1.LikesAPI = (*LikesAPI)(nil)
+
+// GetLikesReceive records the call and calls GetLikesReceiveFunc.
+func (m *LikesAPI) GetLikesReceive(ctx context.Context, opt *v1.GetLikesOptions) (r0 []*v1.ReceiveLikedPost, r1 *shared.Response, r2 error) {
+	m.record("GetLikesReceive", opt)
+	if m.GetLikesReceiveFunc != nil {
+		return m.GetLikesReceiveFunc(ctx, opt)
+	}
+	return
+}
+
+// GetLikesGive records the call and calls GetLikesGiveFunc.
+func (m *LikesAPI) GetLikesGive(ctx context.Context, opt *v1.GetLikesOptions) (r0 []*v1.GiveLikedPost, r1 *shared.Response, r2 error) {
+	m.record("GetLikesGive", opt)
+	if m.GetLikesGiveFunc != nil {
+		return m.GetLikesGiveFunc(ctx, opt)
+	}
+	return
+}
+
+// GetLikesDiscover records the call and calls GetLikesDiscoverFunc.
+func (m *LikesAPI) GetLikesDiscover(ctx context.Context, opt *v1.GetLikesOptions) (r0 []*v1.DiscoverLikedPost, r1 *shared.Response, r2 error) {
+	m.record("GetLikesDiscover", opt)
+	if m.GetLikesDiscoverFunc != nil {
+		return m.GetLikesDiscoverFunc(ctx, opt)
+	}
+	return
+}
+
+// ReadReceivedLikes records the call and calls ReadReceivedLikesFunc.
+func (m *LikesAPI) ReadReceivedLikes(ctx context.Context, likeID int) (r0 *v1.ReadReceivedLikesResult, r1 *shared.Response, r2 error) {
+	m.record("ReadReceivedLikes", likeID)
+	if m.ReadReceivedLikesFunc != nil {
+		return m.ReadReceivedLikesFunc(ctx, likeID)
+	}
+	return
+}
+
+// MentionsAPI is a mock of v1.MentionsAPI. A method returns the results of the
+// matching Func field, or zero values when the field is nil.
+type MentionsAPI struct {
+	recorder
+
+	ReadMentionFunc    func(ctx context.Context, mentionID int) (*v1.Mention, *shared.Response, error)
+	GetMentionListFunc func(ctx context.Context, opt *v1.GetMentionListOptions) ([]*v1.Mention, *shared.Response, error)
+}
+
+var _ v1.MentionsAPI = (*MentionsAPI)(nil)
+
+// ReadMention records the call and calls ReadMentionFunc.
+func (m *MentionsAPI) ReadMention(ctx context.Context, mentionID int) (r0 *v1.Mention, r1 *shared.Response, r2 error) {
+	m.record("ReadMention", mentionID)
+	if m.ReadMentionFunc != nil {
+		return m.ReadMentionFunc(ctx, mentionID)
+	}
+	return
+}
+
+// GetMentionList records the call and calls GetMentionListFunc.
+func (m *MentionsAPI) GetMentionList(ctx context.Context, opt *v1.GetMentionListOptions) (r0 []*v1.Mention, r1 *shared.Response, r2 error) {
+	m.record("GetMentionList", opt)
+	if m.GetMentionListFunc != nil {
+		return m.GetMentionListFunc(ctx, opt)
+	}
+	return
+}
+
+// MessagesAPI is a mock of v1.MessagesAPI. A method returns the results of the
+// matching Func field, or zero values when the field is nil.
+type MessagesAPI struct {
+	recorder
+
+	PostMessageFunc              func(ctx context.Context, topicID int, message string, opt *v1.PostMessageOptions) (*v1.PostedMessageResult, *shared.Response, error)
+	UpdateMessageFunc            func(ctx context.Context, topicID int, postID int, message string) (*v1.UpdatedMessageResult, *shared.Response, error)
+	DeleteMessageFunc            func(ctx context.Context, topicID int, postID int) (*v1.Post, *shared.Response, error)
+	GetMessageFunc               func(ctx context.Context, topicID int, postID int) (*v1.Message, *shared.Response, error)
+	LikeMessageFunc              func(ctx context.Context, topicID int, postID int) (*v1.LikedMessageResult, *shared.Response, error)
+	UnlikeMessageFunc            func(ctx context.Context, topicID int, postID int) (*v1.Like, *shared.Response, error)
+	PostDirectMessageFunc        func(ctx context.Context, accountName string, message string, opt *v1.PostMessageOptions) (*v1.PostedMessageResult, *shared.Response, error)
+	GetDirectMessagesFunc        func(ctx context.Context, accountName string, opt *v1.GetMessagesOptions) (*v1.DirectMessages, *shared.Response, error)
+	GetMyDirectMessageTopicsFunc func(ctx context.Context) ([]*v1.DirectMessageTopic, *shared.Response, error)
+}
+
+var _ v1.MessagesAPI = (*MessagesAPI)(nil)
+
+// PostMessage records the call and calls PostMessageFunc.
+func (m *MessagesAPI) PostMessage(ctx context.Context, topicID int, message string, opt *v1.PostMessageOptions) (r0 *v1.PostedMessageResult, r1 *shared.Response, r2 error) {
+	m.record("PostMessage", topicID, message, opt)
+	if m.PostMessageFunc != nil {
+		return m.PostMessageFunc(ctx, topicID, message, opt)
+	}
+	return
+}
+
+// UpdateMessage records the call and calls UpdateMessageFunc.
+func (m *MessagesAPI) UpdateMessage(ctx context.Context, topicID int, postID int, message string) (r0 *v1.UpdatedMessageResult, r1 *shared.Response, r2 error) {
+	m.record("UpdateMessage", topicID, postID, message)
+	if m.UpdateMessageFunc != nil {
+		return m.UpdateMessageFunc(ctx, topicID, postID, message)
+	}
+	return
+}
+
+// DeleteMessage records the call and calls DeleteMessageFunc.
+func (m *MessagesAPI) DeleteMessage(ctx context.Context, topicID int, postID int) (r0 *v1.Post, r1 *shared.Response, r2 error) {
+	m.record("DeleteMessage", topicID, postID)
+	if m.DeleteMessageFunc != nil {
+		return m.DeleteMessageFunc(ctx, topicID, postID)
+	}
+	return
+}
+
+// GetMessage records the call and calls GetMessageFunc.
+func (m *MessagesAPI) GetMessage(ctx context.Context, topicID int, postID int) (r0 *v1.Message, r1 *shared.Response, r2 error) {
+	m.record("GetMessage", topicID, postID)
+	if m.GetMessageFunc != nil {
+		return m.GetMessageFunc(ctx, topicID, postID)
+	}
+	return
+}
+
+// LikeMessage records the call and calls LikeMessageFunc.
+func (m *MessagesAPI) LikeMessage(ctx context.Context, topicID int, postID int) (r0 *v1.LikedMessageResult, r1 *shared.Response, r2 error) {
+	m.record("LikeMessage", topicID, postID)
+	if m.LikeMessageFunc != nil {
+		return m.LikeMessageFunc(ctx, topicID, postID)
+	}
+	return
+}
+
+// UnlikeMessage records the call and calls UnlikeMessageFunc.
+func (m *MessagesAPI) UnlikeMessage(ctx context.Context, topicID int, postID int) (r0 *v1.Like, r1 *shared.Response, r2 error) {
+	m.record("UnlikeMessage", topicID, postID)
+	if m.UnlikeMessageFunc != nil {
+		return m.UnlikeMessageFunc(ctx, topicID, postID)
+	}
+	return
+}
+
+// PostDirectMessage records the call and calls PostDirectMessageFunc.
+func (m *MessagesAPI) PostDirectMessage(ctx context.Context, accountName string, message string, opt *v1.PostMessageOptions) (r0 *v1.PostedMessageResult, r1 *shared.Response, r2 error) {
+	m.record("PostDirectMessage", accountName, message, opt)
+	if m.PostDirectMessageFunc != nil {
+		return m.PostDirectMessageFunc(ctx, accountName, message, opt)
+	}
+	return
+}
+
+// GetDirectMessages records the call and calls GetDirectMessagesFunc.
+func (m *MessagesAPI) GetDirectMessages(ctx context.Context, accountName string, opt *v1.GetMessagesOptions) (r0 *v1.DirectMessages, r1 *shared.Response, r2 error) {
+	m.record("GetDirectMessages", accountName, opt)
+	if m.GetDirectMessagesFunc != nil {
+		return m.GetDirectMessagesFunc(ctx, accountName, opt)
+	}
+	return
+}
+
+// GetMyDirectMessageTopics records the call and calls GetMyDirectMessageTopicsFunc.
+func (m *MessagesAPI) GetMyDirectMessageTopics(ctx context.Context) (r0 []*v1.DirectMessageTopic, r1 *shared.Response, r2 error) {
+	m.record("GetMyDirectMessageTopics")
+	if m.GetMyDirectMessageTopicsFunc != nil {
+		return m.GetMyDirectMessageTopicsFunc(ctx)
+	}
+	return
+}
+
+// NotificationsAPI is a mock of v1.NotificationsAPI. A method returns the results of the
+// matching Func field, or zero values when the field is nil.
+type NotificationsAPI struct {
+	recorder
+
+	GetNotificationListFunc  func(ctx context.Context) (*v1.NotificationList, *shared.Response, error)
+	GetNotificationCountFunc func(ctx context.Context) (*v1.NotificationCount, *shared.Response, error)
+	ReadNotificationFunc     func(ctx context.Context) (*v1.Access, *shared.Response, error)
+}
+
+var _ v1.NotificationsAPI = (*NotificationsAPI)(nil)
+
+// GetNotificationList records the call and calls GetNotificationListFunc.
+func (m *NotificationsAPI) GetNotificationList(ctx context.Context) (r0 *v1.NotificationList, r1 *shared.Response, r2 error) {
+	m.record("GetNotificationList")
+	if m.GetNotificationListFunc != nil {
+		return m.GetNotificationListFunc(ctx)
+	}
+	return
+}
+
+// GetNotificationCount records the call and calls GetNotificationCountFunc.
+func (m *NotificationsAPI) GetNotificationCount(ctx context.Context) (r0 *v1.NotificationCount, r1 *shared.Response, r2 error) {
+	m.record("GetNotificationCount")
+	if m.GetNotificationCountFunc != nil {
+		return m.GetNotificationCountFunc(ctx)
+	}
+	return
+}
+
+// ReadNotification records the call and calls ReadNotificationFunc.
+func (m *NotificationsAPI) ReadNotification(ctx context.Context) (r0 *v1.Access, r1 *shared.Response, r2 error) {
+	m.record("ReadNotification")
+	if m.ReadNotificationFunc != nil {
+		return m.ReadNotificationFunc(ctx)
+	}
+	return
+}
+
+// OrganizationsAPI is a mock of v1.OrganizationsAPI. A method returns the results of the
+// matching Func field, or zero values when the field is nil.
+type OrganizationsAPI struct {
+	recorder
+
+	GetMyOrganizationsFunc     func(ctx context.Context, excludesGuest bool) ([]*v1.Organization, *shared.Response, error)
+	GetOrganizationMembersFunc func(ctx context.Context, spaceKey string) (*v1.OrganizationMembers, *shared.Response, error)
+}
+
+var _ v1.OrganizationsAPI = (*OrganizationsAPI)(nil)
+
+// GetMyOrganizations records the call and calls GetMyOrganizationsFunc.
+func (m *OrganizationsAPI) GetMyOrganizations(ctx context.Context, excludesGuest bool) (r0 []*v1.Organization, r1 *shared.Response, r2 error) {
+	m.record("GetMyOrganizations", excludesGuest)
+	if m.GetMyOrganizationsFunc != nil {
+		return m.GetMyOrganizationsFunc(ctx, excludesGuest)
+	}
+	return
+}
+
+// GetOrganizationMembers records the call and calls GetOrganizationMembersFunc.
+func (m *OrganizationsAPI) GetOrganizationMembers(ctx context.Context, spaceKey string) (r0 *v1.OrganizationMembers, r1 *shared.Response, r2 error) {
+	m.record("GetOrganizationMembers", spaceKey)
+	if m.GetOrganizationMembersFunc != nil {
+		return m.GetOrganizationMembersFunc(ctx, spaceKey)
+	}
+	return
+}
+
+// StatusesAPI is a mock of v1.StatusesAPI. A method returns the results of the
+// matching Func field, or zero values when the field is nil.
+type StatusesAPI struct {
+	recorder
+
+	SaveUserStatusFunc func(ctx context.Context, spaceKey string, emoji string, opt *v1.SaveUserStatusOptions) (*v1.SaveUserStatusResult, *shared.Response, error)
+}
+
+var _ v1.StatusesAPI = (*StatusesAPI)(nil)
+
+// SaveUserStatus records the call and calls SaveUserStatusFunc.
+func (m *StatusesAPI) SaveUserStatus(ctx context.Context, spaceKey string, emoji string, opt *v1.SaveUserStatusOptions) (r0 *v1.SaveUserStatusResult, r1 *shared.Response, r2 error) {
+	m.record("SaveUserStatus", spaceKey, emoji, opt)
+	if m.SaveUserStatusFunc != nil {
+		return m.SaveUserStatusFunc(ctx, spaceKey, emoji, opt)
+	}
+	return
+}
+
+// TalksAPI is a mock of v1.TalksAPI. A method returns the results of the
+// matching Func field, or zero values when the field is nil.
+type TalksAPI struct {
+	recorder
+
+	CreateTalkFunc             func(ctx context.Context, topicID int, talkName string, postIds ...int) (*v1.CreatedTalkResult, *shared.Response, error)
+	UpdateTalkFunc             func(ctx context.Context, topicID int, talkID int, talkName string) (*v1.UpdatedTalkResult, *shared.Response, error)
+	DeleteTalkFunc             func(ctx context.Context, topicID int, talkID int) (*v1.DeletedTalkResult, *shared.Response, error)
+	GetTalkListFunc            func(ctx context.Context, topicID int) ([]*v1.Talk, *shared.Response, error)
+	GetMessagesInTalkFunc      func(ctx context.Context, topicID int, talkID int, opt *v1.GetMessagesOptions) (*v1.MessagesInTalk, *shared.Response, error)
+	AddMessagesToTalkFunc      func(ctx context.Context, topicID int, talkID int, postIds ...int) (*v1.MessagesInTalk, *shared.Response, error)
+	RemoveMessagesFromTalkFunc func(ctx context.Context, topicID int, talkID int, postIds ...int) (*v1.RemovedMessagesResult, *shared.Response, error)
+}
+
+var _ v1.TalksAPI = (*TalksAPI)(nil)
+
+// CreateTalk records the call and calls CreateTalkFunc.
+func (m *TalksAPI) CreateTalk(ctx context.Context, topicID int, talkName string, postIds ...int) (r0 *v1.CreatedTalkResult, r1 *shared.Response, r2 error) {
+	m.record("CreateTalk", topicID, talkName, postIds)
+	if m.CreateTalkFunc != nil {
+		return m.CreateTalkFunc(ctx, topicID, talkName, postIds...)
+	}
+	return
+}
+
+// UpdateTalk records the call and calls UpdateTalkFunc.
+func (m *TalksAPI) UpdateTalk(ctx context.Context, topicID int, talkID int, talkName string) (r0 *v1.UpdatedTalkResult, r1 *shared.Response, r2 error) {
+	m.record("UpdateTalk", topicID, talkID, talkName)
+	if m.UpdateTalkFunc != nil {
+		return m.UpdateTalkFunc(ctx, topicID, talkID, talkName)
+	}
+	return
+}
+
+// DeleteTalk records the call and calls DeleteTalkFunc.
+func (m *TalksAPI) DeleteTalk(ctx context.Context, topicID int, talkID int) (r0 *v1.DeletedTalkResult, r1 *shared.Response, r2 error) {
+	m.record("DeleteTalk", topicID, talkID)
+	if m.DeleteTalkFunc != nil {
+		return m.DeleteTalkFunc(ctx, topicID, talkID)
+	}
+	return
+}
+
+// GetTalkList records the call and calls GetTalkListFunc.
+func (m *TalksAPI) GetTalkList(ctx context.Context, topicID int) (r0 []*v1.Talk, r1 *shared.Response, r2 error) {
+	m.record("GetTalkList", topicID)
+	if m.GetTalkListFunc != nil {
+		return m.GetTalkListFunc(ctx, topicID)
+	}
+	return
+}
+
+// GetMessagesInTalk records the call and calls GetMessagesInTalkFunc.
+func (m *TalksAPI) GetMessagesInTalk(ctx context.Context, topicID int, talkID int, opt *v1.GetMessagesOptions) (r0 *v1.MessagesInTalk, r1 *shared.Response, r2 error) {
+	m.record("GetMessagesInTalk", topicID, talkID, opt)
+	if m.GetMessagesInTalkFunc != nil {
+		return m.GetMessagesInTalkFunc(ctx, topicID, talkID, opt)
+	}
+	return
+}
+
+// AddMessagesToTalk records the call and calls AddMessagesToTalkFunc.
+func (m *TalksAPI) AddMessagesToTalk(ctx context.Context, topicID int, talkID int, postIds ...int) (r0 *v1.MessagesInTalk, r1 *shared.Response, r2 error) {
+	m.record("AddMessagesToTalk", topicID, talkID, postIds)
+	if m.AddMessagesToTalkFunc != nil {
+		return m.AddMessagesToTalkFunc(ctx, topicID, talkID, postIds...)
+	}
+	return
+}
+
+// RemoveMessagesFromTalk records the call and calls RemoveMessagesFromTalkFunc.
+func (m *TalksAPI) RemoveMessagesFromTalk(ctx context.Context, topicID int, talkID int, postIds ...int) (r0 *v1.RemovedMessagesResult, r1 *shared.Response, r2 error) {
+	m.record("RemoveMessagesFromTalk", topicID, talkID, postIds)
+	if m.RemoveMessagesFromTalkFunc != nil {
+		return m.RemoveMessagesFromTalkFunc(ctx, topicID, talkID, postIds...)
+	}
+	return
+}
+
+// TopicsAPI is a mock of v1.TopicsAPI. A method returns the results of the
+// matching Func field, or zero values when the field is nil.
+type TopicsAPI struct {
+	recorder
+
+	CreateTopicFunc         func(ctx context.Context, opt *v1.CreateTopicOptions) (*v1.TopicDetails, *shared.Response, error)
+	UpdateTopicFunc         func(ctx context.Context, topicID int, opt *v1.UpdateTopicOptions) (*v1.TopicDetails, *shared.Response, error)
+	DeleteTopicFunc         func(ctx context.Context, topicID int) (*v1.Topic, *shared.Response, error)
+	GetTopicDetailsFunc     func(ctx context.Context, topicID int) (*v1.TopicDetails, *shared.Response, error)
+	GetTopicMessagesFunc    func(ctx context.Context, topicID int, opt *v1.GetTopicMessagesOptions) (*v1.TopicMessages, *shared.Response, error)
+	UpdateTopicMembersFunc  func(ctx context.Context, topicID int, opt *v1.UpdateTopicMembersOptions) (*v1.TopicDetails, *shared.Response, error)
+	FavoriteTopicFunc       func(ctx context.Context, topicID int) (*v1.FavoriteTopic, *shared.Response, error)
+	UnfavoriteTopicFunc     func(ctx context.Context, topicID int) (*v1.FavoriteTopic, *shared.Response, error)
+	ReadMessagesInTopicFunc func(ctx context.Context, topicID int, postID int) (*v1.Unread, *shared.Response, error)
+	GetMyTopicsFunc         func(ctx context.Context) ([]*v1.FavoriteTopicWithUnread, *shared.Response, error)
+}
+
+var _ v1.TopicsAPI = (*TopicsAPI)(nil)
+
+// CreateTopic records the call and calls CreateTopicFunc.
+func (m *TopicsAPI) CreateTopic(ctx context.Context, opt *v1.CreateTopicOptions) (r0 *v1.TopicDetails, r1 *shared.Response, r2 error) {
+	m.record("CreateTopic", opt)
+	if m.CreateTopicFunc != nil {
+		return m.CreateTopicFunc(ctx, opt)
+	}
+	return
+}
+
+// UpdateTopic records the call and calls UpdateTopicFunc.
+func (m *TopicsAPI) UpdateTopic(ctx context.Context, topicID int, opt *v1.UpdateTopicOptions) (r0 *v1.TopicDetails, r1 *shared.Response, r2 error) {
+	m.record("UpdateTopic", topicID, opt)
+	if m.UpdateTopicFunc != nil {
+		return m.UpdateTopicFunc(ctx, topicID, opt)
+	}
+	return
+}
+
+// DeleteTopic records the call and calls DeleteTopicFunc.
+func (m *TopicsAPI) DeleteTopic(ctx context.Context, topicID int) (r0 *v1.Topic, r1 *shared.Response, r2 error) {
+	m.record("DeleteTopic", topicID)
+	if m.DeleteTopicFunc != nil {
+		return m.DeleteTopicFunc(ctx, topicID)
+	}
+	return
+}
+
+// GetTopicDetails records the call and calls GetTopicDetailsFunc.
+func (m *TopicsAPI) GetTopicDetails(ctx context.Context, topicID int) (r0 *v1.TopicDetails, r1 *shared.Response, r2 error) {
+	m.record("GetTopicDetails", topicID)
+	if m.GetTopicDetailsFunc != nil {
+		return m.GetTopicDetailsFunc(ctx, topicID)
+	}
+	return
+}
+
+// GetTopicMessages records the call and calls GetTopicMessagesFunc.
+func (m *TopicsAPI) GetTopicMessages(ctx context.Context, topicID int, opt *v1.GetTopicMessagesOptions) (r0 *v1.TopicMessages, r1 *shared.Response, r2 error) {
+	m.record("GetTopicMessages", topicID, opt)
+	if m.GetTopicMessagesFunc != nil {
+		return m.GetTopicMessagesFunc(ctx, topicID, opt)
+	}
+	return
+}
+
+// UpdateTopicMembers records the call and calls UpdateTopicMembersFunc.
+func (m *TopicsAPI) UpdateTopicMembers(ctx context.Context, topicID int, opt *v1.UpdateTopicMembersOptions) (r0 *v1.TopicDetails, r1 *shared.Response, r2 error) {
+	m.record("UpdateTopicMembers", topicID, opt)
+	if m.UpdateTopicMembersFunc != nil {
+		return m.UpdateTopicMembersFunc(ctx, topicID, opt)
+	}
+	return
+}
+
+// FavoriteTopic records the call and calls FavoriteTopicFunc.
+func (m *TopicsAPI) FavoriteTopic(ctx context.Context, topicID int) (r0 *v1.FavoriteTopic, r1 *shared.Response, r2 error) {
+	m.record("FavoriteTopic", topicID)
+	if m.FavoriteTopicFunc != nil {
+		return m.FavoriteTopicFunc(ctx, topicID)
+	}
+	return
+}
+
+// UnfavoriteTopic records the call and calls UnfavoriteTopicFunc.
+func (m *TopicsAPI) UnfavoriteTopic(ctx context.Context, topicID int) (r0 *v1.FavoriteTopic, r1 *shared.Response, r2 error) {
+	m.record("UnfavoriteTopic", topicID)
+	if m.UnfavoriteTopicFunc != nil {
+		return m.UnfavoriteTopicFunc(ctx, topicID)
+	}
+	return
+}
+
+// ReadMessagesInTopic records the call and calls ReadMessagesInTopicFunc.
+func (m *TopicsAPI) ReadMessagesInTopic(ctx context.Context, topicID int, postID int) (r0 *v1.Unread, r1 *shared.Response, r2 error) {
+	m.record("ReadMessagesInTopic", topicID, postID)
+	if m.ReadMessagesInTopicFunc != nil {
+		return m.ReadMessagesInTopicFunc(ctx, topicID, postID)
+	}
+	return
+}
+
+// GetMyTopics records the call and calls GetMyTopicsFunc.
+func (m *TopicsAPI) GetMyTopics(ctx context.Context) (r0 []*v1.FavoriteTopicWithUnread, r1 *shared.Response, r2 error) {
+	m.record("GetMyTopics")
+	if m.GetMyTopicsFunc != nil {
+		return m.GetMyTopicsFunc(ctx)
+	}
+	return
+}
@@ -0,0 +1,46 @@
+package mocks
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/nulab/go-typetalk/typetalk/shared"
+	v1 "github.com/nulab/go-typetalk/v3/typetalk/v1"
+)
+
+func Test_Client_should_record_calls_and_return_programmed_results(t *testing.T) {
+	mocks := NewClient()
+	mocks.Messages.PostMessageFunc = func(ctx context.Context, topicID int, message string, opt *v1.PostMessageOptions) (*v1.PostedMessageResult, *shared.Response, error) {
+		return &v1.PostedMessageResult{Post: &v1.Post{ID: 10, TopicID: topicID, Message: message}}, nil, nil
+	}
+	mocks.Talks.CreateTalkFunc = func(ctx context.Context, topicID int, talkName string, postIds ...int) (*v1.CreatedTalkResult, *shared.Response, error) {
+		return nil, nil, errors.New("no talks")
+	}
+	client := mocks.Client().SetTypetalkToken("DUMMY_TOKEN")
+	ctx := context.Background()
+
+	posted, _, err := client.Messages.PostMessage(ctx, 1, "hello", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if posted.Post.ID != 10 || posted.Post.Message != "hello" {
+		t.Errorf("PostMessage: got %+v", posted.Post)
+	}
+	if _, _, err := client.Talks.CreateTalk(ctx, 1, "talk", 10, 11); err == nil {
+		t.Error("CreateTalk: want the programmed error")
+	}
+	if topics, _, err := client.Topics.GetMyTopics(ctx); topics != nil || err != nil {
+		t.Errorf("GetMyTopics without a Func: got %v, %v", topics, err)
+	}
+
+	want := []*Call{{Method: "PostMessage", Args: []interface{}{1, "hello", (*v1.PostMessageOptions)(nil)}}}
+	if got := mocks.Messages.Calls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Calls: got %+v, want %+v", got, want)
+	}
+	want = []*Call{{Method: "CreateTalk", Args: []interface{}{1, "talk", []int{10, 11}}}}
+	if got := mocks.Talks.CallsTo("CreateTalk"); !reflect.DeepEqual(got, want) {
+		t.Errorf("CallsTo: got %+v, want %+v", got, want)
+	}
+}
@@ -2,18 +2,33 @@ package v1
 
 import (
 	"context"
+	"time"
 
 	"github.com/nulab/go-typetalk/typetalk/shared"
 )
 
 type NotificationsService service
 
+// Teams is untyped, as no captured response shows an invitation to a team.
 type Invites struct {
-	Teams  []interface{} `json:"teams"`
-	Topics []*Topic      `json:"topics"`
+	Teams  []interface{}      `json:"teams"`
+	Topics []*TopicInvitation `json:"topics"`
+}
+
+// TopicInvitation represents an invitation of the user to a topic.
+type TopicInvitation struct {
+	ID        int        `json:"id"`
+	Topic     *Topic     `json:"topic"`
+	Sender    *Account   `json:"sender"`
+	Account   *Account   `json:"account"`
+	Message   string     `json:"message"`
+	CreatedAt *time.Time `json:"createdAt"`
+	UpdatedAt *time.Time `json:"updatedAt"`
 }
 
 type NotificationList struct {
+	RawJSON
+
 	Mentions []*Mention `json:"mentions"`
 	Invites  *Invites   `json:"invites"`
 }
@@ -23,6 +38,8 @@ type Access struct {
 }
 
 type NotificationCount struct {
+	RawJSON
+
 	Mention *struct {
 		Unread int `json:"unread"`
 	} `json:"mention"`
@@ -36,7 +53,10 @@ type NotificationCount struct {
 		} `json:"topic"`
 	} `json:"invite"`
 	Like *struct {
-		Receive interface{} `json:"receive"`
+		Receive *struct {
+			HasUnread  bool `json:"hasUnread"`
+			ReadLikeID int  `json:"readLikeId"`
+		} `json:"receive"`
 	} `json:"like"`
 	DirectMessage *struct {
 		UnreadTopics int `json:"unreadTopics"`
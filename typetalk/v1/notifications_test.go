@@ -31,6 +31,9 @@ func Test_NotificationsService_GetNotificationList_should_get_some_notifications
 	if !reflect.DeepEqual(result, want) {
 		t.Errorf("Returned result:\n result  %v,\n want %v", result, want)
 	}
+	if inv := result.Invites.Topics[0]; inv.Topic.Name != "Development" || inv.Sender.Name != "brad" || inv.Message != "It is a new project. Join us!" {
+		t.Errorf("Invites: got %+v", inv)
+	}
 }
 
 func Test_NotificationsService_GetNotificationCount_should_get_notification_count(t *testing.T) {
@@ -26,13 +26,20 @@ type Space struct {
 }
 
 type MyPlan struct {
-	Plan                *Plan       `json:"plan"`
-	Enabled             bool        `json:"enabled"`
-	Trial               interface{} `json:"trial"`
-	NumberOfUsers       int         `json:"numberOfUsers"`
-	TotalAttachmentSize int         `json:"totalAttachmentSize"`
-	CreatedAt           *time.Time  `json:"createdAt"`
-	UpdatedAt           *time.Time  `json:"updatedAt"`
+	Plan                *Plan      `json:"plan"`
+	Enabled             bool       `json:"enabled"`
+	Trial               *Trial     `json:"trial"`
+	NumberOfUsers       int        `json:"numberOfUsers"`
+	TotalAttachmentSize int        `json:"totalAttachmentSize"`
+	CreatedAt           *time.Time `json:"createdAt"`
+	UpdatedAt           *time.Time `json:"updatedAt"`
+}
+
+// Trial represents the trial period of a plan. EndDate is a date such as
+// "2017-01-04".
+type Trial struct {
+	EndDate  string `json:"endDate"`
+	DaysLeft int    `json:"daysLeft"`
 }
 
 type Plan struct {
@@ -53,6 +60,8 @@ type Group struct {
 }
 
 type OrganizationMembers struct {
+	RawJSON
+
 	Accounts []*Account `json:"accounts"`
 	Groups   []*struct {
 		Group       *Group `json:"group"`
@@ -34,6 +34,9 @@ func Test_OrganizationsService_GetMyOrganizations_should_get_my_organizations(t
 	if !reflect.DeepEqual(result, want.MySpaces) {
 		t.Errorf("Returned result:\n result  %v,\n want %v", result, want)
 	}
+	if trial := result[1].MyPlan.Trial; trial.EndDate != "2017-01-04" || trial.DaysLeft != 15 {
+		t.Errorf("Trial: got %+v", trial)
+	}
 }
 
 func Test_OrganizationsService_GetOrganizationMembers_should_get_some_organization_members(t *testing.T) {
@@ -11,6 +11,7 @@ import (
 
 type TalksService service
 
+// Backlog is untyped, as no captured response shows the issue of a talk.
 type Talk struct {
 	ID         int         `json:"id"`
 	TopicID    int         `json:"topicId"`
@@ -22,12 +23,16 @@ type Talk struct {
 }
 
 type CreatedTalkResult struct {
+	RawJSON
+
 	Topic   *Topic `json:"topic"`
 	Talk    *Talk  `json:"talk"`
 	PostIds []int  `json:"postIds"`
 }
 
 type UpdatedTalkResult struct {
+	RawJSON
+
 	Topic *Topic `json:"topic"`
 	Talk  *Talk  `json:"talk"`
 }
@@ -37,6 +42,8 @@ type DeletedTalkResult CreatedTalkResult
 type RemovedMessagesResult CreatedTalkResult
 
 type MessagesInTalk struct {
+	RawJSON
+
 	MySpace       *Organization  `json:"mySpace"`
 	Topic         *Topic         `json:"topic"`
 	DirectMessage *DirectMessage `json:"directMessage"`
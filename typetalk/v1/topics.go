@@ -22,7 +22,11 @@ type Topic struct {
 	UpdatedAt       *time.Time `json:"updatedAt"`
 }
 
+// Teams, AccountsForAPI, Integrations and RemainingInvitations are
+// untyped, as no captured response has a value for them.
 type TopicDetails struct {
+	RawJSON
+
 	Topic   *Topic        `json:"topic"`
 	MySpace *Organization `json:"mySpace"`
 	Teams   []interface{} `json:"teams"`
@@ -30,12 +34,29 @@ type TopicDetails struct {
 		Group       *Group `json:"group"`
 		MemberCount int    `json:"memberCount"`
 	} `json:"groups"`
-	Accounts             []*Account    `json:"accounts"`
-	InvitingAccounts     []interface{} `json:"invitingAccounts"`
-	Invites              []interface{} `json:"invites"`
-	AccountsForAPI       []interface{} `json:"accountsForApi"`
-	Integrations         []interface{} `json:"integrations"`
-	RemainingInvitations interface{}   `json:"remainingInvitations"`
+	Accounts             []*Account               `json:"accounts"`
+	InvitingAccounts     []*TopicMemberInvitation `json:"invitingAccounts"`
+	Invites              []*TopicMemberInvitation `json:"invites"`
+	AccountsForAPI       []interface{}            `json:"accountsForApi"`
+	Integrations         []interface{}            `json:"integrations"`
+	RemainingInvitations interface{}              `json:"remainingInvitations"`
+}
+
+// TopicMemberInvitation is an invitation to a topic that is not accepted
+// yet. The invitation of an account has Account set; the invitation of an
+// email address without an account only has MailAddress.
+type TopicMemberInvitation struct {
+	ID          int             `json:"id"`
+	Account     *InvitedAccount `json:"account"`
+	MailAddress string          `json:"mailAddress"`
+	Role        string          `json:"role"`
+}
+
+// InvitedAccount is the account of an invitation, with the email address
+// the invitation was sent to.
+type InvitedAccount struct {
+	Account
+	MailAddress string `json:"mailAddress"`
 }
 
 type Bookmark struct {
@@ -43,7 +64,10 @@ type Bookmark struct {
 	UpdatedAt time.Time `json:"updatedAt"`
 }
 
+// Team is untyped, as no captured response shows the team of a topic.
 type TopicMessages struct {
+	RawJSON
+
 	MySpace                *Organization `json:"mySpace"`
 	Team                   interface{}   `json:"team"`
 	Topic                  *Topic        `json:"topic"`
@@ -156,7 +180,7 @@ type UpdateTopicMembersOptions struct {
 	InvitationsRole                     []string `json:"invitations[%d].role,omitempty"`
 	RemoveAccountsID                    []int    `json:"removeAccounts[%d].id,omitempty"`
 	RemoveAccountsCancelSpaceInvitation []bool   `json:"removeAccounts[%d].cancelSpaceInvitation,omitempty"`
-	RemoveGroupIds                      []bool   `json:"removeGroupIds[%d],omitempty"`
+	RemoveGroupIds                      []int    `json:"removeGroupIds[%d],omitempty"`
 }
 
 // UpdateTopicMembers updates members in a topic.
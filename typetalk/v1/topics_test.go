@@ -137,6 +137,52 @@ func Test_TopicsService_GetTopicMessages_should_get_some_topic_messages(t *testi
 	if !reflect.DeepEqual(result, want) {
 		t.Errorf("Returned result:\n result  %v,\n want %v", result, want)
 	}
+	links := result.Posts[0].Links
+	if len(links) != 2 || links[0].Title != "Fun. Creative. Collaboration. | Nulab Inc." || links[0].Embed != nil {
+		t.Fatalf("Links: got %+v", links)
+	}
+	if embed := links[1].Embed; embed.ProviderName != "Speaker Deck" || embed.Version != "1" || embed.Width != 710 {
+		t.Errorf("Embed: got %+v", embed)
+	}
+}
+
+func Test_TopicsService_GetTopicMessages_should_keep_raw_json_and_report_unknown_fields(t *testing.T) {
+	setup()
+	defer teardown()
+	topicID := 1
+	b := `{"topic":{"id":1,"name":"go","color":"red"},"posts":[{"id":2,"pinned":true}],"hasNext":false,"cursor":"abc"}`
+	mux.HandleFunc(fmt.Sprintf("/topics/%d", topicID),
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, b)
+		})
+
+	var unknown []string
+	client.SetRawJSON(true).SetStrict(func(req *http.Request, fields []string) {
+		unknown = fields
+	})
+	result, _, err := client.Topics.GetTopicMessages(context.Background(), topicID, nil)
+	if err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+	if string(result.Raw) != b || result.Topic.Name != "go" {
+		t.Errorf("Raw: got %s", result.Raw)
+	}
+	if want := map[string]json.RawMessage{"cursor": json.RawMessage(`"abc"`)}; !reflect.DeepEqual(result.Extra, want) {
+		t.Errorf("Extra: got %s", result.Extra)
+	}
+	if want := []string{"cursor", "posts[].pinned", "topic.color"}; !reflect.DeepEqual(unknown, want) {
+		t.Errorf("Strict: got %v, want %v", unknown, want)
+	}
+
+	unknown = nil
+	client.SetRawJSON(false).SetStrict(nil)
+	result, _, err = client.Topics.GetTopicMessages(context.Background(), topicID, nil)
+	if err != nil {
+		t.Fatalf("Returned error: %v", err)
+	}
+	if result.Raw != nil || result.Extra != nil || unknown != nil {
+		t.Errorf("got %s, %s, %v without the options", result.Raw, result.Extra, unknown)
+	}
 }
 
 func Test_TopicsService_UpdateTopicMembers_should_add_some_topic_members(t *testing.T) {
@@ -154,7 +200,7 @@ func Test_TopicsService_UpdateTopicMembers_should_add_some_topic_members(t *test
 				"invitations[0].role":                     "Admin",
 				"removeAccounts[0].id":                    4,
 				"removeAccounts[0].cancelSpaceInvitation": true,
-				"removeGroupIds[0]":                       true,
+				"removeGroupIds[0]":                       2,
 			})
 			fmt.Fprint(w, string(b))
 		})
@@ -168,7 +214,7 @@ func Test_TopicsService_UpdateTopicMembers_should_add_some_topic_members(t *test
 		InvitationsRole:                     []string{"Admin"},
 		RemoveAccountsID:                    []int{4},
 		RemoveAccountsCancelSpaceInvitation: []bool{true},
-		RemoveGroupIds:                      []bool{true},
+		RemoveGroupIds:                      []int{2},
 	})
 	if err != nil {
 		t.Errorf("Returned error: %v", err)
@@ -1,9 +1,17 @@
 package v1
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/nulab/go-typetalk/v3/typetalk/auth"
+	"golang.org/x/oauth2"
 )
 
 var (
@@ -27,3 +35,104 @@ func setup() {
 func teardown() {
 	server.Close()
 }
+
+func Test_Client_SetCredentials_should_rotate_tokens_during_requests(t *testing.T) {
+	setup()
+	defer teardown()
+	tokens := map[string]bool{"DUMMY_TOKEN": true, "ROTATED": true, "": false}
+	mux.HandleFunc("/profile", func(w http.ResponseWriter, r *http.Request) {
+		if token := r.Header.Get("X-Typetalk-Token"); !tokens[token] && r.Header.Get("Authorization") != "Bearer ACCESS" {
+			t.Errorf("unexpected credentials: %v", r.Header)
+		}
+		fmt.Fprint(w, `{"account":{"id":1}}`)
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				if _, _, err := client.Accounts.GetMyProfile(context.Background()); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	for i := 0; i < 50; i++ {
+		switch i % 3 {
+		case 0:
+			client.SetTypetalkToken("ROTATED")
+		case 1:
+			client.SetCredentials(auth.TokenSource(oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "ACCESS"})))
+		default:
+			client.SetCredentials(auth.Static("DUMMY_TOKEN"))
+		}
+	}
+	wg.Wait()
+}
+
+func Test_Client_SetRawJSON_and_SetStrict_should_be_safe_during_requests(t *testing.T) {
+	setup()
+	defer teardown()
+	mux.HandleFunc("/profile", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"account":{"id":1},"new":true}`)
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				if _, _, err := client.Accounts.GetMyProfile(context.Background()); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	for i := 0; i < 50; i++ {
+		client.SetRawJSON(i%2 == 0).SetStrict(func(req *http.Request, unknown []string) {})
+		client.SetStrict(nil)
+	}
+	wg.Wait()
+	client.SetRawJSON(false)
+}
+
+func Test_Client_SetCredentials_should_return_the_error_of_the_provider(t *testing.T) {
+	setup()
+	defer teardown()
+	mux.HandleFunc("/profile", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the request is sent without credentials")
+	})
+	client.SetCredentials(auth.Env("GO_TYPETALK_TEST_MISSING"))
+	if _, _, err := client.Accounts.GetMyProfile(context.Background()); err == nil || !strings.Contains(err.Error(), "GO_TYPETALK_TEST_MISSING") {
+		t.Errorf("GetMyProfile: got %v", err)
+	}
+}
+
+func Test_Client_SetCredentials_should_send_the_token_of_each_topic(t *testing.T) {
+	setup()
+	defer teardown()
+	for _, id := range []int{1, 2} {
+		want := fmt.Sprintf("TOKEN%d", id)
+		mux.HandleFunc(fmt.Sprintf("/topics/%d", id), func(w http.ResponseWriter, r *http.Request) {
+			if got := r.Header.Get("X-Typetalk-Token"); got != want {
+				t.Errorf("token: got %q, want %q", got, want)
+			}
+			fmt.Fprint(w, `{"post":{"id":1}}`)
+		})
+	}
+	client.SetCredentials(auth.NewTopicTokens(map[int]string{1: "TOKEN1", 2: "TOKEN2"}))
+	ctx := context.Background()
+	for _, id := range []int{1, 2} {
+		if _, _, err := client.Messages.PostMessage(ctx, id, "hello", nil); err != nil {
+			t.Error(err)
+		}
+	}
+	if _, _, err := client.Messages.PostMessage(ctx, 3, "hello", nil); err == nil || err.Error() != "auth: no token is configured for the topic 3" {
+		t.Errorf("PostMessage to a topic without a token: got %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package v2
+
+import (
+	"context"
+
+	"github.com/nulab/go-typetalk/typetalk/shared"
+)
+
+//go:generate go run ../internal/mockgen -o mocks/mocks.go api.go
+
+// LikesAPI is the interface of LikesService.
+type LikesAPI interface {
+	GetLikesReceive(ctx context.Context, spaceKey string, opt *GetLikesOptions) ([]*ReceiveLikedPost, *shared.Response, error)
+	GetLikesGive(ctx context.Context, spaceKey string, opt *GetLikesOptions) ([]*GiveLikedPost, *shared.Response, error)
+	GetLikesDiscover(ctx context.Context, spaceKey string, opt *GetLikesOptions) ([]*DiscoverLikedPost, *shared.Response, error)
+	ReadReceivedLikes(ctx context.Context, spaceKey string, opt *ReadReceivedLikesOptions) (*ReadReceivedLikesResult, *shared.Response, error)
+}
+
+// MentionsAPI is the interface of MentionsService.
+type MentionsAPI interface {
+	GetMentionList(ctx context.Context, spaceKey string, opt *GetMentionListOptions) ([]*Mention, *shared.Response, error)
+}
+
+// MessagesAPI is the interface of MessagesService.
+type MessagesAPI interface {
+	GetDirectMessages(ctx context.Context, spaceKey, accountName string, opt *GetMessagesOptions) (*DirectMessages, *shared.Response, error)
+	PostDirectMessage(ctx context.Context, spaceKey, accountName, message string, opt *PostMessageOptions) (*PostedMessageResult, *shared.Response, error)
+	SearchMessages(ctx context.Context, spaceKey, q string, opt *SearchMessagesOptions) (*SearchMessagesResult, *shared.Response, error)
+	GetMyDirectMessageTopics(ctx context.Context, spaceKey string) ([]*DirectMessageTopic, *shared.Response, error)
+}
+
+// NotificationsAPI is the interface of NotificationsService.
+type NotificationsAPI interface {
+	GetNotificationCount(ctx context.Context) (*NotificationCount, *shared.Response, error)
+	ReadNotification(ctx context.Context) (*ReadNotificationResult, *shared.Response, error)
+}
+
+// TopicsAPI is the interface of TopicsService.
+type TopicsAPI interface {
+	GetMyTopics(ctx context.Context, spaceKey string) ([]*FavoriteTopicWithUnread, *shared.Response, error)
+}
+
+var (
+	_ LikesAPI         = (*LikesService)(nil)
+	_ MentionsAPI      = (*MentionsService)(nil)
+	_ MessagesAPI      = (*MessagesService)(nil)
+	_ NotificationsAPI = (*NotificationsService)(nil)
+	_ TopicsAPI        = (*TopicsService)(nil)
+)
@@ -2,6 +2,7 @@ package v2
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	"github.com/nulab/go-typetalk/v3/typetalk/internal"
@@ -18,12 +19,39 @@ type Post struct {
 	Message       string            `json:"message"`
 	Account       Account           `json:"account"`
 	Attachments   []*AttachmentFile `json:"attachments"`
-	Links         []interface{}     `json:"links"`
+	Links         []*Link           `json:"links"`
 	DirectMessage *DirectMessage    `json:"directMessage"`
 	CreatedAt     time.Time         `json:"createdAt"`
 	UpdatedAt     time.Time         `json:"updatedAt"`
 }
 
+type Link struct {
+	ID          int        `json:"id"`
+	URL         string     `json:"url"`
+	ContentType string     `json:"contentType"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	ImageURL    string     `json:"imageUrl"`
+	Embed       *LinkEmbed `json:"embed"`
+	CreatedAt   *time.Time `json:"createdAt"`
+	UpdatedAt   *time.Time `json:"updatedAt"`
+}
+
+type LinkEmbed struct {
+	Type         string      `json:"type"`
+	Version      json.Number `json:"version"`
+	ProviderName string      `json:"provider_name"`
+	ProviderURL  string      `json:"provider_url"`
+	Title        string      `json:"title"`
+	AuthorName   string      `json:"author_name"`
+	AuthorURL    string      `json:"author_url"`
+	HTML         string      `json:"html"`
+	URL          string      `json:"url"`
+	ThumbnailURL string      `json:"thumbnail_url"`
+	Width        int         `json:"width"`
+	Height       int         `json:"height"`
+}
+
 type Account struct {
 	ID         int        `json:"id"`
 	Name       string     `json:"name"`
@@ -56,6 +84,7 @@ type DirectMessage struct {
 	Status  *Status  `json:"status"`
 }
 
+// Web and Mobile are untyped, as no captured response shows their shape.
 type Status struct {
 	Presence *string     `json:"presence"`
 	Web      interface{} `json:"web"`
@@ -160,6 +189,8 @@ type readReceivedLikesOptions struct {
 }
 
 type ReadReceivedLikesResult struct {
+	RawJSON
+
 	Like struct {
 		Receive struct {
 			HasUnread  bool `json:"hasUnread"`
@@ -13,6 +13,8 @@ import (
 type MessagesService service
 
 type DirectMessages struct {
+	RawJSON
+
 	Topic         *Topic         `json:"topic"`
 	DirectMessage *DirectMessage `json:"directMessage"`
 	Bookmark      *Bookmark      `json:"bookmark"`
@@ -26,6 +28,8 @@ type Bookmark struct {
 }
 
 type SearchMessagesResult struct {
+	RawJSON
+
 	Count     int     `json:"count"`
 	Posts     []*Post `json:"posts"`
 	IsLimited bool    `json:"isLimited"`
@@ -54,6 +58,8 @@ type postMessageOptions struct {
 }
 
 type PostedMessageResult struct {
+	RawJSON
+
 	Space                  *Space         `json:"space"`
 	Topic                  *Topic         `json:"topic"`
 	Post                   *Post          `json:"post"`
@@ -0,0 +1,247 @@
+// Code generated by mockgen from api.go; DO NOT EDIT.
+
+// Package mocks provides mocks of the services of v2.Client, which record
+// their calls and return the results of programmable functions.
+package mocks
+
+import (
+	"context"
+	"sync"
+
+	"github.com/nulab/go-typetalk/typetalk/shared"
+	v2 "github.com/nulab/go-typetalk/v3/typetalk/v2"
+)
+
+// Call is a call made to a mock.
+type Call struct {
+	// Method is the name of the method.
+	Method string
+	// Args are the arguments of the call, without the context.
+	Args []interface{}
+}
+
+type recorder struct {
+	mu    sync.Mutex
+	calls []*Call
+}
+
+func (r *recorder) record(method string, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, &Call{Method: method, Args: args})
+}
+
+// Calls returns the calls made to the mock.
+func (r *recorder) Calls() []*Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*Call(nil), r.calls...)
+}
+
+// CallsTo returns the calls made to a method of the mock.
+func (r *recorder) CallsTo(method string) []*Call {
+	var calls []*Call
+	for _, c := range r.Calls() {
+		if c.Method == method {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// Client holds a mock of every service of v2.Client.
+type Client struct {
+	Topics        *TopicsAPI
+	Likes         *LikesAPI
+	Mentions      *MentionsAPI
+	Notifications *NotificationsAPI
+	Messages      *MessagesAPI
+}
+
+// NewClient returns a Client with a new mock of every service.
+func NewClient() *Client {
+	return &Client{
+		Topics:        &TopicsAPI{},
+		Likes:         &LikesAPI{},
+		Mentions:      &MentionsAPI{},
+		Notifications: &NotificationsAPI{},
+		Messages:      &MessagesAPI{},
+	}
+}
+
+// Client returns a v2.Client whose services are the mocks. It is made by
+// v2.NewClient, so its setters, such as SetTypetalkToken, can be called.
+func (c *Client) Client() *v2.Client {
+	client := v2.NewClient(nil)
+	client.Topics = c.Topics
+	client.Likes = c.Likes
+	client.Mentions = c.Mentions
+	client.Notifications = c.Notifications
+	client.Messages = c.Messages
+	return client
+}
+
+// LikesAPI is a mock of v2.LikesAPI. A method returns the results of the
+// matching Func field, or zero values when the field is nil.
+type LikesAPI struct {
+	recorder
+
+	GetLikesReceiveFunc   func(ctx context.Context, spaceKey string, opt *v2.GetLikesOptions) ([]*v2.ReceiveLikedPost, *shared.Response, error)
+	GetLikesGiveFunc      func(ctx context.Context, spaceKey string, opt *v2.GetLikesOptions) ([]*v2.GiveLikedPost, *shared.Response, error)
+	GetLikesDiscoverFunc  func(ctx context.Context, spaceKey string, opt *v2.GetLikesOptions) ([]*v2.DiscoverLikedPost, *shared.Response, error)
+	ReadReceivedLikesFunc func(ctx context.Context, spaceKey string, opt *v2.ReadReceivedLikesOptions) (*v2.ReadReceivedLikesResult, *shared.Response, error)
+}
+
+var _ v2.LikesAPI = (*LikesAPI)(nil)
+
+// GetLikesReceive records the call and calls GetLikesReceiveFunc.
+func (m *LikesAPI) GetLikesReceive(ctx context.Context, spaceKey string, opt *v2.GetLikesOptions) (r0 []*v2.ReceiveLikedPost, r1 *shared.Response, r2 error) {
+	m.record("GetLikesReceive", spaceKey, opt)
+	if m.GetLikesReceiveFunc != nil {
+		return m.GetLikesReceiveFunc(ctx, spaceKey, opt)
+	}
+	return
+}
+
+// GetLikesGive records the call and calls GetLikesGiveFunc.
+func (m *LikesAPI) GetLikesGive(ctx context.Context, spaceKey string, opt *v2.GetLikesOptions) (r0 []*v2.GiveLikedPost, r1 *shared.Response, r2 error) {
+	m.record("GetLikesGive", spaceKey, opt)
+	if m.GetLikesGiveFunc != nil {
+		return m.GetLikesGiveFunc(ctx, spaceKey, opt)
+	}
+	return
+}
+
+// GetLikesDiscover records the call and calls GetLikesDiscoverFunc.
+func (m *LikesAPI) GetLikesDiscover(ctx context.Context, spaceKey string, opt *v2.GetLikesOptions) (r0 []*v2.DiscoverLikedPost, r1 *shared.Response, r2 error) {
+	m.record("GetLikesDiscover", spaceKey, opt)
+	if m.GetLikesDiscoverFunc != nil {
+		return m.GetLikesDiscoverFunc(ctx, spaceKey, opt)
+	}
+	return
+}
+
+// ReadReceivedLikes records the call and calls ReadReceivedLikesFunc.
+func (m *LikesAPI) ReadReceivedLikes(ctx context.Context, spaceKey string, opt *v2.ReadReceivedLikesOptions) (r0 *v2.ReadReceivedLikesResult, r1 *shared.Response, r2 error) {
+	m.record("ReadReceivedLikes", spaceKey, opt)
+	if m.ReadReceivedLikesFunc != nil {
+		return m.ReadReceivedLikesFunc(ctx, spaceKey, opt)
+	}
+	return
+}
+
+// MentionsAPI is a mock of v2.MentionsAPI. A method returns the results of the
+// matching Func field, or zero values when the field is nil.
+type MentionsAPI struct {
+	recorder
+
+	GetMentionListFunc func(ctx context.Context, spaceKey string, opt *v2.GetMentionListOptions) ([]*v2.Mention, *shared.Response, error)
+}
+
+var _ v2.MentionsAPI = (*MentionsAPI)(nil)
+
+// GetMentionList records the call and calls GetMentionListFunc.
+func (m *MentionsAPI) GetMentionList(ctx context.Context, spaceKey string, opt *v2.GetMentionListOptions) (r0 []*v2.Mention, r1 *shared.Response, r2 error) {
+	m.record("GetMentionList", spaceKey, opt)
+	if m.GetMentionListFunc != nil {
+		return m.GetMentionListFunc(ctx, spaceKey, opt)
+	}
+	return
+}
+
+// MessagesAPI is a mock of v2.MessagesAPI. A method returns the results of the
+// matching Func field, or zero values when the field is nil.
+type MessagesAPI struct {
+	recorder
+
+	GetDirectMessagesFunc        func(ctx context.Context, spaceKey string, accountName string, opt *v2.GetMessagesOptions) (*v2.DirectMessages, *shared.Response, error)
+	PostDirectMessageFunc        func(ctx context.Context, spaceKey string, accountName string, message string, opt *v2.PostMessageOptions) (*v2.PostedMessageResult, *shared.Response, error)
+	SearchMessagesFunc           func(ctx context.Context, spaceKey string, q string, opt *v2.SearchMessagesOptions) (*v2.SearchMessagesResult, *shared.Response, error)
+	GetMyDirectMessageTopicsFunc func(ctx context.Context, spaceKey string) ([]*v2.DirectMessageTopic, *shared.Response, error)
+}
+
+var _ v2.MessagesAPI = (*MessagesAPI)(nil)
+
+// GetDirectMessages records the call and calls GetDirectMessagesFunc.
+func (m *MessagesAPI) GetDirectMessages(ctx context.Context, spaceKey string, accountName string, opt *v2.GetMessagesOptions) (r0 *v2.DirectMessages, r1 *shared.Response, r2 error) {
+	m.record("GetDirectMessages", spaceKey, accountName, opt)
+	if m.GetDirectMessagesFunc != nil {
+		return m.GetDirectMessagesFunc(ctx, spaceKey, accountName, opt)
+	}
+	return
+}
+
+// PostDirectMessage records the call and calls PostDirectMessageFunc.
+func (m *MessagesAPI) PostDirectMessage(ctx context.Context, spaceKey string, accountName string, message string, opt *v2.PostMessageOptions) (r0 *v2.PostedMessageResult, r1 *shared.Response, r2 error) {
+	m.record("PostDirectMessage", spaceKey, accountName, message, opt)
+	if m.PostDirectMessageFunc != nil {
+		return m.PostDirectMessageFunc(ctx, spaceKey, accountName, message, opt)
+	}
+	return
+}
+
+// SearchMessages records the call and calls SearchMessagesFunc.
+func (m *MessagesAPI) SearchMessages(ctx context.Context, spaceKey string, q string, opt *v2.SearchMessagesOptions) (r0 *v2.SearchMessagesResult, r1 *shared.Response, r2 error) {
+	m.record("SearchMessages", spaceKey, q, opt)
+	if m.SearchMessagesFunc != nil {
+		return m.SearchMessagesFunc(ctx, spaceKey, q, opt)
+	}
+	return
+}
+
+// GetMyDirectMessageTopics records the call and calls GetMyDirectMessageTopicsFunc.
+func (m *MessagesAPI) GetMyDirectMessageTopics(ctx context.Context, spaceKey string) (r0 []*v2.DirectMessageTopic, r1 *shared.Response, r2 error) {
+	m.record("GetMyDirectMessageTopics", spaceKey)
+	if m.GetMyDirectMessageTopicsFunc != nil {
+		return m.GetMyDirectMessageTopicsFunc(ctx, spaceKey)
+	}
+	return
+}
+
+// NotificationsAPI is a mock of v2.NotificationsAPI. A method returns the results of the
+// matching Func field, or zero values when the field is nil.
+type NotificationsAPI struct {
+	recorder
+
+	GetNotificationCountFunc func(ctx context.Context) (*v2.NotificationCount, *shared.Response, error)
+	ReadNotificationFunc     func(ctx context.Context) (*v2.ReadNotificationResult, *shared.Response, error)
+}
+
+var _ v2.NotificationsAPI = (*NotificationsAPI)(nil)
+
+// GetNotificationCount records the call and calls GetNotificationCountFunc.
+func (m *NotificationsAPI) GetNotificationCount(ctx context.Context) (r0 *v2.NotificationCount, r1 *shared.Response, r2 error) {
+	m.record("GetNotificationCount")
+	if m.GetNotificationCountFunc != nil {
+		return m.GetNotificationCountFunc(ctx)
+	}
+	return
+}
+
+// ReadNotification records the call and calls ReadNotificationFunc.
+func (m *NotificationsAPI) ReadNotification(ctx context.Context) (r0 *v2.ReadNotificationResult, r1 *shared.Response, r2 error) {
+	m.record("ReadNotification")
+	if m.ReadNotificationFunc != nil {
+		return m.ReadNotificationFunc(ctx)
+	}
+	return
+}
+
+// TopicsAPI is a mock of v2.TopicsAPI. A method returns the results of the
+// matching Func field, or zero values when the field is nil.
+type TopicsAPI struct {
+	recorder
+
+	GetMyTopicsFunc func(ctx context.Context, spaceKey string) ([]*v2.FavoriteTopicWithUnread, *shared.Response, error)
+}
+
+var _ v2.TopicsAPI = (*TopicsAPI)(nil)
+
+// GetMyTopics records the call and calls GetMyTopicsFunc.
+func (m *TopicsAPI) GetMyTopics(ctx context.Context, spaceKey string) (r0 []*v2.FavoriteTopicWithUnread, r1 *shared.Response, r2 error) {
+	m.record("GetMyTopics", spaceKey)
+	if m.GetMyTopicsFunc != nil {
+		return m.GetMyTopicsFunc(ctx, spaceKey)
+	}
+	return
+}
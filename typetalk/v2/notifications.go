@@ -11,6 +11,8 @@ import (
 type NotificationsService service
 
 type ReadNotificationResult struct {
+	RawJSON
+
 	Space  *Space  `json:"space"`
 	Access *Access `json:"access"`
 }
@@ -28,6 +30,8 @@ type Access struct {
 }
 
 type NotificationCount struct {
+	RawJSON
+
 	Statuses []*struct {
 		MySpace *MySpace `json:"mySpace"`
 		Space   *Space   `json:"space"`
@@ -54,13 +58,18 @@ type MySpace struct {
 }
 
 type MyPlan struct {
-	Plan                *Plan       `json:"plan"`
-	Enabled             bool        `json:"enabled"`
-	Trial               interface{} `json:"trial"`
-	NumberOfUsers       int         `json:"numberOfUsers"`
-	TotalAttachmentSize int         `json:"totalAttachmentSize"`
-	CreatedAt           *time.Time  `json:"createdAt"`
-	UpdatedAt           *time.Time  `json:"updatedAt"`
+	Plan                *Plan      `json:"plan"`
+	Enabled             bool       `json:"enabled"`
+	Trial               *Trial     `json:"trial"`
+	NumberOfUsers       int        `json:"numberOfUsers"`
+	TotalAttachmentSize int        `json:"totalAttachmentSize"`
+	CreatedAt           *time.Time `json:"createdAt"`
+	UpdatedAt           *time.Time `json:"updatedAt"`
+}
+
+type Trial struct {
+	EndDate  string `json:"endDate"`
+	DaysLeft int    `json:"daysLeft"`
 }
 
 type Plan struct {
@@ -76,6 +85,7 @@ type DoNotDisturb struct {
 	Scheduled    *Scheduled `json:"scheduled"`
 }
 
+// RemainingTimeInMinutes is untyped, as no captured response has a value.
 type Manual struct {
 	RemainingTimeInMinutes interface{} `json:"remainingTimeInMinutes"`
 }
@@ -31,6 +31,9 @@ func Test_NotificationsService_GetNotificationCount_should_get_notification_coun
 	if !reflect.DeepEqual(result, want) {
 		t.Errorf("Returned result:\n result  %v,\n want %v", result, want)
 	}
+	if trial := result.Statuses[1].MySpace.MyPlan.Trial; trial.EndDate != "2017-03-02" || trial.DaysLeft != -375 {
+		t.Errorf("Trial: got %+v", trial)
+	}
 }
 
 func Test_NotificationsService_GetNotificationCount_errorResponse(t *testing.T) {
@@ -0,0 +1,24 @@
+package v3
+
+import (
+	"context"
+
+	"github.com/nulab/go-typetalk/typetalk/shared"
+)
+
+//go:generate go run ../internal/mockgen -o mocks/mocks.go api.go
+
+// AccountsAPI is the interface of AccountsService.
+type AccountsAPI interface {
+	GetMyFriends(ctx context.Context, spaceKey, q string, opt *GetMyFriendsOptions) ([]*Account, *shared.Response, error)
+}
+
+// NotificationsAPI is the interface of NotificationsService.
+type NotificationsAPI interface {
+	ReadNotification(ctx context.Context, spaceKey string) (*ReadNotificationResult, *shared.Response, error)
+}
+
+var (
+	_ AccountsAPI      = (*AccountsService)(nil)
+	_ NotificationsAPI = (*NotificationsService)(nil)
+)
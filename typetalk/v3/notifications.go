@@ -9,6 +9,8 @@ import (
 type NotificationsService service
 
 type ReadNotificationResult struct {
+	RawJSON
+
 	Space  *Space  `json:"space"`
 	Access *Access `json:"access"`
 }
@@ -21,6 +21,7 @@ type Account struct {
 	UpdatedAt  *time.Time `json:"updatedAt"`
 }
 
+// Web and Mobile are untyped, as no captured response shows their shape.
 type Status struct {
 	Presence *string     `json:"presence"`
 	Web      interface{} `json:"web"`
@@ -33,6 +34,8 @@ type AccountStatus struct {
 }
 
 type Friends struct {
+	RawJSON
+
 	Count    int              `json:"count"`
 	Accounts []*AccountStatus `json:"accounts"`
 }
@@ -0,0 +1,16 @@
+package v4
+
+import (
+	"context"
+
+	"github.com/nulab/go-typetalk/typetalk/shared"
+)
+
+//go:generate go run ../internal/mockgen -o mocks/mocks.go api.go
+
+// AccountsAPI is the interface of AccountsService.
+type AccountsAPI interface {
+	GetMyFriends(ctx context.Context, spaceKey, q string, opt *GetMyFriendsOptions) (*Friends, *shared.Response, error)
+}
+
+var _ AccountsAPI = (*AccountsService)(nil)
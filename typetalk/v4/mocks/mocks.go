@@ -0,0 +1,89 @@
+// Code generated by mockgen from api.go; DO NOT EDIT.
+
+// Package mocks provides mocks of the services of v4.Client, which record
+// their calls and return the results of programmable functions.
+package mocks
+
+import (
+	"context"
+	"sync"
+
+	"github.com/nulab/go-typetalk/typetalk/shared"
+	v4 "github.com/nulab/go-typetalk/v3/typetalk/v4"
+)
+
+// Call is a call made to a mock.
+type Call struct {
+	// Method is the name of the method.
+	Method string
+	// Args are the arguments of the call, without the context.
+	Args []interface{}
+}
+
+type recorder struct {
+	mu    sync.Mutex
+	calls []*Call
+}
+
+func (r *recorder) record(method string, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, &Call{Method: method, Args: args})
+}
+
+// Calls returns the calls made to the mock.
+func (r *recorder) Calls() []*Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*Call(nil), r.calls...)
+}
+
+// CallsTo returns the calls made to a method of the mock.
+func (r *recorder) CallsTo(method string) []*Call {
+	var calls []*Call
+	for _, c := range r.Calls() {
+		if c.Method == method {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// Client holds a mock of every service of v4.Client.
+type Client struct {
+	Accounts *AccountsAPI
+}
+
+// NewClient returns a Client with a new mock of every service.
+func NewClient() *Client {
+	return &Client{
+		Accounts: &AccountsAPI{},
+	}
+}
+
+// Client returns a v4.Client whose services are the mocks. It is made by
+// v4.NewClient, so its setters, such as SetTypetalkToken, can be called.
+func (c *Client) Client() *v4.Client {
+	client := v4.NewClient(nil)
+	client.Accounts = c.Accounts
+	return client
+}
+
+// AccountsAPI is a mock of v4.AccountsAPI. A method returns the results of the
+// matching Func field, or zero values when the field is nil.
+type AccountsAPI struct {
+	recorder
+
+	GetMyFriendsFunc func(ctx context.Context, spaceKey string, q string, opt *v4.GetMyFriendsOptions) (*v4.Friends, *shared.Response, error)
+}
+
+var _ v4.AccountsAPI = (*AccountsAPI)(nil)
+
+// GetMyFriends records the call and calls GetMyFriendsFunc.
+func (m *AccountsAPI) GetMyFriends(ctx context.Context, spaceKey string, q string, opt *v4.GetMyFriendsOptions) (r0 *v4.Friends, r1 *shared.Response, r2 error) {
+	m.record("GetMyFriends", spaceKey, q, opt)
+	if m.GetMyFriendsFunc != nil {
+		return m.GetMyFriendsFunc(ctx, spaceKey, q, opt)
+	}
+	return
+}
@@ -0,0 +1,16 @@
+package v5
+
+import (
+	"context"
+
+	"github.com/nulab/go-typetalk/typetalk/shared"
+)
+
+//go:generate go run ../internal/mockgen -o mocks/mocks.go api.go
+
+// NotificationsAPI is the interface of NotificationsService.
+type NotificationsAPI interface {
+	GetNotificationCount(ctx context.Context) (*NotificationCount, *shared.Response, error)
+}
+
+var _ NotificationsAPI = (*NotificationsService)(nil)
@@ -0,0 +1,89 @@
+// Code generated by mockgen from api.go; DO NOT EDIT.
+
+// Package mocks provides mocks of the services of v5.Client, which record
+// their calls and return the results of programmable functions.
+package mocks
+
+import (
+	"context"
+	"sync"
+
+	"github.com/nulab/go-typetalk/typetalk/shared"
+	v5 "github.com/nulab/go-typetalk/v3/typetalk/v5"
+)
+
+// Call is a call made to a mock.
+type Call struct {
+	// Method is the name of the method.
+	Method string
+	// Args are the arguments of the call, without the context.
+	Args []interface{}
+}
+
+type recorder struct {
+	mu    sync.Mutex
+	calls []*Call
+}
+
+func (r *recorder) record(method string, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, &Call{Method: method, Args: args})
+}
+
+// Calls returns the calls made to the mock.
+func (r *recorder) Calls() []*Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*Call(nil), r.calls...)
+}
+
+// CallsTo returns the calls made to a method of the mock.
+func (r *recorder) CallsTo(method string) []*Call {
+	var calls []*Call
+	for _, c := range r.Calls() {
+		if c.Method == method {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// Client holds a mock of every service of v5.Client.
+type Client struct {
+	Notifications *NotificationsAPI
+}
+
+// NewClient returns a Client with a new mock of every service.
+func NewClient() *Client {
+	return &Client{
+		Notifications: &NotificationsAPI{},
+	}
+}
+
+// Client returns a v5.Client whose services are the mocks. It is made by
+// v5.NewClient, so its setters, such as SetTypetalkToken, can be called.
+func (c *Client) Client() *v5.Client {
+	client := v5.NewClient(nil)
+	client.Notifications = c.Notifications
+	return client
+}
+
+// NotificationsAPI is a mock of v5.NotificationsAPI. A method returns the results of the
+// matching Func field, or zero values when the field is nil.
+type NotificationsAPI struct {
+	recorder
+
+	GetNotificationCountFunc func(ctx context.Context) (*v5.NotificationCount, *shared.Response, error)
+}
+
+var _ v5.NotificationsAPI = (*NotificationsAPI)(nil)
+
+// GetNotificationCount records the call and calls GetNotificationCountFunc.
+func (m *NotificationsAPI) GetNotificationCount(ctx context.Context) (r0 *v5.NotificationCount, r1 *shared.Response, r2 error) {
+	m.record("GetNotificationCount")
+	if m.GetNotificationCountFunc != nil {
+		return m.GetNotificationCountFunc(ctx)
+	}
+	return
+}
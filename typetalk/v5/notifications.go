@@ -11,6 +11,8 @@ import (
 type NotificationsService service
 
 type NotificationCount struct {
+	RawJSON
+
 	Statuses []*struct {
 		MySpace *MySpace `json:"mySpace"`
 		Access  *Access  `json:"access"`
@@ -47,14 +49,14 @@ type Space struct {
 }
 
 type MyPlan struct {
-	Plan                     *Plan       `json:"plan"`
-	Enabled                  bool        `json:"enabled"`
-	Trial                    interface{} `json:"trial"`
-	NumberOfUsers            int         `json:"numberOfUsers"`
-	NumberOfAllowedAddresses int         `json:"numberOfAllowedAddresses"`
-	TotalAttachmentSize      int         `json:"totalAttachmentSize"`
-	CreatedAt                *time.Time  `json:"createdAt"`
-	UpdatedAt                *time.Time  `json:"updatedAt"`
+	Plan                     *Plan      `json:"plan"`
+	Enabled                  bool       `json:"enabled"`
+	Trial                    *Trial     `json:"trial"`
+	NumberOfUsers            int        `json:"numberOfUsers"`
+	NumberOfAllowedAddresses int        `json:"numberOfAllowedAddresses"`
+	TotalAttachmentSize      int        `json:"totalAttachmentSize"`
+	CreatedAt                *time.Time `json:"createdAt"`
+	UpdatedAt                *time.Time `json:"updatedAt"`
 }
 
 type Access struct {
@@ -62,6 +64,11 @@ type Access struct {
 	UnopenedExcludeDM int `json:"unopenedExcludeDM"`
 }
 
+type Trial struct {
+	EndDate  string `json:"endDate"`
+	DaysLeft int    `json:"daysLeft"`
+}
+
 type Plan struct {
 	Key                           string `json:"key"`
 	Name                          string `json:"name"`
@@ -76,6 +83,7 @@ type DoNotDisturb struct {
 	Scheduled    *Scheduled `json:"scheduled"`
 }
 
+// RemainingTimeInMinutes is untyped, as no captured response has a value.
 type Manual struct {
 	RemainingTimeInMinutes interface{} `json:"remainingTimeInMinutes"`
 }
@@ -1,9 +1,11 @@
 package v5
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/url"
 
+	"github.com/nulab/go-typetalk/v3/typetalk/auth"
 	"github.com/nulab/go-typetalk/v3/typetalk/internal"
 )
 
@@ -11,6 +13,17 @@ const (
 	APIVersion = "v5"
 )
 
+// RawJSON is embedded in the results to hold their raw JSON in Raw, and the
+// keys that their fields do not represent in Extra. It is only set by a
+// client with SetRawJSON(true), on every value that embeds it wherever it is
+// in the response. The values that a method returns from inside the
+// response, such as the elements of a list, don't embed it, so they keep
+// no raw JSON.
+type RawJSON struct {
+	Raw   json.RawMessage            `json:"-"`
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
 type service struct {
 	client *internal.ClientCore
 }
@@ -18,11 +31,33 @@ type service struct {
 type Client struct {
 	client *internal.ClientCore
 
-	Notifications *NotificationsService
+	Notifications NotificationsAPI
 }
 
 func (c *Client) SetTypetalkToken(token string) *Client {
-	c.client.TypetalkToken = token
+	c.client.SetTypetalkToken(token)
+	return c
+}
+
+// SetCredentials makes the client get the credentials of every request from
+// p, such as a token rotated from a file. It is safe to call while requests
+// are in flight.
+func (c *Client) SetCredentials(p auth.CredentialsProvider) *Client {
+	c.client.SetCredentials(p)
+	return c
+}
+
+// SetRawJSON makes the results keep their raw JSON and unknown keys.
+func (c *Client) SetRawJSON(retain bool) *Client {
+	c.client.SetRawJSON(retain)
+	return c
+}
+
+// SetStrict makes the client pass the paths of the unknown fields of every
+// response, such as "posts[].account.nickname", to hook. A nil hook turns
+// it off.
+func (c *Client) SetStrict(hook func(req *http.Request, unknown []string)) *Client {
+	c.client.SetStrict(hook)
 	return c
 }
 